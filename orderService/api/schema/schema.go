@@ -0,0 +1,10 @@
+// Package schema publishes the contract of the Orders Kafka topic
+package schema
+
+import "embed"
+
+// Files holds the JSON Schema (order.v<version>.json) and the Avro schema (order.v<version>.avsc)
+// of every order message version, the Protobuf definition lives in api/proto/order
+//
+//go:embed order.v*.json order.v*.avsc
+var Files embed.FS
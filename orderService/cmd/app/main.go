@@ -16,6 +16,14 @@ import (
 
 // @host 	localhost:8080
 // @BasePath /api
+
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
 func main() {
 	signals := []os.Signal{
 		syscall.SIGINT, syscall.SIGTERM, syscall.SIGKILL, syscall.SIGQUIT,
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"log"
+	"orderService/configs"
+	"orderService/internal/export"
+	"orderService/internal/models"
+	"orderService/internal/repository"
+	"orderService/pkg/db"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// chunkSize is the number of orders loaded with their associations at once
+const chunkSize = 1000
+
+// Exports orders in the same formats as GET /orders/export without masking personal data.
+// The database is configured with the same DB_* variables as the service
+func main() {
+	output := flag.String("output", "", "output file, stdout by default")
+	formatFlag := flag.String("format", string(export.FormatCSV), "csv, ndjson or parquet")
+	from := flag.String("from", "", "orders created at or after, RFC3339 time or YYYY-MM-DD")
+	to := flag.String("to", "", "orders created before, RFC3339 time or YYYY-MM-DD")
+	customerID := flag.String("customer-id", "", "only orders of the customer")
+	flag.Parse()
+
+	format, err := export.ParseFormat(*formatFlag)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	filter, err := models.ParseOrderFilter(*from, *to, *customerID)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	exported, err := run(ctx, *output, format, filter)
+	if err != nil {
+		log.Fatalf("Export failed after %d orders: %s", exported, err.Error())
+	}
+	log.Printf("Exported %d orders\n", exported)
+}
+
+func run(ctx context.Context, output string, format export.Format, filter models.OrderFilter) (int, error) {
+	cnf, err := configs.NewParsedDatabaseConfig()
+	if err != nil {
+		return 0, err
+	}
+
+	database, err := db.Connect(cnf)
+	if err != nil {
+		return 0, err
+	}
+	// The query log goes to stdout and would corrupt the export written there
+	database = database.Session(&gorm.Session{Logger: logger.Default.LogMode(logger.Silent)})
+
+	file := os.Stdout
+	if output != "" {
+		file, err = os.Create(output)
+		if err != nil {
+			return 0, err
+		}
+		defer file.Close()
+	}
+	buffered := bufio.NewWriter(file)
+
+	exported := 0
+	writer := export.NewWriter(format, buffered)
+	err = repository.NewRepository(database).StreamOrders(ctx, filter, chunkSize, func(orders []models.Order) error {
+		exported += len(orders)
+		return writer.Write(orders)
+	})
+	if err != nil {
+		return exported, err
+	}
+	if err = writer.Close(); err != nil {
+		return exported, err
+	}
+	return exported, buffered.Flush()
+}
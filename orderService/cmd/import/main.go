@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"orderService/configs"
+	"orderService/internal/importer"
+	"orderService/internal/repository"
+	"orderService/pkg/db"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// Imports historical orders from NDJSON or flattened CSV files bypassing Kafka.
+// The database is configured with the same DB_* variables as the service
+func main() {
+	file := flag.String("file", "", "NDJSON or CSV file with orders")
+	format := flag.String("format", "", "ndjson or csv, detected from the file extension by default")
+	batchSize := flag.Int("batch-size", 500, "orders inserted in one transaction")
+	dryRun := flag.Bool("dry-run", false, "validate and look up duplicates without inserting")
+	checkpoint := flag.String("checkpoint", "", "checkpoint file, <file>.checkpoint by default")
+	errorsFile := flag.String("errors", "", "error report file, <file>.errors.ndjson by default")
+	flag.Parse()
+
+	if *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *checkpoint == "" {
+		*checkpoint = *file + ".checkpoint"
+	}
+	if *errorsFile == "" {
+		*errorsFile = *file + ".errors.ndjson"
+	}
+	if *dryRun {
+		*checkpoint = ""
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	summary, err := run(ctx, *file, *format, *errorsFile, importer.Options{
+		BatchSize:      *batchSize,
+		DryRun:         *dryRun,
+		CheckpointFile: *checkpoint,
+	})
+	log.Printf("Import summary: %s\n", summary)
+	if errors.Is(err, context.Canceled) {
+		log.Printf("Import interrupted, run again to resume from %s\n", *checkpoint)
+		os.Exit(1)
+	}
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+}
+
+func run(ctx context.Context, file, format, errorsFile string, options importer.Options) (importer.Summary, error) {
+	cnf, err := configs.NewParsedDatabaseConfig()
+	if err != nil {
+		return importer.Summary{}, err
+	}
+
+	gorm, err := db.Connect(cnf)
+	if err != nil {
+		return importer.Summary{}, err
+	}
+
+	input, err := os.Open(file)
+	if err != nil {
+		return importer.Summary{}, err
+	}
+	defer input.Close()
+
+	reader, err := newReader(input, file, format)
+	if err != nil {
+		return importer.Summary{}, err
+	}
+
+	checkpoint, err := importer.LoadCheckpoint(options.CheckpointFile)
+	if err != nil {
+		return importer.Summary{}, err
+	}
+	report, err := importer.OpenReport(errorsFile, checkpoint)
+	if err != nil {
+		return importer.Summary{}, err
+	}
+	defer report.Close()
+
+	return importer.NewImporter(repository.NewRepository(gorm), options, report).Run(ctx, reader)
+}
+
+func newReader(input *os.File, file, format string) (importer.Reader, error) {
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(file)), ".")
+	}
+
+	switch format {
+	case "csv":
+		return importer.NewCSVReader(input)
+	case "ndjson", "jsonl", "json":
+		return importer.NewNDJSONReader(input), nil
+	default:
+		return nil, fmt.Errorf("unknown format %q, use ndjson or csv", format)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"orderService/configs"
+	"orderService/internal/cache"
+	"orderService/internal/events"
+	eventHandler "orderService/internal/kafka"
+	"orderService/internal/replay"
+	"orderService/internal/repository"
+	"orderService/internal/service"
+	"orderService/pkg/db"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Replays a range of a Kafka partition, or of the dead-letter topic, through the same validation and
+// OrderService.Create as the consumer. Kafka and the database are configured with the KAFKA_* and DB_* variables
+// of the service, the consumer group of the service is left untouched
+func main() {
+	topic := flag.String("topic", "", "topic to replay, the first of KAFKA_TOPICS by default")
+	deadLetter := flag.Bool("dead-letter", false, "replay KAFKA_DEAD_LETTER_TOPIC")
+	partition := flag.Int("partition", 0, "partition to replay")
+	fromOffset := flag.Int64("from-offset", -1, "first offset, the earliest retained message by default")
+	toOffset := flag.Int64("to-offset", -1, "offset to stop before, the high watermark by default")
+	from := flag.String("from", "", "replay messages produced at or after this RFC 3339 time")
+	to := flag.String("to", "", "replay messages produced before this RFC 3339 time")
+	dryRun := flag.Bool("dry-run", false, "decode, validate and look up the orders without creating them")
+	reportFile := flag.String("report", "", "file with the outcome of every message as JSON lines, stdout by default")
+	idle := flag.Duration("idle", 10*time.Second, "fail when no message arrives for this long before the end of the range")
+	flag.Parse()
+
+	rng, err := newRange(*topic, *partition, *fromOffset, *toOffset, *from, *to)
+	if err != nil {
+		log.Println(err.Error())
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	summary, err := run(ctx, rng, *deadLetter, *reportFile, replay.Options{DryRun: *dryRun, Idle: *idle})
+	if *dryRun {
+		log.Printf("Replay summary (dry run, nothing was created): %s\n", summary)
+	} else {
+		log.Printf("Replay summary: %s\n", summary)
+	}
+	if errors.Is(err, context.Canceled) {
+		log.Println("Replay interrupted, orders replayed so far are kept and are existing when replayed again")
+		os.Exit(1)
+	}
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	if summary.Failed > 0 {
+		log.Printf("%d messages failed, replay the range again once the cause is fixed\n", summary.Failed)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, rng replay.Range, deadLetter bool, reportFile string, options replay.Options) (replay.Summary, error) {
+	kafkaConfig, err := configs.NewParsedKafkaConfig()
+	if err != nil {
+		return replay.Summary{}, err
+	}
+	switch {
+	case deadLetter && kafkaConfig.DeadLetterTopic == "":
+		return replay.Summary{}, errors.New("-dead-letter requires KAFKA_DEAD_LETTER_TOPIC")
+	case deadLetter:
+		rng.Topic = kafkaConfig.DeadLetterTopic
+	case rng.Topic == "":
+		rng.Topic = kafkaConfig.Topics[0]
+	}
+
+	decoder, err := eventHandler.NewDecoder(kafkaConfig)
+	if err != nil {
+		return replay.Summary{}, err
+	}
+
+	dbConfig, err := configs.NewParsedDatabaseConfig()
+	if err != nil {
+		return replay.Summary{}, err
+	}
+	gorm, err := db.Connect(dbConfig)
+	if err != nil {
+		return replay.Summary{}, err
+	}
+	auditService := service.NewAuditService(repository.NewAuditRepository(gorm))
+	orderService := service.NewService(repository.NewRepository(gorm), cache.NewCache(1000, 60), events.NewBus(1), auditService)
+
+	source, err := eventHandler.NewKafkaReplaySource(kafkaConfig)
+	if err != nil {
+		return replay.Summary{}, err
+	}
+	defer source.Close()
+
+	var report io.Writer = os.Stdout
+	if reportFile != "" {
+		file, err := os.Create(reportFile)
+		if err != nil {
+			return replay.Summary{}, err
+		}
+		defer file.Close()
+		report = file
+	}
+
+	return replay.NewReplayer(source, decoder, orderService, options, report).Run(ctx, rng)
+}
+
+func newRange(topic string, partition int, fromOffset, toOffset int64, from, to string) (replay.Range, error) {
+	rng := replay.Range{Topic: topic, Partition: int32(partition)}
+	if fromOffset >= 0 {
+		rng.FromOffset = &fromOffset
+	}
+	if toOffset >= 0 {
+		rng.ToOffset = &toOffset
+	}
+	if rng.FromOffset != nil && from != "" || rng.ToOffset != nil && to != "" {
+		return rng, errors.New("an offset and a time cannot both bound the same end of the range")
+	}
+
+	var err error
+	if from != "" {
+		if rng.From, err = time.Parse(time.RFC3339, from); err != nil {
+			return rng, fmt.Errorf("-from is not an RFC 3339 time: %w", err)
+		}
+	}
+	if to != "" {
+		if rng.To, err = time.Parse(time.RFC3339, to); err != nil {
+			return rng, fmt.Errorf("-to is not an RFC 3339 time: %w", err)
+		}
+	}
+	return rng, nil
+}
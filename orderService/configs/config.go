@@ -1,14 +1,30 @@
 package configs
 
 import (
+	"errors"
+	"fmt"
 	"github.com/kelseyhightower/envconfig"
 )
 
 type Config struct {
-	Database Database
-	Kafka    Kafka
-	Cache    Cache
-	Port     string `envconfig:"PORT" default:":8080"`
+	Database  Database
+	Kafka     Kafka
+	Nats      Nats
+	Cache     Cache
+	Privacy   Privacy
+	Auth      Auth
+	RateLimit RateLimit
+	Cors      Cors
+	Stream    Stream
+	GraphQL   GraphQL
+	Stats     Stats
+	Retention Retention
+	Port      string `envconfig:"PORT" default:":8080"`
+	GRPCPort  string `envconfig:"GRPC_PORT" default:":9090"`
+	// Proxies whose X-Forwarded-For is trusted for the client IP, as IPs or CIDRs. No proxy is trusted by default
+	TrustedProxies []string `envconfig:"TRUSTED_PROXIES"`
+	// Broker the orders are ingested from: kafka or nats
+	Broker string `envconfig:"BROKER" default:"kafka"`
 }
 
 type Database struct {
@@ -21,24 +37,230 @@ type Database struct {
 	MaxOpenConnection int    `envconfig:"DB_MAX_OPEN_CONNECTION" default:"10"`
 }
 
-type Kafka struct {
-	Host    string `envconfig:"KAFKA_HOST" required:"true"`
-	Port    string `envconfig:"KAFKA_PORT" required:"true"`
-	Retry   int    `envconfig:"KAFKA_RETRY"  default:"2"`
-	Backoff int    `envconfig:"KAFKA_BACKOFF"  default:"100"`
-}
-
 type Cache struct {
 	Size int `envconfig:"CACHE_SIZE" required:"true"`
 	TTL  int `envconfig:"CACHE_TTL" required:"true"`
 }
 
+type Privacy struct {
+	NameRoles    []string `envconfig:"PII_NAME_ROLES" default:"admin"`
+	PhoneRoles   []string `envconfig:"PII_PHONE_ROLES" default:"admin"`
+	ZipRoles     []string `envconfig:"PII_ZIP_ROLES" default:"admin"`
+	AddressRoles []string `envconfig:"PII_ADDRESS_ROLES" default:"admin"`
+	EmailRoles   []string `envconfig:"PII_EMAIL_ROLES" default:"admin"`
+}
+
+type Auth struct {
+	Enabled bool `envconfig:"AUTH_ENABLED" default:"true"`
+	// Comma separated list of name;sha256;scope|scope;role|role
+	ApiKeys     []string `envconfig:"AUTH_API_KEYS"`
+	DBApiKeys   bool     `envconfig:"AUTH_DB_API_KEYS" default:"true"`
+	JWTSecret   string   `envconfig:"AUTH_JWT_SECRET"`
+	JWKSFile    string   `envconfig:"AUTH_JWKS_FILE"`
+	JWTIssuer   string   `envconfig:"AUTH_JWT_ISSUER"`
+	JWTAudience string   `envconfig:"AUTH_JWT_AUDIENCE"`
+}
+
+// Every route group is limited by a token bucket: RATE requests per second with bursts of up to BURST requests
+type RateLimit struct {
+	Enabled bool `envconfig:"RATE_LIMIT_ENABLED" default:"true"`
+	// Buckets not used for this time are dropped from the in-memory store
+	IdleTTL      int     `envconfig:"RATE_LIMIT_IDLE_TTL" default:"600"`
+	OrdersRate   float64 `envconfig:"RATE_LIMIT_ORDERS_RATE" default:"10"`
+	OrdersBurst  int     `envconfig:"RATE_LIMIT_ORDERS_BURST" default:"20"`
+	GraphQLRate  float64 `envconfig:"RATE_LIMIT_GRAPHQL_RATE" default:"5"`
+	GraphQLBurst int     `envconfig:"RATE_LIMIT_GRAPHQL_BURST" default:"10"`
+	StatsRate    float64 `envconfig:"RATE_LIMIT_STATS_RATE" default:"2"`
+	StatsBurst   int     `envconfig:"RATE_LIMIT_STATS_BURST" default:"10"`
+	// Exports and streams hold a connection for long, a client opens a few of them per minute
+	ExportRate  float64 `envconfig:"RATE_LIMIT_EXPORT_RATE" default:"0.05"`
+	ExportBurst int     `envconfig:"RATE_LIMIT_EXPORT_BURST" default:"2"`
+	StreamRate  float64 `envconfig:"RATE_LIMIT_STREAM_RATE" default:"0.1"`
+	StreamBurst int     `envconfig:"RATE_LIMIT_STREAM_BURST" default:"5"`
+	// Admin routes: audit log, Kafka consumer control and personal data erasure
+	AdminRate  float64 `envconfig:"RATE_LIMIT_ADMIN_RATE" default:"1"`
+	AdminBurst int     `envconfig:"RATE_LIMIT_ADMIN_BURST" default:"5"`
+	// Every authenticated route is also limited per client IP before the credentials are checked
+	IPRate  float64 `envconfig:"RATE_LIMIT_IP_RATE" default:"50"`
+	IPBurst int     `envconfig:"RATE_LIMIT_IP_BURST" default:"100"`
+}
+
+func (r RateLimit) Validate() error {
+	if !r.Enabled {
+		return nil
+	}
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(r.IdleTTL > 0, "RATE_LIMIT_IDLE_TTL must be positive, got %d", r.IdleTTL)
+	check(r.OrdersRate > 0, "RATE_LIMIT_ORDERS_RATE must be positive, got %v", r.OrdersRate)
+	check(r.OrdersBurst > 0, "RATE_LIMIT_ORDERS_BURST must be positive, got %d", r.OrdersBurst)
+	check(r.GraphQLRate > 0, "RATE_LIMIT_GRAPHQL_RATE must be positive, got %v", r.GraphQLRate)
+	check(r.GraphQLBurst > 0, "RATE_LIMIT_GRAPHQL_BURST must be positive, got %d", r.GraphQLBurst)
+	check(r.StatsRate > 0, "RATE_LIMIT_STATS_RATE must be positive, got %v", r.StatsRate)
+	check(r.StatsBurst > 0, "RATE_LIMIT_STATS_BURST must be positive, got %d", r.StatsBurst)
+	check(r.ExportRate > 0, "RATE_LIMIT_EXPORT_RATE must be positive, got %v", r.ExportRate)
+	check(r.ExportBurst > 0, "RATE_LIMIT_EXPORT_BURST must be positive, got %d", r.ExportBurst)
+	check(r.StreamRate > 0, "RATE_LIMIT_STREAM_RATE must be positive, got %v", r.StreamRate)
+	check(r.StreamBurst > 0, "RATE_LIMIT_STREAM_BURST must be positive, got %d", r.StreamBurst)
+	check(r.AdminRate > 0, "RATE_LIMIT_ADMIN_RATE must be positive, got %v", r.AdminRate)
+	check(r.AdminBurst > 0, "RATE_LIMIT_ADMIN_BURST must be positive, got %d", r.AdminBurst)
+	check(r.IPRate > 0, "RATE_LIMIT_IP_RATE must be positive, got %v", r.IPRate)
+	check(r.IPBurst > 0, "RATE_LIMIT_IP_BURST must be positive, got %d", r.IPBurst)
+	return errors.Join(errs...)
+}
+
+type Cors struct {
+	AllowedOrigins []string `envconfig:"CORS_ALLOWED_ORIGINS"`
+	// Regular expressions matched against the whole Origin header, e.g. https://[a-z0-9-]+\.example\.com
+	AllowedOriginPatterns []string `envconfig:"CORS_ALLOWED_ORIGIN_PATTERNS"`
+	AllowedMethods        []string `envconfig:"CORS_ALLOWED_METHODS" default:"GET,POST,PATCH,DELETE"`
+	AllowedHeaders        []string `envconfig:"CORS_ALLOWED_HEADERS" default:"Content-Type,Authorization,X-API-Key,If-Match,If-None-Match,If-Modified-Since"`
+	ExposedHeaders        []string `envconfig:"CORS_EXPOSED_HEADERS" default:"X-Request-ID,X-RateLimit-Limit,X-RateLimit-Remaining,X-RateLimit-Reset,Retry-After,ETag"`
+	AllowCredentials      bool     `envconfig:"CORS_ALLOW_CREDENTIALS" default:"false"`
+	MaxAge                int      `envconfig:"CORS_MAX_AGE" default:"600"`
+}
+
+type Stream struct {
+	// Number of last order events kept to resume streams by Last-Event-ID
+	HistorySize int `envconfig:"STREAM_HISTORY_SIZE" default:"1000"`
+	// Events buffered per client, a client that falls behind by more is disconnected
+	BufferSize int `envconfig:"STREAM_BUFFER_SIZE" default:"64"`
+	// Seconds between heartbeats sent to idle clients
+	HeartbeatInterval int `envconfig:"STREAM_HEARTBEAT_INTERVAL" default:"15"`
+}
+
+func (s Stream) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(s.HistorySize >= 0, "STREAM_HISTORY_SIZE must not be negative, got %d", s.HistorySize)
+	check(s.BufferSize > 0, "STREAM_BUFFER_SIZE must be positive, got %d", s.BufferSize)
+	check(s.HeartbeatInterval > 0, "STREAM_HEARTBEAT_INTERVAL must be positive, got %d", s.HeartbeatInterval)
+	return errors.Join(errs...)
+}
+
+// Queries whose fields are nested deeper than MaxDepth or cost more than MaxComplexity are rejected before execution.
+// Every field costs 1, the fields under orders count once per requested order
+type GraphQL struct {
+	MaxDepth      int `envconfig:"GRAPHQL_MAX_DEPTH" default:"10"`
+	MaxComplexity int `envconfig:"GRAPHQL_MAX_COMPLEXITY" default:"5000"`
+}
+
+func (g GraphQL) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(g.MaxDepth > 0, "GRAPHQL_MAX_DEPTH must be positive, got %d", g.MaxDepth)
+	check(g.MaxComplexity > 0, "GRAPHQL_MAX_COMPLEXITY must be positive, got %d", g.MaxComplexity)
+	return errors.Join(errs...)
+}
+
+type Stats struct {
+	// Serve statistics from materialized views refreshed every RefreshInterval seconds instead of the tables
+	MaterializedView bool `envconfig:"STATS_MATERIALIZED_VIEW" default:"false"`
+	RefreshInterval  int  `envconfig:"STATS_REFRESH_INTERVAL" default:"300"`
+}
+
+func (s Stats) Validate() error {
+	if s.MaterializedView && s.RefreshInterval <= 0 {
+		return fmt.Errorf("STATS_REFRESH_INTERVAL must be positive, got %d", s.RefreshInterval)
+	}
+	return nil
+}
+
+type Retention struct {
+	// Orders created more than Days ago are deleted for good every Interval seconds, BatchSize orders per transaction
+	Enabled   bool `envconfig:"RETENTION_ENABLED" default:"false"`
+	Days      int  `envconfig:"RETENTION_DAYS" default:"1825"`
+	BatchSize int  `envconfig:"RETENTION_BATCH_SIZE" default:"1000"`
+	Interval  int  `envconfig:"RETENTION_INTERVAL" default:"3600"`
+}
+
+func (r Retention) Validate() error {
+	if !r.Enabled {
+		return nil
+	}
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(r.Days > 0, "RETENTION_DAYS must be positive, got %d", r.Days)
+	check(r.BatchSize > 0, "RETENTION_BATCH_SIZE must be positive, got %d", r.BatchSize)
+	check(r.Interval > 0, "RETENTION_INTERVAL must be positive, got %d", r.Interval)
+	return errors.Join(errs...)
+}
+
 func NewParsedConfig() (Config, error) {
 	var config Config
 	err := envconfig.Process("", &config)
 	if err != nil {
 		return config, err
 	}
+	switch config.Broker {
+	case BrokerKafka:
+		if err = config.Kafka.Validate(); err != nil {
+			return config, fmt.Errorf("invalid kafka config:\n%w", err)
+		}
+	case BrokerNats:
+		if err = errors.Join(config.Kafka.ValidateProcessing(), config.Nats.Validate()); err != nil {
+			return config, fmt.Errorf("invalid nats config:\n%w", err)
+		}
+	default:
+		return config, fmt.Errorf("BROKER must be %s or %s, got %q", BrokerKafka, BrokerNats, config.Broker)
+	}
+	if err = config.RateLimit.Validate(); err != nil {
+		return config, fmt.Errorf("invalid rate limit config:\n%w", err)
+	}
+	if err = config.Stream.Validate(); err != nil {
+		return config, fmt.Errorf("invalid stream config:\n%w", err)
+	}
+	if err = config.GraphQL.Validate(); err != nil {
+		return config, fmt.Errorf("invalid graphql config:\n%w", err)
+	}
+	if err = errors.Join(config.Retention.Validate(), config.Stats.Validate()); err != nil {
+		return config, fmt.Errorf("invalid lifecycle config:\n%w", err)
+	}
+
+	return config, nil
+}
+
+// NewParsedDatabaseConfig reads only the database settings, for command line tools that do not need the rest
+func NewParsedDatabaseConfig() (Database, error) {
+	var config Database
+	err := envconfig.Process("", &config)
+	if err != nil {
+		return config, err
+	}
+
+	return config, nil
+}
+
+// NewParsedKafkaConfig reads only the Kafka settings, for command line tools that read the topics themselves
+func NewParsedKafkaConfig() (Kafka, error) {
+	var config Kafka
+	err := envconfig.Process("", &config)
+	if err != nil {
+		return config, err
+	}
+	if err = config.Validate(); err != nil {
+		return config, fmt.Errorf("invalid kafka config:\n%w", err)
+	}
 
 	return config, nil
 }
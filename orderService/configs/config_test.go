@@ -0,0 +1,60 @@
+package configs
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRateLimit_Validate(t *testing.T) {
+	valid := RateLimit{Enabled: true, IdleTTL: 600, OrdersRate: 10, OrdersBurst: 20, GraphQLRate: 5, GraphQLBurst: 10,
+		StatsRate: 2, StatsBurst: 10, ExportRate: 0.05, ExportBurst: 2, StreamRate: 0.1, StreamBurst: 5,
+		AdminRate: 1, AdminBurst: 5, IPRate: 50, IPBurst: 100}
+	assert.Nil(t, valid.Validate())
+
+	invalid := valid
+	invalid.OrdersRate, invalid.OrdersBurst, invalid.IPBurst = 0, -1, 0
+	assert.EqualError(t, invalid.Validate(), "RATE_LIMIT_ORDERS_RATE must be positive, got 0\n"+
+		"RATE_LIMIT_ORDERS_BURST must be positive, got -1\n"+
+		"RATE_LIMIT_IP_BURST must be positive, got 0")
+
+	groups := valid
+	groups.GraphQLBurst, groups.StatsRate, groups.ExportBurst, groups.StreamRate, groups.AdminRate = 0, 0, 0, -1, 0
+	assert.EqualError(t, groups.Validate(), "RATE_LIMIT_GRAPHQL_BURST must be positive, got 0\n"+
+		"RATE_LIMIT_STATS_RATE must be positive, got 0\n"+
+		"RATE_LIMIT_EXPORT_BURST must be positive, got 0\n"+
+		"RATE_LIMIT_STREAM_RATE must be positive, got -1\n"+
+		"RATE_LIMIT_ADMIN_RATE must be positive, got 0")
+
+	invalid.Enabled = false
+	assert.Nil(t, invalid.Validate(), "a disabled limit is not checked")
+}
+
+func TestStream_Validate(t *testing.T) {
+	assert.Nil(t, Stream{HistorySize: 0, BufferSize: 64, HeartbeatInterval: 15}.Validate())
+
+	assert.EqualError(t, Stream{HistorySize: -1, BufferSize: 0, HeartbeatInterval: 0}.Validate(), "STREAM_HISTORY_SIZE must not be negative, got -1\n"+
+		"STREAM_BUFFER_SIZE must be positive, got 0\n"+
+		"STREAM_HEARTBEAT_INTERVAL must be positive, got 0")
+}
+
+func TestGraphQL_Validate(t *testing.T) {
+	assert.Nil(t, GraphQL{MaxDepth: 10, MaxComplexity: 5000}.Validate())
+
+	assert.EqualError(t, GraphQL{MaxDepth: 0, MaxComplexity: -1}.Validate(), "GRAPHQL_MAX_DEPTH must be positive, got 0\n"+
+		"GRAPHQL_MAX_COMPLEXITY must be positive, got -1")
+}
+
+func TestRetention_Validate(t *testing.T) {
+	assert.Nil(t, Retention{Enabled: true, Days: 1825, BatchSize: 1000, Interval: 3600}.Validate())
+	assert.Nil(t, Retention{Interval: 0}.Validate(), "a disabled retention job is not checked")
+
+	assert.EqualError(t, Retention{Enabled: true, Days: 0, BatchSize: 1000, Interval: -1}.Validate(), "RETENTION_DAYS must be positive, got 0\n"+
+		"RETENTION_INTERVAL must be positive, got -1")
+}
+
+func TestStats_Validate(t *testing.T) {
+	assert.Nil(t, Stats{MaterializedView: true, RefreshInterval: 300}.Validate())
+	assert.Nil(t, Stats{RefreshInterval: 0}.Validate(), "views are not refreshed without STATS_MATERIALIZED_VIEW")
+
+	assert.EqualError(t, Stats{MaterializedView: true, RefreshInterval: 0}.Validate(), "STATS_REFRESH_INTERVAL must be positive, got 0")
+}
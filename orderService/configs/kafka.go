@@ -0,0 +1,173 @@
+package configs
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type Kafka struct {
+	// Brokers lists the bootstrap servers as host:port, KAFKA_HOST and KAFKA_PORT (e.g. :9092) are used when it is empty
+	Brokers []string `envconfig:"KAFKA_BROKERS"`
+	Host    string   `envconfig:"KAFKA_HOST"`
+	Port    string   `envconfig:"KAFKA_PORT"`
+	// GroupID defaults to the group the service always used, so committed offsets are kept
+	GroupID string   `envconfig:"KAFKA_GROUP_ID" default:"1"`
+	Topics  []string `envconfig:"KAFKA_TOPICS" default:"Orders"`
+	// AutoOffsetReset is where a partition without committed offset starts: earliest, latest or error
+	AutoOffsetReset string `envconfig:"KAFKA_AUTO_OFFSET_RESET" default:"latest"`
+	// SessionTimeout, HeartbeatInterval and MaxPollInterval in milliseconds
+	SessionTimeout    int `envconfig:"KAFKA_SESSION_TIMEOUT" default:"45000"`
+	HeartbeatInterval int `envconfig:"KAFKA_HEARTBEAT_INTERVAL" default:"3000"`
+	MaxPollInterval   int `envconfig:"KAFKA_MAX_POLL_INTERVAL" default:"300000"`
+	// SecurityProtocol is plaintext, ssl, sasl_plaintext or sasl_ssl
+	SecurityProtocol string `envconfig:"KAFKA_SECURITY_PROTOCOL" default:"plaintext"`
+	SASLMechanism    string `envconfig:"KAFKA_SASL_MECHANISM" default:"PLAIN"`
+	SASLUsername     string `envconfig:"KAFKA_SASL_USERNAME"`
+	SASLPassword     string `envconfig:"KAFKA_SASL_PASSWORD"`
+	SSLCALocation    string `envconfig:"KAFKA_SSL_CA_LOCATION"`
+	SSLCertLocation  string `envconfig:"KAFKA_SSL_CERT_LOCATION"`
+	SSLKeyLocation   string `envconfig:"KAFKA_SSL_KEY_LOCATION"`
+	SSLKeyPassword   string `envconfig:"KAFKA_SSL_KEY_PASSWORD"`
+	// Properties are passed to librdkafka as is and override the settings above,
+	// e.g. fetch.min.bytes=1024,client.id=orders-ingest
+	Properties Properties `envconfig:"KAFKA_PROPERTIES"`
+
+	Retry   int `envconfig:"KAFKA_RETRY"  default:"2"`
+	Backoff int `envconfig:"KAFKA_BACKOFF"  default:"100"`
+	// Concurrency is the number of workers handling messages in parallel
+	Concurrency int `envconfig:"KAFKA_CONCURRENCY" default:"4"`
+	// Ordering keeps messages of one "partition" or of one message "key" in order
+	Ordering string `envconfig:"KAFKA_ORDERING" default:"partition"`
+	// CommitInterval in milliseconds between commits of processed offsets
+	CommitInterval int `envconfig:"KAFKA_COMMIT_INTERVAL" default:"1000"`
+	// BatchSize above 1 stores up to that many messages of a worker in one transaction
+	BatchSize int `envconfig:"KAFKA_BATCH_SIZE" default:"1"`
+	// BatchWait in milliseconds a worker waits for a batch to fill up
+	BatchWait int `envconfig:"KAFKA_BATCH_WAIT" default:"50"`
+	// RetryTopics delays messages that keep failing transiently in <topic>.retry.1m and <topic>.retry.10m
+	RetryTopics bool `envconfig:"KAFKA_RETRY_TOPICS" default:"false"`
+	// DeadLetterTopic receives messages that failed for good, they are only logged when empty
+	DeadLetterTopic string `envconfig:"KAFKA_DEAD_LETTER_TOPIC"`
+	// SchemaRegistryURL of a Confluent Schema Registry resolving wire format payloads
+	SchemaRegistryURL string `envconfig:"KAFKA_SCHEMA_REGISTRY_URL"`
+	// SchemaRegistryDir replaces the schema registry with <id>.json files for local runs
+	SchemaRegistryDir string `envconfig:"KAFKA_SCHEMA_REGISTRY_DIR"`
+	// TopicContentTypes sets the payload encoding of topics whose producers omit the content-type header,
+	// e.g. Orders.proto:application/x-protobuf
+	TopicContentTypes map[string]string `envconfig:"KAFKA_TOPIC_CONTENT_TYPES"`
+}
+
+// Properties are comma separated key=value pairs, values may contain colons unlike envconfig maps
+type Properties map[string]string
+
+func (p *Properties) Decode(value string) error {
+	properties := make(Properties)
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, val, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return fmt.Errorf("property %q must be key=value", pair)
+		}
+		properties[strings.TrimSpace(key)] = strings.TrimSpace(val)
+	}
+	*p = properties
+	return nil
+}
+
+// managedProperties are set by the consumer itself, overriding them would break offset tracking
+var managedProperties = []string{"enable.auto.commit", "enable.auto.offset.store"}
+
+var (
+	topicName      = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,249}$`)
+	saslMechanisms = []string{"PLAIN", "SCRAM-SHA-256", "SCRAM-SHA-512", "GSSAPI", "OAUTHBEARER"}
+)
+
+// BootstrapServers returns the brokers as librdkafka expects them
+func (k Kafka) BootstrapServers() string {
+	if len(k.Brokers) > 0 {
+		return strings.Join(k.Brokers, ",")
+	}
+	return k.Host + k.Port
+}
+
+// Validate reports every invalid setting at once
+func (k Kafka) Validate() error {
+	return errors.Join(append(k.connectionErrors(), k.processingErrors()...)...)
+}
+
+// ValidateProcessing checks only the settings of message processing, which other brokers share
+func (k Kafka) ValidateProcessing() error {
+	return errors.Join(k.processingErrors()...)
+}
+
+func (k Kafka) connectionErrors() []error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	servers := k.BootstrapServers()
+	check(servers != "", "KAFKA_BROKERS or KAFKA_HOST and KAFKA_PORT are required")
+	if servers != "" {
+		for _, broker := range strings.Split(servers, ",") {
+			host, port, err := net.SplitHostPort(broker)
+			_, portErr := strconv.ParseUint(port, 10, 16)
+			check(err == nil && host != "" && portErr == nil, "broker %q must be host:port", broker)
+		}
+	}
+
+	check(k.GroupID != "", "KAFKA_GROUP_ID must not be empty")
+	check(slices.Contains([]string{"earliest", "latest", "error"}, k.AutoOffsetReset), "KAFKA_AUTO_OFFSET_RESET must be earliest, latest or error, got %q", k.AutoOffsetReset)
+	check(k.SessionTimeout > 0, "KAFKA_SESSION_TIMEOUT must be positive, got %d", k.SessionTimeout)
+	check(k.HeartbeatInterval > 0 && k.HeartbeatInterval < k.SessionTimeout, "KAFKA_HEARTBEAT_INTERVAL must be positive and below KAFKA_SESSION_TIMEOUT, got %d", k.HeartbeatInterval)
+	check(k.MaxPollInterval >= k.SessionTimeout, "KAFKA_MAX_POLL_INTERVAL must not be below KAFKA_SESSION_TIMEOUT, got %d", k.MaxPollInterval)
+
+	protocol := strings.ToLower(k.SecurityProtocol)
+	check(slices.Contains([]string{"plaintext", "ssl", "sasl_plaintext", "sasl_ssl"}, protocol), "KAFKA_SECURITY_PROTOCOL must be plaintext, ssl, sasl_plaintext or sasl_ssl, got %q", k.SecurityProtocol)
+	if strings.HasPrefix(protocol, "sasl_") {
+		mechanism := strings.ToUpper(k.SASLMechanism)
+		check(slices.Contains(saslMechanisms, mechanism), "KAFKA_SASL_MECHANISM must be one of %s, got %q", strings.Join(saslMechanisms, ", "), k.SASLMechanism)
+		if mechanism == "PLAIN" || strings.HasPrefix(mechanism, "SCRAM-") {
+			check(k.SASLUsername != "" && k.SASLPassword != "", "KAFKA_SASL_USERNAME and KAFKA_SASL_PASSWORD are required for %s", mechanism)
+		}
+	}
+	check((k.SSLCertLocation == "") == (k.SSLKeyLocation == ""), "KAFKA_SSL_CERT_LOCATION and KAFKA_SSL_KEY_LOCATION must be set together")
+	for _, key := range managedProperties {
+		_, ok := k.Properties[key]
+		check(!ok, "KAFKA_PROPERTIES must not set %s, offsets are committed by the consumer", key)
+	}
+	return errs
+}
+
+func (k Kafka) processingErrors() []error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(len(k.Topics) > 0, "KAFKA_TOPICS must list at least one topic")
+	for _, topic := range k.Topics {
+		check(topicName.MatchString(topic), "topic %q must be 1 to 249 letters, digits, '.', '_' or '-'", topic)
+	}
+	check(k.DeadLetterTopic == "" || topicName.MatchString(k.DeadLetterTopic), "KAFKA_DEAD_LETTER_TOPIC %q is not a valid topic name", k.DeadLetterTopic)
+	check(!slices.Contains(k.Topics, k.DeadLetterTopic), "KAFKA_DEAD_LETTER_TOPIC %q must not be one of KAFKA_TOPICS", k.DeadLetterTopic)
+	check(k.Retry >= 0, "KAFKA_RETRY must not be negative, got %d", k.Retry)
+	check(k.Backoff > 0, "KAFKA_BACKOFF must be positive, got %d", k.Backoff)
+	check(k.Concurrency > 0, "KAFKA_CONCURRENCY must be positive, got %d", k.Concurrency)
+	check(k.Ordering == "partition" || k.Ordering == "key", "KAFKA_ORDERING must be partition or key, got %q", k.Ordering)
+	check(k.CommitInterval > 0, "KAFKA_COMMIT_INTERVAL must be positive, got %d", k.CommitInterval)
+	check(k.BatchSize > 0, "KAFKA_BATCH_SIZE must be positive, got %d", k.BatchSize)
+	check(k.BatchSize == 1 || k.BatchWait > 0, "KAFKA_BATCH_WAIT must be positive, got %d", k.BatchWait)
+	return errs
+}
@@ -0,0 +1,99 @@
+package configs
+
+import (
+	"github.com/kelseyhightower/envconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func defaultKafka(t *testing.T) Kafka {
+	var cnf Kafka
+	require.NoError(t, envconfig.Process("", &cnf))
+	return cnf
+}
+
+func TestKafka_Defaults(t *testing.T) {
+	t.Setenv("KAFKA_HOST", "kafka")
+	t.Setenv("KAFKA_PORT", ":9092")
+
+	cnf := defaultKafka(t)
+
+	assert.Nil(t, cnf.Validate())
+	assert.Equal(t, "kafka:9092", cnf.BootstrapServers())
+	assert.Equal(t, "1", cnf.GroupID)
+	assert.Equal(t, []string{"Orders"}, cnf.Topics)
+}
+
+func TestKafka_Environment(t *testing.T) {
+	t.Setenv("KAFKA_BROKERS", "kafka-1:9092,kafka-2:9092")
+	t.Setenv("KAFKA_TOPICS", "Orders,Orders.proto")
+	t.Setenv("KAFKA_PROPERTIES", "client.id=orders-ingest, ssl.endpoint.identification.algorithm=none,sasl.oauthbearer.config=scope=orders:read")
+
+	cnf := defaultKafka(t)
+
+	assert.Nil(t, cnf.Validate())
+	assert.Equal(t, "kafka-1:9092,kafka-2:9092", cnf.BootstrapServers())
+	assert.Equal(t, []string{"Orders", "Orders.proto"}, cnf.Topics)
+	assert.Equal(t, Properties{
+		"client.id":                             "orders-ingest",
+		"ssl.endpoint.identification.algorithm": "none",
+		"sasl.oauthbearer.config":               "scope=orders:read",
+	}, cnf.Properties)
+}
+
+func TestProperties_Decode(t *testing.T) {
+	var properties Properties
+
+	assert.EqualError(t, properties.Decode("fetch.min.bytes"), `property "fetch.min.bytes" must be key=value`)
+	assert.Nil(t, properties.Decode(""))
+	assert.Empty(t, properties)
+}
+
+func TestKafka_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(cnf *Kafka)
+		errors []string
+	}{
+		{"NoBrokers", func(cnf *Kafka) { cnf.Brokers = nil }, []string{"KAFKA_BROKERS or KAFKA_HOST and KAFKA_PORT are required"}},
+		{"BrokerWithoutPort", func(cnf *Kafka) { cnf.Brokers = []string{"kafka-1:9092", "kafka-2"} }, []string{`broker "kafka-2" must be host:port`}},
+		{"EmptyGroup", func(cnf *Kafka) { cnf.GroupID = "" }, []string{"KAFKA_GROUP_ID must not be empty"}},
+		{"InvalidTopic", func(cnf *Kafka) { cnf.Topics = []string{"Orders", "new orders"} }, []string{`topic "new orders" must be 1 to 249 letters, digits, '.', '_' or '-'`}},
+		{"DeadLetterIsSubscribed", func(cnf *Kafka) { cnf.DeadLetterTopic = "Orders" }, []string{`KAFKA_DEAD_LETTER_TOPIC "Orders" must not be one of KAFKA_TOPICS`}},
+		{"AutoOffsetReset", func(cnf *Kafka) { cnf.AutoOffsetReset = "beginning" }, []string{`KAFKA_AUTO_OFFSET_RESET must be earliest, latest or error, got "beginning"`}},
+		{"HeartbeatAboveSessionTimeout", func(cnf *Kafka) { cnf.HeartbeatInterval = 60000 }, []string{"KAFKA_HEARTBEAT_INTERVAL must be positive and below KAFKA_SESSION_TIMEOUT, got 60000"}},
+		{"MaxPollBelowSessionTimeout", func(cnf *Kafka) { cnf.MaxPollInterval = 10000 }, []string{"KAFKA_MAX_POLL_INTERVAL must not be below KAFKA_SESSION_TIMEOUT, got 10000"}},
+		{"SecurityProtocol", func(cnf *Kafka) { cnf.SecurityProtocol = "tls" }, []string{`KAFKA_SECURITY_PROTOCOL must be plaintext, ssl, sasl_plaintext or sasl_ssl, got "tls"`}},
+		{"SASLWithoutCredentials", func(cnf *Kafka) { cnf.SecurityProtocol = "SASL_SSL"; cnf.SASLMechanism = "scram-sha-512" }, []string{"KAFKA_SASL_USERNAME and KAFKA_SASL_PASSWORD are required for SCRAM-SHA-512"}},
+		{"SASLMechanism", func(cnf *Kafka) { cnf.SecurityProtocol = "sasl_plaintext"; cnf.SASLMechanism = "DIGEST-MD5" }, []string{`KAFKA_SASL_MECHANISM must be one of PLAIN, SCRAM-SHA-256, SCRAM-SHA-512, GSSAPI, OAUTHBEARER, got "DIGEST-MD5"`}},
+		{"CertificateWithoutKey", func(cnf *Kafka) { cnf.SSLCertLocation = "/etc/kafka/client.pem" }, []string{"KAFKA_SSL_CERT_LOCATION and KAFKA_SSL_KEY_LOCATION must be set together"}},
+		{"ManagedProperty", func(cnf *Kafka) { cnf.Properties = Properties{"enable.auto.commit": "true"} }, []string{"KAFKA_PROPERTIES must not set enable.auto.commit, offsets are committed by the consumer"}},
+		{"Workers", func(cnf *Kafka) { cnf.Concurrency = 0; cnf.Ordering = "order" }, []string{"KAFKA_CONCURRENCY must be positive, got 0", `KAFKA_ORDERING must be partition or key, got "order"`}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cnf := defaultKafka(t)
+			cnf.Brokers = []string{"kafka:9092"}
+			test.change(&cnf)
+
+			err := cnf.Validate()
+
+			require.Error(t, err)
+			for _, message := range test.errors {
+				assert.Contains(t, err.Error(), message)
+			}
+		})
+	}
+
+	t.Run("SASL", func(t *testing.T) {
+		cnf := defaultKafka(t)
+		cnf.Brokers = []string{"kafka:9093"}
+		cnf.SecurityProtocol = "sasl_ssl"
+		cnf.SASLMechanism = "SCRAM-SHA-256"
+		cnf.SASLUsername = "orders"
+		cnf.SASLPassword = "secret"
+
+		assert.Nil(t, cnf.Validate())
+	})
+}
@@ -0,0 +1,56 @@
+package configs
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Brokers the orders are ingested from, selected by BROKER
+const (
+	BrokerKafka = "kafka"
+	BrokerNats  = "nats"
+)
+
+// Nats is the JetStream connection used when BROKER=nats. KAFKA_TOPICS are the subjects then,
+// the processing settings of Kafka (concurrency, retries, dead-letter topic, decoders) apply as well
+type Nats struct {
+	// URL lists the servers, comma separated
+	URL string `envconfig:"NATS_URL" default:"nats://localhost:4222"`
+	// CredentialsFile is a .creds file with the user JWT and NKey seed
+	CredentialsFile string `envconfig:"NATS_CREDENTIALS_FILE"`
+	Stream          string `envconfig:"NATS_STREAM" default:"ORDERS"`
+	// CreateStream creates the stream with the subjects and the dead-letter subject when it does not exist
+	CreateStream bool `envconfig:"NATS_CREATE_STREAM" default:"false"`
+	// Durable names the consumer on the stream, its acknowledged position survives restarts
+	Durable string `envconfig:"NATS_DURABLE" default:"order-service"`
+	// AckWait in milliseconds before the server redelivers a message nobody works on
+	AckWait int `envconfig:"NATS_ACK_WAIT" default:"30000"`
+	// MaxAckPending bounds the messages delivered but not yet acknowledged
+	MaxAckPending int `envconfig:"NATS_MAX_ACK_PENDING" default:"1000"`
+}
+
+var streamName = regexp.MustCompile(`^[^\s.*>/\\]{1,255}$`)
+
+// Validate reports every invalid setting at once
+func (n Nats) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	for _, server := range strings.Split(n.URL, ",") {
+		u, err := url.Parse(strings.TrimSpace(server))
+		check(err == nil && u.Host != "", "NATS_URL %q must be a list of server URLs, e.g. nats://localhost:4222", server)
+	}
+	check(streamName.MatchString(n.Stream), "NATS_STREAM %q must not be empty or contain whitespace, '.', '*', '>', '/' or '\\'", n.Stream)
+	check(streamName.MatchString(n.Durable), "NATS_DURABLE %q must not be empty or contain whitespace, '.', '*', '>', '/' or '\\'", n.Durable)
+	check(n.AckWait > 0, "NATS_ACK_WAIT must be positive, got %d", n.AckWait)
+	check(n.MaxAckPending > 0, "NATS_MAX_ACK_PENDING must be positive, got %d", n.MaxAckPending)
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,36 @@
+package configs
+
+import (
+	"github.com/kelseyhightower/envconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestNats_Validate(t *testing.T) {
+	var cnf Nats
+	require.NoError(t, envconfig.Process("", &cnf))
+	assert.Nil(t, cnf.Validate())
+
+	cnf.URL = "nats://nats-1:4222, localhost"
+	cnf.Stream = "orders.created"
+	cnf.AckWait = 0
+
+	err := cnf.Validate()
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `NATS_URL " localhost" must be a list of server URLs`)
+	assert.Contains(t, err.Error(), `NATS_STREAM "orders.created" must not be empty or contain whitespace`)
+	assert.Contains(t, err.Error(), "NATS_ACK_WAIT must be positive, got 0")
+}
+
+func TestKafka_ValidateProcessing(t *testing.T) {
+	cnf := defaultKafka(t)
+
+	// the connection settings are not needed when the orders come from another broker
+	assert.Nil(t, cnf.ValidateProcessing())
+	assert.ErrorContains(t, cnf.Validate(), "KAFKA_BROKERS or KAFKA_HOST and KAFKA_PORT are required")
+
+	cnf.BatchSize = 0
+	assert.ErrorContains(t, cnf.ValidateProcessing(), "KAFKA_BATCH_SIZE must be positive, got 0")
+}
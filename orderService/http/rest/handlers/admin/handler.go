@@ -0,0 +1,58 @@
+package admin
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"log"
+	"net/http"
+	"orderService/internal/service"
+	"strconv"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+type Handler struct {
+	audit service.IAuditService
+}
+
+func NewHandler(audit service.IAuditService) Handler {
+	return Handler{audit: audit}
+}
+
+// Audit 				godoc
+// @Summary				Audit log of an order
+// @Param				order_uid query string true "Order id"
+// @Param				limit query int false "Number of entries, 100 by default, at most 1000"
+// @Description			Return who created, read personal data of, changed or deleted the order, newest first. Personal data in diffs is redacted
+// @Produce				application/json
+// @Tags				admin
+// @Security			ApiKeyAuth
+// @Security			BearerAuth
+// @Success				200 {array} models.AuditEntry
+// @Router				/admin/audit [get]
+func (h Handler) Audit(c *gin.Context) {
+	uid, err := uuid.Parse(c.Query("order_uid"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "order_uid is not UUID format"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultAuditLimit)))
+	if err != nil || limit < 1 || limit > maxAuditLimit {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be a number from 1 to %d", maxAuditLimit)})
+		return
+	}
+
+	entries, err := h.audit.FindByOrder(uid, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read audit log"})
+		log.Println(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
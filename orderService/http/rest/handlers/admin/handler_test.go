@@ -0,0 +1,73 @@
+package admin
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http/httptest"
+	"orderService/internal/models"
+	"orderService/internal/service/mocks"
+	"testing"
+	"time"
+)
+
+var uid = uuid.MustParse("1e9ad4fb-2615-46f9-9458-20b59253086b")
+
+func serve(handler Handler, url string) *httptest.ResponseRecorder {
+	g := gin.New()
+	g.GET("/admin/audit", handler.Audit)
+
+	h := httptest.NewRecorder()
+	g.ServeHTTP(h, httptest.NewRequest("GET", url, nil))
+	return h
+}
+
+func TestHandler_Audit(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockAudit := new(mocks.IAuditService)
+		mockAudit.On("FindByOrder", uid, 100).Return([]models.AuditEntry{{
+			ID:         7,
+			OccurredAt: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+			Actor:      "jwt:courier",
+			Action:     models.AuditOrderStatusUpdated,
+			OrderUid:   &uid,
+			Diff:       []byte(`{"items.0.status":{"old":100,"new":202}}`),
+		}}, nil)
+
+		h := serve(NewHandler(mockAudit), fmt.Sprintf("/admin/audit?order_uid=%s", uid))
+
+		assert.Equal(t, 200, h.Code)
+		assert.JSONEq(t, fmt.Sprintf(`[{"id":7,"occurred_at":"2021-11-26T06:22:19Z","actor":"jwt:courier","action":"order.status_updated",
+			"order_uid":"%s","diff":{"items.0.status":{"old":100,"new":202}}}]`, uid), h.Body.String())
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		mockAudit := new(mocks.IAuditService)
+		mockAudit.On("FindByOrder", uid, 5).Return([]models.AuditEntry{}, nil)
+
+		h := serve(NewHandler(mockAudit), fmt.Sprintf("/admin/audit?order_uid=%s&limit=5", uid))
+
+		assert.Equal(t, 200, h.Code)
+		assert.JSONEq(t, `[]`, h.Body.String())
+	})
+
+	t.Run("OrderUidIsNotUUID", func(t *testing.T) {
+		mockAudit := new(mocks.IAuditService)
+
+		h := serve(NewHandler(mockAudit), "/admin/audit?order_uid=1")
+
+		assert.Equal(t, 400, h.Code)
+		mockAudit.AssertNotCalled(t, "FindByOrder", mock.Anything, mock.Anything)
+	})
+
+	t.Run("LimitOutOfRange", func(t *testing.T) {
+		mockAudit := new(mocks.IAuditService)
+
+		h := serve(NewHandler(mockAudit), fmt.Sprintf("/admin/audit?order_uid=%s&limit=5000", uid))
+
+		assert.Equal(t, 400, h.Code)
+		assert.JSONEq(t, `{"error":"limit must be a number from 1 to 1000"}`, h.Body.String())
+	})
+}
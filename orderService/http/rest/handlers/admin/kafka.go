@@ -0,0 +1,186 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	consumer "orderService/internal/kafka"
+	"time"
+)
+
+// seekTimeout bounds the wait for the in-flight messages of the partition before it seeks
+const seekTimeout = 30 * time.Second
+
+type partition struct {
+	Topic     string `json:"topic" binding:"required"`
+	Partition int32  `json:"partition"`
+}
+
+type partitionsRequest struct {
+	Partitions []partition `json:"partitions" binding:"dive"`
+}
+
+type partitionsResponse struct {
+	Partitions []partition `json:"partitions"`
+}
+
+type seekRequest struct {
+	Topic     string     `json:"topic" binding:"required"`
+	Partition int32      `json:"partition"`
+	Offset    *int64     `json:"offset"`
+	Timestamp *time.Time `json:"timestamp"`
+}
+
+type seekResponse struct {
+	Topic     string `json:"topic"`
+	Partition int32  `json:"partition"`
+	Offset    int64  `json:"offset"`
+}
+
+type KafkaHandler struct {
+	consumer consumer.IConsumerAdmin
+}
+
+func NewKafkaHandler(consumer consumer.IConsumerAdmin) KafkaHandler {
+	return KafkaHandler{consumer: consumer}
+}
+
+// Status 				godoc
+// @Summary				Order consumer state
+// @Description			Return the assigned partitions with their committed offset, high watermark and lag, the timestamp of the last message read, the number of failed messages and whether the partition is paused
+// @Produce				application/json
+// @Tags				admin
+// @Security			ApiKeyAuth
+// @Security			BearerAuth
+// @Success				200 {object} eventHandler.ConsumerStatus
+// @Failure				501 {object} map[string]string "The broker does not support consumer administration"
+// @Router				/admin/kafka [get]
+func (h KafkaHandler) Status(c *gin.Context) {
+	status, err := h.consumer.Status()
+	if err != nil {
+		respondConsumerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// Pause 				godoc
+// @Summary				Pause consuming partitions
+// @Param				request body partitionsRequest false "Partitions to pause, every assigned partition when empty"
+// @Description			Stop reading the partitions until they are resumed. Return the partitions that were not paused already
+// @Accept				application/json
+// @Produce				application/json
+// @Tags				admin
+// @Security			ApiKeyAuth
+// @Security			BearerAuth
+// @Success				200 {object} partitionsResponse
+// @Router				/admin/kafka/pause [post]
+func (h KafkaHandler) Pause(c *gin.Context) {
+	h.toggle(c, h.consumer.Pause)
+}
+
+// Resume 				godoc
+// @Summary				Resume consuming partitions
+// @Param				request body partitionsRequest false "Partitions to resume, every assigned partition when empty"
+// @Description			Resume the partitions paused with /admin/kafka/pause. Return the partitions that were paused
+// @Accept				application/json
+// @Produce				application/json
+// @Tags				admin
+// @Security			ApiKeyAuth
+// @Security			BearerAuth
+// @Success				200 {object} partitionsResponse
+// @Router				/admin/kafka/resume [post]
+func (h KafkaHandler) Resume(c *gin.Context) {
+	h.toggle(c, h.consumer.Resume)
+}
+
+func (h KafkaHandler) toggle(c *gin.Context, apply func(partitions []consumer.TopicPartition) ([]consumer.TopicPartition, error)) {
+	var req partitionsRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	partitions := make([]consumer.TopicPartition, 0, len(req.Partitions))
+	for _, p := range req.Partitions {
+		partitions = append(partitions, consumer.TopicPartition{Topic: p.Topic, Partition: p.Partition})
+	}
+	changed, err := apply(partitions)
+	if err != nil {
+		respondConsumerError(c, err)
+		return
+	}
+
+	resp := partitionsResponse{Partitions: make([]partition, 0, len(changed))}
+	for _, tp := range changed {
+		resp.Partitions = append(resp.Partitions, partition{Topic: tp.Topic, Partition: tp.Partition})
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Seek 				godoc
+// @Summary				Replay a partition
+// @Param				request body seekRequest true "Partition and either the offset or the timestamp to read from"
+// @Description			Wait for the in-flight messages of the partition, then continue reading it from the offset or from the first message produced at or after the timestamp. The new position is committed. Orders that exist already are not overwritten and are skipped
+// @Accept				application/json
+// @Produce				application/json
+// @Tags				admin
+// @Security			ApiKeyAuth
+// @Security			BearerAuth
+// @Success				200 {object} seekResponse
+// @Failure				409 {object} map[string]string "In-flight messages of the partition are still processed"
+// @Router				/admin/kafka/seek [post]
+func (h KafkaHandler) Seek(c *gin.Context) {
+	var req seekRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if (req.Offset == nil) == (req.Timestamp == nil) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "exactly one of offset and timestamp is required"})
+		return
+	}
+	if req.Offset != nil && *req.Offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("offset must not be negative, got %d", *req.Offset)})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), seekTimeout)
+	defer cancel()
+	tp := consumer.TopicPartition{Topic: req.Topic, Partition: req.Partition}
+	var err error
+	if req.Offset != nil {
+		tp.Offset = *req.Offset
+		err = h.consumer.Seek(ctx, tp)
+	} else {
+		tp, err = h.consumer.SeekToTime(ctx, tp, *req.Timestamp)
+	}
+	if err != nil {
+		respondConsumerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, seekResponse{Topic: tp.Topic, Partition: tp.Partition, Offset: tp.Offset})
+}
+
+func respondConsumerError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, consumer.ErrNotSupported):
+		c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+	case errors.Is(err, consumer.ErrNotAssigned):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, consumer.ErrBusy):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error() + ", retry the seek"})
+	case errors.Is(err, context.DeadlineExceeded):
+		c.JSON(http.StatusGatewayTimeout, gin.H{"error": "in-flight messages of the partition are still processed, the seek may be applied once they are done, check /admin/kafka"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to administer the consumer"})
+		log.Println(err.Error())
+	}
+}
@@ -0,0 +1,138 @@
+package admin
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http/httptest"
+	consumer "orderService/internal/kafka"
+	"orderService/internal/kafka/mocks"
+	"strings"
+	"testing"
+	"time"
+)
+
+func serveKafka(handler KafkaHandler, method string, url string, body string) *httptest.ResponseRecorder {
+	g := gin.New()
+	g.GET("/admin/kafka", handler.Status)
+	g.POST("/admin/kafka/pause", handler.Pause)
+	g.POST("/admin/kafka/resume", handler.Resume)
+	g.POST("/admin/kafka/seek", handler.Seek)
+
+	h := httptest.NewRecorder()
+	g.ServeHTTP(h, httptest.NewRequest(method, url, strings.NewReader(body)))
+	return h
+}
+
+func TestKafkaHandler_Status(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		committed, lag := int64(40), int64(2)
+		lastMessageAt := time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC)
+		mockConsumer := new(mocks.IConsumerAdmin)
+		mockConsumer.On("Status").Return(consumer.ConsumerStatus{Broker: "kafka", PollErrors: 1, Partitions: []consumer.PartitionStatus{
+			{Topic: "Orders", Partition: 0, Committed: &committed, HighWatermark: 42, Lag: &lag, LastMessageAt: &lastMessageAt, Errors: 3, Paused: true, PausedByAdmin: true},
+			{Topic: "Orders", Partition: 1},
+		}}, nil)
+
+		h := serveKafka(NewKafkaHandler(mockConsumer), "GET", "/admin/kafka", "")
+
+		assert.Equal(t, 200, h.Code)
+		assert.JSONEq(t, `{"broker":"kafka","poll_errors":1,"partitions":[
+			{"topic":"Orders","partition":0,"committed":40,"high_watermark":42,"lag":2,"last_message_at":"2021-11-26T06:22:19Z","errors":3,"paused":true,"paused_by_admin":true},
+			{"topic":"Orders","partition":1,"committed":null,"high_watermark":0,"lag":null,"last_message_at":null,"errors":0,"paused":false,"paused_by_admin":false}]}`, h.Body.String())
+	})
+
+	t.Run("NotSupported", func(t *testing.T) {
+		mockConsumer := new(mocks.IConsumerAdmin)
+		mockConsumer.On("Status").Return(consumer.ConsumerStatus{}, fmt.Errorf("%w: nats", consumer.ErrNotSupported))
+
+		h := serveKafka(NewKafkaHandler(mockConsumer), "GET", "/admin/kafka", "")
+
+		assert.Equal(t, 501, h.Code)
+	})
+}
+
+func TestKafkaHandler_Pause(t *testing.T) {
+	t.Run("Partitions", func(t *testing.T) {
+		mockConsumer := new(mocks.IConsumerAdmin)
+		mockConsumer.On("Pause", []consumer.TopicPartition{{Topic: "Orders", Partition: 1}}).Return([]consumer.TopicPartition{{Topic: "Orders", Partition: 1}}, nil)
+
+		h := serveKafka(NewKafkaHandler(mockConsumer), "POST", "/admin/kafka/pause", `{"partitions":[{"topic":"Orders","partition":1}]}`)
+
+		assert.Equal(t, 200, h.Code)
+		assert.JSONEq(t, `{"partitions":[{"topic":"Orders","partition":1}]}`, h.Body.String())
+	})
+
+	t.Run("EveryPartitionWithoutBody", func(t *testing.T) {
+		mockConsumer := new(mocks.IConsumerAdmin)
+		mockConsumer.On("Pause", []consumer.TopicPartition{}).Return([]consumer.TopicPartition{}, nil)
+
+		h := serveKafka(NewKafkaHandler(mockConsumer), "POST", "/admin/kafka/pause", "")
+
+		assert.Equal(t, 200, h.Code)
+		assert.JSONEq(t, `{"partitions":[]}`, h.Body.String())
+	})
+
+	t.Run("NotAssigned", func(t *testing.T) {
+		mockConsumer := new(mocks.IConsumerAdmin)
+		mockConsumer.On("Resume", mock.Anything).Return(nil, consumer.ErrNotAssigned)
+
+		h := serveKafka(NewKafkaHandler(mockConsumer), "POST", "/admin/kafka/resume", `{"partitions":[{"topic":"Unknown","partition":0}]}`)
+
+		assert.Equal(t, 404, h.Code)
+	})
+
+	t.Run("TopicIsRequired", func(t *testing.T) {
+		mockConsumer := new(mocks.IConsumerAdmin)
+
+		h := serveKafka(NewKafkaHandler(mockConsumer), "POST", "/admin/kafka/pause", `{"partitions":[{"partition":1}]}`)
+
+		assert.Equal(t, 400, h.Code)
+		mockConsumer.AssertNotCalled(t, "Pause", mock.Anything)
+	})
+}
+
+func TestKafkaHandler_Seek(t *testing.T) {
+	t.Run("Offset", func(t *testing.T) {
+		tp := consumer.TopicPartition{Topic: "Orders", Partition: 2, Offset: 100}
+		mockConsumer := new(mocks.IConsumerAdmin)
+		mockConsumer.On("Seek", mock.Anything, tp).Return(nil)
+
+		h := serveKafka(NewKafkaHandler(mockConsumer), "POST", "/admin/kafka/seek", `{"topic":"Orders","partition":2,"offset":100}`)
+
+		assert.Equal(t, 200, h.Code)
+		assert.JSONEq(t, `{"topic":"Orders","partition":2,"offset":100}`, h.Body.String())
+	})
+
+	t.Run("Timestamp", func(t *testing.T) {
+		ts := time.Date(2021, 11, 26, 6, 0, 0, 0, time.UTC)
+		mockConsumer := new(mocks.IConsumerAdmin)
+		mockConsumer.On("SeekToTime", mock.Anything, consumer.TopicPartition{Topic: "Orders"}, ts).
+			Return(consumer.TopicPartition{Topic: "Orders", Offset: 17}, nil)
+
+		h := serveKafka(NewKafkaHandler(mockConsumer), "POST", "/admin/kafka/seek", `{"topic":"Orders","timestamp":"2021-11-26T06:00:00Z"}`)
+
+		assert.Equal(t, 200, h.Code)
+		assert.JSONEq(t, `{"topic":"Orders","partition":0,"offset":17}`, h.Body.String())
+	})
+
+	t.Run("PartitionBusy", func(t *testing.T) {
+		mockConsumer := new(mocks.IConsumerAdmin)
+		mockConsumer.On("Seek", mock.Anything, mock.Anything).Return(fmt.Errorf("%w: Orders [0]", consumer.ErrBusy))
+
+		h := serveKafka(NewKafkaHandler(mockConsumer), "POST", "/admin/kafka/seek", `{"topic":"Orders","offset":100}`)
+
+		assert.Equal(t, 409, h.Code)
+	})
+
+	t.Run("OffsetOrTimestampIsRequired", func(t *testing.T) {
+		for _, body := range []string{`{"topic":"Orders"}`, `{"topic":"Orders","offset":1,"timestamp":"2021-11-26T06:00:00Z"}`, `{"topic":"Orders","offset":-1}`} {
+			mockConsumer := new(mocks.IConsumerAdmin)
+
+			h := serveKafka(NewKafkaHandler(mockConsumer), "POST", "/admin/kafka/seek", body)
+
+			assert.Equal(t, 400, h.Code, body)
+		}
+	})
+}
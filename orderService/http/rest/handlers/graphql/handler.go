@@ -0,0 +1,82 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	gql "github.com/graphql-go/graphql"
+	"net/http"
+	"orderService/configs"
+	"orderService/http/rest/middleware"
+	"orderService/internal/privacy"
+	"orderService/internal/service"
+)
+
+type Handler struct {
+	service service.IOrderService
+	schema  gql.Schema
+	limits  limits
+}
+
+type queryRequest struct {
+	Query         string         `json:"query" form:"query" binding:"required"`
+	OperationName string         `json:"operationName" form:"operationName"`
+	Variables     map[string]any `json:"variables" form:"-"`
+}
+
+func NewHandler(service service.IOrderService, projector privacy.Projector, cnf configs.GraphQL) (Handler, error) {
+	schema, err := NewSchema(service, projector)
+	if err != nil {
+		return Handler{}, err
+	}
+
+	return Handler{
+		service: service,
+		schema:  schema,
+		limits:  limits{maxDepth: cnf.MaxDepth, maxComplexity: cnf.MaxComplexity},
+	}, nil
+}
+
+// Query 				godoc
+// @Summary				GraphQL query over orders
+// @Description			Executes a GraphQL query. Available queries: order(uid), orderByTrackNumber(trackNumber), orders(customerId, first, after).
+// @Description			A GET request passes query, operationName and variables (JSON) as query parameters. Queries deeper or more complex than the configured limits are rejected
+// @Accept				application/json
+// @Produce				application/json
+// @Tags				graphql
+// @Security			ApiKeyAuth
+// @Security			BearerAuth
+// @Success				200 {object} map[string]interface{}
+// @Router				/graphql [post]
+func (h Handler) Query(c *gin.Context) {
+	var req queryRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "query is required"})
+		return
+	}
+	if variables := c.Query("variables"); c.Request.Method == http.MethodGet && variables != "" {
+		if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "variables must be a JSON object"})
+			return
+		}
+	}
+	if err := h.limits.check(req.Query, req.OperationName, req.Variables); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.WithValue(c.Request.Context(), requestKey, &request{
+		viewer: middleware.Viewer(c),
+		loader: newItemLoader(h.service),
+	})
+
+	result := gql.Do(gql.Params{
+		Schema:         h.schema,
+		RequestString:  req.Query,
+		OperationName:  req.OperationName,
+		VariableValues: req.Variables,
+		Context:        ctx,
+	})
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,298 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"orderService/configs"
+	"orderService/http/rest/middleware"
+	"orderService/internal/models"
+	"orderService/internal/privacy"
+	"orderService/internal/service/mocks"
+	"strings"
+	"testing"
+	"time"
+)
+
+var policy = privacy.Policy{privacy.FieldPhone: {"support"}}
+var projector = privacy.NewProjector(policy, nil)
+
+var limitsConfig = configs.GraphQL{MaxDepth: 10, MaxComplexity: 5000}
+
+func newOrder(uid uuid.UUID, dateCreated time.Time) models.Order {
+	return models.Order{
+		Uid:             uid,
+		TrackNumber:     "WBILMTESTTRACK",
+		CustomerID:      "100900",
+		DeliveryService: "meest",
+		DateCreated:     dateCreated,
+		Delivery:        models.Delivery{Name: "Test Testov", Phone: "+9720000000", City: "Moscow"},
+		Payment:         models.Payment{Currency: "USD", Amount: 1817},
+	}
+}
+
+func query(t *testing.T, handler Handler, roles []string, q string) string {
+	g := gin.New()
+	g.POST("/graphql", func(c *gin.Context) {
+		c.Set(middleware.RolesKey, roles)
+	}, handler.Query)
+
+	body, err := json.Marshal(map[string]string{"query": q})
+	require.NoError(t, err)
+
+	h := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	g.ServeHTTP(h, r)
+
+	assert.Equal(t, http.StatusOK, h.Code)
+	return h.Body.String()
+}
+
+func TestHandler_Order(t *testing.T) {
+	uid := uuid.MustParse("1e9ad4fb-2615-46f9-9458-20b59253086b")
+	order := newOrder(uid, time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC))
+	order.Items = []models.Item{{Name: "Mascaras", TotalPrice: 317, Brand: "Vivienne Sabo"}}
+
+	mockOrderService := new(mocks.IOrderService)
+	mockOrderService.On("GetById", uid).Return(order.ToOrderView(), nil)
+	handler, err := NewHandler(mockOrderService, projector, limitsConfig)
+	require.NoError(t, err)
+
+	t.Run("SelectedFieldsOnly", func(t *testing.T) {
+		body := query(t, handler, nil, fmt.Sprintf(`{ order(uid: "%s") { delivery { city phone } items { brand } } }`, uid))
+
+		assert.JSONEq(t, `{"data":{"order":{"delivery":{"city":"Moscow","phone":"+972*****00"},"items":[{"brand":"Vivienne Sabo"}]}}}`, body)
+		mockOrderService.AssertNotCalled(t, "GetItems", mock.Anything)
+	})
+
+	t.Run("UnmaskedForAllowedRole", func(t *testing.T) {
+		body := query(t, handler, []string{"support"}, fmt.Sprintf(`{ order(uid: "%s") { delivery { name phone } } }`, uid))
+
+		assert.JSONEq(t, `{"data":{"order":{"delivery":{"name":"T*** T*****","phone":"+9720000000"}}}}`, body)
+	})
+
+	t.Run("UnmaskedReadIsAudited", func(t *testing.T) {
+		mockAudit := new(mocks.IAuditService)
+		mockAudit.On("Record", mock.Anything).Return()
+		handler, err := NewHandler(mockOrderService, privacy.NewProjector(policy, mockAudit), limitsConfig)
+		require.NoError(t, err)
+
+		query(t, handler, nil, fmt.Sprintf(`{ order(uid: "%s") { delivery { phone } } }`, uid))
+		mockAudit.AssertNotCalled(t, "Record", mock.Anything)
+
+		query(t, handler, []string{"support"}, fmt.Sprintf(`{ order(uid: "%s") { delivery { phone } } }`, uid))
+		mockAudit.AssertNumberOfCalls(t, "Record", 1)
+		entry := mockAudit.Calls[0].Arguments.Get(0).(models.AuditEntry)
+		assert.Equal(t, models.AuditOrderViewed, entry.Action)
+		assert.Equal(t, uid, *entry.OrderUid)
+		assert.JSONEq(t, `{"unmasked":["phone"]}`, string(entry.Details))
+	})
+
+	t.Run("ReadWithoutUnmaskedFieldsIsNotAudited", func(t *testing.T) {
+		mockAudit := new(mocks.IAuditService)
+		mockAudit.On("Record", mock.Anything).Return()
+		handler, err := NewHandler(mockOrderService, privacy.NewProjector(policy, mockAudit), limitsConfig)
+		require.NoError(t, err)
+
+		query(t, handler, []string{"support"}, fmt.Sprintf(`{ order(uid: "%s") { trackNumber delivery { city name } } }`, uid))
+
+		mockAudit.AssertNotCalled(t, "Record", mock.Anything)
+	})
+
+	t.Run("ReadIsAuditedOncePerOrder", func(t *testing.T) {
+		mockAudit := new(mocks.IAuditService)
+		mockAudit.On("Record", mock.Anything).Return()
+		handler, err := NewHandler(mockOrderService, privacy.NewProjector(policy, mockAudit), limitsConfig)
+		require.NoError(t, err)
+
+		query(t, handler, []string{"support"}, fmt.Sprintf(`{ order(uid: "%s") { delivery { phone } again: delivery { phone } } }`, uid))
+
+		mockAudit.AssertNumberOfCalls(t, "Record", 1)
+	})
+
+	t.Run("InvalidUid", func(t *testing.T) {
+		body := query(t, handler, nil, `{ order(uid: "1") { uid } }`)
+
+		assert.Contains(t, body, "uid is not UUID format")
+	})
+}
+
+func TestHandler_Orders(t *testing.T) {
+	dateCreated := time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC)
+	uids := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	orders := []models.Order{
+		newOrder(uids[0], dateCreated.Add(2*time.Hour)),
+		newOrder(uids[1], dateCreated.Add(time.Hour)),
+		newOrder(uids[2], dateCreated),
+	}
+
+	mockOrderService := new(mocks.IOrderService)
+	mockOrderService.On("ListByCustomer", "100900", (*models.OrderCursor)(nil), 3).Return(orders, nil)
+	mockOrderService.On("GetItems", []uuid.UUID{uids[0], uids[1]}).Return(map[uuid.UUID][]models.Item{
+		uids[0]: {{OrderUid: uids[0], Brand: "Vivienne Sabo"}, {OrderUid: uids[0], Brand: "Nivea"}},
+	}, nil).Once()
+	handler, err := NewHandler(mockOrderService, projector, limitsConfig)
+	require.NoError(t, err)
+
+	body := query(t, handler, nil, `{ orders(customerId: "100900", first: 2) { edges { cursor node { uid items { brand } } } pageInfo { hasNextPage endCursor } } }`)
+
+	var result struct {
+		Data struct {
+			Orders struct {
+				Edges []struct {
+					Cursor string
+					Node   struct {
+						Uid   string
+						Items []struct{ Brand string }
+					}
+				}
+				PageInfo struct {
+					HasNextPage bool
+					EndCursor   string
+				}
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal([]byte(body), &result), body)
+
+	edges := result.Data.Orders.Edges
+	require.Len(t, edges, 2)
+	assert.Equal(t, uids[0].String(), edges[0].Node.Uid)
+	assert.Len(t, edges[0].Node.Items, 2)
+	assert.Empty(t, edges[1].Node.Items)
+	assert.True(t, result.Data.Orders.PageInfo.HasNextPage)
+	assert.Equal(t, edges[1].Cursor, result.Data.Orders.PageInfo.EndCursor)
+
+	cursor, err := models.DecodeOrderCursor(result.Data.Orders.PageInfo.EndCursor)
+	require.NoError(t, err)
+	assert.Equal(t, models.OrderCursor{DateCreated: dateCreated.Add(time.Hour), Uid: uids[1]}, cursor)
+	mockOrderService.AssertNumberOfCalls(t, "GetItems", 1)
+}
+
+func TestHandler_OrdersAfterCursor(t *testing.T) {
+	cursor := models.OrderCursor{DateCreated: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC), Uid: uuid.New()}
+
+	mockOrderService := new(mocks.IOrderService)
+	mockOrderService.On("ListByCustomer", "100900", &cursor, 21).Return([]models.Order{}, nil)
+	handler, err := NewHandler(mockOrderService, projector, limitsConfig)
+	require.NoError(t, err)
+
+	body := query(t, handler, nil, fmt.Sprintf(`{ orders(customerId: "100900", after: "%s") { edges { cursor } pageInfo { hasNextPage } } }`, cursor.Encode()))
+
+	assert.JSONEq(t, `{"data":{"orders":{"edges":[],"pageInfo":{"hasNextPage":false}}}}`, body)
+	mockOrderService.AssertNotCalled(t, "GetItems", mock.Anything)
+}
+
+func TestHandler_QueryString(t *testing.T) {
+	uid := uuid.MustParse("1e9ad4fb-2615-46f9-9458-20b59253086b")
+	mockOrderService := new(mocks.IOrderService)
+	order := newOrder(uid, time.Now())
+	mockOrderService.On("GetById", uid).Return(order.ToOrderView(), nil)
+	handler, err := NewHandler(mockOrderService, projector, limitsConfig)
+	require.NoError(t, err)
+	g := gin.New()
+	g.GET("/graphql", handler.Query)
+
+	get := func(params url.Values) *httptest.ResponseRecorder {
+		h := httptest.NewRecorder()
+		g.ServeHTTP(h, httptest.NewRequest(http.MethodGet, "/graphql?"+params.Encode(), nil))
+		return h
+	}
+
+	t.Run("VariablesAndOperationName", func(t *testing.T) {
+		h := get(url.Values{
+			"query":         {`query ByUid($uid: ID!) { order(uid: $uid) { trackNumber } } query Other { orders(customerId: "1") { pageInfo { hasNextPage } } }`},
+			"operationName": {"ByUid"},
+			"variables":     {fmt.Sprintf(`{"uid":"%s"}`, uid)},
+		})
+
+		assert.Equal(t, http.StatusOK, h.Code)
+		assert.JSONEq(t, `{"data":{"order":{"trackNumber":"WBILMTESTTRACK"}}}`, h.Body.String())
+	})
+
+	t.Run("InvalidVariables", func(t *testing.T) {
+		h := get(url.Values{"query": {`{ order(uid: "1") { uid } }`}, "variables": {"uid"}})
+
+		assert.Equal(t, http.StatusBadRequest, h.Code)
+		assert.JSONEq(t, `{"error":"variables must be a JSON object"}`, h.Body.String())
+	})
+}
+
+func TestHandler_Limits(t *testing.T) {
+	mockOrderService := new(mocks.IOrderService)
+	handler, err := NewHandler(mockOrderService, projector, configs.GraphQL{MaxDepth: 4, MaxComplexity: 100})
+	require.NoError(t, err)
+
+	tableData := []struct {
+		name      string
+		query     string
+		variables map[string]any
+		err       string
+	}{
+		{
+			name:  "TooDeep",
+			query: `{ orders(customerId: "1", first: 1) { edges { node { delivery { city } } } } }`,
+			err:   "query depth 5 exceeds the limit of 4",
+		},
+		{
+			name:  "TooComplex",
+			query: `{ orders(customerId: "1", first: 50) { edges { cursor node { uid } } } }`,
+			err:   "query complexity 201 exceeds the limit of 100",
+		},
+		{
+			name:      "FirstFromVariables",
+			query:     `query List($first: Int) { orders(customerId: "1", first: $first) { edges { cursor node { uid } } } }`,
+			variables: map[string]any{"first": float64(50)},
+			err:       "query complexity 201 exceeds the limit of 100",
+		},
+		{
+			name:  "DefaultPageSize",
+			query: `{ orders(customerId: "1") { edges { node { uid trackNumber customerId } } } }`,
+			err:   "query complexity 101 exceeds the limit of 100",
+		},
+		{
+			name:  "DepthThroughFragments",
+			query: `{ orders(customerId: "1", first: 1) { ...Edges } } fragment Edges on OrderConnection { edges { node { ... on Order { delivery { city } } } } }`,
+			err:   "query depth 5 exceeds the limit of 4",
+		},
+		{
+			name:  "CyclicFragments",
+			query: `{ order(uid: "1") { ...A } } fragment A on Order { ...B } fragment B on Order { ...A }`,
+		},
+	}
+
+	for _, td := range tableData {
+		t.Run(td.name, func(t *testing.T) {
+			err := handler.limits.check(td.query, "", td.variables)
+
+			if td.err == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.EqualError(t, err, td.err)
+			}
+		})
+	}
+
+	t.Run("RejectedBeforeExecution", func(t *testing.T) {
+		g := gin.New()
+		g.POST("/graphql", handler.Query)
+		h := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query":"{ orders(customerId: \"1\", first: 100) { edges { cursor } } }"}`))
+		r.Header.Set("Content-Type", "application/json")
+
+		g.ServeHTTP(h, r)
+
+		assert.Equal(t, http.StatusBadRequest, h.Code)
+		assert.JSONEq(t, `{"error":"query complexity 201 exceeds the limit of 100"}`, h.Body.String())
+		mockOrderService.AssertNotCalled(t, "ListByCustomer", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,145 @@
+package graphql
+
+import (
+	"fmt"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"strconv"
+)
+
+// maxCost saturates the complexity so nested fragments do not overflow it
+const maxCost = 1 << 31
+
+// limits rejects queries that are too expensive before they are executed
+type limits struct {
+	maxDepth      int
+	maxComplexity int
+}
+
+// queryCost is the nesting depth of the selected fields and their complexity: every field costs 1,
+// the fields under a paginated field count once per requested item
+type queryCost struct {
+	depth      int
+	complexity int
+}
+
+// check measures the operation that is executed, every operation when operationName is empty.
+// A query that does not parse is left to the execution to report
+func (l limits) check(query string, operationName string, variables map[string]any) error {
+	document, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return nil
+	}
+
+	m := &measurer{
+		fragments: make(map[string]*ast.FragmentDefinition),
+		costs:     make(map[string]queryCost),
+		visiting:  make(map[string]bool),
+		variables: variables,
+	}
+	for _, definition := range document.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			m.fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok || (operationName != "" && (operation.Name == nil || operation.Name.Value != operationName)) {
+			continue
+		}
+		cost := m.selectionSet(operation.SelectionSet, 1)
+		if cost.depth > l.maxDepth {
+			return fmt.Errorf("query depth %d exceeds the limit of %d", cost.depth, l.maxDepth)
+		}
+		if cost.complexity > l.maxComplexity {
+			return fmt.Errorf("query complexity %d exceeds the limit of %d", cost.complexity, l.maxComplexity)
+		}
+	}
+	return nil
+}
+
+type measurer struct {
+	fragments map[string]*ast.FragmentDefinition
+	// costs keeps the cost of every fragment measured from depth 1, a fragment spread many times is walked once
+	costs     map[string]queryCost
+	visiting  map[string]bool
+	variables map[string]any
+}
+
+// selectionSet returns the cost of the selections whose fields are at depth
+func (m *measurer) selectionSet(set *ast.SelectionSet, depth int) queryCost {
+	var total queryCost
+	if set == nil {
+		return total
+	}
+
+	for _, selection := range set.Selections {
+		var cost queryCost
+		switch s := selection.(type) {
+		case *ast.Field:
+			children := m.selectionSet(s.SelectionSet, depth+1)
+			cost = queryCost{depth: max(depth, children.depth), complexity: min(1+children.complexity*m.multiplier(s), maxCost)}
+		case *ast.InlineFragment:
+			cost = m.selectionSet(s.SelectionSet, depth)
+		case *ast.FragmentSpread:
+			fragment, ok := m.fragment(s.Name.Value)
+			if !ok {
+				continue
+			}
+			cost = queryCost{depth: depth - 1 + fragment.depth, complexity: fragment.complexity}
+		}
+		total.depth = max(total.depth, cost.depth)
+		total.complexity = min(total.complexity+cost.complexity, maxCost)
+	}
+	return total
+}
+
+// fragment returns the cost of a named fragment measured from depth 1. Unknown and cyclic spreads are
+// not measured, the validation rejects them
+func (m *measurer) fragment(name string) (queryCost, bool) {
+	if cost, ok := m.costs[name]; ok {
+		return cost, true
+	}
+	definition, ok := m.fragments[name]
+	if !ok || m.visiting[name] {
+		return queryCost{}, false
+	}
+
+	m.visiting[name] = true
+	cost := m.selectionSet(definition.SelectionSet, 1)
+	delete(m.visiting, name)
+	m.costs[name] = cost
+	return cost, true
+}
+
+// multiplier is the number of items a paginated field returns at most, 1 for other fields
+func (m *measurer) multiplier(field *ast.Field) int {
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != "first" {
+			continue
+		}
+		switch value := argument.Value.(type) {
+		case *ast.IntValue:
+			if first, err := strconv.Atoi(value.Value); err == nil {
+				return pageSize(first)
+			}
+		case *ast.Variable:
+			// JSON numbers are decoded as float64
+			if first, ok := m.variables[value.Name.Value].(float64); ok {
+				return pageSize(int(first))
+			}
+		}
+		return maxPageSize
+	}
+
+	if field.Name.Value == "orders" {
+		return defaultPageSize
+	}
+	return 1
+}
+
+// pageSize bounds first the way the orders resolver does, values outside the bounds are rejected by it
+func pageSize(first int) int {
+	return min(max(first, 1), maxPageSize)
+}
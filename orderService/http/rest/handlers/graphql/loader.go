@@ -0,0 +1,63 @@
+package graphql
+
+import (
+	"github.com/google/uuid"
+	"orderService/internal/models"
+	"orderService/internal/service"
+	"sync"
+)
+
+// itemLoader collects the order uids requested while resolving one level of the query and loads
+// their items with a single service call when the first thunk is resolved
+type itemLoader struct {
+	service service.IOrderService
+
+	mu      sync.Mutex
+	pending []uuid.UUID
+	loaded  map[uuid.UUID][]models.ItemView
+	err     error
+}
+
+func newItemLoader(service service.IOrderService) *itemLoader {
+	return &itemLoader{service: service, loaded: make(map[uuid.UUID][]models.ItemView)}
+}
+
+func (l *itemLoader) Load(uid uuid.UUID) func() (any, error) {
+	l.mu.Lock()
+	if _, ok := l.loaded[uid]; !ok {
+		l.pending = append(l.pending, uid)
+	}
+	l.mu.Unlock()
+
+	return func() (any, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if len(l.pending) > 0 {
+			l.dispatch()
+		}
+		if l.err != nil {
+			return nil, l.err
+		}
+		return l.loaded[uid], nil
+	}
+}
+
+func (l *itemLoader) dispatch() {
+	uids := l.pending
+	l.pending = nil
+
+	items, err := l.service.GetItems(uids)
+	if err != nil {
+		l.err = err
+		return
+	}
+
+	for _, uid := range uids {
+		views := make([]models.ItemView, 0, len(items[uid]))
+		for _, item := range items[uid] {
+			views = append(views, models.ItemView{Name: item.Name, TotalPrice: item.TotalPrice, Brand: item.Brand})
+		}
+		l.loaded[uid] = views
+	}
+}
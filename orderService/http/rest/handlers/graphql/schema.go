@@ -0,0 +1,261 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	gql "github.com/graphql-go/graphql"
+	"orderService/internal/models"
+	"orderService/internal/privacy"
+	"orderService/internal/service"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+type contextKey string
+
+const requestKey contextKey = "graphqlRequest"
+
+// request holds the per-request state shared by resolvers
+type request struct {
+	viewer privacy.Viewer
+	loader *itemLoader
+
+	mu sync.Mutex
+	// audited keeps the orders whose personal data read is already recorded in this request
+	audited map[uuid.UUID]bool
+}
+
+// viewed audits the read of the order personal data once per request
+func (r *request) viewed(projector privacy.Projector, uid uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.audited[uid] {
+		return
+	}
+	if r.audited == nil {
+		r.audited = make(map[uuid.UUID]bool)
+	}
+	r.audited[uid] = true
+	projector.Viewed(r.viewer, uid)
+}
+
+// orderNode is the source of the Order type. Orders loaded from the cache already have items,
+// orders loaded from the repository get their items from the loader
+type orderNode struct {
+	view        models.OrderView
+	itemsLoaded bool
+}
+
+// deliveryNode is the source of the Delivery type, the order uid is kept to audit the personal data reads
+type deliveryNode struct {
+	uid      uuid.UUID
+	delivery models.DeliveryView
+}
+
+type orderEdge struct {
+	Cursor string
+	Node   orderNode
+}
+
+type pageInfo struct {
+	HasNextPage bool
+	EndCursor   string
+}
+
+type orderConnection struct {
+	Edges    []orderEdge
+	PageInfo pageInfo
+}
+
+func NewSchema(orderService service.IOrderService, projector privacy.Projector) (gql.Schema, error) {
+	// the orders are only masked here, the read is audited when an unmasked personal data field is resolved
+	node := func(ctx context.Context, order models.OrderView, itemsLoaded bool) orderNode {
+		return orderNode{view: projector.Mask(order, requestFrom(ctx).viewer.Roles), itemsLoaded: itemsLoaded}
+	}
+	personalField := func(field privacy.Field, get func(models.DeliveryView) any) gql.FieldResolveFn {
+		return func(p gql.ResolveParams) (any, error) {
+			n := p.Source.(deliveryNode)
+			req := requestFrom(p.Context)
+			if slices.Contains(projector.Unmasked(req.viewer.Roles), field) {
+				req.viewed(projector, n.uid)
+			}
+			return get(n.delivery), nil
+		}
+	}
+
+	deliveryType := gql.NewObject(gql.ObjectConfig{
+		Name: "Delivery",
+		Fields: gql.Fields{
+			"name":    &gql.Field{Type: gql.String, Resolve: personalField(privacy.FieldName, func(d models.DeliveryView) any { return d.Name })},
+			"phone":   &gql.Field{Type: gql.String, Resolve: personalField(privacy.FieldPhone, func(d models.DeliveryView) any { return d.Phone })},
+			"zip":     &gql.Field{Type: gql.String, Resolve: personalField(privacy.FieldZip, func(d models.DeliveryView) any { return d.Zip })},
+			"city":    &gql.Field{Type: gql.String, Resolve: deliveryField(func(d models.DeliveryView) any { return d.City })},
+			"address": &gql.Field{Type: gql.String, Resolve: personalField(privacy.FieldAddress, func(d models.DeliveryView) any { return d.Address })},
+			"region":  &gql.Field{Type: gql.String, Resolve: deliveryField(func(d models.DeliveryView) any { return d.Region })},
+			"email":   &gql.Field{Type: gql.String, Resolve: personalField(privacy.FieldEmail, func(d models.DeliveryView) any { return d.Email })},
+		},
+	})
+
+	paymentType := gql.NewObject(gql.ObjectConfig{
+		Name: "Payment",
+		Fields: gql.Fields{
+			"currency":     &gql.Field{Type: gql.String},
+			"provider":     &gql.Field{Type: gql.String},
+			"amount":       &gql.Field{Type: gql.Int},
+			"deliveryCost": &gql.Field{Type: gql.Int},
+			"goodsTotal":   &gql.Field{Type: gql.Int},
+		},
+	})
+
+	itemType := gql.NewObject(gql.ObjectConfig{
+		Name: "Item",
+		Fields: gql.Fields{
+			"name":       &gql.Field{Type: gql.String},
+			"totalPrice": &gql.Field{Type: gql.Int},
+			"brand":      &gql.Field{Type: gql.String},
+		},
+	})
+
+	orderType := gql.NewObject(gql.ObjectConfig{
+		Name: "Order",
+		Fields: gql.Fields{
+			"uid":             &gql.Field{Type: gql.NewNonNull(gql.ID), Resolve: viewField(func(v models.OrderView) any { return v.Uid.String() })},
+			"trackNumber":     &gql.Field{Type: gql.String, Resolve: viewField(func(v models.OrderView) any { return v.TrackNumber })},
+			"customerId":      &gql.Field{Type: gql.String, Resolve: viewField(func(v models.OrderView) any { return v.CustomerID })},
+			"deliveryService": &gql.Field{Type: gql.String, Resolve: viewField(func(v models.OrderView) any { return v.DeliveryService })},
+			"dateCreated":     &gql.Field{Type: gql.DateTime, Resolve: viewField(func(v models.OrderView) any { return v.DateCreated.UTC().Format(time.RFC3339) })},
+			"delivery":        &gql.Field{Type: deliveryType, Resolve: viewField(func(v models.OrderView) any { return deliveryNode{uid: v.Uid, delivery: v.Delivery} })},
+			"payment":         &gql.Field{Type: paymentType, Resolve: viewField(func(v models.OrderView) any { return v.Payment })},
+			"items": &gql.Field{
+				Type: gql.NewList(itemType),
+				Resolve: func(p gql.ResolveParams) (any, error) {
+					n := p.Source.(orderNode)
+					if n.itemsLoaded {
+						return n.view.Items, nil
+					}
+					return requestFrom(p.Context).loader.Load(n.view.Uid), nil
+				},
+			},
+		},
+	})
+
+	connectionType := gql.NewObject(gql.ObjectConfig{
+		Name: "OrderConnection",
+		Fields: gql.Fields{
+			"edges": &gql.Field{Type: gql.NewList(gql.NewObject(gql.ObjectConfig{
+				Name: "OrderEdge",
+				Fields: gql.Fields{
+					"cursor": &gql.Field{Type: gql.NewNonNull(gql.String)},
+					"node":   &gql.Field{Type: orderType},
+				},
+			}))},
+			"pageInfo": &gql.Field{Type: gql.NewObject(gql.ObjectConfig{
+				Name: "PageInfo",
+				Fields: gql.Fields{
+					"hasNextPage": &gql.Field{Type: gql.NewNonNull(gql.Boolean)},
+					"endCursor":   &gql.Field{Type: gql.String},
+				},
+			})},
+		},
+	})
+
+	queryType := gql.NewObject(gql.ObjectConfig{
+		Name: "Query",
+		Fields: gql.Fields{
+			"order": &gql.Field{
+				Type: orderType,
+				Args: gql.FieldConfigArgument{"uid": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)}},
+				Resolve: func(p gql.ResolveParams) (any, error) {
+					uid, err := uuid.Parse(p.Args["uid"].(string))
+					if err != nil {
+						return nil, errors.New("uid is not UUID format")
+					}
+					order, err := orderService.GetById(uid)
+					if err != nil {
+						return nil, err
+					}
+					return node(p.Context, order, true), nil
+				},
+			},
+			"orderByTrackNumber": &gql.Field{
+				Type: orderType,
+				Args: gql.FieldConfigArgument{"trackNumber": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.String)}},
+				Resolve: func(p gql.ResolveParams) (any, error) {
+					order, err := orderService.GetByTrackNumber(p.Args["trackNumber"].(string))
+					if err != nil {
+						return nil, err
+					}
+					return node(p.Context, order.ToOrderView(), false), nil
+				},
+			},
+			"orders": &gql.Field{
+				Type: connectionType,
+				Args: gql.FieldConfigArgument{
+					"customerId": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.String)},
+					"first":      &gql.ArgumentConfig{Type: gql.Int, DefaultValue: defaultPageSize},
+					"after":      &gql.ArgumentConfig{Type: gql.String},
+				},
+				Resolve: func(p gql.ResolveParams) (any, error) {
+					first := p.Args["first"].(int)
+					if first <= 0 || first > maxPageSize {
+						return nil, errors.New("first must be between 1 and 100")
+					}
+
+					var after *models.OrderCursor
+					if cursorStr, ok := p.Args["after"].(string); ok {
+						cursor, err := models.DecodeOrderCursor(cursorStr)
+						if err != nil {
+							return nil, err
+						}
+						after = &cursor
+					}
+
+					orders, err := orderService.ListByCustomer(p.Args["customerId"].(string), after, first+1)
+					if err != nil {
+						return nil, err
+					}
+
+					connection := orderConnection{PageInfo: pageInfo{HasNextPage: len(orders) > first}}
+					if connection.PageInfo.HasNextPage {
+						orders = orders[:first]
+					}
+					for _, order := range orders {
+						connection.Edges = append(connection.Edges, orderEdge{
+							Cursor: models.NewOrderCursor(order).Encode(),
+							Node:   node(p.Context, order.ToOrderView(), false),
+						})
+					}
+					if len(connection.Edges) > 0 {
+						connection.PageInfo.EndCursor = connection.Edges[len(connection.Edges)-1].Cursor
+					}
+					return connection, nil
+				},
+			},
+		},
+	})
+
+	return gql.NewSchema(gql.SchemaConfig{Query: queryType})
+}
+
+func viewField(get func(models.OrderView) any) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (any, error) {
+		return get(p.Source.(orderNode).view), nil
+	}
+}
+
+func deliveryField(get func(models.DeliveryView) any) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (any, error) {
+		return get(p.Source.(deliveryNode).delivery), nil
+	}
+}
+
+func requestFrom(ctx context.Context) *request {
+	return ctx.Value(requestKey).(*request)
+}
@@ -0,0 +1,79 @@
+package lifecycle
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
+	"orderService/http/rest/middleware"
+	"orderService/internal/service"
+)
+
+type erasureResponse struct {
+	CustomerID string `json:"customer_id"`
+	Orders     int    `json:"orders"`
+}
+
+type Handler struct {
+	service service.ILifecycleService
+}
+
+func NewHandler(service service.ILifecycleService) Handler {
+	return Handler{service: service}
+}
+
+// DeleteOrder 			godoc
+// @Summary				Delete order
+// @Param				id path string true "Order id"
+// @Description			Soft delete the order, it is no longer returned and is removed for good by the retention job
+// @Tags				order
+// @Security			ApiKeyAuth
+// @Security			BearerAuth
+// @Success				204
+// @Router				/order/{id} [delete]
+func (h Handler) DeleteOrder(c *gin.Context) {
+	uidStr := c.Param("uid")
+	uid, err := uuid.Parse(uidStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "uid is not UUID format"})
+		log.Printf("uid %s is not UUID format", uidStr)
+		return
+	}
+
+	if err = h.service.Delete(uid, middleware.Actor(c)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete order"})
+		log.Println(err.Error())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ErasePersonalData 	godoc
+// @Summary				Erase customer personal data
+// @Param				customerId path string true "Customer id"
+// @Description			Anonymize name, phone, zip, address and email of every customer order. Payments and items are kept
+// @Produce				application/json
+// @Tags				customer
+// @Security			ApiKeyAuth
+// @Security			BearerAuth
+// @Success				200 {object} erasureResponse
+// @Router				/customers/{customerId}/personal-data [delete]
+func (h Handler) ErasePersonalData(c *gin.Context) {
+	customerID := c.Param("customerId")
+
+	orders, err := h.service.ErasePersonalData(customerID, middleware.Actor(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to erase personal data"})
+		log.Println(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, erasureResponse{CustomerID: customerID, Orders: orders})
+}
@@ -0,0 +1,82 @@
+package lifecycle
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"net/http/httptest"
+	"orderService/http/rest/middleware"
+	"orderService/internal/auth"
+	"orderService/internal/service/mocks"
+	"testing"
+)
+
+var uid = uuid.MustParse("1e9ad4fb-2615-46f9-9458-20b59253086b")
+
+func serve(handler Handler, method, url string) *httptest.ResponseRecorder {
+	g := gin.New()
+	g.Use(func(c *gin.Context) {
+		c.Set(middleware.PrincipalKey, auth.Principal{Subject: "dpo", Method: auth.MethodJWT})
+	})
+	g.DELETE("/order/:uid", handler.DeleteOrder)
+	g.DELETE("/customers/:customerId/personal-data", handler.ErasePersonalData)
+
+	h := httptest.NewRecorder()
+	g.ServeHTTP(h, httptest.NewRequest(method, url, nil))
+	return h
+}
+
+func TestHandler_DeleteOrder(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockLifecycle := new(mocks.ILifecycleService)
+		mockLifecycle.On("Delete", uid, "jwt:dpo").Return(nil)
+
+		h := serve(NewHandler(mockLifecycle), "DELETE", fmt.Sprintf("/order/%s", uid))
+
+		assert.Equal(t, 204, h.Code)
+		mockLifecycle.AssertCalled(t, "Delete", uid, "jwt:dpo")
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockLifecycle := new(mocks.ILifecycleService)
+		mockLifecycle.On("Delete", uid, "jwt:dpo").Return(gorm.ErrRecordNotFound)
+
+		h := serve(NewHandler(mockLifecycle), "DELETE", fmt.Sprintf("/order/%s", uid))
+
+		assert.Equal(t, 404, h.Code)
+		assert.JSONEq(t, `{"error":"record not found"}`, h.Body.String())
+	})
+
+	t.Run("UidIsNotUUIDType", func(t *testing.T) {
+		mockLifecycle := new(mocks.ILifecycleService)
+
+		h := serve(NewHandler(mockLifecycle), "DELETE", "/order/1")
+
+		assert.Equal(t, 400, h.Code)
+		mockLifecycle.AssertNotCalled(t, "Delete")
+	})
+}
+
+func TestHandler_ErasePersonalData(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockLifecycle := new(mocks.ILifecycleService)
+		mockLifecycle.On("ErasePersonalData", "100900", "jwt:dpo").Return(3, nil)
+
+		h := serve(NewHandler(mockLifecycle), "DELETE", "/customers/100900/personal-data")
+
+		assert.Equal(t, 200, h.Code)
+		assert.JSONEq(t, `{"customer_id":"100900","orders":3}`, h.Body.String())
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		mockLifecycle := new(mocks.ILifecycleService)
+		mockLifecycle.On("ErasePersonalData", "100900", "jwt:dpo").Return(0, fmt.Errorf("connection refused"))
+
+		h := serve(NewHandler(mockLifecycle), "DELETE", "/customers/100900/personal-data")
+
+		assert.Equal(t, 500, h.Code)
+		assert.JSONEq(t, `{"error":"failed to erase personal data"}`, h.Body.String())
+	})
+}
@@ -1,30 +1,69 @@
 package order
 
 import (
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 	"log"
 	"net/http"
+	"orderService/http/rest/middleware"
+	"orderService/internal/export"
+	"orderService/internal/models"
+	"orderService/internal/privacy"
+	"orderService/internal/repository"
 	"orderService/internal/service"
+	"strings"
+	"time"
 )
 
+// maxBatchSize bounds the number of uids in one batch-get request
+const maxBatchSize = 500
+
+// cacheControl lets only the caller's own cache keep an order, it holds personal data masked for the caller roles.
+// The order has to be revalidated with its ETag before every reuse
+const cacheControl = "private, no-cache"
+
+type batchGetRequest struct {
+	Uids []uuid.UUID `json:"uids" binding:"required"`
+}
+
+type batchGetResponse struct {
+	Orders  []models.OrderView `json:"orders"`
+	Missing []uuid.UUID        `json:"missing"`
+}
+
+type updateStatusRequest struct {
+	Status int `json:"status" binding:"required"`
+}
+
 type Handler struct {
-	service service.IOrderService
+	service   service.IOrderService
+	projector privacy.Projector
 }
 
-func NewHandler(service service.IOrderService) Handler {
+func NewHandler(service service.IOrderService, projector privacy.Projector) Handler {
 	return Handler{
-		service: service,
+		service:   service,
+		projector: projector,
 	}
 }
 
 // FindByIdTags 		godoc
 // @Summary				Get Order by id
 // @Param				id path string true "Get order by id"
-// @Description			Return order by id
+// @Param				If-None-Match header string false "ETag of the cached order"
+// @Param				If-Modified-Since header string false "Last-Modified of the cached order, ignored with If-None-Match"
+// @Description			Return order by id. Personal data is masked unless the caller role is allowed to see it, unmasked reads are audited.
+// @Description			The response has ETag and Last-Modified headers, a conditional request for an unchanged order gets 304
 // @Produce				application/json
 // @Tags				order
+// @Security			ApiKeyAuth
+// @Security			BearerAuth
 // @Success				200 {object} models.OrderView
+// @Success				304
 // @Router				/order/{id} [get]
 func (h Handler) GetOrderById(c *gin.Context) {
 	uidStr := c.Param("uid")
@@ -42,5 +81,294 @@ func (h Handler) GetOrderById(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, order)
+	// a 304 does not disclose the order, it is masked for the validators only and not audited
+	masked := h.projector.Mask(order, middleware.Roles(c))
+	setValidators(c, masked)
+	c.Header("Cache-Control", cacheControl)
+	c.Writer.Header().Add("Vary", "Authorization, X-API-Key")
+	if notModified(c, masked) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.JSON(http.StatusOK, h.projector.Project(order, middleware.Viewer(c)))
+}
+
+// BatchGet 			godoc
+// @Summary				Get orders by ids
+// @Param				request body batchGetRequest true "Order ids, at most 500"
+// @Description			Return the found orders and the ids of orders that do not exist. Personal data is masked unless the caller role is allowed to see it, unmasked reads are audited
+// @Accept				application/json
+// @Produce				application/json
+// @Tags				order
+// @Security			ApiKeyAuth
+// @Security			BearerAuth
+// @Success				200 {object} batchGetResponse
+// @Router				/orders/batch-get [post]
+func (h Handler) BatchGet(c *gin.Context) {
+	var req batchGetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "uids must be a list of UUIDs"})
+		return
+	}
+	if len(req.Uids) > maxBatchSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("at most %d uids are allowed", maxBatchSize)})
+		return
+	}
+
+	orders, missing, err := h.service.GetByIds(req.Uids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get orders"})
+		log.Println(err.Error())
+		return
+	}
+
+	h.projector.ProjectViews(orders, middleware.Viewer(c))
+
+	c.JSON(http.StatusOK, batchGetResponse{Orders: orders, Missing: missing})
+}
+
+// Export 				godoc
+// @Summary				Export orders
+// @Param				format query string false "csv (default), ndjson or parquet"
+// @Param				from query string false "Created at or after, RFC3339 time or YYYY-MM-DD"
+// @Param				to query string false "Created before, RFC3339 time or YYYY-MM-DD"
+// @Param				customer_id query string false "Only orders of the customer"
+// @Description			Stream orders oldest first. CSV and Parquet have a row per item, NDJSON has the Kafka message format. Personal data is masked unless the caller role is allowed to see it, unmasked reads are audited
+// @Produce				text/csv
+// @Produce				application/x-ndjson
+// @Produce				application/vnd.apache.parquet
+// @Tags				order
+// @Security			ApiKeyAuth
+// @Security			BearerAuth
+// @Success				200 {file} file
+// @Router				/orders/export [get]
+func (h Handler) Export(c *gin.Context) {
+	format, err := export.ParseFormat(c.DefaultQuery("format", string(export.FormatCSV)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter, err := models.ParseOrderFilter(c.Query("from"), c.Query("to"), c.Query("customer_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", format.ContentType())
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="orders.%s"`, format))
+	c.Status(http.StatusOK)
+
+	viewer := middleware.Viewer(c)
+	writer := export.NewWriter(format, c.Writer)
+	err = h.service.StreamOrders(c.Request.Context(), filter, func(orders []models.Order) error {
+		h.projector.ProjectOrders(orders, viewer)
+		if err := writer.Write(orders); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	})
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		// The status is already sent, the client sees a truncated body
+		log.Printf("Export failed: %v", err)
+		_ = c.Error(err)
+	}
+}
+
+// UpdateStatus 		godoc
+// @Summary				Update order status
+// @Param				id path string true "Order id"
+// @Param				request body updateStatusRequest true "New status of all order items"
+// @Description			Set the status of all order items and notify order stream subscribers
+// @Accept				application/json
+// @Produce				application/json
+// @Tags				order
+// @Security			ApiKeyAuth
+// @Security			BearerAuth
+// @Success				200 {object} models.OrderView
+// @Router				/order/{id}/status [patch]
+func (h Handler) UpdateStatus(c *gin.Context) {
+	uidStr := c.Param("uid")
+	uid, err := uuid.Parse(uidStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "uid is not UUID format"})
+		log.Printf("uid %s is not UUID format", uidStr)
+		return
+	}
+
+	var req updateStatusRequest
+	if err = c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status is required"})
+		return
+	}
+
+	order, err := h.service.UpdateStatus(uid, req.Status, middleware.Actor(c))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update order status"})
+		log.Println(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, h.projector.Project(order, middleware.Viewer(c)))
+}
+
+// UpdateDelivery 		godoc
+// @Summary				Update order delivery
+// @Param				id path string true "Order id"
+// @Param				If-Match header string true "ETag of the order"
+// @Param				request body models.DeliveryPatch true "Delivery fields to change, omitted fields are kept"
+// @Description			Change the delivery of the order if it was not modified since the ETag was received. The merged order is validated
+// @Accept				application/json
+// @Produce				application/json
+// @Tags				order
+// @Security			ApiKeyAuth
+// @Security			BearerAuth
+// @Success				200 {object} models.OrderView
+// @Failure				412 {object} map[string]string
+// @Failure				422 {object} map[string]string
+// @Failure				428 {object} map[string]string
+// @Router				/order/{id}/delivery [patch]
+func (h Handler) UpdateDelivery(c *gin.Context) {
+	uid, version, ok := amendment(c)
+	if !ok {
+		return
+	}
+
+	var patch models.DeliveryPatch
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "body must be a JSON object of delivery fields"})
+		return
+	}
+
+	order, err := h.service.UpdateDelivery(uid, version, patch, middleware.Actor(c))
+	h.respondAmended(c, order, err)
+}
+
+// AddItem 				godoc
+// @Summary				Add order item
+// @Param				id path string true "Order id"
+// @Param				If-Match header string true "ETag of the order"
+// @Param				request body models.Item true "New item, its total price is added to the payment goods total and amount"
+// @Description			Add the item to the order if it was not modified since the ETag was received. The merged order is validated
+// @Accept				application/json
+// @Produce				application/json
+// @Tags				order
+// @Security			ApiKeyAuth
+// @Security			BearerAuth
+// @Success				200 {object} models.OrderView
+// @Failure				412 {object} map[string]string
+// @Failure				422 {object} map[string]string
+// @Failure				428 {object} map[string]string
+// @Router				/order/{id}/items [post]
+func (h Handler) AddItem(c *gin.Context) {
+	uid, version, ok := amendment(c)
+	if !ok {
+		return
+	}
+
+	var item models.Item
+	if err := c.ShouldBindJSON(&item); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "body must be an item"})
+		return
+	}
+
+	order, err := h.service.AddItem(uid, version, item, middleware.Actor(c))
+	h.respondAmended(c, order, err)
+}
+
+// RemoveItem 			godoc
+// @Summary				Remove order item
+// @Param				id path string true "Order id"
+// @Param				rid path string true "Item rid"
+// @Param				If-Match header string true "ETag of the order"
+// @Description			Remove the item from the order if it was not modified since the ETag was received. Its total price is subtracted from the payment goods total and amount
+// @Produce				application/json
+// @Tags				order
+// @Security			ApiKeyAuth
+// @Security			BearerAuth
+// @Success				200 {object} models.OrderView
+// @Failure				412 {object} map[string]string
+// @Failure				422 {object} map[string]string
+// @Failure				428 {object} map[string]string
+// @Router				/order/{id}/items/{rid} [delete]
+func (h Handler) RemoveItem(c *gin.Context) {
+	uid, version, ok := amendment(c)
+	if !ok {
+		return
+	}
+
+	order, err := h.service.RemoveItem(uid, version, c.Param("rid"), middleware.Actor(c))
+	h.respondAmended(c, order, err)
+}
+
+// amendment parses the order uid and the version from the If-Match header, an amendment without it is rejected
+func amendment(c *gin.Context) (uuid.UUID, int, bool) {
+	uidStr := c.Param("uid")
+	uid, err := uuid.Parse(uidStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "uid is not UUID format"})
+		log.Printf("uid %s is not UUID format", uidStr)
+		return uuid.UUID{}, 0, false
+	}
+
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" {
+		c.JSON(http.StatusPreconditionRequired, gin.H{"error": "If-Match header with the order ETag is required"})
+		return uuid.UUID{}, 0, false
+	}
+	version, err := models.ParseETagVersion(ifMatch)
+	if err != nil {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "If-Match does not match the order ETag"})
+		return uuid.UUID{}, 0, false
+	}
+	return uid, version, true
+}
+
+func (h Handler) respondAmended(c *gin.Context, order models.OrderView, err error) {
+	var validationErrors validator.ValidationErrors
+	switch {
+	case err == nil:
+		projected := h.projector.Project(order, middleware.Viewer(c))
+		setValidators(c, projected)
+		c.JSON(http.StatusOK, projected)
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, service.ErrItemNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrVersionConflict):
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": err.Error()})
+	case errors.As(err, &validationErrors), errors.Is(err, models.ErrPaymentMismatch):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to amend order"})
+		log.Println(err.Error())
+	}
+}
+
+func setValidators(c *gin.Context, order models.OrderView) {
+	c.Header("ETag", order.ETag)
+	c.Header("Last-Modified", order.LastModified.UTC().Format(http.TimeFormat))
+}
+
+// notModified reports whether the client copy is current. If-None-Match takes precedence over If-Modified-Since
+func notModified(c *gin.Context, order models.OrderView) bool {
+	if ifNoneMatch := c.GetHeader("If-None-Match"); ifNoneMatch != "" {
+		for _, tag := range strings.Split(ifNoneMatch, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == order.ETag {
+				return true
+			}
+		}
+		return false
+	}
+
+	since, err := http.ParseTime(c.GetHeader("If-Modified-Since"))
+	return err == nil && !order.LastModified.Truncate(time.Second).After(since)
 }
@@ -1,21 +1,39 @@
 package order
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"go.uber.org/mock/gomock"
 	"log"
 	"net/http/httptest"
+	"orderService/configs"
+	"orderService/http/rest/middleware"
+	"orderService/internal/auth"
 	"orderService/internal/models"
+	"orderService/internal/privacy"
+	"orderService/internal/repository"
+	"orderService/internal/service"
 	"orderService/internal/service/mocks"
+	"strings"
 	"testing"
 	"time"
 )
 
 var uid uuid.UUID
 var dateCreated time.Time
+var policy = privacy.NewPolicy(configs.Privacy{
+	NameRoles:    []string{"admin"},
+	PhoneRoles:   []string{"admin", "support"},
+	ZipRoles:     []string{"admin"},
+	AddressRoles: []string{"admin"},
+	EmailRoles:   []string{"admin", "support"},
+})
+var projector = privacy.NewProjector(policy, nil)
 
 func init() {
 	var err error
@@ -32,7 +50,7 @@ func init() {
 }
 
 func TestHandler_GetOrderById(t *testing.T) {
-	orderView := models.OrderView{DeliveryService: "meest", DateCreated: dateCreated, Delivery: models.DeliveryView{
+	orderView := models.OrderView{Uid: uid, TrackNumber: "WBILMTESTTRACK", CustomerID: "100900", DeliveryService: "meest", DateCreated: dateCreated, Delivery: models.DeliveryView{
 		Name:    "Test Testov",
 		Phone:   "+9720000000",
 		Zip:     "2639809",
@@ -53,9 +71,45 @@ func TestHandler_GetOrderById(t *testing.T) {
 			TotalPrice: 317,
 			Brand:      "Vivienne Sabo",
 		},
-		}}
+		},
+		LastModified: dateCreated,
+	}
+
+	maskedOrderViewResponse := `{
+    "Uid": "1e9ad4fb-2615-46f9-9458-20b59253086b",
+    "TrackNumber": "WBILMTESTTRACK",
+    "CustomerID": "100900",
+    "DeliveryService": "meest",
+    "DateCreated": "2021-11-26T06:22:19Z",
+    "Delivery": {
+        "Name": "T*** T*****",
+        "Phone": "+972*****00",
+        "Zip": "26*****",
+        "City": "Moscow",
+        "Address": "P******* M*** 1*",
+        "Region": "Moscow",
+        "Email": "t***@gmail.com"
+    },
+    "Payment": {
+        "Currency": "USD",
+        "Provider": "wbpay",
+        "Amount": 1817,
+        "DeliveryCost": 1500,
+        "GoodsTotal": 317
+    },
+    "Items": [
+        {
+            "Name": "Mascaras",
+            "TotalPrice": 317,
+            "Brand": "Vivienne Sabo"
+        }
+    ]
+}`
 
 	orderViewResponse := `{
+    "Uid": "1e9ad4fb-2615-46f9-9458-20b59253086b",
+    "TrackNumber": "WBILMTESTTRACK",
+    "CustomerID": "100900",
     "DeliveryService": "meest",
     "DateCreated": "2021-11-26T06:22:19Z",
     "Delivery": {
@@ -89,8 +143,9 @@ func TestHandler_GetOrderById(t *testing.T) {
 
 		mockOrderService := new(mocks.IOrderService)
 		mockOrderService.On("GetById", uid).Return(orderView, nil)
+		mockAudit := new(mocks.IAuditService)
 
-		handler := NewHandler(mockOrderService)
+		handler := NewHandler(mockOrderService, privacy.NewProjector(policy, mockAudit))
 		g := gin.New()
 		g.GET("/order/:uid", handler.GetOrderById)
 
@@ -99,18 +154,110 @@ func TestHandler_GetOrderById(t *testing.T) {
 
 		g.ServeHTTP(h, r)
 
+		assert.Equal(t, 200, h.Code)
+		assert.JSONEq(t, maskedOrderViewResponse, h.Body.String())
+		mockOrderService.AssertCalled(t, "GetById", uid)
+		mockAudit.AssertNotCalled(t, "Record", mock.Anything)
+	})
+
+	t.Run("SuccessWithFullAccessRole", func(t *testing.T) {
+		mockOrderService := new(mocks.IOrderService)
+		mockOrderService.On("GetById", uid).Return(orderView, nil)
+		mockAudit := new(mocks.IAuditService)
+		mockAudit.On("Record", mock.Anything).Return()
+
+		handler := NewHandler(mockOrderService, privacy.NewProjector(policy, mockAudit))
+		g := gin.New()
+		g.GET("/order/:uid", func(c *gin.Context) {
+			c.Set(middleware.RolesKey, []string{"admin"})
+			c.Set(middleware.PrincipalKey, auth.Principal{Subject: "support-bot", Method: auth.MethodApiKey})
+		}, handler.GetOrderById)
+
+		h := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", fmt.Sprintf("/order/%s", uid.String()), nil)
+
+		g.ServeHTTP(h, r)
+
 		assert.Equal(t, 200, h.Code)
 		assert.JSONEq(t, orderViewResponse, h.Body.String())
 		mockOrderService.AssertCalled(t, "GetById", uid)
+		entry := mockAudit.Calls[0].Arguments.Get(0).(models.AuditEntry)
+		assert.Equal(t, models.AuditOrderViewed, entry.Action)
+		assert.Equal(t, "api_key:support-bot", entry.Actor)
+		assert.Equal(t, uid, *entry.OrderUid)
+		assert.JSONEq(t, `{"unmasked":["name","phone","zip","address","email"]}`, string(entry.Details))
+	})
+
+	t.Run("ConditionalHeaders", func(t *testing.T) {
+		mockOrderService := new(mocks.IOrderService)
+		mockOrderService.On("GetById", uid).Return(orderView.WithETag(), nil)
+
+		handler := NewHandler(mockOrderService, projector)
+		g := gin.New()
+		g.GET("/order/:uid", handler.GetOrderById)
+
+		h := httptest.NewRecorder()
+		g.ServeHTTP(h, httptest.NewRequest("GET", fmt.Sprintf("/order/%s", uid.String()), nil))
+
+		assert.Equal(t, 200, h.Code)
+		assert.Equal(t, projector.Mask(orderView.WithETag(), nil).ETag, h.Header().Get("ETag"))
+		assert.NotEqual(t, orderView.WithETag().ETag, h.Header().Get("ETag"))
+		assert.Equal(t, "Fri, 26 Nov 2021 06:22:19 GMT", h.Header().Get("Last-Modified"))
+		assert.Equal(t, "private, no-cache", h.Header().Get("Cache-Control"))
 	})
 
+	conditionalTableData := []struct {
+		name    string
+		headers map[string]string
+		code    int
+	}{
+		{name: "IfNoneMatchCurrent", headers: map[string]string{"If-None-Match": orderView.WithETag().ETag}, code: 304},
+		{name: "IfNoneMatchWeakInList", headers: map[string]string{"If-None-Match": `"1-aaaaaaaaaaaaaaaa", W/` + orderView.WithETag().ETag}, code: 304},
+		{name: "IfNoneMatchStale", headers: map[string]string{"If-None-Match": `"0-aaaaaaaaaaaaaaaa"`}, code: 200},
+		{name: "IfNoneMatchOfMaskedBody", headers: map[string]string{"If-None-Match": projector.Mask(orderView.WithETag(), nil).ETag}, code: 200},
+		{name: "IfModifiedSinceCurrent", headers: map[string]string{"If-Modified-Since": "Fri, 26 Nov 2021 06:22:19 GMT"}, code: 304},
+		{name: "IfModifiedSinceStale", headers: map[string]string{"If-Modified-Since": "Fri, 26 Nov 2021 06:22:18 GMT"}, code: 200},
+		{name: "IfNoneMatchWinsOverIfModifiedSince", headers: map[string]string{"If-None-Match": `"0-aaaaaaaaaaaaaaaa"`, "If-Modified-Since": "Fri, 26 Nov 2021 06:22:19 GMT"}, code: 200},
+	}
+
+	for _, td := range conditionalTableData {
+		t.Run(td.name, func(t *testing.T) {
+			mockOrderService := new(mocks.IOrderService)
+			mockOrderService.On("GetById", uid).Return(orderView.WithETag(), nil)
+			mockAudit := new(mocks.IAuditService)
+			mockAudit.On("Record", mock.Anything).Return()
+
+			handler := NewHandler(mockOrderService, privacy.NewProjector(policy, mockAudit))
+			g := gin.New()
+			g.GET("/order/:uid", func(c *gin.Context) {
+				c.Set(middleware.RolesKey, []string{"admin"})
+			}, handler.GetOrderById)
+
+			h := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", fmt.Sprintf("/order/%s", uid.String()), nil)
+			for name, value := range td.headers {
+				r.Header.Set(name, value)
+			}
+			g.ServeHTTP(h, r)
+
+			assert.Equal(t, td.code, h.Code)
+			assert.Equal(t, orderView.WithETag().ETag, h.Header().Get("ETag"))
+			if td.code == 304 {
+				assert.Empty(t, h.Body.String())
+				mockAudit.AssertNotCalled(t, "Record", mock.Anything)
+			} else {
+				mockAudit.AssertNumberOfCalls(t, "Record", 1)
+			}
+		})
+	}
+
 	t.Run("UidIsNotUUIDType", func(t *testing.T) {
 		c := gomock.NewController(t)
 		defer c.Finish()
 
 		mockOrderService := new(mocks.IOrderService)
 
-		handler := NewHandler(mockOrderService)
+		handler := NewHandler(mockOrderService, projector)
 		g := gin.New()
 		g.GET("/order/:uid", handler.GetOrderById)
 
@@ -127,7 +274,7 @@ func TestHandler_GetOrderById(t *testing.T) {
 	t.Run("OrderNotFound", func(t *testing.T) {
 		mockOrderService := new(mocks.IOrderService)
 		mockOrderService.On("GetById", uid).Return(models.OrderView{}, fmt.Errorf("record not found"))
-		handler := NewHandler(mockOrderService)
+		handler := NewHandler(mockOrderService, projector)
 
 		c := gomock.NewController(t)
 		defer c.Finish()
@@ -145,3 +292,280 @@ func TestHandler_GetOrderById(t *testing.T) {
 		mockOrderService.AssertCalled(t, "GetById", uid)
 	})
 }
+
+func TestHandler_BatchGet(t *testing.T) {
+	missingUid := uuid.MustParse("3e9ad4fb-2615-46f9-9458-20b59253086b")
+
+	t.Run("Success", func(t *testing.T) {
+		mockOrderService := new(mocks.IOrderService)
+		mockOrderService.On("GetByIds", []uuid.UUID{uid, missingUid}).
+			Return([]models.OrderView{{Uid: uid, Delivery: models.DeliveryView{Phone: "+9720000000"}}}, []uuid.UUID{missingUid}, nil)
+
+		handler := NewHandler(mockOrderService, projector)
+		g := gin.New()
+		g.POST("/orders/batch-get", handler.BatchGet)
+
+		h := httptest.NewRecorder()
+		body := fmt.Sprintf(`{"uids":["%s","%s"]}`, uid, missingUid)
+		r := httptest.NewRequest("POST", "/orders/batch-get", strings.NewReader(body))
+
+		g.ServeHTTP(h, r)
+
+		assert.Equal(t, 200, h.Code)
+		var response batchGetResponse
+		assert.NoError(t, json.Unmarshal(h.Body.Bytes(), &response))
+		assert.Len(t, response.Orders, 1)
+		assert.Equal(t, "+972*****00", response.Orders[0].Delivery.Phone)
+		assert.Equal(t, []uuid.UUID{missingUid}, response.Missing)
+	})
+
+	t.Run("UnmaskedReadsAreAudited", func(t *testing.T) {
+		mockOrderService := new(mocks.IOrderService)
+		mockOrderService.On("GetByIds", []uuid.UUID{uid, missingUid}).
+			Return([]models.OrderView{{Uid: uid, Delivery: models.DeliveryView{Phone: "+9720000000"}}}, []uuid.UUID{missingUid}, nil)
+		mockAudit := new(mocks.IAuditService)
+		mockAudit.On("Record", mock.Anything).Return()
+
+		handler := NewHandler(mockOrderService, privacy.NewProjector(policy, mockAudit))
+		g := gin.New()
+		g.POST("/orders/batch-get", func(c *gin.Context) {
+			c.Set(middleware.RolesKey, []string{"support"})
+			c.Set(middleware.PrincipalKey, auth.Principal{Subject: "support-bot", Method: auth.MethodApiKey})
+		}, handler.BatchGet)
+
+		h := httptest.NewRecorder()
+		body := fmt.Sprintf(`{"uids":["%s","%s"]}`, uid, missingUid)
+		g.ServeHTTP(h, httptest.NewRequest("POST", "/orders/batch-get", strings.NewReader(body)))
+
+		assert.Equal(t, 200, h.Code)
+		assert.Contains(t, h.Body.String(), `"Phone":"+9720000000"`)
+		mockAudit.AssertNumberOfCalls(t, "Record", 1)
+		entry := mockAudit.Calls[0].Arguments.Get(0).(models.AuditEntry)
+		assert.Equal(t, models.AuditOrderViewed, entry.Action)
+		assert.Equal(t, "api_key:support-bot", entry.Actor)
+		assert.Equal(t, uid, *entry.OrderUid)
+		assert.JSONEq(t, `{"unmasked":["phone","email"]}`, string(entry.Details))
+	})
+
+	t.Run("NotUUID", func(t *testing.T) {
+		mockOrderService := new(mocks.IOrderService)
+
+		handler := NewHandler(mockOrderService, projector)
+		g := gin.New()
+		g.POST("/orders/batch-get", handler.BatchGet)
+
+		h := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/orders/batch-get", strings.NewReader(`{"uids":["1"]}`))
+
+		g.ServeHTTP(h, r)
+
+		assert.Equal(t, 400, h.Code)
+		assert.JSONEq(t, `{"error":"uids must be a list of UUIDs"}`, h.Body.String())
+		mockOrderService.AssertNotCalled(t, "GetByIds")
+	})
+
+	t.Run("TooManyUids", func(t *testing.T) {
+		mockOrderService := new(mocks.IOrderService)
+
+		handler := NewHandler(mockOrderService, projector)
+		g := gin.New()
+		g.POST("/orders/batch-get", handler.BatchGet)
+
+		uids := make([]string, maxBatchSize+1)
+		for i := range uids {
+			uids[i] = fmt.Sprintf("%q", uuid.New())
+		}
+		h := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/orders/batch-get", strings.NewReader(`{"uids":[`+strings.Join(uids, ",")+`]}`))
+
+		g.ServeHTTP(h, r)
+
+		assert.Equal(t, 400, h.Code)
+		assert.JSONEq(t, `{"error":"at most 500 uids are allowed"}`, h.Body.String())
+		mockOrderService.AssertNotCalled(t, "GetByIds")
+	})
+}
+
+func TestHandler_Export(t *testing.T) {
+	t.Run("StreamsMaskedNDJSON", func(t *testing.T) {
+		from := time.Date(2021, 11, 1, 0, 0, 0, 0, time.UTC)
+		filter := models.OrderFilter{From: from, CustomerID: "100900"}
+		orders := []models.Order{{Uid: uid, CustomerID: "100900", Delivery: models.Delivery{Phone: "+9720000000"}}}
+
+		mockOrderService := new(mocks.IOrderService)
+		mockOrderService.On("StreamOrders", mock.Anything, filter, mock.Anything).
+			Run(func(args mock.Arguments) {
+				assert.NoError(t, args.Get(2).(func([]models.Order) error)(orders))
+			}).
+			Return(nil)
+
+		handler := NewHandler(mockOrderService, projector)
+		g := gin.New()
+		g.GET("/orders/export", handler.Export)
+
+		h := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/orders/export?format=ndjson&from=2021-11-01&customer_id=100900", nil)
+
+		g.ServeHTTP(h, r)
+
+		assert.Equal(t, 200, h.Code)
+		assert.Equal(t, "application/x-ndjson", h.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="orders.ndjson"`, h.Header().Get("Content-Disposition"))
+		assert.Contains(t, h.Body.String(), `"phone":"+972*****00"`)
+	})
+
+	t.Run("UnmaskedReadsAreAudited", func(t *testing.T) {
+		second := uuid.MustParse("2e9ad4fb-2615-46f9-9458-20b59253086b")
+		orders := []models.Order{{Uid: uid, Delivery: models.Delivery{Phone: "+9720000000"}}, {Uid: second}}
+
+		mockOrderService := new(mocks.IOrderService)
+		mockOrderService.On("StreamOrders", mock.Anything, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				assert.NoError(t, args.Get(2).(func([]models.Order) error)(orders))
+			}).
+			Return(nil)
+		mockAudit := new(mocks.IAuditService)
+		mockAudit.On("Record", mock.Anything, mock.Anything).Return()
+
+		handler := NewHandler(mockOrderService, privacy.NewProjector(policy, mockAudit))
+		g := gin.New()
+		g.GET("/orders/export", func(c *gin.Context) {
+			c.Set(middleware.RolesKey, []string{"admin"})
+		}, handler.Export)
+
+		h := httptest.NewRecorder()
+		g.ServeHTTP(h, httptest.NewRequest("GET", "/orders/export?format=ndjson", nil))
+
+		assert.Equal(t, 200, h.Code)
+		assert.Contains(t, h.Body.String(), `"phone":"+9720000000"`)
+		mockAudit.AssertNumberOfCalls(t, "Record", 1)
+		arguments := mockAudit.Calls[0].Arguments
+		assert.Equal(t, uid, *arguments.Get(0).(models.AuditEntry).OrderUid)
+		assert.Equal(t, second, *arguments.Get(1).(models.AuditEntry).OrderUid)
+	})
+
+	t.Run("UnknownFormat", func(t *testing.T) {
+		mockOrderService := new(mocks.IOrderService)
+
+		handler := NewHandler(mockOrderService, projector)
+		g := gin.New()
+		g.GET("/orders/export", handler.Export)
+
+		h := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/orders/export?format=xml", nil)
+
+		g.ServeHTTP(h, r)
+
+		assert.Equal(t, 400, h.Code)
+		mockOrderService.AssertNotCalled(t, "StreamOrders")
+	})
+
+	t.Run("InvalidDateRange", func(t *testing.T) {
+		mockOrderService := new(mocks.IOrderService)
+
+		handler := NewHandler(mockOrderService, projector)
+		g := gin.New()
+		g.GET("/orders/export", handler.Export)
+
+		h := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/orders/export?from=2021-12-01&to=2021-11-01", nil)
+
+		g.ServeHTTP(h, r)
+
+		assert.Equal(t, 400, h.Code)
+		assert.JSONEq(t, `{"error":"from must be before to"}`, h.Body.String())
+	})
+}
+
+func TestHandler_UpdateDelivery(t *testing.T) {
+	city := "Kazan"
+	serveAmendment := func(mockOrderService *mocks.IOrderService, ifMatch string, body string) *httptest.ResponseRecorder {
+		handler := NewHandler(mockOrderService, projector)
+		g := gin.New()
+		g.PATCH("/order/:uid/delivery", handler.UpdateDelivery)
+
+		h := httptest.NewRecorder()
+		r := httptest.NewRequest("PATCH", fmt.Sprintf("/order/%s/delivery", uid), strings.NewReader(body))
+		if ifMatch != "" {
+			r.Header.Set("If-Match", ifMatch)
+		}
+		g.ServeHTTP(h, r)
+		return h
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		amended := models.OrderView{Uid: uid, Delivery: models.DeliveryView{City: city}, Version: 4, LastModified: dateCreated, ETag: `"4-0123456789abcdef"`}
+		mockOrderService := new(mocks.IOrderService)
+		mockOrderService.On("UpdateDelivery", uid, 3, models.DeliveryPatch{City: &city}, "anonymous").Return(amended, nil)
+
+		h := serveAmendment(mockOrderService, `"3"`, `{"city":"Kazan"}`)
+
+		assert.Equal(t, 200, h.Code)
+		assert.Equal(t, `"4-0123456789abcdef"`, h.Header().Get("ETag"))
+		assert.Equal(t, "Fri, 26 Nov 2021 06:22:19 GMT", h.Header().Get("Last-Modified"))
+		assert.Contains(t, h.Body.String(), `"City":"Kazan"`)
+	})
+
+	t.Run("IfMatchIsRequired", func(t *testing.T) {
+		mockOrderService := new(mocks.IOrderService)
+
+		h := serveAmendment(mockOrderService, "", `{"city":"Kazan"}`)
+
+		assert.Equal(t, 428, h.Code)
+		mockOrderService.AssertNotCalled(t, "UpdateDelivery")
+	})
+
+	t.Run("VersionConflict", func(t *testing.T) {
+		mockOrderService := new(mocks.IOrderService)
+		mockOrderService.On("UpdateDelivery", uid, 2, mock.Anything, mock.Anything).Return(models.OrderView{}, repository.ErrVersionConflict)
+
+		h := serveAmendment(mockOrderService, `W/"2"`, `{"city":"Kazan"}`)
+
+		assert.Equal(t, 412, h.Code)
+		assert.JSONEq(t, `{"error":"order was modified, fetch it again and retry"}`, h.Body.String())
+	})
+
+	t.Run("InvalidMergedOrder", func(t *testing.T) {
+		mockOrderService := new(mocks.IOrderService)
+		mockOrderService.On("UpdateDelivery", uid, 3, mock.Anything, mock.Anything).Return(models.OrderView{}, validator.ValidationErrors{})
+
+		h := serveAmendment(mockOrderService, `"3"`, `{"email":"not an email"}`)
+
+		assert.Equal(t, 422, h.Code)
+	})
+}
+
+func TestHandler_RemoveItem(t *testing.T) {
+	t.Run("ItemNotFound", func(t *testing.T) {
+		mockOrderService := new(mocks.IOrderService)
+		mockOrderService.On("RemoveItem", uid, 0, "unknown", "anonymous").Return(models.OrderView{}, service.ErrItemNotFound)
+
+		handler := NewHandler(mockOrderService, projector)
+		g := gin.New()
+		g.DELETE("/order/:uid/items/:rid", handler.RemoveItem)
+
+		h := httptest.NewRecorder()
+		r := httptest.NewRequest("DELETE", fmt.Sprintf("/order/%s/items/unknown", uid), nil)
+		r.Header.Set("If-Match", `"0"`)
+		g.ServeHTTP(h, r)
+
+		assert.Equal(t, 404, h.Code)
+		assert.JSONEq(t, `{"error":"item not found"}`, h.Body.String())
+	})
+
+	t.Run("PaymentMismatch", func(t *testing.T) {
+		mockOrderService := new(mocks.IOrderService)
+		mockOrderService.On("RemoveItem", uid, 0, "ab4219087a764ae0btest", "anonymous").Return(models.OrderView{}, models.ErrPaymentMismatch)
+
+		handler := NewHandler(mockOrderService, projector)
+		g := gin.New()
+		g.DELETE("/order/:uid/items/:rid", handler.RemoveItem)
+
+		h := httptest.NewRecorder()
+		r := httptest.NewRequest("DELETE", fmt.Sprintf("/order/%s/items/ab4219087a764ae0btest", uid), nil)
+		r.Header.Set("If-Match", `"0"`)
+		g.ServeHTTP(h, r)
+
+		assert.Equal(t, 422, h.Code)
+	})
+}
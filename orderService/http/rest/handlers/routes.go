@@ -4,16 +4,112 @@ import (
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"net/http"
+	"orderService/configs"
+	"orderService/http/rest/handlers/admin"
+	"orderService/http/rest/handlers/graphql"
+	"orderService/http/rest/handlers/lifecycle"
 	"orderService/http/rest/handlers/order"
+	"orderService/http/rest/handlers/stats"
+	"orderService/http/rest/handlers/stream"
 	"orderService/http/rest/middleware"
+	"orderService/internal/auth"
+	"orderService/internal/events"
+	consumer "orderService/internal/kafka"
+	"orderService/internal/privacy"
+	"orderService/internal/ratelimit"
 	"orderService/internal/service"
 )
 
-func Register(gin *gin.Engine, orderService service.IOrderService) {
-	orderHandler := order.NewHandler(orderService)
+type Dependencies struct {
+	OrderService   service.IOrderService
+	StatsService   service.IStatsService
+	Lifecycle      service.ILifecycleService
+	Audit          service.IAuditService
+	Projector      privacy.Projector
+	Authenticator  auth.Authenticator
+	RateLimit      configs.RateLimit
+	RateLimitStore ratelimit.Store
+	Cors           middleware.CorsPolicy
+	Bus            *events.Bus
+	Stream         configs.Stream
+	GraphQL        configs.GraphQL
+	Consumer       consumer.IConsumerAdmin
+}
+
+func Register(gin *gin.Engine, deps Dependencies) error {
+	orderHandler := order.NewHandler(deps.OrderService, deps.Projector)
+	graphqlHandler, err := graphql.NewHandler(deps.OrderService, deps.Projector, deps.GraphQL)
+	if err != nil {
+		return err
+	}
+	streamHandler := stream.NewHandler(deps.Bus, deps.Projector, deps.Stream, deps.Cors)
+	statsHandler := stats.NewHandler(deps.StatsService)
+	lifecycleHandler := lifecycle.NewHandler(deps.Lifecycle)
+	adminHandler := admin.NewHandler(deps.Audit)
+	kafkaHandler := admin.NewKafkaHandler(deps.Consumer)
+
+	// CORS must wrap every route and answer preflight requests for all of them
+	gin.Use(middleware.Cors(deps.Cors))
+	gin.OPTIONS("/*path", noContent)
 
 	gin.GET("/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	gin.GET("/order/:uid", middleware.RequestIdMiddleware("getOrderById"), middleware.SetCors(), orderHandler.GetOrderById)
+	// the IP limit runs first so invalid credentials are throttled before they are looked up
+	ipLimit := rateLimitByIP(deps)
+	authenticate := middleware.Authenticate(deps.Authenticator)
+	ordersLimit := rateLimit(deps, "orders", deps.RateLimit.OrdersRate, deps.RateLimit.OrdersBurst)
+	graphqlLimit := rateLimit(deps, "graphql", deps.RateLimit.GraphQLRate, deps.RateLimit.GraphQLBurst)
+	statsLimit := rateLimit(deps, "stats", deps.RateLimit.StatsRate, deps.RateLimit.StatsBurst)
+	exportLimit := rateLimit(deps, "export", deps.RateLimit.ExportRate, deps.RateLimit.ExportBurst)
+	streamLimit := rateLimit(deps, "stream", deps.RateLimit.StreamRate, deps.RateLimit.StreamBurst)
+	adminLimit := rateLimit(deps, "admin", deps.RateLimit.AdminRate, deps.RateLimit.AdminBurst)
+
+	gin.GET("/order/:uid", middleware.RequestIdMiddleware("getOrderById"), ipLimit, authenticate, ordersLimit, middleware.RequireScope(auth.ScopeOrdersRead), orderHandler.GetOrderById)
+	gin.DELETE("/order/:uid", middleware.RequestIdMiddleware("deleteOrder"), ipLimit, authenticate, ordersLimit, middleware.RequireScope(auth.ScopeOrdersWrite), lifecycleHandler.DeleteOrder)
+	gin.DELETE("/customers/:customerId/personal-data", middleware.RequestIdMiddleware("erasePersonalData"), ipLimit, authenticate, adminLimit, middleware.RequireScope(auth.ScopeAdmin), lifecycleHandler.ErasePersonalData)
+	gin.GET("/admin/audit", middleware.RequestIdMiddleware("auditLog"), ipLimit, authenticate, adminLimit, middleware.RequireScope(auth.ScopeAdmin), adminHandler.Audit)
+	gin.GET("/admin/kafka", middleware.RequestIdMiddleware("consumerStatus"), ipLimit, authenticate, adminLimit, middleware.RequireScope(auth.ScopeAdmin), kafkaHandler.Status)
+	gin.POST("/admin/kafka/pause", middleware.RequestIdMiddleware("pauseConsumer"), ipLimit, authenticate, adminLimit, middleware.RequireScope(auth.ScopeAdmin), kafkaHandler.Pause)
+	gin.POST("/admin/kafka/resume", middleware.RequestIdMiddleware("resumeConsumer"), ipLimit, authenticate, adminLimit, middleware.RequireScope(auth.ScopeAdmin), kafkaHandler.Resume)
+	gin.POST("/admin/kafka/seek", middleware.RequestIdMiddleware("seekConsumer"), ipLimit, authenticate, adminLimit, middleware.RequireScope(auth.ScopeAdmin), kafkaHandler.Seek)
+	gin.PATCH("/order/:uid/delivery", middleware.RequestIdMiddleware("updateOrderDelivery"), ipLimit, authenticate, ordersLimit, middleware.RequireScope(auth.ScopeOrdersWrite), orderHandler.UpdateDelivery)
+	gin.POST("/order/:uid/items", middleware.RequestIdMiddleware("addOrderItem"), ipLimit, authenticate, ordersLimit, middleware.RequireScope(auth.ScopeOrdersWrite), orderHandler.AddItem)
+	gin.DELETE("/order/:uid/items/:rid", middleware.RequestIdMiddleware("removeOrderItem"), ipLimit, authenticate, ordersLimit, middleware.RequireScope(auth.ScopeOrdersWrite), orderHandler.RemoveItem)
+	gin.PATCH("/order/:uid/status", middleware.RequestIdMiddleware("updateOrderStatus"), ipLimit, authenticate, ordersLimit, middleware.RequireScope(auth.ScopeOrdersWrite), orderHandler.UpdateStatus)
+	gin.POST("/orders/batch-get", middleware.RequestIdMiddleware("batchGetOrders"), ipLimit, authenticate, ordersLimit, middleware.RequireScope(auth.ScopeOrdersRead), orderHandler.BatchGet)
+	gin.GET("/orders/export", middleware.RequestIdMiddleware("exportOrders"), ipLimit, authenticate, exportLimit, middleware.RequireScope(auth.ScopeOrdersRead), orderHandler.Export)
+	gin.GET("/orders/stream", middleware.RequestIdMiddleware("streamOrders"), ipLimit, authenticate, streamLimit, middleware.RequireScope(auth.ScopeOrdersRead), streamHandler.SSE)
+	gin.GET("/orders/ws", middleware.RequestIdMiddleware("streamOrdersWebSocket"), ipLimit, authenticate, streamLimit, middleware.RequireScope(auth.ScopeOrdersRead), streamHandler.WebSocket)
+	gin.GET("/graphql", middleware.RequestIdMiddleware("graphql"), ipLimit, authenticate, graphqlLimit, middleware.RequireScope(auth.ScopeOrdersRead), graphqlHandler.Query)
+	gin.POST("/graphql", middleware.RequestIdMiddleware("graphql"), ipLimit, authenticate, graphqlLimit, middleware.RequireScope(auth.ScopeOrdersRead), graphqlHandler.Query)
+
+	statsGroup := gin.Group("/stats", middleware.RequestIdMiddleware("stats"), ipLimit, authenticate, statsLimit, middleware.RequireScope(auth.ScopeOrdersRead))
+	statsGroup.GET("/orders", statsHandler.Orders)
+	statsGroup.GET("/revenue", statsHandler.Revenue)
+	statsGroup.GET("/top-brands", statsHandler.TopBrands)
+	statsGroup.GET("/top-items", statsHandler.TopItems)
+	statsGroup.GET("/delivery-services", statsHandler.DeliveryServices)
+	statsGroup.GET("/locations", statsHandler.Locations)
+	statsGroup.GET("/basket", statsHandler.Basket)
+
+	return nil
+}
+
+func rateLimit(deps Dependencies, group string, rate float64, burst int) gin.HandlerFunc {
+	if !deps.RateLimit.Enabled {
+		return func(c *gin.Context) {}
+	}
+	return middleware.RateLimit(deps.RateLimitStore, group, ratelimit.Limit{Rate: rate, Burst: burst})
+}
+
+func rateLimitByIP(deps Dependencies) gin.HandlerFunc {
+	if !deps.RateLimit.Enabled {
+		return func(c *gin.Context) {}
+	}
+	return middleware.RateLimitByIP(deps.RateLimitStore, "ip", ratelimit.Limit{Rate: deps.RateLimit.IPRate, Burst: deps.RateLimit.IPBurst})
+}
 
+func noContent(c *gin.Context) {
+	c.Status(http.StatusNoContent)
 }
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"orderService/configs"
+	"orderService/http/rest/middleware"
+	"orderService/internal/auth"
+	"orderService/internal/events"
+	"orderService/internal/privacy"
+	"orderService/internal/ratelimit"
+	"orderService/internal/service/mocks"
+	"testing"
+	"time"
+)
+
+func TestRegister_Preflight(t *testing.T) {
+	authenticator, err := auth.NewAuthenticator(configs.Auth{Enabled: true})
+	require.NoError(t, err)
+	corsPolicy, err := middleware.NewCorsPolicy(configs.Cors{
+		AllowedOrigins: []string{"http://localhost"},
+		AllowedMethods: []string{"GET"},
+		AllowedHeaders: []string{"X-API-Key"},
+	})
+	require.NoError(t, err)
+
+	g := gin.New()
+	require.NoError(t, g.SetTrustedProxies(nil))
+	err = Register(g, Dependencies{
+		OrderService:   new(mocks.IOrderService),
+		Projector:      privacy.NewProjector(privacy.Policy{}, nil),
+		Authenticator:  authenticator,
+		RateLimit:      configs.RateLimit{Enabled: true, OrdersRate: 1, OrdersBurst: 1, IPRate: 1, IPBurst: 1},
+		RateLimitStore: ratelimit.NewMemoryStore(time.Minute),
+		Cors:           corsPolicy,
+		Bus:            events.NewBus(0),
+		Stream:         configs.Stream{BufferSize: 1, HeartbeatInterval: 1},
+		GraphQL:        configs.GraphQL{MaxDepth: 1, MaxComplexity: 1},
+	})
+	require.NoError(t, err)
+
+	t.Run("PreflightDoesNotRequireAuthentication", func(t *testing.T) {
+		h := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodOptions, "/order/1e9ad4fb-2615-46f9-9458-20b59253086b", nil)
+		r.Header.Set("Origin", "http://localhost")
+		r.Header.Set("Access-Control-Request-Method", "GET")
+		r.Header.Set("Access-Control-Request-Headers", "X-API-Key")
+
+		g.ServeHTTP(h, r)
+
+		assert.Equal(t, http.StatusNoContent, h.Code)
+		assert.Equal(t, "http://localhost", h.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "X-Api-Key", h.Header().Get("Access-Control-Allow-Headers"))
+	})
+
+	t.Run("UnauthorizedResponseHasCorsHeaders", func(t *testing.T) {
+		h := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/order/1e9ad4fb-2615-46f9-9458-20b59253086b", nil)
+		r.Header.Set("Origin", "http://localhost")
+
+		g.ServeHTTP(h, r)
+
+		assert.Equal(t, http.StatusUnauthorized, h.Code)
+		assert.Equal(t, "http://localhost", h.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("InvalidCredentialsAreLimitedPerIP", func(t *testing.T) {
+		request := func(forwardedFor string) int {
+			h := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/order/1e9ad4fb-2615-46f9-9458-20b59253086b", nil)
+			r.RemoteAddr = "10.0.0.9:1234"
+			r.Header.Set("X-API-Key", "guessed")
+			r.Header.Set("X-Forwarded-For", forwardedFor)
+			g.ServeHTTP(h, r)
+			return h.Code
+		}
+
+		assert.Equal(t, http.StatusUnauthorized, request("203.0.113.1"))
+		// X-Forwarded-For of an untrusted peer does not get a fresh bucket
+		assert.Equal(t, http.StatusTooManyRequests, request("203.0.113.2"))
+	})
+}
+
+func TestRegister_RateLimitGroups(t *testing.T) {
+	authenticator, err := auth.NewAuthenticator(configs.Auth{Enabled: false})
+	require.NoError(t, err)
+
+	g := gin.New()
+	require.NoError(t, g.SetTrustedProxies(nil))
+	err = Register(g, Dependencies{
+		OrderService:  new(mocks.IOrderService),
+		Projector:     privacy.NewProjector(privacy.Policy{}, nil),
+		Authenticator: authenticator,
+		RateLimit: configs.RateLimit{Enabled: true, OrdersRate: 0.001, OrdersBurst: 1, GraphQLRate: 0.001, GraphQLBurst: 1,
+			StatsRate: 0.001, StatsBurst: 1, ExportRate: 0.001, ExportBurst: 1, StreamRate: 0.001, StreamBurst: 1,
+			AdminRate: 0.001, AdminBurst: 1, IPRate: 100, IPBurst: 100},
+		RateLimitStore: ratelimit.NewMemoryStore(time.Minute),
+		Bus:            events.NewBus(0),
+		Stream:         configs.Stream{BufferSize: 1, HeartbeatInterval: 1},
+		GraphQL:        configs.GraphQL{MaxDepth: 1, MaxComplexity: 1},
+	})
+	require.NoError(t, err)
+
+	request := func(method, path string) int {
+		h := httptest.NewRecorder()
+		r := httptest.NewRequest(method, path, nil)
+		r.Header.Set("Last-Event-ID", "invalid")
+		g.ServeHTTP(h, r)
+		return h.Code
+	}
+
+	// the requests are rejected by the handlers before any service call, only the status tells whether they were limited
+	for _, group := range []struct {
+		name                string
+		first, second       string
+		firstMethod, method string
+	}{
+		{"Orders", "/order/invalid", "/order/invalid", http.MethodGet, http.MethodGet},
+		{"GraphQL", "/graphql", "/graphql", http.MethodGet, http.MethodPost},
+		{"Stats", "/stats/orders?period=year", "/stats/basket?from=invalid", http.MethodGet, http.MethodGet},
+		{"Export", "/orders/export?format=xml", "/orders/export?format=xml", http.MethodGet, http.MethodGet},
+		{"Stream", "/orders/stream", "/orders/ws?last_event_id=invalid", http.MethodGet, http.MethodGet},
+		{"Admin", "/admin/kafka/seek", "/customers/100900/personal-data", http.MethodPost, http.MethodDelete},
+	} {
+		t.Run(group.name, func(t *testing.T) {
+			assert.NotEqual(t, http.StatusTooManyRequests, request(group.firstMethod, group.first), "other groups do not use up the bucket")
+			assert.Equal(t, http.StatusTooManyRequests, request(group.method, group.second))
+		})
+	}
+}
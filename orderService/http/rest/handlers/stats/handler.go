@@ -0,0 +1,183 @@
+package stats
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"orderService/internal/models"
+	"orderService/internal/service"
+	"strconv"
+)
+
+const (
+	defaultTopLimit = 10
+	maxTopLimit     = 100
+)
+
+type Handler struct {
+	service service.IStatsService
+}
+
+func NewHandler(service service.IStatsService) Handler {
+	return Handler{service: service}
+}
+
+// Orders 				godoc
+// @Summary				Orders per day or week
+// @Param				period query string false "day (default) or week"
+// @Param				from query string false "Created at or after, RFC3339 time or YYYY-MM-DD"
+// @Param				to query string false "Created before, RFC3339 time or YYYY-MM-DD"
+// @Produce				application/json
+// @Tags				stats
+// @Security			ApiKeyAuth
+// @Security			BearerAuth
+// @Success				200 {array} models.PeriodCount
+// @Router				/stats/orders [get]
+func (h Handler) Orders(c *gin.Context) {
+	period := models.Period(c.DefaultQuery("period", string(models.PeriodDay)))
+	if period != models.PeriodDay && period != models.PeriodWeek {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "period must be day or week"})
+		return
+	}
+
+	respond(c, func(filter models.OrderFilter) (any, error) {
+		return h.service.OrdersPerPeriod(filter, period)
+	})
+}
+
+// Revenue 				godoc
+// @Summary				Revenue per currency
+// @Param				from query string false "Created at or after, RFC3339 time or YYYY-MM-DD"
+// @Param				to query string false "Created before, RFC3339 time or YYYY-MM-DD"
+// @Produce				application/json
+// @Tags				stats
+// @Security			ApiKeyAuth
+// @Security			BearerAuth
+// @Success				200 {array} models.CurrencyRevenue
+// @Router				/stats/revenue [get]
+func (h Handler) Revenue(c *gin.Context) {
+	respond(c, func(filter models.OrderFilter) (any, error) {
+		return h.service.RevenueByCurrency(filter)
+	})
+}
+
+// TopBrands 			godoc
+// @Summary				Top brands by items total price
+// @Param				limit query int false "Number of brands, 10 by default, at most 100"
+// @Param				from query string false "Created at or after, RFC3339 time or YYYY-MM-DD"
+// @Param				to query string false "Created before, RFC3339 time or YYYY-MM-DD"
+// @Produce				application/json
+// @Tags				stats
+// @Security			ApiKeyAuth
+// @Security			BearerAuth
+// @Success				200 {array} models.TopBrand
+// @Router				/stats/top-brands [get]
+func (h Handler) TopBrands(c *gin.Context) {
+	limit, ok := topLimit(c)
+	if !ok {
+		return
+	}
+
+	respond(c, func(filter models.OrderFilter) (any, error) {
+		return h.service.TopBrands(filter, limit)
+	})
+}
+
+// TopItems 			godoc
+// @Summary				Top items by total price
+// @Param				limit query int false "Number of items, 10 by default, at most 100"
+// @Param				from query string false "Created at or after, RFC3339 time or YYYY-MM-DD"
+// @Param				to query string false "Created before, RFC3339 time or YYYY-MM-DD"
+// @Produce				application/json
+// @Tags				stats
+// @Security			ApiKeyAuth
+// @Security			BearerAuth
+// @Success				200 {array} models.TopItem
+// @Router				/stats/top-items [get]
+func (h Handler) TopItems(c *gin.Context) {
+	limit, ok := topLimit(c)
+	if !ok {
+		return
+	}
+
+	respond(c, func(filter models.OrderFilter) (any, error) {
+		return h.service.TopItems(filter, limit)
+	})
+}
+
+// DeliveryServices 	godoc
+// @Summary				Orders per delivery service
+// @Param				from query string false "Created at or after, RFC3339 time or YYYY-MM-DD"
+// @Param				to query string false "Created before, RFC3339 time or YYYY-MM-DD"
+// @Produce				application/json
+// @Tags				stats
+// @Security			ApiKeyAuth
+// @Security			BearerAuth
+// @Success				200 {array} models.DeliveryServiceCount
+// @Router				/stats/delivery-services [get]
+func (h Handler) DeliveryServices(c *gin.Context) {
+	respond(c, func(filter models.OrderFilter) (any, error) {
+		return h.service.OrdersByDeliveryService(filter)
+	})
+}
+
+// Locations 			godoc
+// @Summary				Orders per delivery region and city
+// @Param				from query string false "Created at or after, RFC3339 time or YYYY-MM-DD"
+// @Param				to query string false "Created before, RFC3339 time or YYYY-MM-DD"
+// @Produce				application/json
+// @Tags				stats
+// @Security			ApiKeyAuth
+// @Security			BearerAuth
+// @Success				200 {array} models.LocationCount
+// @Router				/stats/locations [get]
+func (h Handler) Locations(c *gin.Context) {
+	respond(c, func(filter models.OrderFilter) (any, error) {
+		return h.service.OrdersByLocation(filter)
+	})
+}
+
+// Basket 				godoc
+// @Summary				Average basket per currency
+// @Description			Average order amount and number of items per order
+// @Param				from query string false "Created at or after, RFC3339 time or YYYY-MM-DD"
+// @Param				to query string false "Created before, RFC3339 time or YYYY-MM-DD"
+// @Produce				application/json
+// @Tags				stats
+// @Security			ApiKeyAuth
+// @Security			BearerAuth
+// @Success				200 {array} models.BasketStats
+// @Router				/stats/basket [get]
+func (h Handler) Basket(c *gin.Context) {
+	respond(c, func(filter models.OrderFilter) (any, error) {
+		return h.service.Basket(filter)
+	})
+}
+
+// respond parses the date range and writes the statistic
+func respond(c *gin.Context, stat func(filter models.OrderFilter) (any, error)) {
+	filter, err := models.ParseOrderFilter(c.Query("from"), c.Query("to"), "")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := stat(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to compute statistics"})
+		log.Println(err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func topLimit(c *gin.Context) (int, bool) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultTopLimit)))
+	if err != nil || limit < 1 || limit > maxTopLimit {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be a number from 1 to %d", maxTopLimit)})
+		return 0, false
+	}
+	return limit, true
+}
@@ -0,0 +1,102 @@
+package stats
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"orderService/internal/models"
+	"orderService/internal/service/mocks"
+	"testing"
+	"time"
+)
+
+func serve(handler Handler, url string) *httptest.ResponseRecorder {
+	g := gin.New()
+	g.GET("/stats/orders", handler.Orders)
+	g.GET("/stats/top-brands", handler.TopBrands)
+	g.GET("/stats/basket", handler.Basket)
+
+	h := httptest.NewRecorder()
+	g.ServeHTTP(h, httptest.NewRequest("GET", url, nil))
+	return h
+}
+
+func TestHandler_Orders(t *testing.T) {
+	t.Run("PerWeekWithDateRange", func(t *testing.T) {
+		filter := models.OrderFilter{
+			From: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			To:   time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		}
+		mockStatsService := new(mocks.IStatsService)
+		mockStatsService.On("OrdersPerPeriod", filter, models.PeriodWeek).
+			Return([]models.PeriodCount{{Period: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Orders: 42}}, nil)
+
+		h := serve(NewHandler(mockStatsService), "/stats/orders?period=week&from=2024-01-01&to=2024-02-01")
+
+		assert.Equal(t, 200, h.Code)
+		assert.JSONEq(t, `[{"period":"2024-01-01T00:00:00Z","orders":42}]`, h.Body.String())
+	})
+
+	t.Run("UnknownPeriod", func(t *testing.T) {
+		mockStatsService := new(mocks.IStatsService)
+
+		h := serve(NewHandler(mockStatsService), "/stats/orders?period=month")
+
+		assert.Equal(t, 400, h.Code)
+		assert.JSONEq(t, `{"error":"period must be day or week"}`, h.Body.String())
+	})
+
+	t.Run("InvalidDate", func(t *testing.T) {
+		mockStatsService := new(mocks.IStatsService)
+
+		h := serve(NewHandler(mockStatsService), "/stats/orders?from=yesterday")
+
+		assert.Equal(t, 400, h.Code)
+		assert.JSONEq(t, `{"error":"from must be RFC3339 time or YYYY-MM-DD date"}`, h.Body.String())
+	})
+
+	t.Run("FailedInRepo", func(t *testing.T) {
+		mockStatsService := new(mocks.IStatsService)
+		mockStatsService.On("OrdersPerPeriod", models.OrderFilter{}, models.PeriodDay).Return(nil, fmt.Errorf("connection refused"))
+
+		h := serve(NewHandler(mockStatsService), "/stats/orders")
+
+		assert.Equal(t, 500, h.Code)
+		assert.JSONEq(t, `{"error":"failed to compute statistics"}`, h.Body.String())
+	})
+}
+
+func TestHandler_TopBrands(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockStatsService := new(mocks.IStatsService)
+		mockStatsService.On("TopBrands", models.OrderFilter{}, 3).
+			Return([]models.TopBrand{{Brand: "Vivienne Sabo", Quantity: 2, Revenue: 417}}, nil)
+
+		h := serve(NewHandler(mockStatsService), "/stats/top-brands?limit=3")
+
+		assert.Equal(t, 200, h.Code)
+		assert.JSONEq(t, `[{"brand":"Vivienne Sabo","quantity":2,"revenue":417}]`, h.Body.String())
+	})
+
+	t.Run("LimitOutOfRange", func(t *testing.T) {
+		mockStatsService := new(mocks.IStatsService)
+
+		h := serve(NewHandler(mockStatsService), "/stats/top-brands?limit=1000")
+
+		assert.Equal(t, 400, h.Code)
+		assert.JSONEq(t, `{"error":"limit must be a number from 1 to 100"}`, h.Body.String())
+		mockStatsService.AssertNotCalled(t, "TopBrands")
+	})
+}
+
+func TestHandler_Basket(t *testing.T) {
+	mockStatsService := new(mocks.IStatsService)
+	mockStatsService.On("Basket", models.OrderFilter{}).
+		Return([]models.BasketStats{{Currency: "USD", Orders: 4, AvgAmount: 1817, AvgItems: 1.5}}, nil)
+
+	h := serve(NewHandler(mockStatsService), "/stats/basket")
+
+	assert.Equal(t, 200, h.Code)
+	assert.JSONEq(t, `[{"currency":"USD","orders":4,"avg_amount":1817,"avg_items":1.5}]`, h.Body.String())
+}
@@ -0,0 +1,202 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"log"
+	"net/http"
+	"orderService/configs"
+	"orderService/http/rest/middleware"
+	"orderService/internal/events"
+	"orderService/internal/models"
+	"orderService/internal/privacy"
+	"strconv"
+	"time"
+)
+
+const writeTimeout = 10 * time.Second
+
+type Handler struct {
+	bus       *events.Bus
+	projector privacy.Projector
+	cnf       configs.Stream
+	upgrader  websocket.Upgrader
+}
+
+type eventMessage struct {
+	ID    uint64           `json:"id"`
+	Type  string           `json:"type"`
+	Order models.OrderView `json:"order"`
+}
+
+func NewHandler(bus *events.Bus, projector privacy.Projector, cnf configs.Stream, cors middleware.CorsPolicy) Handler {
+	return Handler{
+		bus:       bus,
+		projector: projector,
+		cnf:       cnf,
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				return origin == "" || cors.AllowsOrigin(origin)
+			},
+		},
+	}
+}
+
+// SSE 					godoc
+// @Summary				Stream order events
+// @Description			Server-Sent Events stream of created and updated orders. Reconnect with Last-Event-ID header to receive missed events
+// @Param				customer_id query string false "Only orders of the customer"
+// @Param				delivery_service query string false "Only orders of the delivery service"
+// @Produce				text/event-stream
+// @Tags				order
+// @Security			ApiKeyAuth
+// @Security			BearerAuth
+// @Success				200 {object} models.OrderView
+// @Router				/orders/stream [get]
+func (h Handler) SSE(c *gin.Context) {
+	subscription, missed, err := h.subscribe(c, c.GetHeader("Last-Event-ID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer subscription.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	viewer := middleware.Viewer(c)
+	send := func(event events.Event) error {
+		data, err := json.Marshal(h.projector.Project(event.Order, viewer))
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+		c.Writer.Flush()
+		return err
+	}
+	heartbeat := func() error {
+		_, err := fmt.Fprint(c.Writer, ": heartbeat\n\n")
+		c.Writer.Flush()
+		return err
+	}
+
+	if err := h.serve(c.Request.Context(), subscription, missed, send, heartbeat); err != nil {
+		log.Printf("SSE stream closed: %v", err)
+		_, _ = fmt.Fprintf(c.Writer, "event: error\ndata: %s\n\n", err.Error())
+		c.Writer.Flush()
+	}
+}
+
+// WebSocket 			godoc
+// @Summary				Stream order events over WebSocket
+// @Description			WebSocket stream of created and updated orders as {"id","type","order"} messages. Reconnect with last_event_id to receive missed events
+// @Param				customer_id query string false "Only orders of the customer"
+// @Param				delivery_service query string false "Only orders of the delivery service"
+// @Param				last_event_id query int false "Last received event id"
+// @Tags				order
+// @Security			ApiKeyAuth
+// @Security			BearerAuth
+// @Router				/orders/ws [get]
+func (h Handler) WebSocket(c *gin.Context) {
+	subscription, missed, err := h.subscribe(c, c.Query("last_event_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer subscription.Close()
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Printf("WebSocket upgrade failed: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	// Reading is required to process control frames, the stream is stopped when the client goes away
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	viewer := middleware.Viewer(c)
+	send := func(event events.Event) error {
+		_ = conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		return conn.WriteJSON(eventMessage{ID: event.ID, Type: string(event.Type), Order: h.projector.Project(event.Order, viewer)})
+	}
+	heartbeat := func() error {
+		return conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout))
+	}
+
+	if err := h.serve(ctx, subscription, missed, send, heartbeat); err != nil {
+		log.Printf("WebSocket stream closed: %v", err)
+		_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, err.Error()), time.Now().Add(writeTimeout))
+	}
+}
+
+// serve writes the missed events and then live events until the client disconnects.
+// An error is returned when the subscription was closed because the client did not keep up
+func (h Handler) serve(ctx context.Context, subscription *events.Subscription, missed []events.Event, send func(events.Event) error, heartbeat func() error) error {
+	for _, event := range missed {
+		if err := send(event); err != nil {
+			return nil
+		}
+	}
+
+	ticker := time.NewTicker(time.Duration(h.cnf.HeartbeatInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-subscription.Events():
+			if !ok {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return fmt.Errorf("client is too slow, reconnect with the last event id")
+			}
+			if err := send(event); err != nil {
+				return nil
+			}
+		case <-ticker.C:
+			if err := heartbeat(); err != nil {
+				return nil
+			}
+		}
+	}
+}
+
+// subscribe replays the retained events after lastEventID when the client resumes a stream
+func (h Handler) subscribe(c *gin.Context, lastEventID string) (*events.Subscription, []events.Event, error) {
+	filter := events.Filter{
+		CustomerID:      c.Query("customer_id"),
+		DeliveryService: c.Query("delivery_service"),
+	}
+
+	if lastEventID == "" {
+		return h.bus.Subscribe(h.cnf.BufferSize, filter), nil, nil
+	}
+
+	id, err := strconv.ParseUint(lastEventID, 10, 64)
+	if err != nil {
+		return nil, nil, fmt.Errorf("last event id must be a number")
+	}
+
+	subscription, missed := h.bus.SubscribeSince(id, h.cnf.BufferSize, filter)
+	return subscription, missed, nil
+}
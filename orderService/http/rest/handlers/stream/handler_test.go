@@ -0,0 +1,194 @@
+package stream
+
+import (
+	"bufio"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"orderService/configs"
+	"orderService/http/rest/middleware"
+	"orderService/internal/events"
+	"orderService/internal/models"
+	"orderService/internal/privacy"
+	"orderService/internal/service/mocks"
+	"strings"
+	"testing"
+	"time"
+)
+
+var policy = privacy.NewPolicy(configs.Privacy{
+	PhoneRoles: []string{"admin"},
+})
+
+var streamConfig = configs.Stream{BufferSize: 8, HeartbeatInterval: 60}
+
+func newServer(t *testing.T, bus *events.Bus) *httptest.Server {
+	return newServerFor(t, bus, privacy.NewProjector(policy, nil), nil)
+}
+
+// newServerFor serves the stream to a caller with the roles
+func newServerFor(t *testing.T, bus *events.Bus, projector privacy.Projector, roles []string) *httptest.Server {
+	cors, err := middleware.NewCorsPolicy(configs.Cors{AllowedOrigins: []string{"http://localhost"}})
+	require.NoError(t, err)
+
+	handler := NewHandler(bus, projector, streamConfig, cors)
+	g := gin.New()
+	g.Use(func(c *gin.Context) {
+		c.Set(middleware.RolesKey, roles)
+	})
+	g.GET("/orders/stream", handler.SSE)
+	g.GET("/orders/ws", handler.WebSocket)
+
+	server := httptest.NewServer(g)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// readSSE returns the next event of the stream skipping comments
+func readSSE(t *testing.T, reader *bufio.Reader) map[string]string {
+	event := map[string]string{}
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimRight(line, "\n")
+		if line == "" {
+			if len(event) > 0 {
+				return event
+			}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		key, value, _ := strings.Cut(line, ": ")
+		event[key] = value
+	}
+}
+
+// waitSubscribed publishes once the handler has subscribed to the bus, events published earlier are not delivered
+func waitSubscribed(t *testing.T, bus *events.Bus, publish func()) {
+	deadline := time.Now().Add(2 * time.Second)
+	for bus.Subscribers() == 0 {
+		require.True(t, time.Now().Before(deadline), "stream did not subscribe")
+		time.Sleep(5 * time.Millisecond)
+	}
+	publish()
+}
+
+func TestHandler_SSE(t *testing.T) {
+	t.Run("FilteredAndMasked", func(t *testing.T) {
+		bus := events.NewBus(10)
+		server := newServer(t, bus)
+
+		response, err := http.Get(server.URL + "/orders/stream?customer_id=100900")
+		require.NoError(t, err)
+		defer response.Body.Close()
+		assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+
+		waitSubscribed(t, bus, func() {
+			bus.Publish(events.OrderCreated, models.OrderView{CustomerID: "other"})
+			bus.Publish(events.OrderCreated, models.OrderView{CustomerID: "100900", Delivery: models.DeliveryView{Phone: "+9720000000"}})
+		})
+
+		event := readSSE(t, bufio.NewReader(response.Body))
+		assert.Equal(t, "2", event["id"])
+		assert.Equal(t, string(events.OrderCreated), event["event"])
+
+		var view models.OrderView
+		require.NoError(t, json.Unmarshal([]byte(event["data"]), &view))
+		assert.Equal(t, "100900", view.CustomerID)
+		assert.Equal(t, "+972*****00", view.Delivery.Phone)
+	})
+
+	t.Run("UnmaskedEventIsAudited", func(t *testing.T) {
+		uid := uuid.MustParse("1e9ad4fb-2615-46f9-9458-20b59253086b")
+		bus := events.NewBus(10)
+		mockAudit := new(mocks.IAuditService)
+		mockAudit.On("Record", mock.Anything).Return()
+		server := newServerFor(t, bus, privacy.NewProjector(policy, mockAudit), []string{"admin"})
+
+		response, err := http.Get(server.URL + "/orders/stream")
+		require.NoError(t, err)
+		defer response.Body.Close()
+		waitSubscribed(t, bus, func() {
+			bus.Publish(events.OrderCreated, models.OrderView{Uid: uid, Delivery: models.DeliveryView{Phone: "+9720000000"}})
+		})
+
+		event := readSSE(t, bufio.NewReader(response.Body))
+		assert.Contains(t, event["data"], "+9720000000")
+		mockAudit.AssertNumberOfCalls(t, "Record", 1)
+		entry := mockAudit.Calls[0].Arguments.Get(0).(models.AuditEntry)
+		assert.Equal(t, models.AuditOrderViewed, entry.Action)
+		assert.Equal(t, uid, *entry.OrderUid)
+	})
+
+	t.Run("ResumeFromLastEventID", func(t *testing.T) {
+		bus := events.NewBus(10)
+		server := newServer(t, bus)
+		bus.Publish(events.OrderCreated, models.OrderView{TrackNumber: "first"})
+		bus.Publish(events.OrderStatusChanged, models.OrderView{TrackNumber: "second"})
+
+		request, err := http.NewRequest(http.MethodGet, server.URL+"/orders/stream", nil)
+		require.NoError(t, err)
+		request.Header.Set("Last-Event-ID", "1")
+		response, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		defer response.Body.Close()
+
+		event := readSSE(t, bufio.NewReader(response.Body))
+		assert.Equal(t, "2", event["id"])
+		assert.Equal(t, string(events.OrderStatusChanged), event["event"])
+	})
+
+	t.Run("InvalidLastEventID", func(t *testing.T) {
+		server := newServer(t, events.NewBus(10))
+
+		request, err := http.NewRequest(http.MethodGet, server.URL+"/orders/stream", nil)
+		require.NoError(t, err)
+		request.Header.Set("Last-Event-ID", "abc")
+		response, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		defer response.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	})
+}
+
+func TestHandler_WebSocket(t *testing.T) {
+	t.Run("ReceivesEvents", func(t *testing.T) {
+		bus := events.NewBus(10)
+		server := newServer(t, bus)
+
+		url := "ws" + strings.TrimPrefix(server.URL, "http") + "/orders/ws?delivery_service=meest"
+		conn, _, err := websocket.DefaultDialer.Dial(url, http.Header{"Origin": {"http://localhost"}})
+		require.NoError(t, err)
+		defer conn.Close()
+
+		waitSubscribed(t, bus, func() {
+			bus.Publish(events.OrderCreated, models.OrderView{DeliveryService: "dhl"})
+			bus.Publish(events.OrderCreated, models.OrderView{DeliveryService: "meest"})
+		})
+
+		var message eventMessage
+		require.NoError(t, conn.ReadJSON(&message))
+		assert.Equal(t, uint64(2), message.ID)
+		assert.Equal(t, string(events.OrderCreated), message.Type)
+		assert.Equal(t, "meest", message.Order.DeliveryService)
+	})
+
+	t.Run("ForbiddenOrigin", func(t *testing.T) {
+		server := newServer(t, events.NewBus(10))
+
+		url := "ws" + strings.TrimPrefix(server.URL, "http") + "/orders/ws"
+		_, response, err := websocket.DefaultDialer.Dial(url, http.Header{"Origin": {"http://evil.example"}})
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, response.StatusCode)
+	})
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"orderService/internal/auth"
+)
+
+// PrincipalKey is the gin context key holding the auth.Principal of the caller
+const PrincipalKey = "principal"
+
+func Authenticate(authenticator auth.Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, err := authenticator.Authenticate(c.GetHeader("X-API-Key"), c.GetHeader("Authorization"))
+		if err != nil {
+			if !errors.Is(err, auth.ErrUnauthenticated) {
+				log.Printf("Authentication failed: %v", err)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "authentication failed"})
+				return
+			}
+			log.Printf("Unauthenticated request to %s: %v", c.FullPath(), err)
+			c.Header("WWW-Authenticate", `Bearer realm="orderService"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": auth.ErrUnauthenticated.Error()})
+			return
+		}
+
+		c.Set(PrincipalKey, principal)
+		c.Set(RolesKey, principal.Roles)
+		c.Next()
+	}
+}
+
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := GetPrincipal(c)
+		if !ok || !principal.HasScope(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "scope " + scope + " is required"})
+			return
+		}
+		c.Next()
+	}
+}
+
+func GetPrincipal(c *gin.Context) (auth.Principal, bool) {
+	value, ok := c.Get(PrincipalKey)
+	if !ok {
+		return auth.Principal{}, false
+	}
+	principal, ok := value.(auth.Principal)
+	return principal, ok
+}
+
+// Actor returns the audit log actor of the authenticated principal
+func Actor(c *gin.Context) string {
+	if principal, ok := GetPrincipal(c); ok {
+		return principal.Actor()
+	}
+	return auth.Anonymous.Actor()
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"orderService/configs"
+	"orderService/internal/auth"
+	"testing"
+)
+
+func newAuthEngine(t *testing.T, cnf configs.Auth) *gin.Engine {
+	staticKeys, err := auth.NewStaticKeyStore([]string{
+		fmt.Sprintf("reader;%s;orders:read;support", auth.HashKey("reader-key")),
+		fmt.Sprintf("writer;%s;orders:write", auth.HashKey("writer-key")),
+	})
+	require.NoError(t, err)
+	authenticator, err := auth.NewAuthenticator(cnf, staticKeys)
+	require.NoError(t, err)
+
+	g := gin.New()
+	g.GET("/order", Authenticate(authenticator), RequireScope(auth.ScopeOrdersRead), func(c *gin.Context) {
+		principal, _ := GetPrincipal(c)
+		c.JSON(http.StatusOK, gin.H{"subject": principal.Subject, "roles": Roles(c)})
+	})
+	return g
+}
+
+func TestAuthenticate(t *testing.T) {
+	g := newAuthEngine(t, configs.Auth{Enabled: true})
+
+	tableData := []struct {
+		name   string
+		apiKey string
+		code   int
+		body   string
+	}{
+		{name: "Success", apiKey: "reader-key", code: http.StatusOK, body: `{"subject":"reader","roles":["support"]}`},
+		{name: "MissingScope", apiKey: "writer-key", code: http.StatusForbidden, body: `{"error":"scope orders:read is required"}`},
+		{name: "UnknownKey", apiKey: "other-key", code: http.StatusUnauthorized, body: `{"error":"missing or invalid credentials"}`},
+		{name: "NoCredentials", code: http.StatusUnauthorized, body: `{"error":"missing or invalid credentials"}`},
+	}
+
+	for _, td := range tableData {
+		t.Run(td.name, func(t *testing.T) {
+			h := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/order", nil)
+			if td.apiKey != "" {
+				r.Header.Set("X-API-Key", td.apiKey)
+			}
+
+			g.ServeHTTP(h, r)
+
+			assert.Equal(t, td.code, h.Code)
+			assert.JSONEq(t, td.body, h.Body.String())
+		})
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		g := newAuthEngine(t, configs.Auth{Enabled: false})
+		h := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/order", nil)
+
+		g.ServeHTTP(h, r)
+
+		assert.Equal(t, http.StatusOK, h.Code)
+		assert.JSONEq(t, `{"subject":"anonymous","roles":null}`, h.Body.String())
+	})
+}
@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"orderService/configs"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type CorsPolicy struct {
+	allowAnyOrigin   bool
+	origins          []string
+	originPatterns   []*regexp.Regexp
+	methods          []string
+	headers          []string
+	exposedHeaders   []string
+	allowCredentials bool
+	maxAge           int
+}
+
+func NewCorsPolicy(cnf configs.Cors) (CorsPolicy, error) {
+	policy := CorsPolicy{
+		methods:          upperAll(cnf.AllowedMethods),
+		headers:          canonicalAll(cnf.AllowedHeaders),
+		exposedHeaders:   cnf.ExposedHeaders,
+		allowCredentials: cnf.AllowCredentials,
+		maxAge:           cnf.MaxAge,
+	}
+
+	for _, origin := range cnf.AllowedOrigins {
+		if origin == "*" {
+			policy.allowAnyOrigin = true
+			continue
+		}
+		policy.origins = append(policy.origins, strings.ToLower(strings.TrimSuffix(origin, "/")))
+	}
+
+	for _, pattern := range cnf.AllowedOriginPatterns {
+		// a pattern must match the whole origin, otherwise https://app\.example\.com allows https://app.example.com.evil.net
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return CorsPolicy{}, fmt.Errorf("invalid cors origin pattern %q: %w", pattern, err)
+		}
+		policy.originPatterns = append(policy.originPatterns, re)
+	}
+
+	if policy.allowAnyOrigin && policy.allowCredentials {
+		return CorsPolicy{}, fmt.Errorf("cors credentials can not be allowed for any origin")
+	}
+
+	return policy, nil
+}
+
+func (p CorsPolicy) AllowsOrigin(origin string) bool {
+	if p.allowAnyOrigin || slices.Contains(p.origins, strings.ToLower(origin)) {
+		return true
+	}
+	for _, re := range p.originPatterns {
+		if re.MatchString(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p CorsPolicy) allowsHeaders(requested string) bool {
+	for _, header := range strings.Split(requested, ",") {
+		header = strings.TrimSpace(header)
+		if header != "" && !slices.Contains(p.headers, http.CanonicalHeaderKey(header)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Cors applies the policy to cross-origin requests and answers preflight requests with 204, or 403 if the
+// origin, method or headers are not allowed. It must be registered globally, so it runs before routing
+func Cors(policy CorsPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		if preflight {
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Method")
+			c.Writer.Header().Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		if !policy.AllowsOrigin(origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if policy.allowAnyOrigin {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
+		if policy.allowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if len(policy.exposedHeaders) > 0 {
+				c.Header("Access-Control-Expose-Headers", strings.Join(policy.exposedHeaders, ", "))
+			}
+			c.Next()
+			return
+		}
+
+		method := strings.ToUpper(c.GetHeader("Access-Control-Request-Method"))
+		if !slices.Contains(policy.methods, method) || !policy.allowsHeaders(c.GetHeader("Access-Control-Request-Headers")) {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+
+		c.Header("Access-Control-Allow-Methods", strings.Join(policy.methods, ", "))
+		if len(policy.headers) > 0 {
+			c.Header("Access-Control-Allow-Headers", strings.Join(policy.headers, ", "))
+		}
+		if policy.maxAge > 0 {
+			c.Header("Access-Control-Max-Age", strconv.Itoa(policy.maxAge))
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+func upperAll(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		result = append(result, strings.ToUpper(strings.TrimSpace(value)))
+	}
+	return result
+}
+
+func canonicalAll(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		result = append(result, http.CanonicalHeaderKey(strings.TrimSpace(value)))
+	}
+	return result
+}
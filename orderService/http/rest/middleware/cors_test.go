@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"orderService/configs"
+	"testing"
+)
+
+var corsConfig = configs.Cors{
+	AllowedOrigins:        []string{"http://localhost"},
+	AllowedOriginPatterns: []string{`^https://[a-z0-9-]+\.example\.com$`},
+	AllowedMethods:        []string{"GET", "POST"},
+	AllowedHeaders:        []string{"Content-Type", "X-API-Key"},
+	ExposedHeaders:        []string{"X-Request-ID"},
+	AllowCredentials:      true,
+	MaxAge:                600,
+}
+
+func newCorsEngine(t *testing.T, cnf configs.Cors) *gin.Engine {
+	policy, err := NewCorsPolicy(cnf)
+	require.NoError(t, err)
+
+	g := gin.New()
+	g.Use(Cors(policy))
+	g.OPTIONS("/*path", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	g.GET("/order/:uid", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return g
+}
+
+func TestCors(t *testing.T) {
+	g := newCorsEngine(t, corsConfig)
+
+	tableData := []struct {
+		name           string
+		method         string
+		headers        map[string]string
+		code           int
+		expectedHeader map[string]string
+	}{
+		{
+			name:    "SimpleRequestFromAllowedOrigin",
+			method:  http.MethodGet,
+			headers: map[string]string{"Origin": "http://localhost"},
+			code:    http.StatusOK,
+			expectedHeader: map[string]string{
+				"Access-Control-Allow-Origin":      "http://localhost",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Expose-Headers":    "X-Request-ID",
+				"Vary":                             "Origin",
+			},
+		},
+		{
+			name:    "SimpleRequestFromOriginMatchedByPattern",
+			method:  http.MethodGet,
+			headers: map[string]string{"Origin": "https://shop-1.example.com"},
+			code:    http.StatusOK,
+			expectedHeader: map[string]string{
+				"Access-Control-Allow-Origin": "https://shop-1.example.com",
+			},
+		},
+		{
+			name:    "SimpleRequestFromNotAllowedOrigin",
+			method:  http.MethodGet,
+			headers: map[string]string{"Origin": "https://evil.com"},
+			code:    http.StatusOK,
+			expectedHeader: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+		{
+			name:   "SimpleRequestWithoutOrigin",
+			method: http.MethodGet,
+			code:   http.StatusOK,
+			expectedHeader: map[string]string{
+				"Access-Control-Allow-Origin": "",
+				"Vary":                        "",
+			},
+		},
+		{
+			name:   "Preflight",
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                         "http://localhost",
+				"Access-Control-Request-Method":  "GET",
+				"Access-Control-Request-Headers": "x-api-key, content-type",
+			},
+			code: http.StatusNoContent,
+			expectedHeader: map[string]string{
+				"Access-Control-Allow-Origin":  "http://localhost",
+				"Access-Control-Allow-Methods": "GET, POST",
+				"Access-Control-Allow-Headers": "Content-Type, X-Api-Key",
+				"Access-Control-Max-Age":       "600",
+			},
+		},
+		{
+			name:   "PreflightFromNotAllowedOrigin",
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                        "https://evil.com",
+				"Access-Control-Request-Method": "GET",
+			},
+			code: http.StatusForbidden,
+			expectedHeader: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+		{
+			name:   "PreflightWithNotAllowedMethod",
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                        "http://localhost",
+				"Access-Control-Request-Method": "DELETE",
+			},
+			code: http.StatusForbidden,
+			expectedHeader: map[string]string{
+				"Access-Control-Allow-Methods": "",
+			},
+		},
+		{
+			name:   "PreflightWithNotAllowedHeader",
+			method: http.MethodOptions,
+			headers: map[string]string{
+				"Origin":                         "http://localhost",
+				"Access-Control-Request-Method":  "GET",
+				"Access-Control-Request-Headers": "X-Custom",
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			name:    "OptionsWithoutPreflightHeaders",
+			method:  http.MethodOptions,
+			headers: map[string]string{"Origin": "http://localhost"},
+			code:    http.StatusNoContent,
+			expectedHeader: map[string]string{
+				"Access-Control-Allow-Origin":  "http://localhost",
+				"Access-Control-Allow-Methods": "",
+			},
+		},
+	}
+
+	for _, td := range tableData {
+		t.Run(td.name, func(t *testing.T) {
+			h := httptest.NewRecorder()
+			r := httptest.NewRequest(td.method, "/order/1e9ad4fb-2615-46f9-9458-20b59253086b", nil)
+			for key, value := range td.headers {
+				r.Header.Set(key, value)
+			}
+
+			g.ServeHTTP(h, r)
+
+			assert.Equal(t, td.code, h.Code)
+			for key, value := range td.expectedHeader {
+				assert.Equal(t, value, h.Header().Get(key), key)
+			}
+		})
+	}
+}
+
+func TestCors_AnyOrigin(t *testing.T) {
+	g := newCorsEngine(t, configs.Cors{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}})
+
+	h := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/order/1", nil)
+	r.Header.Set("Origin", "https://any.com")
+
+	g.ServeHTTP(h, r)
+
+	assert.Equal(t, "*", h.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, h.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func TestNewCorsPolicy(t *testing.T) {
+	t.Run("InvalidPattern", func(t *testing.T) {
+		_, err := NewCorsPolicy(configs.Cors{AllowedOriginPatterns: []string{"("}})
+
+		assert.ErrorContains(t, err, `invalid cors origin pattern "("`)
+	})
+
+	t.Run("PatternMatchesTheWholeOrigin", func(t *testing.T) {
+		policy, err := NewCorsPolicy(configs.Cors{AllowedOriginPatterns: []string{`https://app\.example\.com`, `http://localhost:\d+|https://admin\.example\.com`}})
+		require.NoError(t, err)
+
+		assert.True(t, policy.AllowsOrigin("https://app.example.com"))
+		assert.True(t, policy.AllowsOrigin("http://localhost:3000"))
+		assert.True(t, policy.AllowsOrigin("https://admin.example.com"))
+		assert.False(t, policy.AllowsOrigin("https://app.example.com.evil.net"), "suffix")
+		assert.False(t, policy.AllowsOrigin("https://evil.net/https://app.example.com"), "prefix")
+		assert.False(t, policy.AllowsOrigin("http://localhost:3000.evil.net"), "suffix of an alternative")
+		assert.False(t, policy.AllowsOrigin("https://evil.https://admin.example.com"), "prefix of an alternative")
+	})
+
+	t.Run("CredentialsForAnyOrigin", func(t *testing.T) {
+		_, err := NewCorsPolicy(configs.Cors{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+
+		assert.EqualError(t, err, "cors credentials can not be allowed for any origin")
+	})
+}
@@ -4,9 +4,13 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"log"
+	"orderService/internal/privacy"
 	"time"
 )
 
+// RolesKey is the gin context key holding the roles of the authenticated caller
+const RolesKey = "roles"
+
 func RequestIdMiddleware(methodName string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		requestId := uuid.New().String()
@@ -22,10 +26,11 @@ func RequestIdMiddleware(methodName string) gin.HandlerFunc {
 	}
 }
 
-func SetCors() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type")
-	}
+func Roles(c *gin.Context) []string {
+	return c.GetStringSlice(RolesKey)
+}
+
+// Viewer is the caller the orders of the response are projected for
+func Viewer(c *gin.Context) privacy.Viewer {
+	return privacy.Viewer{Actor: Actor(c), Roles: Roles(c)}
 }
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"log"
+	"math"
+	"net/http"
+	"orderService/internal/auth"
+	"orderService/internal/ratelimit"
+	"strconv"
+	"time"
+)
+
+// RateLimit limits requests of the route group per API key/JWT subject, or per client IP for anonymous callers.
+// It must be placed after Authenticate to key by credentials
+func RateLimit(store ratelimit.Store, group string, limit ratelimit.Limit) gin.HandlerFunc {
+	return limitBy(store, group, limit, clientKey)
+}
+
+// RateLimitByIP limits requests per client IP and must be placed before Authenticate, so invalid credentials
+// are throttled before they are looked up. The IP is taken from X-Forwarded-For only behind a trusted proxy
+func RateLimitByIP(store ratelimit.Store, group string, limit ratelimit.Limit) gin.HandlerFunc {
+	return limitBy(store, group, limit, func(c *gin.Context) string { return "ip:" + c.ClientIP() })
+}
+
+func limitBy(store ratelimit.Store, group string, limit ratelimit.Limit, key func(c *gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result, err := store.Take(group+":"+key(c), limit)
+		if err != nil {
+			log.Printf("Rate limit store error, request is allowed: %v", err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+			return
+		}
+		c.Next()
+	}
+}
+
+func clientKey(c *gin.Context) string {
+	if principal, ok := GetPrincipal(c); ok && principal.Method != auth.MethodAnonymous {
+		return principal.Actor()
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
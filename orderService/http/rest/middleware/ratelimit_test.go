@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"orderService/internal/auth"
+	"orderService/internal/ratelimit"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	store := ratelimit.NewMemoryStore(time.Minute)
+	g := gin.New()
+	g.GET("/order", func(c *gin.Context) {
+		if key := c.GetHeader("X-API-Key"); key != "" {
+			c.Set(PrincipalKey, auth.Principal{Subject: key, Method: auth.MethodApiKey})
+		}
+	}, RateLimit(store, "orders", ratelimit.Limit{Rate: 0.5, Burst: 1}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	request := func(apiKey, ip string) *httptest.ResponseRecorder {
+		h := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/order", nil)
+		r.RemoteAddr = ip + ":1234"
+		if apiKey != "" {
+			r.Header.Set("X-API-Key", apiKey)
+		}
+		g.ServeHTTP(h, r)
+		return h
+	}
+
+	t.Run("Allowed", func(t *testing.T) {
+		h := request("", "10.0.0.1")
+
+		assert.Equal(t, http.StatusOK, h.Code)
+		assert.Equal(t, "1", h.Header().Get("X-RateLimit-Limit"))
+		assert.Equal(t, "0", h.Header().Get("X-RateLimit-Remaining"))
+		assert.Equal(t, "2", h.Header().Get("X-RateLimit-Reset"))
+		assert.Empty(t, h.Header().Get("Retry-After"))
+	})
+
+	t.Run("Limited", func(t *testing.T) {
+		h := request("", "10.0.0.1")
+
+		assert.Equal(t, http.StatusTooManyRequests, h.Code)
+		assert.Equal(t, "2", h.Header().Get("Retry-After"))
+		assert.JSONEq(t, `{"error":"rate limit exceeded"}`, h.Body.String())
+	})
+
+	t.Run("OtherIP", func(t *testing.T) {
+		h := request("", "10.0.0.2")
+
+		assert.Equal(t, http.StatusOK, h.Code)
+	})
+
+	t.Run("KeyedByApiKey", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, request("reporting", "10.0.0.1").Code)
+		assert.Equal(t, http.StatusTooManyRequests, request("reporting", "10.0.0.3").Code)
+	})
+}
@@ -4,21 +4,33 @@ import (
 	"context"
 	"github.com/gin-gonic/gin"
 	"github.com/pressly/goose/v3"
+	"google.golang.org/grpc"
 	"log"
+	"net"
 	"orderService/configs"
 	"orderService/http/rest/handlers"
+	"orderService/http/rest/middleware"
+	"orderService/internal/auth"
 	"orderService/internal/cache"
+	"orderService/internal/events"
 	consumer "orderService/internal/kafka"
+	"orderService/internal/privacy"
+	"orderService/internal/ratelimit"
 	"orderService/internal/repository"
 	"orderService/internal/service"
 	"orderService/pkg/db"
+	"orderService/rpc"
+	"time"
 )
 
 type Server struct {
-	config   configs.Config
-	gin      *gin.Engine
-	consumer *consumer.Consumer
-	ctx      context.Context
+	config    configs.Config
+	gin       *gin.Engine
+	grpc      *grpc.Server
+	consumer  *consumer.Consumer
+	stats     service.StatsService
+	lifecycle service.LifecycleService
+	ctx       context.Context
 }
 
 func NewServer(ctx context.Context) (*Server, error) {
@@ -46,31 +58,94 @@ func NewServer(ctx context.Context) (*Server, error) {
 	repo := repository.NewRepository(gorm)
 	lruCache := cache.NewCache(cnf.Cache.Size, cnf.Cache.TTL)
 	lruCacheLoader := cache.NewLCacheLoader(repo, lruCache)
-	orderService := service.NewService(repo, lruCache)
+	bus := events.NewBus(cnf.Stream.HistorySize)
+	auditService := service.NewAuditService(repository.NewAuditRepository(gorm))
+	orderService := service.NewService(repo, lruCache, bus, auditService)
+	lifecycleService := service.NewLifecycleService(repo, lruCache, bus, auditService)
+	statsService := service.NewStatsService(repository.NewStatsRepository(gorm, cnf.Stats.MaterializedView))
 
 	//Наполнение кеша при инициализации сервера
 	if err = lruCacheLoader.LoadCache(lruCache, cnf.Cache.Size); err != nil {
 		log.Printf("Error initializing cache: %s\n", err.Error())
 	}
 
-	engine := gin.Default()
-	handlers.Register(engine, orderService)
+	authenticator, err := newAuthenticator(cnf.Auth, repository.NewApiKeyRepository(gorm))
+	if err != nil {
+		log.Fatalf("Error configuring authentication: %s", err.Error())
+	}
+
+	corsPolicy, err := middleware.NewCorsPolicy(cnf.Cors)
+	if err != nil {
+		log.Fatalf("Error configuring CORS: %s", err.Error())
+	}
 
-	consumer, err := consumer.CreateConsumer(cnf.Kafka, orderService)
+	consumer, err := newConsumer(cnf, orderService)
 	if err != nil {
-		log.Fatalf("Error creating kafka consumer %s", err.Error())
+		log.Fatalf("Error creating %s consumer %s", cnf.Broker, err.Error())
 	}
 
+	engine := gin.Default()
+	if err = engine.SetTrustedProxies(cnf.TrustedProxies); err != nil {
+		log.Fatalf("Error configuring trusted proxies: %s", err.Error())
+	}
+	projector := privacy.NewProjector(privacy.NewPolicy(cnf.Privacy), auditService)
+	err = handlers.Register(engine, handlers.Dependencies{
+		OrderService:   orderService,
+		StatsService:   statsService,
+		Lifecycle:      lifecycleService,
+		Audit:          auditService,
+		Projector:      projector,
+		Authenticator:  authenticator,
+		RateLimit:      cnf.RateLimit,
+		RateLimitStore: ratelimit.NewMemoryStore(time.Duration(cnf.RateLimit.IdleTTL) * time.Second),
+		Cors:           corsPolicy,
+		Bus:            bus,
+		Stream:         cnf.Stream,
+		GraphQL:        cnf.GraphQL,
+		Consumer:       consumer,
+	})
+	if err != nil {
+		log.Fatalf("Error registering handlers: %s", err.Error())
+	}
+
+	grpcServer := rpc.NewServer(orderService, projector, bus, authenticator)
+
 	return &Server{
-		config:   cnf,
-		gin:      engine,
-		consumer: consumer,
-		ctx:      ctx}, nil
+		config:    cnf,
+		gin:       engine,
+		grpc:      grpcServer,
+		consumer:  consumer,
+		stats:     statsService,
+		lifecycle: lifecycleService,
+		ctx:       ctx}, nil
 }
 
 func (s *Server) Run() error {
 	go s.consumer.Start(s.ctx)
-	err := s.gin.Run(s.config.Port)
+	if s.config.Stats.MaterializedView {
+		go s.stats.RefreshViews(s.ctx, time.Duration(s.config.Stats.RefreshInterval)*time.Second)
+	}
+	if s.config.Retention.Enabled {
+		go s.lifecycle.RunRetention(s.ctx, s.config.Retention)
+	}
+
+	listener, err := net.Listen("tcp", s.config.GRPCPort)
+	if err != nil {
+		log.Fatalf("Error listening gRPC port: %s", err.Error())
+		return err
+	}
+	go func() {
+		log.Printf("gRPC server listening at %s", listener.Addr())
+		if err := s.grpc.Serve(listener); err != nil {
+			log.Printf("gRPC server stopped: %s", err.Error())
+		}
+	}()
+	go func() {
+		<-s.ctx.Done()
+		s.grpc.GracefulStop()
+	}()
+
+	err = s.gin.Run(s.config.Port)
 
 	if err != nil {
 		log.Fatal(err.Error())
@@ -79,3 +154,25 @@ func (s *Server) Run() error {
 
 	return nil
 }
+
+func newAuthenticator(cnf configs.Auth, apiKeyRepo repository.IApiKeyRepository) (auth.Authenticator, error) {
+	staticKeys, err := auth.NewStaticKeyStore(cnf.ApiKeys)
+	if err != nil {
+		return auth.Authenticator{}, err
+	}
+
+	keyStores := []auth.KeyStore{staticKeys}
+	if cnf.DBApiKeys {
+		keyStores = append(keyStores, auth.NewDBKeyStore(apiKeyRepo))
+	}
+
+	return auth.NewAuthenticator(cnf, keyStores...)
+}
+
+// newConsumer ingests orders from the broker selected by BROKER
+func newConsumer(cnf configs.Config, orderService service.IOrderService) (*consumer.Consumer, error) {
+	if cnf.Broker == configs.BrokerNats {
+		return consumer.CreateNatsConsumer(cnf.Nats, cnf.Kafka, orderService)
+	}
+	return consumer.CreateConsumer(cnf.Kafka, orderService)
+}
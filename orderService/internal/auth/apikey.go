@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"orderService/internal/repository"
+	"strings"
+	"time"
+)
+
+type KeyStore interface {
+	FindByHash(hash string) (Principal, bool, error)
+}
+
+// HashKey returns the hex encoded SHA-256 of the API key. Only hashes are stored in config and DB
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+type StaticKeyStore struct {
+	keys map[string]Principal
+}
+
+// NewStaticKeyStore parses keys in the "name;sha256;scope|scope;role|role" format
+func NewStaticKeyStore(keys []string) (StaticKeyStore, error) {
+	store := StaticKeyStore{keys: make(map[string]Principal, len(keys))}
+	for _, key := range keys {
+		parts := strings.Split(key, ";")
+		if len(parts) < 3 || len(parts) > 4 {
+			return StaticKeyStore{}, fmt.Errorf("api key %q must have name;sha256;scopes[;roles] format", key)
+		}
+
+		name, hash := parts[0], strings.ToLower(parts[1])
+		if _, err := hex.DecodeString(hash); err != nil || len(hash) != sha256.Size*2 {
+			return StaticKeyStore{}, fmt.Errorf("api key %q hash is not a hex encoded sha256", name)
+		}
+
+		principal := Principal{Subject: name, Method: MethodApiKey, Scopes: splitList(parts[2], "|")}
+		if len(parts) == 4 {
+			principal.Roles = splitList(parts[3], "|")
+		}
+		store.keys[hash] = principal
+	}
+
+	return store, nil
+}
+
+func (s StaticKeyStore) FindByHash(hash string) (Principal, bool, error) {
+	principal, ok := s.keys[hash]
+	return principal, ok, nil
+}
+
+type DBKeyStore struct {
+	repo repository.IApiKeyRepository
+}
+
+func NewDBKeyStore(r repository.IApiKeyRepository) DBKeyStore {
+	return DBKeyStore{repo: r}
+}
+
+func (s DBKeyStore) FindByHash(hash string) (Principal, bool, error) {
+	key, err := s.repo.GetByHash(hash)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return Principal{}, false, nil
+	}
+	if err != nil {
+		return Principal{}, false, err
+	}
+
+	if key.RevokedAt != nil && key.RevokedAt.Before(time.Now()) {
+		return Principal{}, false, nil
+	}
+
+	return Principal{
+		Subject: key.Name,
+		Method:  MethodApiKey,
+		Scopes:  splitList(key.Scopes, " "),
+		Roles:   splitList(key.Roles, " "),
+	}, true, nil
+}
+
+func splitList(value, sep string) []string {
+	result := make([]string, 0)
+	for _, part := range strings.Split(value, sep) {
+		if part = strings.TrimSpace(part); part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"errors"
+	"orderService/configs"
+	"strings"
+)
+
+var ErrUnauthenticated = errors.New("missing or invalid credentials")
+
+type Authenticator struct {
+	enabled   bool
+	keyStores []KeyStore
+	jwt       *JWTVerifier
+}
+
+func NewAuthenticator(cnf configs.Auth, keyStores ...KeyStore) (Authenticator, error) {
+	authenticator := Authenticator{enabled: cnf.Enabled, keyStores: keyStores}
+
+	if cnf.JWTSecret != "" || cnf.JWKSFile != "" {
+		var keys map[string]any
+		if cnf.JWKSFile != "" {
+			var err error
+			if keys, err = LoadJWKS(cnf.JWKSFile); err != nil {
+				return Authenticator{}, err
+			}
+		}
+		verifier := NewJWTVerifier(cnf.JWTSecret, keys, cnf.JWTIssuer, cnf.JWTAudience)
+		authenticator.jwt = &verifier
+	}
+
+	return authenticator, nil
+}
+
+func (a Authenticator) Enabled() bool {
+	return a.enabled
+}
+
+// Authenticate resolves the principal from an "X-API-Key" header value or an "Authorization: Bearer" header value
+func (a Authenticator) Authenticate(apiKey, authorization string) (Principal, error) {
+	if !a.enabled {
+		return Anonymous, nil
+	}
+
+	if apiKey != "" {
+		return a.authenticateApiKey(apiKey)
+	}
+
+	token, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok || token == "" {
+		return Principal{}, ErrUnauthenticated
+	}
+
+	if a.jwt == nil {
+		return Principal{}, ErrUnauthenticated
+	}
+
+	principal, err := a.jwt.Verify(strings.TrimSpace(token))
+	if err != nil {
+		return Principal{}, errors.Join(ErrUnauthenticated, err)
+	}
+	return principal, nil
+}
+
+func (a Authenticator) authenticateApiKey(apiKey string) (Principal, error) {
+	hash := HashKey(apiKey)
+	for _, store := range a.keyStores {
+		principal, ok, err := store.FindByHash(hash)
+		if err != nil {
+			return Principal{}, err
+		}
+		if ok {
+			return principal, nil
+		}
+	}
+
+	return Principal{}, ErrUnauthenticated
+}
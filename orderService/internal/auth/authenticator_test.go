@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"math/big"
+	"orderService/configs"
+	"orderService/internal/models"
+	"orderService/internal/repository/mocks"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const secret = "test-secret"
+
+func signHMAC(t *testing.T, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	require.NoError(t, err)
+	return token
+}
+
+func TestAuthenticator_ApiKey(t *testing.T) {
+	staticKeys, err := NewStaticKeyStore([]string{fmt.Sprintf("reporting;%s;orders:read;support", HashKey("static-key"))})
+	require.NoError(t, err)
+
+	revokedAt := time.Now().Add(-time.Hour)
+	mockRepo := new(mocks.IApiKeyRepository)
+	mockRepo.On("GetByHash", HashKey("db-key")).Return(models.ApiKey{Name: "importer", Scopes: "orders:read orders:write", Roles: "admin"}, nil)
+	mockRepo.On("GetByHash", HashKey("revoked-key")).Return(models.ApiKey{Name: "old", Scopes: "admin", RevokedAt: &revokedAt}, nil)
+	mockRepo.On("GetByHash", HashKey("unknown-key")).Return(models.ApiKey{}, gorm.ErrRecordNotFound)
+
+	authenticator, err := NewAuthenticator(configs.Auth{Enabled: true}, staticKeys, NewDBKeyStore(mockRepo))
+	require.NoError(t, err)
+
+	t.Run("StaticKey", func(t *testing.T) {
+		principal, err := authenticator.Authenticate("static-key", "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, Principal{Subject: "reporting", Method: MethodApiKey, Scopes: []string{ScopeOrdersRead}, Roles: []string{"support"}}, principal)
+		mockRepo.AssertNotCalled(t, "GetByHash", HashKey("static-key"))
+	})
+
+	t.Run("DBKey", func(t *testing.T) {
+		principal, err := authenticator.Authenticate("db-key", "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "importer", principal.Subject)
+		assert.True(t, principal.HasScope(ScopeOrdersWrite))
+		assert.False(t, principal.HasScope(ScopeAdmin))
+		assert.Equal(t, []string{"admin"}, principal.Roles)
+	})
+
+	t.Run("RevokedKey", func(t *testing.T) {
+		_, err := authenticator.Authenticate("revoked-key", "")
+
+		assert.ErrorIs(t, err, ErrUnauthenticated)
+	})
+
+	t.Run("UnknownKey", func(t *testing.T) {
+		_, err := authenticator.Authenticate("unknown-key", "")
+
+		assert.ErrorIs(t, err, ErrUnauthenticated)
+	})
+
+	t.Run("NoCredentials", func(t *testing.T) {
+		_, err := authenticator.Authenticate("", "")
+
+		assert.ErrorIs(t, err, ErrUnauthenticated)
+	})
+}
+
+func TestNewStaticKeyStore(t *testing.T) {
+	t.Run("ScopesAndRoles", func(t *testing.T) {
+		store, err := NewStaticKeyStore([]string{fmt.Sprintf("reporting;%s;orders:read|orders:write;support|admin", HashKey("key"))})
+		require.NoError(t, err)
+
+		principal, ok, err := store.FindByHash(HashKey("key"))
+
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, []string{ScopeOrdersRead, ScopeOrdersWrite}, principal.Scopes)
+		assert.Equal(t, []string{"support", "admin"}, principal.Roles)
+	})
+
+	t.Run("InvalidHash", func(t *testing.T) {
+		_, err := NewStaticKeyStore([]string{"reporting;plain-text-key;admin"})
+
+		assert.EqualError(t, err, `api key "reporting" hash is not a hex encoded sha256`)
+	})
+
+	t.Run("InvalidFormat", func(t *testing.T) {
+		_, err := NewStaticKeyStore([]string{"reporting"})
+
+		assert.EqualError(t, err, `api key "reporting" must have name;sha256;scopes[;roles] format`)
+	})
+}
+
+func TestAuthenticator_JWT(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwks, err := json.Marshal(map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": "main",
+		"n":   base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+	}}})
+	require.NoError(t, err)
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(jwksFile, jwks, 0o600))
+
+	authenticator, err := NewAuthenticator(configs.Auth{Enabled: true, JWTSecret: secret, JWKSFile: jwksFile, JWTIssuer: "issuer"})
+	require.NoError(t, err)
+
+	t.Run("HMAC", func(t *testing.T) {
+		token := signHMAC(t, jwt.MapClaims{"sub": "frontend", "iss": "issuer", "exp": time.Now().Add(time.Hour).Unix(), "scope": "orders:read", "roles": []string{"support"}})
+
+		principal, err := authenticator.Authenticate("", "Bearer "+token)
+
+		assert.NoError(t, err)
+		assert.Equal(t, Principal{Subject: "frontend", Method: MethodJWT, Scopes: []string{ScopeOrdersRead}, Roles: []string{"support"}}, principal)
+	})
+
+	t.Run("RSAFromJWKS", func(t *testing.T) {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"sub": "billing", "iss": "issuer", "exp": time.Now().Add(time.Hour).Unix(), "scopes": []string{ScopeAdmin}})
+		token.Header["kid"] = "main"
+		signed, err := token.SignedString(rsaKey)
+		require.NoError(t, err)
+
+		principal, err := authenticator.Authenticate("", "Bearer "+signed)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "billing", principal.Subject)
+		assert.True(t, principal.HasScope(ScopeOrdersWrite))
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		token := signHMAC(t, jwt.MapClaims{"sub": "frontend", "iss": "issuer", "exp": time.Now().Add(-time.Hour).Unix()})
+
+		_, err := authenticator.Authenticate("", "Bearer "+token)
+
+		assert.ErrorIs(t, err, ErrUnauthenticated)
+	})
+
+	t.Run("WrongIssuer", func(t *testing.T) {
+		token := signHMAC(t, jwt.MapClaims{"sub": "frontend", "iss": "other", "exp": time.Now().Add(time.Hour).Unix()})
+
+		_, err := authenticator.Authenticate("", "Bearer "+token)
+
+		assert.ErrorIs(t, err, ErrUnauthenticated)
+	})
+
+	t.Run("NotBearer", func(t *testing.T) {
+		_, err := authenticator.Authenticate("", "Basic dXNlcjpwYXNz")
+
+		assert.ErrorIs(t, err, ErrUnauthenticated)
+	})
+}
+
+func TestAuthenticator_Disabled(t *testing.T) {
+	authenticator, err := NewAuthenticator(configs.Auth{Enabled: false})
+	require.NoError(t, err)
+
+	principal, err := authenticator.Authenticate("", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, Anonymous, principal)
+}
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"strings"
+)
+
+type claims struct {
+	jwt.RegisteredClaims
+	Scope  string   `json:"scope"`
+	Scopes []string `json:"scopes"`
+	Roles  []string `json:"roles"`
+}
+
+type JWTVerifier struct {
+	secret []byte
+	keys   map[string]any
+	parser *jwt.Parser
+}
+
+func NewJWTVerifier(secret string, keys map[string]any, issuer, audience string) JWTVerifier {
+	options := []jwt.ParserOption{jwt.WithExpirationRequired()}
+	if issuer != "" {
+		options = append(options, jwt.WithIssuer(issuer))
+	}
+	if audience != "" {
+		options = append(options, jwt.WithAudience(audience))
+	}
+
+	return JWTVerifier{
+		secret: []byte(secret),
+		keys:   keys,
+		parser: jwt.NewParser(options...),
+	}
+}
+
+func (v JWTVerifier) Verify(token string) (Principal, error) {
+	var c claims
+	if _, err := v.parser.ParseWithClaims(token, &c, v.keyFunc); err != nil {
+		return Principal{}, err
+	}
+
+	scopes := append(splitList(c.Scope, " "), c.Scopes...)
+	return Principal{
+		Subject: c.Subject,
+		Method:  MethodJWT,
+		Scopes:  scopes,
+		Roles:   c.Roles,
+	}, nil
+}
+
+func (v JWTVerifier) keyFunc(token *jwt.Token) (any, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if len(v.secret) == 0 {
+			return nil, errors.New("hmac signed tokens are not accepted")
+		}
+		return v.secret, nil
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS, *jwt.SigningMethodECDSA:
+		kid, _ := token.Header["kid"].(string)
+		key, ok := v.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key id %q", kid)
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unexpected signing method %s", strings.ToUpper(token.Method.Alg()))
+	}
+}
@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"slices"
+)
+
+const (
+	ScopeOrdersRead  = "orders:read"
+	ScopeOrdersWrite = "orders:write"
+	ScopeAdmin       = "admin"
+)
+
+const (
+	MethodAnonymous = "anonymous"
+	MethodApiKey    = "api_key"
+	MethodJWT       = "jwt"
+)
+
+// Principal is the authenticated caller of the API
+type Principal struct {
+	Subject string
+	Method  string
+	Scopes  []string
+	Roles   []string
+}
+
+// Anonymous is used for every request when authentication is disabled
+var Anonymous = Principal{
+	Subject: "anonymous",
+	Method:  MethodAnonymous,
+	Scopes:  []string{ScopeAdmin},
+}
+
+// HasScope reports whether the principal was granted the scope. The admin scope grants every scope
+func (p Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope) || slices.Contains(p.Scopes, ScopeAdmin)
+}
+
+// Actor identifies the principal in the audit log
+func (p Principal) Actor() string {
+	if p.Method == MethodAnonymous {
+		return p.Subject
+	}
+	return p.Method + ":" + p.Subject
+}
@@ -23,7 +23,7 @@ func (l *LCacheLoader) LoadCache(c OrderLRuCache, limit int) error {
 	}
 
 	for _, order := range recentOrders {
-		c.LruCache.Add(order.Uid.String(), order.ToOrderView())
+		c.Add(order.Uid.String(), order.ToOrderView())
 	}
 
 	return nil
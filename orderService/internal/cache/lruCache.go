@@ -10,6 +10,7 @@ import (
 type ILruCache interface {
 	Get(key string) (models.OrderView, bool)
 	Add(key string, value models.OrderView) bool
+	Remove(key string) bool
 }
 
 type OrderLRuCache struct {
@@ -25,6 +26,11 @@ func (o OrderLRuCache) Get(key string) (models.OrderView, bool) {
 	return o.LruCache.Get(key)
 }
 
+// Add stores the view with its ETag so cache hits do not hash the view again
 func (o OrderLRuCache) Add(key string, value models.OrderView) bool {
-	return o.LruCache.Add(key, value)
+	return o.LruCache.Add(key, value.WithETag())
+}
+
+func (o OrderLRuCache) Remove(key string) bool {
+	return o.LruCache.Remove(key)
 }
@@ -124,6 +124,52 @@ func (_c *ILruCache_Get_Call) RunAndReturn(run func(string) (models.OrderView, b
 	return _c
 }
 
+// Remove provides a mock function with given fields: key
+func (_m *ILruCache) Remove(key string) bool {
+	ret := _m.Called(key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Remove")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(key)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// ILruCache_Remove_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Remove'
+type ILruCache_Remove_Call struct {
+	*mock.Call
+}
+
+// Remove is a helper method to define mock.On call
+//   - key string
+func (_e *ILruCache_Expecter) Remove(key interface{}) *ILruCache_Remove_Call {
+	return &ILruCache_Remove_Call{Call: _e.mock.On("Remove", key)}
+}
+
+func (_c *ILruCache_Remove_Call) Run(run func(key string)) *ILruCache_Remove_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *ILruCache_Remove_Call) Return(_a0 bool) *ILruCache_Remove_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *ILruCache_Remove_Call) RunAndReturn(run func(string) bool) *ILruCache_Remove_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewILruCache creates a new instance of ILruCache. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewILruCache(t interface {
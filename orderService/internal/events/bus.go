@@ -0,0 +1,173 @@
+package events
+
+import (
+	"github.com/google/uuid"
+	"orderService/internal/models"
+	"slices"
+	"sync"
+	"time"
+)
+
+type EventType string
+
+const (
+	OrderCreated       EventType = "order.created"
+	OrderStatusChanged EventType = "order.status_changed"
+	OrderAmended       EventType = "order.amended"
+)
+
+type Event struct {
+	ID    uint64
+	Type  EventType
+	Order models.OrderView
+	Time  time.Time
+}
+
+type Publisher interface {
+	Publish(eventType EventType, order models.OrderView)
+}
+
+// History drops the retained events of orders that were deleted or whose personal data was erased
+//
+//go:generate mockery --name=History --output=mocks --outpkg=mocks --case=snake --with-expecter
+type History interface {
+	Forget(uids []uuid.UUID)
+}
+
+// Filter selects events by order fields, empty fields match any value
+type Filter struct {
+	CustomerID      string
+	DeliveryService string
+}
+
+func (f Filter) Matches(order models.OrderView) bool {
+	return (f.CustomerID == "" || f.CustomerID == order.CustomerID) &&
+		(f.DeliveryService == "" || f.DeliveryService == order.DeliveryService)
+}
+
+// Bus delivers order events to in-process subscribers and keeps the last events so subscribers can resume.
+// Publishing never blocks: a subscriber whose buffer is full is closed and has to resubscribe from its last event
+type Bus struct {
+	mu     sync.Mutex
+	lastID uint64
+	// history is a ring of the last events, oldest is the index of the oldest one once the ring is full
+	history     []Event
+	oldest      int
+	historySize int
+	subscribers map[*Subscription]struct{}
+}
+
+func NewBus(historySize int) *Bus {
+	return &Bus{
+		history:     make([]Event, 0, historySize),
+		historySize: historySize,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+func (b *Bus) Publish(eventType EventType, order models.OrderView) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	event := Event{ID: b.lastID, Type: eventType, Order: order, Time: time.Now()}
+
+	if len(b.history) < b.historySize {
+		b.history = append(b.history, event)
+	} else if b.historySize > 0 {
+		b.history[b.oldest] = event
+		b.oldest = (b.oldest + 1) % b.historySize
+	}
+
+	for sub := range b.subscribers {
+		if !sub.filter.Matches(order) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			b.remove(sub)
+		}
+	}
+}
+
+func (b *Bus) Subscribe(buffer int, filter Filter) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.subscribe(buffer, filter)
+}
+
+// SubscribeSince subscribes and returns the retained events published after lastID that match the filter.
+// Events older than the history are lost
+func (b *Bus) SubscribeSince(lastID uint64, buffer int, filter Filter) (*Subscription, []Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	missed := make([]Event, 0)
+	for _, event := range b.retained() {
+		if event.ID > lastID && filter.Matches(event.Order) {
+			missed = append(missed, event)
+		}
+	}
+
+	return b.subscribe(buffer, filter), missed
+}
+
+// Forget drops the retained events of the orders, so resuming subscribers do not receive erased personal data
+func (b *Bus) Forget(uids []uuid.UUID) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	kept := make([]Event, 0, b.historySize)
+	for _, event := range b.retained() {
+		if !slices.Contains(uids, event.Order.Uid) {
+			kept = append(kept, event)
+		}
+	}
+	b.history = kept
+	b.oldest = 0
+}
+
+// retained returns the history oldest first
+func (b *Bus) retained() []Event {
+	return append(b.history[b.oldest:len(b.history):len(b.history)], b.history[:b.oldest]...)
+}
+
+// Subscribers returns the number of active subscriptions
+func (b *Bus) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.subscribers)
+}
+
+func (b *Bus) subscribe(buffer int, filter Filter) *Subscription {
+	sub := &Subscription{bus: b, events: make(chan Event, buffer), filter: filter}
+	b.subscribers[sub] = struct{}{}
+	return sub
+}
+
+func (b *Bus) remove(sub *Subscription) {
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+type Subscription struct {
+	bus    *Bus
+	events chan Event
+	filter Filter
+}
+
+// Events is closed when the subscription is closed or the subscriber is too slow
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	s.bus.remove(s)
+}
@@ -0,0 +1,112 @@
+package events
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"orderService/internal/models"
+	"testing"
+)
+
+func order(customerID, deliveryService string) models.OrderView {
+	return models.OrderView{Uid: uuid.New(), CustomerID: customerID, DeliveryService: deliveryService}
+}
+
+func TestBus_Subscribe(t *testing.T) {
+	bus := NewBus(10)
+	all := bus.Subscribe(10, Filter{})
+	customer := bus.Subscribe(10, Filter{CustomerID: "100900"})
+	meest := bus.Subscribe(10, Filter{CustomerID: "100900", DeliveryService: "meest"})
+
+	first := order("100900", "meest")
+	second := order("100900", "dhl")
+	third := order("other", "meest")
+	bus.Publish(OrderCreated, first)
+	bus.Publish(OrderCreated, second)
+	bus.Publish(OrderStatusChanged, third)
+
+	assert.Len(t, all.Events(), 3)
+	assert.Len(t, customer.Events(), 2)
+	assert.Len(t, meest.Events(), 1)
+
+	event := <-meest.Events()
+	assert.Equal(t, Event{ID: 1, Type: OrderCreated, Order: first, Time: event.Time}, event)
+}
+
+func TestBus_SlowSubscriberIsClosed(t *testing.T) {
+	bus := NewBus(0)
+	slow := bus.Subscribe(1, Filter{})
+	fast := bus.Subscribe(2, Filter{})
+
+	bus.Publish(OrderCreated, order("100900", "meest"))
+	bus.Publish(OrderCreated, order("100900", "meest"))
+
+	<-slow.Events()
+	_, ok := <-slow.Events()
+	assert.False(t, ok)
+	assert.Len(t, fast.Events(), 2)
+
+	slow.Close()
+	fast.Close()
+	received := 0
+	for range fast.Events() {
+		received++
+	}
+	assert.Equal(t, 2, received)
+}
+
+func TestBus_SubscribeSince(t *testing.T) {
+	bus := NewBus(3)
+	for i := 0; i < 5; i++ {
+		bus.Publish(OrderCreated, order("100900", "meest"))
+	}
+	bus.Publish(OrderCreated, order("other", "meest"))
+
+	t.Run("ResumeAfterLastEvent", func(t *testing.T) {
+		_, missed := bus.SubscribeSince(4, 10, Filter{CustomerID: "100900"})
+
+		assert.Len(t, missed, 1)
+		assert.Equal(t, uint64(5), missed[0].ID)
+	})
+
+	t.Run("OnlyRetainedEventsAreReplayed", func(t *testing.T) {
+		_, missed := bus.SubscribeSince(0, 10, Filter{})
+
+		assert.Equal(t, []uint64{4, 5, 6}, ids(missed))
+	})
+
+	t.Run("LiveEventsAfterReplay", func(t *testing.T) {
+		sub, missed := bus.SubscribeSince(6, 10, Filter{})
+		bus.Publish(OrderCreated, order("100900", "meest"))
+
+		assert.Empty(t, missed)
+		assert.Equal(t, uint64(7), (<-sub.Events()).ID)
+	})
+}
+
+func ids(events []Event) []uint64 {
+	ids := make([]uint64, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+func TestBus_Forget(t *testing.T) {
+	bus := NewBus(3)
+	erased := order("100900", "meest")
+	bus.Publish(OrderCreated, order("other", "meest"))
+	bus.Publish(OrderCreated, erased)
+	bus.Publish(OrderCreated, order("other", "meest"))
+	bus.Publish(OrderAmended, erased)
+
+	bus.Forget([]uuid.UUID{erased.Uid})
+
+	_, missed := bus.SubscribeSince(0, 10, Filter{})
+	assert.Equal(t, []uint64{3}, ids(missed))
+
+	bus.Publish(OrderCreated, order("other", "meest"))
+	bus.Publish(OrderCreated, order("other", "meest"))
+	bus.Publish(OrderCreated, order("other", "meest"))
+	_, missed = bus.SubscribeSince(0, 10, Filter{})
+	assert.Equal(t, []uint64{5, 6, 7}, ids(missed))
+}
@@ -0,0 +1,68 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	uuid "github.com/google/uuid"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// History is an autogenerated mock type for the History type
+type History struct {
+	mock.Mock
+}
+
+type History_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *History) EXPECT() *History_Expecter {
+	return &History_Expecter{mock: &_m.Mock}
+}
+
+// Forget provides a mock function with given fields: uids
+func (_m *History) Forget(uids []uuid.UUID) {
+	_m.Called(uids)
+}
+
+// History_Forget_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Forget'
+type History_Forget_Call struct {
+	*mock.Call
+}
+
+// Forget is a helper method to define mock.On call
+//   - uids []uuid.UUID
+func (_e *History_Expecter) Forget(uids interface{}) *History_Forget_Call {
+	return &History_Forget_Call{Call: _e.mock.On("Forget", uids)}
+}
+
+func (_c *History_Forget_Call) Run(run func(uids []uuid.UUID)) *History_Forget_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].([]uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *History_Forget_Call) Return() *History_Forget_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *History_Forget_Call) RunAndReturn(run func([]uuid.UUID)) *History_Forget_Call {
+	_c.Run(run)
+	return _c
+}
+
+// NewHistory creates a new instance of History. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewHistory(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *History {
+	mock := &History{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,251 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"github.com/parquet-go/parquet-go"
+	"io"
+	"orderService/internal/importer"
+	"orderService/internal/models"
+	"strconv"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatNDJSON  Format = "ndjson"
+	FormatParquet Format = "parquet"
+)
+
+func ParseFormat(value string) (Format, error) {
+	switch format := Format(value); format {
+	case FormatCSV, FormatNDJSON, FormatParquet:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown format %q, use csv, ndjson or parquet", value)
+	}
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	default:
+		return "application/vnd.apache.parquet"
+	}
+}
+
+// Writer encodes orders as they arrive, Close writes what is buffered and the format trailer
+type Writer interface {
+	Write(orders []models.Order) error
+	Close() error
+}
+
+func NewWriter(format Format, w io.Writer) Writer {
+	switch format {
+	case FormatNDJSON:
+		return &ndjsonWriter{w: bufio.NewWriter(w)}
+	case FormatParquet:
+		return &parquetWriter{w: parquet.NewGenericWriter[Row](w)}
+	default:
+		return &csvWriter{w: csv.NewWriter(w)}
+	}
+}
+
+// Row is an order flattened with one of its items, the columns match the import CSV format
+type Row struct {
+	OrderUid            string    `parquet:"order_uid"`
+	TrackNumber         string    `parquet:"track_number"`
+	Entry               string    `parquet:"entry"`
+	Locale              string    `parquet:"locale"`
+	InternalSignature   string    `parquet:"internal_signature"`
+	CustomerID          string    `parquet:"customer_id"`
+	DeliveryService     string    `parquet:"delivery_service"`
+	ShardKey            string    `parquet:"shardkey"`
+	SmID                int64     `parquet:"sm_id"`
+	DateCreated         time.Time `parquet:"date_created,timestamp(millisecond)"`
+	OofShard            string    `parquet:"oof_shard"`
+	DeliveryName        string    `parquet:"delivery_name"`
+	DeliveryPhone       string    `parquet:"delivery_phone"`
+	DeliveryZip         string    `parquet:"delivery_zip"`
+	DeliveryCity        string    `parquet:"delivery_city"`
+	DeliveryAddress     string    `parquet:"delivery_address"`
+	DeliveryRegion      string    `parquet:"delivery_region"`
+	DeliveryEmail       string    `parquet:"delivery_email"`
+	PaymentTransaction  string    `parquet:"payment_transaction"`
+	PaymentRequestID    string    `parquet:"payment_request_id"`
+	PaymentCurrency     string    `parquet:"payment_currency"`
+	PaymentProvider     string    `parquet:"payment_provider"`
+	PaymentAmount       int64     `parquet:"payment_amount"`
+	PaymentDt           int64     `parquet:"payment_dt"`
+	PaymentBank         string    `parquet:"payment_bank"`
+	PaymentDeliveryCost int64     `parquet:"payment_delivery_cost"`
+	PaymentGoodsTotal   int64     `parquet:"payment_goods_total"`
+	PaymentCustomFee    int64     `parquet:"payment_custom_fee"`
+	ItemChrtID          *int64    `parquet:"item_chrt_id,optional"`
+	ItemTrackNumber     string    `parquet:"item_track_number"`
+	ItemPrice           int64     `parquet:"item_price"`
+	ItemRID             string    `parquet:"item_rid"`
+	ItemName            string    `parquet:"item_name"`
+	ItemSale            int64     `parquet:"item_sale"`
+	ItemSize            string    `parquet:"item_size"`
+	ItemTotalPrice      int64     `parquet:"item_total_price"`
+	ItemNmID            int64     `parquet:"item_nm_id"`
+	ItemBrand           string    `parquet:"item_brand"`
+	ItemStatus          int64     `parquet:"item_status"`
+}
+
+// Flatten returns a row per item, an order without items still produces one row with empty item columns
+func Flatten(order models.Order) []Row {
+	base := Row{
+		OrderUid:            order.Uid.String(),
+		TrackNumber:         order.TrackNumber,
+		Entry:               order.Entry,
+		Locale:              order.Locale,
+		InternalSignature:   order.InternalSignature,
+		CustomerID:          order.CustomerID,
+		DeliveryService:     order.DeliveryService,
+		ShardKey:            order.ShardKey,
+		SmID:                int64(order.SmID),
+		DateCreated:         order.DateCreated,
+		OofShard:            order.OofShard,
+		DeliveryName:        order.Delivery.Name,
+		DeliveryPhone:       order.Delivery.Phone,
+		DeliveryZip:         order.Delivery.Zip,
+		DeliveryCity:        order.Delivery.City,
+		DeliveryAddress:     order.Delivery.Address,
+		DeliveryRegion:      order.Delivery.Region,
+		DeliveryEmail:       order.Delivery.Email,
+		PaymentTransaction:  order.Payment.Transaction,
+		PaymentRequestID:    order.Payment.RequestID,
+		PaymentCurrency:     order.Payment.Currency,
+		PaymentProvider:     order.Payment.Provider,
+		PaymentAmount:       int64(order.Payment.Amount),
+		PaymentDt:           int64(order.Payment.PaymentDt),
+		PaymentBank:         order.Payment.Bank,
+		PaymentDeliveryCost: int64(order.Payment.DeliveryCost),
+		PaymentGoodsTotal:   int64(order.Payment.GoodsTotal),
+		PaymentCustomFee:    int64(order.Payment.CustomFee),
+	}
+	if len(order.Items) == 0 {
+		return []Row{base}
+	}
+
+	rows := make([]Row, 0, len(order.Items))
+	for _, item := range order.Items {
+		row := base
+		chrtID := int64(item.ChrtID)
+		row.ItemChrtID = &chrtID
+		row.ItemTrackNumber = item.TrackNumber
+		row.ItemPrice = int64(item.Price)
+		row.ItemRID = item.RID
+		row.ItemName = item.Name
+		row.ItemSale = int64(item.Sale)
+		row.ItemSize = item.Size
+		row.ItemTotalPrice = int64(item.TotalPrice)
+		row.ItemNmID = int64(item.NmID)
+		row.ItemBrand = item.Brand
+		row.ItemStatus = int64(item.Status)
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// values returns the row in the importer.CSVColumns order, so exported files can be imported back
+func (r Row) values() []string {
+	number := func(value int64) string { return strconv.FormatInt(value, 10) }
+
+	values := []string{
+		r.OrderUid, r.TrackNumber, r.Entry, r.Locale, r.InternalSignature, r.CustomerID, r.DeliveryService,
+		r.ShardKey, number(r.SmID), r.DateCreated.UTC().Format(time.RFC3339), r.OofShard,
+		r.DeliveryName, r.DeliveryPhone, r.DeliveryZip, r.DeliveryCity, r.DeliveryAddress, r.DeliveryRegion, r.DeliveryEmail,
+		r.PaymentTransaction, r.PaymentRequestID, r.PaymentCurrency, r.PaymentProvider, number(r.PaymentAmount),
+		number(r.PaymentDt), r.PaymentBank, number(r.PaymentDeliveryCost), number(r.PaymentGoodsTotal), number(r.PaymentCustomFee),
+	}
+	if r.ItemChrtID == nil {
+		return append(values, make([]string, len(importer.CSVColumns)-len(values))...)
+	}
+	return append(values,
+		number(*r.ItemChrtID), r.ItemTrackNumber, number(r.ItemPrice), r.ItemRID, r.ItemName, number(r.ItemSale), r.ItemSize,
+		number(r.ItemTotalPrice), number(r.ItemNmID), r.ItemBrand, number(r.ItemStatus),
+	)
+}
+
+type csvWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (c *csvWriter) Write(orders []models.Order) error {
+	if !c.headerWritten {
+		if err := c.w.Write(importer.CSVColumns); err != nil {
+			return err
+		}
+		c.headerWritten = true
+	}
+
+	for _, order := range orders {
+		for _, row := range Flatten(order) {
+			if err := c.w.Write(row.values()); err != nil {
+				return err
+			}
+		}
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	if !c.headerWritten {
+		return c.Write(nil)
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// ndjsonWriter writes orders in the Kafka message format, one per line
+type ndjsonWriter struct {
+	w *bufio.Writer
+}
+
+func (n *ndjsonWriter) Write(orders []models.Order) error {
+	for _, order := range orders {
+		data, err := order.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		if _, err = n.w.Write(data); err != nil {
+			return err
+		}
+		if err = n.w.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+	return n.w.Flush()
+}
+
+func (n *ndjsonWriter) Close() error {
+	return n.w.Flush()
+}
+
+type parquetWriter struct {
+	w *parquet.GenericWriter[Row]
+}
+
+func (p *parquetWriter) Write(orders []models.Order) error {
+	for _, order := range orders {
+		if _, err := p.w.Write(Flatten(order)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *parquetWriter) Close() error {
+	return p.w.Close()
+}
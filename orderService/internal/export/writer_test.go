@@ -0,0 +1,119 @@
+package export
+
+import (
+	"bytes"
+	"github.com/google/uuid"
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"orderService/internal/importer"
+	"orderService/internal/models"
+	"testing"
+	"time"
+)
+
+var order = models.Order{
+	Uid:             uuid.MustParse("1e9ad4fb-2615-46f9-9458-20b59253086b"),
+	TrackNumber:     "WBILMTESTTRACK",
+	Entry:           "WBIL",
+	Locale:          "en",
+	CustomerID:      "test",
+	DeliveryService: "meest",
+	ShardKey:        "9",
+	SmID:            99,
+	DateCreated:     time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+	OofShard:        "1",
+	Delivery: models.Delivery{
+		Name:    "Test Testov",
+		Phone:   "+9720000000",
+		Zip:     "2639809",
+		City:    "Kiryat Mozkin",
+		Address: "Ploshad Mira 15",
+		Region:  "Kraiot",
+		Email:   "test@gmail.com",
+	},
+	Payment: models.Payment{
+		Transaction:  "b563feb7b2b84b6test",
+		Currency:     "USD",
+		Provider:     "wbpay",
+		Amount:       1817,
+		PaymentDt:    1637907727,
+		Bank:         "alpha",
+		DeliveryCost: 1500,
+		GoodsTotal:   317,
+	},
+	Items: []models.Item{
+		{ChrtID: 9934930, TrackNumber: "WBILMTESTTRACK", Price: 453, RID: "ab4219087a764ae0btest", Name: "Mascaras", Sale: 30, Size: "0", TotalPrice: 317, NmID: 2389212, Brand: "Vivienne Sabo", Status: 202},
+		{ChrtID: 9934931, TrackNumber: "WBILMTESTTRACK", Price: 100, RID: "ab4219087a764ae0btest", Name: "Lipstick", Size: "0", TotalPrice: 100, NmID: 2389213, Brand: "Vivienne Sabo", Status: 202},
+	},
+}
+
+func write(t *testing.T, format Format, orders ...models.Order) *bytes.Buffer {
+	buffer := &bytes.Buffer{}
+	writer := NewWriter(format, buffer)
+	require.NoError(t, writer.Write(orders))
+	require.NoError(t, writer.Close())
+	return buffer
+}
+
+func TestCSVWriter_RoundTripsThroughImporter(t *testing.T) {
+	withoutItems := order
+	withoutItems.Uid = uuid.MustParse("2e9ad4fb-2615-46f9-9458-20b59253086b")
+	withoutItems.Items = nil
+
+	reader, err := importer.NewCSVReader(write(t, FormatCSV, order, withoutItems))
+	require.NoError(t, err)
+
+	record, err := reader.Next()
+	require.NoError(t, err)
+	require.NoError(t, record.Err)
+	assert.Equal(t, order, record.Order)
+
+	record, err = reader.Next()
+	require.NoError(t, err)
+	require.NoError(t, record.Err)
+	assert.Equal(t, withoutItems, record.Order)
+
+	_, err = reader.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestCSVWriter_HeaderWithoutOrders(t *testing.T) {
+	buffer := write(t, FormatCSV)
+
+	_, err := importer.NewCSVReader(buffer)
+	assert.NoError(t, err)
+}
+
+func TestNDJSONWriter_KafkaMessageFormat(t *testing.T) {
+	reader := importer.NewNDJSONReader(write(t, FormatNDJSON, order))
+
+	record, err := reader.Next()
+	require.NoError(t, err)
+	require.NoError(t, record.Err)
+	assert.Equal(t, order, record.Order)
+	assert.NotContains(t, write(t, FormatNDJSON, order).String(), `"ID"`)
+}
+
+func TestParquetWriter(t *testing.T) {
+	buffer := write(t, FormatParquet, order)
+
+	rows, err := parquet.Read[Row](bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	require.NoError(t, err)
+
+	require.Len(t, rows, 2)
+	assert.Equal(t, order.Uid.String(), rows[0].OrderUid)
+	assert.Equal(t, order.DateCreated, rows[0].DateCreated.UTC())
+	assert.Equal(t, int64(9934931), *rows[1].ItemChrtID)
+	assert.Equal(t, "Lipstick", rows[1].ItemName)
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("parquet")
+	assert.NoError(t, err)
+	assert.Equal(t, FormatParquet, format)
+
+	_, err = ParseFormat("xml")
+	assert.EqualError(t, err, `unknown format "xml", use csv, ndjson or parquet`)
+}
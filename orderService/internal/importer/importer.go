@@ -0,0 +1,325 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	"log"
+	"orderService/internal/models"
+	"orderService/internal/repository"
+	"os"
+	"strings"
+)
+
+const (
+	ProblemInvalid   = "invalid"
+	ProblemDuplicate = "duplicate"
+)
+
+type Options struct {
+	BatchSize int
+	// DryRun validates records and looks up duplicates without inserting or moving the checkpoint
+	DryRun bool
+	// CheckpointFile keeps the line of the last handled record, records up to it are skipped on restart
+	CheckpointFile string
+}
+
+// Checkpoint is the progress of an import: the last handled line and the size of the error report at that point.
+// A resumed run truncates the report to the size, problems of lines it reads again are not reported twice
+type Checkpoint struct {
+	Line       int
+	ReportSize int64
+}
+
+type Summary struct {
+	Inserted  int
+	Duplicate int
+	Invalid   int
+	// Skipped records were imported by a previous run according to the checkpoint
+	Skipped int
+}
+
+func (s Summary) String() string {
+	return fmt.Sprintf("inserted: %d, duplicate: %d, invalid: %d, skipped: %d", s.Inserted, s.Duplicate, s.Invalid, s.Skipped)
+}
+
+// Problem is a line of the error report
+type Problem struct {
+	Line     int       `json:"line"`
+	OrderUid uuid.UUID `json:"order_uid"`
+	Kind     string    `json:"kind"`
+	Error    string    `json:"error"`
+}
+
+type Importer struct {
+	repo    repository.IOrderRepository
+	options Options
+	report  *json.Encoder
+	written *countingWriter
+}
+
+// countingWriter tracks the size of the error report for the checkpoint
+type countingWriter struct {
+	w    io.Writer
+	size int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// progress is the state of one Run
+type progress struct {
+	summary Summary
+	// line is the last handled line, saved is the line of the checkpoint file
+	line  int
+	saved int
+	// planned keeps the uids a dry run would insert, they are not in the database for the next batches to find
+	planned map[uuid.UUID]bool
+}
+
+// NewImporter writes problems with records to report as JSON lines. On resume the report has to be
+// opened with OpenReport so it ends where the checkpoint does
+func NewImporter(repo repository.IOrderRepository, options Options, report io.Writer) Importer {
+	if options.BatchSize <= 0 {
+		options.BatchSize = 1
+	}
+	written := &countingWriter{w: report}
+	return Importer{
+		repo:    repo,
+		options: options,
+		report:  json.NewEncoder(written),
+		written: written,
+	}
+}
+
+// Run imports the records until the reader is exhausted or the context is cancelled.
+// A cancelled import stops after the current batch and can be resumed from the checkpoint
+func (i Importer) Run(ctx context.Context, reader Reader) (Summary, error) {
+	checkpoint, err := LoadCheckpoint(i.options.CheckpointFile)
+	if err != nil {
+		return Summary{}, err
+	}
+	if checkpoint.Line > 0 {
+		log.Printf("Resuming import after line %d\n", checkpoint.Line)
+	}
+	i.written.size = checkpoint.ReportSize
+
+	p := &progress{line: checkpoint.Line, saved: checkpoint.Line}
+	if i.options.DryRun {
+		p.planned = make(map[uuid.UUID]bool)
+	}
+
+	batch := make([]Record, 0, i.options.BatchSize)
+	for {
+		if ctx.Err() != nil {
+			break
+		}
+
+		record, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return p.summary, err
+		}
+
+		if record.Line <= checkpoint.Line {
+			p.summary.Skipped++
+			continue
+		}
+		p.line = record.Line
+
+		if record.Err == nil {
+			record.Err = record.Order.Validate()
+		}
+		if record.Err != nil {
+			p.summary.Invalid++
+			if err = i.reportProblem(record, ProblemInvalid, record.Err); err != nil {
+				return p.summary, err
+			}
+			continue
+		}
+
+		batch = append(batch, record)
+		if len(batch) == i.options.BatchSize {
+			if err = i.flush(batch, p); err != nil {
+				return p.summary, err
+			}
+			batch = batch[:0]
+		}
+	}
+
+	// the last flush also moves the checkpoint past invalid records after the last batch
+	if err = i.flush(batch, p); err != nil {
+		return p.summary, err
+	}
+	return p.summary, ctx.Err()
+}
+
+// flush inserts the batch records that are not stored yet and moves the checkpoint to the last handled line
+func (i Importer) flush(batch []Record, p *progress) error {
+	if len(batch) > 0 {
+		if err := i.store(batch, p); err != nil {
+			return err
+		}
+	}
+
+	if i.options.DryRun || p.line == p.saved {
+		return nil
+	}
+	if err := SaveCheckpoint(i.options.CheckpointFile, Checkpoint{Line: p.line, ReportSize: i.written.size}); err != nil {
+		return err
+	}
+	p.saved = p.line
+	return nil
+}
+
+func (i Importer) store(batch []Record, p *progress) error {
+	uids := make([]uuid.UUID, 0, len(batch))
+	for _, record := range batch {
+		uids = append(uids, record.Order.Uid)
+	}
+	existing, err := i.repo.GetExistingUids(uids)
+	if err != nil {
+		return err
+	}
+
+	stored := make(map[uuid.UUID]bool, len(batch))
+	for _, uid := range existing {
+		stored[uid] = true
+	}
+
+	orders := make([]models.Order, 0, len(batch))
+	for _, record := range batch {
+		if stored[record.Order.Uid] || p.planned[record.Order.Uid] {
+			p.summary.Duplicate++
+			if err = i.reportProblem(record, ProblemDuplicate, fmt.Errorf("order %s already exists", record.Order.Uid)); err != nil {
+				return err
+			}
+			continue
+		}
+		// The same uid twice in one file is a duplicate as well
+		stored[record.Order.Uid] = true
+		orders = append(orders, record.Order)
+	}
+
+	if i.options.DryRun {
+		for _, order := range orders {
+			p.planned[order.Uid] = true
+		}
+		p.summary.Inserted += len(orders)
+		return nil
+	}
+
+	inserted, err := i.insert(orders, batch, &p.summary)
+	if err != nil {
+		return err
+	}
+	p.summary.Inserted += inserted
+	return nil
+}
+
+// insert falls back to one order at a time when an order was inserted concurrently after the duplicate lookup
+func (i Importer) insert(orders []models.Order, batch []Record, summary *Summary) (int, error) {
+	err := i.repo.CreateBatch(orders)
+	if err == nil {
+		return len(orders), nil
+	}
+	if !repository.IsDuplicateKey(err) {
+		return 0, err
+	}
+
+	lines := make(map[uuid.UUID]Record, len(batch))
+	for _, record := range batch {
+		lines[record.Order.Uid] = record
+	}
+
+	inserted := 0
+	for _, order := range orders {
+		err = i.repo.Create(order)
+		if repository.IsDuplicateKey(err) {
+			summary.Duplicate++
+			if err = i.reportProblem(lines[order.Uid], ProblemDuplicate, err); err != nil {
+				return inserted, err
+			}
+			continue
+		}
+		if err != nil {
+			return inserted, err
+		}
+		inserted++
+	}
+	return inserted, nil
+}
+
+func (i Importer) reportProblem(record Record, kind string, problem error) error {
+	return i.report.Encode(Problem{
+		Line:     record.Line,
+		OrderUid: record.Order.Uid,
+		Kind:     kind,
+		Error:    problem.Error(),
+	})
+}
+
+// LoadCheckpoint returns the zero checkpoint when there is no checkpoint file yet
+func LoadCheckpoint(path string) (Checkpoint, error) {
+	if path == "" {
+		return Checkpoint{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Checkpoint{}, nil
+	}
+	if err != nil {
+		return Checkpoint{}, err
+	}
+
+	var checkpoint Checkpoint
+	if _, err = fmt.Sscanf(strings.TrimSpace(string(data)), "%d %d", &checkpoint.Line, &checkpoint.ReportSize); err != nil {
+		return Checkpoint{}, fmt.Errorf("checkpoint file %s is corrupted: %w", path, err)
+	}
+	return checkpoint, nil
+}
+
+// SaveCheckpoint replaces the checkpoint file atomically so an interrupted write does not lose progress
+func SaveCheckpoint(path string, checkpoint Checkpoint) error {
+	if path == "" {
+		return nil
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(fmt.Sprintf("%d %d", checkpoint.Line, checkpoint.ReportSize)), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// OpenReport opens the error report of a run resumed from the checkpoint. Problems reported after the
+// checkpoint by an interrupted run are dropped, their lines are read and reported again
+func OpenReport(path string, checkpoint Checkpoint) (*os.File, error) {
+	report, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := report.Stat()
+	if err == nil {
+		// a report that is shorter than recorded was edited, it is kept instead of padded
+		err = report.Truncate(min(info.Size(), checkpoint.ReportSize))
+	}
+	if err == nil {
+		_, err = report.Seek(0, io.SeekEnd)
+	}
+	if err != nil {
+		_ = report.Close()
+		return nil, err
+	}
+	return report, nil
+}
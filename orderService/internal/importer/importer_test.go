@@ -0,0 +1,221 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"io"
+	"orderService/internal/models"
+	repo "orderService/internal/repository/mocks"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// sliceReader returns prepared records
+type sliceReader struct {
+	records []Record
+}
+
+func (r *sliceReader) Next() (Record, error) {
+	if len(r.records) == 0 {
+		return Record{}, io.EOF
+	}
+	record := r.records[0]
+	r.records = r.records[1:]
+	return record, nil
+}
+
+func validOrder(t *testing.T, uid string) models.Order {
+	var order models.Order
+	data := strings.Replace(ndjsonOrder, "1e9ad4fb-2615-46f9-9458-20b59253086b", uid, 1)
+	require.NoError(t, order.UnmarshalJSON([]byte(data)))
+	return order
+}
+
+func readProblems(t *testing.T, report *bytes.Buffer) []Problem {
+	problems := make([]Problem, 0)
+	decoder := json.NewDecoder(report)
+	for decoder.More() {
+		var problem Problem
+		require.NoError(t, decoder.Decode(&problem))
+		problems = append(problems, problem)
+	}
+	return problems
+}
+
+func TestImporter_Run(t *testing.T) {
+	first := validOrder(t, "1e9ad4fb-2615-46f9-9458-20b59253086b")
+	second := validOrder(t, "2e9ad4fb-2615-46f9-9458-20b59253086b")
+	third := validOrder(t, "3e9ad4fb-2615-46f9-9458-20b59253086b")
+	invalid := validOrder(t, "4e9ad4fb-2615-46f9-9458-20b59253086b")
+	invalid.CustomerID = ""
+
+	records := func() Reader {
+		return &sliceReader{records: []Record{
+			{Line: 1, Order: first},
+			{Line: 2, Order: invalid},
+			{Line: 3, Order: second},
+			{Line: 4, Order: third},
+		}}
+	}
+
+	t.Run("InsertsInBatchesAndSavesCheckpoint", func(t *testing.T) {
+		mockRepo := new(repo.IOrderRepository)
+		mockRepo.On("GetExistingUids", []uuid.UUID{first.Uid, second.Uid}).Return([]uuid.UUID{second.Uid}, nil)
+		mockRepo.On("CreateBatch", []models.Order{first}).Return(nil)
+		mockRepo.On("GetExistingUids", []uuid.UUID{third.Uid}).Return([]uuid.UUID{}, nil)
+		mockRepo.On("CreateBatch", []models.Order{third}).Return(nil)
+
+		checkpoint := filepath.Join(t.TempDir(), "orders.checkpoint")
+		report := &bytes.Buffer{}
+		importer := NewImporter(mockRepo, Options{BatchSize: 2, CheckpointFile: checkpoint}, report)
+
+		summary, err := importer.Run(context.Background(), records())
+
+		require.NoError(t, err)
+		assert.Equal(t, Summary{Inserted: 2, Duplicate: 1, Invalid: 1}, summary)
+		saved, err := LoadCheckpoint(checkpoint)
+		require.NoError(t, err)
+		assert.Equal(t, Checkpoint{Line: 4, ReportSize: int64(report.Len())}, saved)
+
+		problems := readProblems(t, report)
+		require.Len(t, problems, 2)
+		assert.Equal(t, 2, problems[0].Line)
+		assert.Equal(t, ProblemInvalid, problems[0].Kind)
+		assert.Equal(t, 3, problems[1].Line)
+		assert.Equal(t, ProblemDuplicate, problems[1].Kind)
+	})
+
+	t.Run("ResumesFromCheckpoint", func(t *testing.T) {
+		mockRepo := new(repo.IOrderRepository)
+		mockRepo.On("GetExistingUids", []uuid.UUID{third.Uid}).Return([]uuid.UUID{}, nil)
+		mockRepo.On("CreateBatch", []models.Order{third}).Return(nil)
+
+		checkpoint := filepath.Join(t.TempDir(), "orders.checkpoint")
+		require.NoError(t, SaveCheckpoint(checkpoint, Checkpoint{Line: 3}))
+		importer := NewImporter(mockRepo, Options{BatchSize: 2, CheckpointFile: checkpoint}, &bytes.Buffer{})
+
+		summary, err := importer.Run(context.Background(), records())
+
+		require.NoError(t, err)
+		assert.Equal(t, Summary{Inserted: 1, Skipped: 3}, summary)
+	})
+
+	t.Run("TrailingInvalidRecordsMoveCheckpoint", func(t *testing.T) {
+		trailing := func() Reader {
+			return &sliceReader{records: []Record{{Line: 1, Order: first}, {Line: 2, Order: invalid}}}
+		}
+		mockRepo := new(repo.IOrderRepository)
+		mockRepo.On("GetExistingUids", []uuid.UUID{first.Uid}).Return([]uuid.UUID{}, nil).Once()
+		mockRepo.On("CreateBatch", []models.Order{first}).Return(nil).Once()
+
+		checkpoint := filepath.Join(t.TempDir(), "orders.checkpoint")
+		report := &bytes.Buffer{}
+		summary, err := NewImporter(mockRepo, Options{BatchSize: 1, CheckpointFile: checkpoint}, report).Run(context.Background(), trailing())
+		require.NoError(t, err)
+		assert.Equal(t, Summary{Inserted: 1, Invalid: 1}, summary)
+
+		saved, err := LoadCheckpoint(checkpoint)
+		require.NoError(t, err)
+		assert.Equal(t, Checkpoint{Line: 2, ReportSize: int64(report.Len())}, saved)
+
+		summary, err = NewImporter(mockRepo, Options{BatchSize: 1, CheckpointFile: checkpoint}, report).Run(context.Background(), trailing())
+		require.NoError(t, err)
+		assert.Equal(t, Summary{Skipped: 2}, summary)
+		assert.Len(t, readProblems(t, report), 1)
+	})
+
+	t.Run("DryRunCountsDuplicatesAcrossBatches", func(t *testing.T) {
+		mockRepo := new(repo.IOrderRepository)
+		mockRepo.On("GetExistingUids", mock.Anything).Return([]uuid.UUID{}, nil)
+		repeated := &sliceReader{records: []Record{{Line: 1, Order: first}, {Line: 2, Order: second}, {Line: 3, Order: first}}}
+
+		report := &bytes.Buffer{}
+		summary, err := NewImporter(mockRepo, Options{BatchSize: 2, DryRun: true}, report).Run(context.Background(), repeated)
+
+		require.NoError(t, err)
+		assert.Equal(t, Summary{Inserted: 2, Duplicate: 1}, summary)
+		problems := readProblems(t, report)
+		require.Len(t, problems, 1)
+		assert.Equal(t, 3, problems[0].Line)
+	})
+
+	t.Run("DryRun", func(t *testing.T) {
+		mockRepo := new(repo.IOrderRepository)
+		mockRepo.On("GetExistingUids", mock.Anything).Return([]uuid.UUID{}, nil)
+
+		importer := NewImporter(mockRepo, Options{BatchSize: 10, DryRun: true}, &bytes.Buffer{})
+
+		summary, err := importer.Run(context.Background(), records())
+
+		require.NoError(t, err)
+		assert.Equal(t, Summary{Inserted: 3, Invalid: 1}, summary)
+		mockRepo.AssertNotCalled(t, "CreateBatch", mock.Anything)
+	})
+
+	t.Run("ConcurrentDuplicateFallsBackToSingleInserts", func(t *testing.T) {
+		mockRepo := new(repo.IOrderRepository)
+		mockRepo.On("GetExistingUids", mock.Anything).Return([]uuid.UUID{}, nil)
+		mockRepo.On("CreateBatch", mock.Anything).Return(&pgconn.PgError{Code: "23505"})
+		mockRepo.On("Create", first).Return(nil)
+		mockRepo.On("Create", second).Return(&pgconn.PgError{Code: "23505"})
+		mockRepo.On("Create", third).Return(nil)
+
+		importer := NewImporter(mockRepo, Options{BatchSize: 10}, &bytes.Buffer{})
+
+		summary, err := importer.Run(context.Background(), records())
+
+		require.NoError(t, err)
+		assert.Equal(t, Summary{Inserted: 2, Duplicate: 1, Invalid: 1}, summary)
+	})
+}
+
+func TestLoadCheckpoint_Missing(t *testing.T) {
+	checkpoint, err := LoadCheckpoint(filepath.Join(t.TempDir(), "missing"))
+
+	require.NoError(t, err)
+	assert.Equal(t, Checkpoint{}, checkpoint)
+}
+
+func TestLoadCheckpoint_Corrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.checkpoint")
+	require.NoError(t, os.WriteFile(path, []byte("abc"), 0o644))
+
+	_, err := LoadCheckpoint(path)
+
+	assert.Error(t, err)
+}
+
+func TestOpenReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.errors.ndjson")
+	require.NoError(t, os.WriteFile(path, []byte("{\"line\":2}\n{\"line\":7}\n"), 0o644))
+
+	t.Run("DropsProblemsAfterCheckpoint", func(t *testing.T) {
+		report, err := OpenReport(path, Checkpoint{Line: 5, ReportSize: int64(len("{\"line\":2}\n"))})
+		require.NoError(t, err)
+		_, err = report.WriteString("{\"line\":7}\n")
+		require.NoError(t, err)
+		require.NoError(t, report.Close())
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "{\"line\":2}\n{\"line\":7}\n", string(data))
+	})
+
+	t.Run("FreshRunStartsEmpty", func(t *testing.T) {
+		report, err := OpenReport(path, Checkpoint{})
+		require.NoError(t, err)
+		require.NoError(t, report.Close())
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Empty(t, data)
+	})
+}
@@ -0,0 +1,271 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	"orderService/internal/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxLineSize bounds one NDJSON line, orders with many items are a few kilobytes
+const maxLineSize = 4 * 1024 * 1024
+
+// Record is an order read from the file. Line is the line the order starts on.
+// Err is set when the line could not be parsed, the import continues with the next record
+type Record struct {
+	Line  int
+	Order models.Order
+	Err   error
+}
+
+// Reader returns records in file order and io.EOF after the last one
+type Reader interface {
+	Next() (Record, error)
+}
+
+// NDJSONReader reads one order per line in the Kafka message format
+type NDJSONReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func NewNDJSONReader(r io.Reader) *NDJSONReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	return &NDJSONReader{scanner: scanner}
+}
+
+func (r *NDJSONReader) Next() (Record, error) {
+	for r.scanner.Scan() {
+		r.line++
+		data := bytes.TrimSpace(r.scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var order models.Order
+		if err := order.UnmarshalJSON(data); err != nil {
+			return Record{Line: r.line, Err: err}, nil
+		}
+		return Record{Line: r.line, Order: order}, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return Record{}, err
+	}
+	return Record{}, io.EOF
+}
+
+// CSVColumns is the header of the flattened CSV format. Every row holds one item,
+// consecutive rows with the same order_uid repeat the order columns and form one order
+var CSVColumns = []string{
+	"order_uid", "track_number", "entry", "locale", "internal_signature", "customer_id", "delivery_service",
+	"shardkey", "sm_id", "date_created", "oof_shard",
+	"delivery_name", "delivery_phone", "delivery_zip", "delivery_city", "delivery_address", "delivery_region", "delivery_email",
+	"payment_transaction", "payment_request_id", "payment_currency", "payment_provider", "payment_amount",
+	"payment_dt", "payment_bank", "payment_delivery_cost", "payment_goods_total", "payment_custom_fee",
+	"item_chrt_id", "item_track_number", "item_price", "item_rid", "item_name", "item_sale", "item_size",
+	"item_total_price", "item_nm_id", "item_brand", "item_status",
+}
+
+type csvRow struct {
+	line   int
+	values map[string]string
+}
+
+// CSVReader reads the flattened CSV format, the first line is the header with CSVColumns in any order
+type CSVReader struct {
+	reader  *csv.Reader
+	header  []string
+	line    int
+	pending *csvRow
+}
+
+func NewCSVReader(r io.Reader) (*CSVReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	known := make(map[string]bool, len(CSVColumns))
+	for _, column := range CSVColumns {
+		known[column] = true
+	}
+	for i, column := range header {
+		header[i] = strings.TrimSpace(column)
+		if !known[header[i]] {
+			return nil, fmt.Errorf("unknown CSV column %q", header[i])
+		}
+	}
+
+	return &CSVReader{reader: reader, header: header, line: 1}, nil
+}
+
+func (r *CSVReader) Next() (Record, error) {
+	first := r.pending
+	r.pending = nil
+	if first == nil {
+		row, err := r.readRow()
+		if err != nil {
+			return Record{}, err
+		}
+		first = row
+	}
+
+	rows := []*csvRow{first}
+	for {
+		row, err := r.readRow()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Record{}, err
+		}
+		if row.values["order_uid"] != first.values["order_uid"] {
+			r.pending = row
+			break
+		}
+		rows = append(rows, row)
+	}
+
+	order, err := parseOrder(rows)
+	if err != nil {
+		return Record{Line: first.line, Err: err}, nil
+	}
+	return Record{Line: first.line, Order: order}, nil
+}
+
+func (r *CSVReader) readRow() (*csvRow, error) {
+	values, err := r.reader.Read()
+	if err != nil {
+		if parseErr, ok := err.(*csv.ParseError); ok {
+			return nil, fmt.Errorf("line %d: %w", parseErr.Line, parseErr.Err)
+		}
+		return nil, err
+	}
+	r.line, _ = r.reader.FieldPos(0)
+
+	row := &csvRow{line: r.line, values: make(map[string]string, len(r.header))}
+	for i, column := range r.header {
+		if i < len(values) {
+			row.values[column] = values[i]
+		}
+	}
+	return row, nil
+}
+
+func parseOrder(rows []*csvRow) (models.Order, error) {
+	p := fieldParser{values: rows[0].values}
+
+	order := models.Order{
+		TrackNumber:       p.string("track_number"),
+		Entry:             p.string("entry"),
+		Locale:            p.string("locale"),
+		InternalSignature: p.string("internal_signature"),
+		CustomerID:        p.string("customer_id"),
+		DeliveryService:   p.string("delivery_service"),
+		ShardKey:          p.string("shardkey"),
+		SmID:              p.int("sm_id"),
+		OofShard:          p.string("oof_shard"),
+		Delivery: models.Delivery{
+			Name:    p.string("delivery_name"),
+			Phone:   p.string("delivery_phone"),
+			Zip:     p.string("delivery_zip"),
+			City:    p.string("delivery_city"),
+			Address: p.string("delivery_address"),
+			Region:  p.string("delivery_region"),
+			Email:   p.string("delivery_email"),
+		},
+		Payment: models.Payment{
+			Transaction:  p.string("payment_transaction"),
+			RequestID:    p.string("payment_request_id"),
+			Currency:     p.string("payment_currency"),
+			Provider:     p.string("payment_provider"),
+			Amount:       p.int("payment_amount"),
+			PaymentDt:    p.int("payment_dt"),
+			Bank:         p.string("payment_bank"),
+			DeliveryCost: p.int("payment_delivery_cost"),
+			GoodsTotal:   p.int("payment_goods_total"),
+			CustomFee:    p.int("payment_custom_fee"),
+		},
+	}
+	order.Uid = p.uuid("order_uid")
+	order.DateCreated = p.time("date_created")
+
+	for _, row := range rows {
+		p.values = row.values
+		if p.string("item_chrt_id") == "" {
+			continue
+		}
+		order.Items = append(order.Items, models.Item{
+			ChrtID:      p.int("item_chrt_id"),
+			TrackNumber: p.string("item_track_number"),
+			Price:       p.int("item_price"),
+			RID:         p.string("item_rid"),
+			Name:        p.string("item_name"),
+			Sale:        p.int("item_sale"),
+			Size:        p.string("item_size"),
+			TotalPrice:  p.int("item_total_price"),
+			NmID:        p.int("item_nm_id"),
+			Brand:       p.string("item_brand"),
+			Status:      p.int("item_status"),
+		})
+	}
+
+	return order, p.err
+}
+
+// fieldParser keeps the first conversion error so a row is parsed without checking every column
+type fieldParser struct {
+	values map[string]string
+	err    error
+}
+
+func (p *fieldParser) string(column string) string {
+	return p.values[column]
+}
+
+func (p *fieldParser) int(column string) int {
+	value := p.values[column]
+	if value == "" {
+		return 0
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil && p.err == nil {
+		p.err = fmt.Errorf("column %s: %q is not a number", column, value)
+	}
+	return number
+}
+
+func (p *fieldParser) uuid(column string) uuid.UUID {
+	value := p.values[column]
+	if value == "" {
+		return uuid.Nil
+	}
+	uid, err := uuid.Parse(value)
+	if err != nil && p.err == nil {
+		p.err = fmt.Errorf("column %s: %q is not UUID format", column, value)
+	}
+	return uid
+}
+
+func (p *fieldParser) time(column string) time.Time {
+	value := p.values[column]
+	if value == "" {
+		return time.Time{}
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil && p.err == nil {
+		p.err = fmt.Errorf("column %s: %q is not RFC3339 time", column, value)
+	}
+	return parsed
+}
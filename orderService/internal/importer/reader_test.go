@@ -0,0 +1,65 @@
+package importer
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"strings"
+	"testing"
+)
+
+const ndjsonOrder = `{"order_uid":"1e9ad4fb-2615-46f9-9458-20b59253086b","track_number":"WBILMTESTTRACK","entry":"WBIL","delivery":{"name":"Test Testov","phone":"+9720000000","zip":"2639809","city":"Kiryat Mozkin","address":"Ploshad Mira 15","region":"Kraiot","email":"test@gmail.com"},"payment":{"transaction":"b563feb7b2b84b6test","request_id":"","currency":"USD","provider":"wbpay","amount":1817,"payment_dt":1637907727,"bank":"alpha","delivery_cost":1500,"goods_total":317,"custom_fee":0},"items":[{"chrt_id":9934930,"track_number":"WBILMTESTTRACK","price":453,"rid":"ab4219087a764ae0btest","name":"Mascaras","sale":30,"size":"0","total_price":317,"nm_id":2389212,"brand":"Vivienne Sabo","status":202}],"locale":"en","internal_signature":"","customer_id":"test","delivery_service":"meest","shardkey":"9","sm_id":99,"date_created":"2021-11-26T06:22:19Z","oof_shard":"1"}`
+
+func TestNDJSONReader(t *testing.T) {
+	reader := NewNDJSONReader(strings.NewReader(ndjsonOrder + "\n\n{broken\n"))
+
+	record, err := reader.Next()
+	require.NoError(t, err)
+	assert.Equal(t, 1, record.Line)
+	assert.NoError(t, record.Err)
+	assert.Equal(t, uuid.MustParse("1e9ad4fb-2615-46f9-9458-20b59253086b"), record.Order.Uid)
+	assert.Len(t, record.Order.Items, 1)
+	assert.NoError(t, record.Order.Validate())
+
+	record, err = reader.Next()
+	require.NoError(t, err)
+	assert.Equal(t, 3, record.Line)
+	assert.Error(t, record.Err)
+
+	_, err = reader.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestCSVReader(t *testing.T) {
+	csv := strings.Join(CSVColumns, ",") + "\n" +
+		"1e9ad4fb-2615-46f9-9458-20b59253086b,WBILMTESTTRACK,WBIL,en,,test,meest,9,99,2021-11-26T06:22:19Z,1,Test Testov,+9720000000,2639809,Kiryat Mozkin,Ploshad Mira 15,Kraiot,test@gmail.com,b563feb7b2b84b6test,,USD,wbpay,1817,1637907727,alpha,1500,317,0,9934930,WBILMTESTTRACK,453,ab4219087a764ae0btest,Mascaras,30,0,317,2389212,Vivienne Sabo,202\n" +
+		"1e9ad4fb-2615-46f9-9458-20b59253086b,WBILMTESTTRACK,WBIL,en,,test,meest,9,99,2021-11-26T06:22:19Z,1,Test Testov,+9720000000,2639809,Kiryat Mozkin,Ploshad Mira 15,Kraiot,test@gmail.com,b563feb7b2b84b6test,,USD,wbpay,1817,1637907727,alpha,1500,317,0,9934931,WBILMTESTTRACK,100,ab4219087a764ae0btest,Lipstick,0,0,100,2389213,Vivienne Sabo,202\n" +
+		"2e9ad4fb-2615-46f9-9458-20b59253086b,WBILMTESTTRACK,WBIL,en,,test,meest,9,ninety,2021-11-26T06:22:19Z,1,Test Testov,+9720000000,2639809,Kiryat Mozkin,Ploshad Mira 15,Kraiot,test@gmail.com,b563feb7b2b84b6test,,USD,wbpay,1817,1637907727,alpha,1500,317,0,9934930,WBILMTESTTRACK,453,ab4219087a764ae0btest,Mascaras,30,0,317,2389212,Vivienne Sabo,202\n"
+
+	reader, err := NewCSVReader(strings.NewReader(csv))
+	require.NoError(t, err)
+
+	record, err := reader.Next()
+	require.NoError(t, err)
+	assert.Equal(t, 2, record.Line)
+	assert.NoError(t, record.Err)
+	assert.Len(t, record.Order.Items, 2)
+	assert.Equal(t, "Lipstick", record.Order.Items[1].Name)
+	assert.Equal(t, 1637907727, record.Order.Payment.PaymentDt)
+	assert.NoError(t, record.Order.Validate())
+
+	record, err = reader.Next()
+	require.NoError(t, err)
+	assert.Equal(t, 4, record.Line)
+	assert.EqualError(t, record.Err, `column sm_id: "ninety" is not a number`)
+
+	_, err = reader.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestCSVReader_UnknownColumn(t *testing.T) {
+	_, err := NewCSVReader(strings.NewReader("order_uid,unknown\n"))
+
+	assert.EqualError(t, err, `unknown CSV column "unknown"`)
+}
@@ -0,0 +1,262 @@
+package eventHandler
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNotSupported = errors.New("the message source does not support consumer administration")
+	ErrNotAssigned  = errors.New("the partition is not assigned to this consumer")
+	ErrBusy         = errors.New("in-flight messages of the partition are still processed")
+)
+
+// seekDrainTimeout bounds the wait of the polling goroutine for the in-flight messages of a partition to seek,
+// well below max.poll.interval.ms
+const seekDrainTimeout = 10 * time.Second
+
+//go:generate mockery --name=IConsumerAdmin --output=mocks --outpkg=mocks --case=snake --with-expecter
+type IConsumerAdmin interface {
+	Status() (ConsumerStatus, error)
+	// Pause stops fetching the partitions until they are resumed, every assigned partition when none are given.
+	// It returns the partitions that were not paused already
+	Pause(partitions []TopicPartition) ([]TopicPartition, error)
+	// Resume returns the partitions that were paused, every assigned partition when none are given
+	Resume(partitions []TopicPartition) ([]TopicPartition, error)
+	// Seek makes tp.Offset the next message of the partition, it is committed so a restart continues the replay
+	Seek(ctx context.Context, tp TopicPartition) error
+	// SeekToTime seeks to the first message produced at or after ts and returns its offset
+	SeekToTime(ctx context.Context, tp TopicPartition, ts time.Time) (TopicPartition, error)
+}
+
+type ConsumerStatus struct {
+	Broker     string            `json:"broker"`
+	PollErrors int               `json:"poll_errors"`
+	Partitions []PartitionStatus `json:"partitions"`
+}
+
+// PartitionStatus compares the committed offset with the high watermark, the offset of the next message
+// produced. Committed and Lag are empty while nothing was committed for the partition
+type PartitionStatus struct {
+	Topic         string     `json:"topic"`
+	Partition     int32      `json:"partition"`
+	Committed     *int64     `json:"committed"`
+	HighWatermark int64      `json:"high_watermark"`
+	Lag           *int64     `json:"lag"`
+	LastMessageAt *time.Time `json:"last_message_at"`
+	Errors        int        `json:"errors"`
+	Paused        bool       `json:"paused"`
+	PausedByAdmin bool       `json:"paused_by_admin"`
+}
+
+type partitionStats struct {
+	lastMessageAt time.Time
+	errors        int
+}
+
+// consumerStats counts what the consumer saw since it started, per partition
+type consumerStats struct {
+	mu         sync.Mutex
+	partitions map[partitionKey]*partitionStats
+	pollErrors int
+}
+
+func newConsumerStats() *consumerStats {
+	return &consumerStats{partitions: make(map[partitionKey]*partitionStats)}
+}
+
+func (s *consumerStats) received(msg *Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.partition(msg.TopicPartition).lastMessageAt = msg.Timestamp
+}
+
+func (s *consumerStats) failed(tp TopicPartition) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.partition(tp).errors++
+}
+
+func (s *consumerStats) pollFailed() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pollErrors++
+}
+
+func (s *consumerStats) of(tp TopicPartition) (partitionStats, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stats, ok := s.partitions[keyOf(tp)]; ok {
+		return *stats, s.pollErrors
+	}
+	return partitionStats{}, s.pollErrors
+}
+
+func (s *consumerStats) partition(tp TopicPartition) *partitionStats {
+	key := keyOf(tp)
+	stats, ok := s.partitions[key]
+	if !ok {
+		stats = &partitionStats{}
+		s.partitions[key] = stats
+	}
+	return stats
+}
+
+// seekRequest is handled by the polling goroutine, the source must not be polled while it seeks
+type seekRequest struct {
+	tp   TopicPartition
+	done chan error
+}
+
+func (c *Consumer) seekable() (SeekableSource, error) {
+	source, ok := c.source.(SeekableSource)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotSupported, c.source.Name())
+	}
+	return source, nil
+}
+
+func (c *Consumer) Status() (ConsumerStatus, error) {
+	source, err := c.seekable()
+	if err != nil {
+		return ConsumerStatus{}, err
+	}
+	assigned, err := source.Assignment()
+	if err != nil {
+		return ConsumerStatus{}, fmt.Errorf("failed to get the assigned partitions: %w", err)
+	}
+	committed, err := source.Committed(assigned)
+	if err != nil {
+		return ConsumerStatus{}, fmt.Errorf("failed to get the committed offsets: %w", err)
+	}
+
+	status := ConsumerStatus{Broker: source.Name(), Partitions: make([]PartitionStatus, 0, len(committed))}
+	for _, tp := range committed {
+		high, err := source.HighWatermark(tp)
+		if err != nil {
+			return ConsumerStatus{}, fmt.Errorf("failed to get the high watermark of %s [%d]: %w", tp.Topic, tp.Partition, err)
+		}
+		partition := PartitionStatus{Topic: tp.Topic, Partition: tp.Partition, HighWatermark: high}
+		if tp.Offset != offsetUnset {
+			offset, lag := tp.Offset, max(high-tp.Offset, 0)
+			partition.Committed, partition.Lag = &offset, &lag
+		}
+		var stats partitionStats
+		stats, status.PollErrors = c.stats.of(tp)
+		if !stats.lastMessageAt.IsZero() {
+			partition.LastMessageAt = &stats.lastMessageAt
+		}
+		partition.Errors = stats.errors
+		partition.Paused, partition.PausedByAdmin = c.paused.state(tp)
+		status.Partitions = append(status.Partitions, partition)
+	}
+	slices.SortFunc(status.Partitions, func(a, b PartitionStatus) int {
+		return cmp.Or(cmp.Compare(a.Topic, b.Topic), cmp.Compare(a.Partition, b.Partition))
+	})
+	return status, nil
+}
+
+func (c *Consumer) Pause(partitions []TopicPartition) ([]TopicPartition, error) {
+	return c.toggle(partitions, c.paused.pauseByAdmin)
+}
+
+func (c *Consumer) Resume(partitions []TopicPartition) ([]TopicPartition, error) {
+	return c.toggle(partitions, c.paused.resumeByAdmin)
+}
+
+func (c *Consumer) toggle(partitions []TopicPartition, apply func(tp TopicPartition) bool) ([]TopicPartition, error) {
+	source, err := c.seekable()
+	if err != nil {
+		return nil, err
+	}
+	assigned, err := source.Assignment()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the assigned partitions: %w", err)
+	}
+	if len(partitions) == 0 {
+		partitions = assigned
+	}
+	for _, tp := range partitions {
+		if !contains(assigned, keyOf(tp)) {
+			return nil, fmt.Errorf("%w: %s [%d]", ErrNotAssigned, tp.Topic, tp.Partition)
+		}
+	}
+
+	changed := make([]TopicPartition, 0, len(partitions))
+	for _, tp := range partitions {
+		if apply(partitionOf(tp)) {
+			changed = append(changed, partitionOf(tp))
+		}
+	}
+	return changed, nil
+}
+
+func (c *Consumer) Seek(ctx context.Context, tp TopicPartition) error {
+	if tp.Offset < 0 {
+		return fmt.Errorf("offset must not be negative, got %d", tp.Offset)
+	}
+	source, err := c.seekable()
+	if err != nil {
+		return err
+	}
+	assigned, err := source.Assignment()
+	if err != nil {
+		return fmt.Errorf("failed to get the assigned partitions: %w", err)
+	}
+	if !contains(assigned, keyOf(tp)) {
+		return fmt.Errorf("%w: %s [%d]", ErrNotAssigned, tp.Topic, tp.Partition)
+	}
+
+	request := seekRequest{tp: tp, done: make(chan error, 1)}
+	select {
+	case c.seeks <- request:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err = <-request.done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *Consumer) SeekToTime(ctx context.Context, tp TopicPartition, ts time.Time) (TopicPartition, error) {
+	source, err := c.seekable()
+	if err != nil {
+		return TopicPartition{}, err
+	}
+	if tp.Offset, err = source.OffsetForTime(tp, ts); err != nil {
+		return TopicPartition{}, err
+	}
+	return tp, c.Seek(ctx, tp)
+}
+
+// seek runs on the polling goroutine, it waits for the in-flight messages of the partition
+// so none of them is committed over the new position. It fails with ErrBusy when they take too long
+func (c *Consumer) seek(tp TopicPartition) error {
+	partition := []TopicPartition{partitionOf(tp)}
+	if !c.offsets.drainWithin(partition, seekDrainTimeout) {
+		return fmt.Errorf("%w: %s [%d]", ErrBusy, tp.Topic, tp.Partition)
+	}
+	c.undelay(partition)
+	c.offsets.forget(partition)
+	if err := c.source.(SeekableSource).Seek(tp); err != nil {
+		return fmt.Errorf("failed to seek %s: %w", tp, err)
+	}
+	if err := c.source.Commit([]TopicPartition{tp}); err != nil {
+		return fmt.Errorf("failed to commit %s: %w", tp, err)
+	}
+	log.Printf("Consumer of %s seeked to %s\n", c.source.Name(), tp)
+	return nil
+}
@@ -0,0 +1,174 @@
+package eventHandler
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// startConsumer runs the pipeline consumer over a memory source until the test ends
+func startConsumer(t *testing.T, partitions int) (*Consumer, *MemorySource) {
+	orderService, _, _ := newPipelineService()
+	source := NewMemorySource(partitions)
+	consumer, err := NewConsumer(source, source, orderService, pipelineConfig)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		consumer.Start(ctx)
+		close(stopped)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-stopped
+	})
+	return consumer, source
+}
+
+func committedUpTo(source *MemorySource, tp TopicPartition) func() bool {
+	return func() bool { return source.CommittedOffset(tp.Topic, tp.Partition) == tp.Offset }
+}
+
+func TestConsumer_Status(t *testing.T) {
+	consumer, source := startConsumer(t, 1)
+	produced := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, msg := range pipelineMessages(t) {
+		msg.Timestamp = produced.Add(time.Duration(i) * time.Minute)
+		source.Produce(topic, msg)
+	}
+	require.Eventually(t, committedUpTo(source, tp(0, 3)), 5*time.Second, 10*time.Millisecond)
+	_, err := consumer.Pause(nil)
+	require.NoError(t, err)
+	source.Produce(topic, Message{Value: []byte("{}")})
+
+	status, err := consumer.Status()
+
+	require.NoError(t, err)
+	assert.Equal(t, "memory", status.Broker)
+	assert.Equal(t, []PartitionStatus{{
+		Topic:         topic,
+		Partition:     0,
+		Committed:     ptr(int64(3)),
+		HighWatermark: 4,
+		Lag:           ptr(int64(1)),
+		LastMessageAt: ptr(produced.Add(2 * time.Minute)),
+		// the invalid message was dead-lettered
+		Errors:        1,
+		Paused:        true,
+		PausedByAdmin: true,
+	}}, status.Partitions)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestConsumer_PauseResume(t *testing.T) {
+	consumer, source := startConsumer(t, 2)
+
+	paused, err := consumer.Pause(nil)
+	require.NoError(t, err)
+	assert.Equal(t, []TopicPartition{tp(0, 0), tp(1, 0)}, paused)
+
+	paused, err = consumer.Pause([]TopicPartition{tp(1, 0)})
+	require.NoError(t, err)
+	assert.Empty(t, paused, "the partition is paused already")
+
+	_, err = consumer.Pause([]TopicPartition{{Topic: "Unknown"}})
+	assert.ErrorIs(t, err, ErrNotAssigned)
+
+	at := source.Produce(topic, pipelineMessages(t)[0])
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, offsetUnset, source.CommittedOffset(topic, at.Partition), "a paused partition is not read")
+
+	status, err := consumer.Status()
+	require.NoError(t, err)
+	for _, partition := range status.Partitions {
+		assert.True(t, partition.Paused && partition.PausedByAdmin, partition.Partition)
+	}
+
+	resumed, err := consumer.Resume([]TopicPartition{partitionOf(at)})
+	require.NoError(t, err)
+	assert.Equal(t, []TopicPartition{partitionOf(at)}, resumed)
+	at.Offset++
+	require.Eventually(t, committedUpTo(source, at), 5*time.Second, 10*time.Millisecond)
+}
+
+func TestConsumer_Seek(t *testing.T) {
+	consumer, source := startConsumer(t, 1)
+	produced := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, msg := range pipelineMessages(t)[:2] {
+		msg.Timestamp = produced.Add(time.Duration(i) * time.Minute)
+		source.Produce(topic, msg)
+	}
+	require.Eventually(t, committedUpTo(source, tp(0, 2)), 5*time.Second, 10*time.Millisecond)
+
+	t.Run("ToTimestamp", func(t *testing.T) {
+		// pausing keeps the replayed message from being read and committed before the assertion
+		_, err := consumer.Pause(nil)
+		require.NoError(t, err)
+
+		seeked, err := consumer.SeekToTime(context.Background(), tp(0, 0), produced.Add(30*time.Second))
+
+		require.NoError(t, err)
+		assert.Equal(t, tp(0, 1), seeked)
+		assert.Equal(t, int64(1), source.CommittedOffset(topic, 0))
+		_, err = consumer.Resume(nil)
+		require.NoError(t, err)
+		require.Eventually(t, committedUpTo(source, tp(0, 2)), 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("ToOffset", func(t *testing.T) {
+		require.NoError(t, consumer.Seek(context.Background(), tp(0, 0)))
+		require.Eventually(t, committedUpTo(source, tp(0, 2)), 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("NotAssigned", func(t *testing.T) {
+		err := consumer.Seek(context.Background(), TopicPartition{Topic: topic, Partition: 1})
+		assert.ErrorIs(t, err, ErrNotAssigned)
+	})
+
+	t.Run("NotSupported", func(t *testing.T) {
+		consumer := newConsumer(t, nil)
+		consumer.source = &NatsSource{}
+
+		_, err := consumer.Status()
+
+		assert.ErrorIs(t, err, ErrNotSupported)
+	})
+}
+
+// flakySink fails the first publish, like a broker that is briefly unreachable
+type flakySink struct {
+	MessageSink
+	failed atomic.Bool
+}
+
+func (s *flakySink) Publish(topic string, msg Message) error {
+	if s.failed.CompareAndSwap(false, true) {
+		return errors.New("broker down")
+	}
+	return s.MessageSink.Publish(topic, msg)
+}
+
+func TestConsumer_rewind(t *testing.T) {
+	orderService, _, _ := newPipelineService()
+	source := NewMemorySource(1)
+	consumer, err := NewConsumer(source, &flakySink{MessageSink: source}, orderService, pipelineConfig)
+	require.NoError(t, err)
+	var last TopicPartition
+	for _, msg := range pipelineMessages(t) {
+		last = source.Produce(topic, msg)
+	}
+	// an order after the abandoned message must not move the commit past it for good
+	last = source.Produce(topic, pipelineMessages(t)[0])
+
+	runPipeline(t, consumer, committedUpTo(source, tp(0, last.Offset+1)))
+
+	assert.Len(t, source.Messages("Orders.dlq"), 1)
+}
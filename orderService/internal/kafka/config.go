@@ -0,0 +1,62 @@
+package eventHandler
+
+import (
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"orderService/configs"
+	"strings"
+)
+
+// connectionConfig holds the brokers and credentials shared by the consumer and the producer,
+// KAFKA_PROPERTIES are applied last so they override everything else
+func connectionConfig(cnf configs.Kafka, properties kafka.ConfigMap) *kafka.ConfigMap {
+	properties["bootstrap.servers"] = cnf.BootstrapServers()
+	properties["security.protocol"] = strings.ToLower(cnf.SecurityProtocol)
+	if strings.HasPrefix(properties["security.protocol"].(string), "sasl_") {
+		properties["sasl.mechanism"] = strings.ToUpper(cnf.SASLMechanism)
+		setIfPresent(properties, "sasl.username", cnf.SASLUsername)
+		setIfPresent(properties, "sasl.password", cnf.SASLPassword)
+	}
+	setIfPresent(properties, "ssl.ca.location", cnf.SSLCALocation)
+	setIfPresent(properties, "ssl.certificate.location", cnf.SSLCertLocation)
+	setIfPresent(properties, "ssl.key.location", cnf.SSLKeyLocation)
+	setIfPresent(properties, "ssl.key.password", cnf.SSLKeyPassword)
+
+	for key, value := range cnf.Properties {
+		properties[key] = value
+	}
+	return &properties
+}
+
+func consumerConfig(cnf configs.Kafka) *kafka.ConfigMap {
+	return connectionConfig(cnf, kafka.ConfigMap{
+		"group.id":              cnf.GroupID,
+		"enable.auto.commit":    false,
+		"auto.offset.reset":     cnf.AutoOffsetReset,
+		"session.timeout.ms":    cnf.SessionTimeout,
+		"heartbeat.interval.ms": cnf.HeartbeatInterval,
+		"max.poll.interval.ms":  cnf.MaxPollInterval,
+	})
+}
+
+func producerConfig(cnf configs.Kafka) *kafka.ConfigMap {
+	return connectionConfig(cnf, kafka.ConfigMap{})
+}
+
+// subscriptions are the configured topics followed by their retry topics when enabled
+func subscriptions(cnf configs.Kafka) []string {
+	topics := append([]string(nil), cnf.Topics...)
+	if cnf.RetryTopics {
+		for _, topic := range cnf.Topics {
+			for _, stage := range retryStages {
+				topics = append(topics, topic+stage.suffix)
+			}
+		}
+	}
+	return topics
+}
+
+func setIfPresent(properties kafka.ConfigMap, key string, value string) {
+	if value != "" {
+		properties[key] = value
+	}
+}
@@ -0,0 +1,54 @@
+package eventHandler
+
+import (
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/stretchr/testify/assert"
+	"orderService/configs"
+	"testing"
+)
+
+func TestConsumerConfig(t *testing.T) {
+	cnf := configs.Kafka{
+		Brokers:           []string{"kafka-1:9093", "kafka-2:9093"},
+		GroupID:           "orders-ingest",
+		AutoOffsetReset:   "earliest",
+		SessionTimeout:    30000,
+		HeartbeatInterval: 3000,
+		MaxPollInterval:   300000,
+		SecurityProtocol:  "SASL_SSL",
+		SASLMechanism:     "scram-sha-512",
+		SASLUsername:      "orders",
+		SASLPassword:      "secret",
+		SSLCALocation:     "/etc/kafka/ca.pem",
+		Properties:        configs.Properties{"client.id": "orders-ingest-1", "session.timeout.ms": "20000"},
+	}
+
+	assert.Equal(t, &kafka.ConfigMap{
+		"bootstrap.servers":     "kafka-1:9093,kafka-2:9093",
+		"group.id":              "orders-ingest",
+		"enable.auto.commit":    false,
+		"auto.offset.reset":     "earliest",
+		"session.timeout.ms":    "20000",
+		"heartbeat.interval.ms": 3000,
+		"max.poll.interval.ms":  300000,
+		"security.protocol":     "sasl_ssl",
+		"sasl.mechanism":        "SCRAM-SHA-512",
+		"sasl.username":         "orders",
+		"sasl.password":         "secret",
+		"ssl.ca.location":       "/etc/kafka/ca.pem",
+		"client.id":             "orders-ingest-1",
+	}, consumerConfig(cnf))
+
+	assert.Equal(t, &kafka.ConfigMap{
+		"bootstrap.servers": "kafka:9092",
+		"security.protocol": "plaintext",
+	}, producerConfig(configs.Kafka{Host: "kafka", Port: ":9092", SecurityProtocol: "plaintext"}))
+}
+
+func TestSubscriptions(t *testing.T) {
+	cnf := configs.Kafka{Topics: []string{"Orders", "Orders.proto"}}
+	assert.Equal(t, []string{"Orders", "Orders.proto"}, subscriptions(cnf))
+
+	cnf.RetryTopics = true
+	assert.Equal(t, []string{"Orders", "Orders.proto", "Orders.retry.1m", "Orders.retry.10m", "Orders.proto.retry.1m", "Orders.proto.retry.10m"}, subscriptions(cnf))
+}
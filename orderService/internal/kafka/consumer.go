@@ -2,84 +2,241 @@ package eventHandler
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
 	"log"
 	"orderService/configs"
+	"orderService/internal/kafka/decoder"
+	"orderService/internal/kafka/registry"
 	"orderService/internal/models"
+	"orderService/internal/repository"
 	"orderService/internal/service"
 	"time"
 )
 
+// Consumer stores the orders read from a MessageSource
 type Consumer struct {
-	consumer     *kafka.Consumer
+	source       MessageSource
 	orderService service.IOrderService
-	retry        int
-	backoff      time.Duration
+	decoders     *decoder.Registry
+	offsets      *offsetTracker
+	paused       *pausedPartitions
+	router       *router
+	stats        *consumerStats
+	seeks        chan seekRequest
+	// delayed and due are only used by the polling goroutine
+	delayed        map[partitionKey]*delayedPartition
+	due            chan TopicPartition
+	concurrency    int
+	ordering       string
+	commitInterval time.Duration
+	batchSize      int
+	batchWait      time.Duration
+	retry          int
+	backoff        time.Duration
 }
 
-const (
-	ORDER_TOPIC = "Orders"
-)
-
+// CreateConsumer reads the configured Kafka topics, failed messages are republished through Kafka too
 func CreateConsumer(cnf configs.Kafka, service service.IOrderService) (*Consumer, error) {
-	consumer, err := kafka.NewConsumer(&kafka.ConfigMap{
-		"bootstrap.servers":  fmt.Sprintf("%s%s", cnf.Host, cnf.Port),
-		"group.id":           "1",
-		"enable.auto.commit": false,
-	})
+	source, err := NewKafkaSource(cnf)
+	if err != nil {
+		return nil, err
+	}
+	var sink MessageSink
+	if cnf.RetryTopics || cnf.DeadLetterTopic != "" {
+		if sink, err = NewKafkaSink(cnf); err != nil {
+			return nil, err
+		}
+	}
+	return NewConsumer(source, sink, service, cnf)
+}
 
+// NewConsumer subscribes to the configured topics of source, sink may be nil when neither retry topics
+// nor a dead-letter topic are configured
+func NewConsumer(source MessageSource, sink MessageSink, service service.IOrderService, cnf configs.Kafka) (*Consumer, error) {
+	decoders, err := newDecoders(cnf)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create kafka consumer: %w", err)
+		return nil, err
 	}
 
-	if err = consumer.Subscribe(ORDER_TOPIC, nil); err != nil {
+	c := &Consumer{
+		source:         source,
+		orderService:   service,
+		decoders:       decoders,
+		offsets:        newOffsetTracker(),
+		paused:         newPausedPartitions(source),
+		stats:          newConsumerStats(),
+		seeks:          make(chan seekRequest),
+		delayed:        make(map[partitionKey]*delayedPartition),
+		due:            make(chan TopicPartition),
+		concurrency:    cnf.Concurrency,
+		ordering:       cnf.Ordering,
+		commitInterval: time.Duration(cnf.CommitInterval) * time.Millisecond,
+		batchSize:      cnf.BatchSize,
+		batchWait:      time.Duration(cnf.BatchWait) * time.Millisecond,
+		retry:          cnf.Retry,
+		backoff:        time.Duration(cnf.Backoff) * time.Millisecond,
+	}
+	if sink != nil {
+		c.router = &router{sink: sink, retryTopics: cnf.RetryTopics, deadLetter: cnf.DeadLetterTopic}
+	}
+	if err = source.Subscribe(subscriptions(cnf), c.revoked); err != nil {
 		return nil, err
 	}
-	return &Consumer{consumer, service, cnf.Retry, time.Duration(cnf.Backoff) * time.Millisecond}, nil
+	return c, nil
+}
+
+func newDecoders(cnf configs.Kafka) (*decoder.Registry, error) {
+	schemas := schemaRegistry(cnf)
+	validator, err := registry.NewValidator(schemas)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load order schema: %w", err)
+	}
+	decoders, err := decoder.NewRegistry(validator, schemas, cnf.TopicContentTypes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure kafka decoders: %w", err)
+	}
+	return decoders, nil
+}
+
+func schemaRegistry(cnf configs.Kafka) registry.Registry {
+	switch {
+	case cnf.SchemaRegistryDir != "":
+		return registry.NewFileRegistry(cnf.SchemaRegistryDir)
+	case cnf.SchemaRegistryURL != "":
+		return registry.NewHTTPRegistry(cnf.SchemaRegistryURL)
+	default:
+		return nil
+	}
 }
 
 func (c *Consumer) Start(ctx context.Context) {
-	log.Println("Kafka consumer start")
+	log.Printf("Consumer of %s start with %d workers, ordered by %s\n", c.source.Name(), c.concurrency, c.ordering)
+	pool := newWorkerPool(c.concurrency, c.ordering, c.batchSize, c.batchWait, func(batch []*Message) {
+		c.process(ctx, batch)
+	})
+	commit := time.NewTicker(c.commitInterval)
+	defer commit.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
+			pool.close()
 			if err := c.Stop(); err != nil {
 				log.Printf("%v", err.Error())
 			}
-			log.Println("Kafka consumer Stopped")
+			log.Printf("Consumer of %s stopped\n", c.source.Name())
 			return
+		case <-commit.C:
+			if err := c.commit(nil); err != nil {
+				log.Printf("Error while commit offset: %v\n", err)
+			}
+			c.rewind()
+		case request := <-c.seeks:
+			request.done <- c.seek(request.tp)
+		case tp := <-c.due:
+			c.release(ctx, pool, tp)
 		default:
-			msg, err := c.consumer.ReadMessage(-1)
-			if err == nil {
-				log.Printf("Received message in %s topic: %s\n", *msg.TopicPartition.Topic, string(msg.Value))
-				err = c.handleMessage(msg.Value)
-				if err != nil {
-					log.Println(err.Error())
-				}
-			} else {
+			msg, err := c.source.Poll(100 * time.Millisecond)
+			if err != nil {
 				log.Printf("Consumer error: %v\n", err)
+				c.stats.pollFailed()
+				continue
+			}
+			if msg != nil {
+				c.stats.received(msg)
+				if !c.delay(ctx, msg) {
+					c.offsets.track(msg.TopicPartition)
+					pool.dispatch(msg)
+				}
 			}
 		}
 	}
 }
 
+func (c *Consumer) process(ctx context.Context, batch []*Message) {
+	defer func() {
+		for _, msg := range batch {
+			c.offsets.done(msg.TopicPartition)
+		}
+	}()
+	for _, msg := range batch {
+		log.Printf("Received message in %s topic: %s\n", msg.TopicPartition.Topic, string(msg.Value))
+	}
+
+	if len(batch) == 1 {
+		if err := c.handleMessage(batch[0]); err != nil {
+			c.failed(ctx, batch[0], err)
+		}
+		return
+	}
+	c.handleBatch(ctx, batch)
+}
+
+// revoked runs on the polling goroutine, before partitions move to another member it waits
+// for their in-flight messages and commits them, so the new owner does not process them twice
+func (c *Consumer) revoked(partitions []TopicPartition, lost bool) {
+	log.Printf("Partitions revoked: %v, draining in-flight messages\n", partitions)
+	c.undelay(partitions)
+	c.offsets.drain(partitions)
+	if !lost {
+		if err := c.commitWithRetry(partitions); err != nil {
+			log.Printf("Error while commit offset of revoked partitions: %v\n", err)
+		}
+	}
+	c.offsets.forget(partitions)
+	c.paused.forget(partitions)
+}
+
+// rewind reads abandoned messages again, so a message that could not be routed does not hold the commit position
+// of its partition back until a restart. A source that cannot seek redelivers it after a restart or rebalance
+func (c *Consumer) rewind() {
+	if _, ok := c.source.(SeekableSource); !ok {
+		return
+	}
+	for _, tp := range c.offsets.abandoned() {
+		log.Printf("Reading %s [%d] again from the abandoned offset %d\n", tp.Topic, tp.Partition, tp.Offset)
+		if err := c.seek(tp); err != nil {
+			log.Printf("Failed to read %s [%d] again, retrying on the next commit: %v\n", tp.Topic, tp.Partition, err)
+		}
+	}
+}
+
+// Stop must be called after the worker pool is closed
 func (c *Consumer) Stop() error {
-	if err := c.commitLastOffset(); err != nil {
-		log.Printf("Kafka consumer Stop failed: %s\n", err.Error())
+	if err := c.commitWithRetry(nil); err != nil {
+		log.Printf("Consumer Stop failed: %s\n", err.Error())
+	}
+	if c.router != nil {
+		if err := c.router.sink.Close(); err != nil {
+			log.Printf("Failed to close message sink: %v\n", err)
+		}
+	}
+	return c.source.Close()
+}
+
+// commit stores the offsets of processed messages, of every partition when partitions is nil
+func (c *Consumer) commit(partitions []TopicPartition) error {
+	offsets := c.offsets.committable(partitions)
+	if len(offsets) == 0 {
+		return nil
 	}
-	return c.consumer.Close()
+	if err := c.source.Commit(offsets); err != nil {
+		return err
+	}
+	c.offsets.markCommitted(offsets)
+	return nil
 }
 
-func (c *Consumer) commitLastOffset() error {
+func (c *Consumer) commitWithRetry(partitions []TopicPartition) error {
 	var err error
 	backoff := c.backoff
 	for attempt := 1; attempt <= c.retry; attempt++ {
-		_, err = c.consumer.Commit()
+		err = c.commit(partitions)
 		if err == nil {
 			return nil
 		}
-		log.Printf("Error while commit kafka offset: %v. %dth attempt out of %d, wait %v\n", err, attempt, c.retry, backoff)
+		log.Printf("Error while commit offset: %v. %dth attempt out of %d, wait %v\n", err, attempt, c.retry, backoff)
 		<-time.After(backoff)
 		backoff *= 2
 	}
@@ -87,11 +244,94 @@ func (c *Consumer) commitLastOffset() error {
 	return err
 }
 
-func (c *Consumer) handleMessage(msg []byte) error {
-	var order models.Order
-	if err := order.UnmarshalJSON(msg); err != nil {
+func (c *Consumer) handleMessage(msg *Message) error {
+	order, err := c.decode(msg)
+	if err != nil {
 		return err
 	}
 
-	return c.orderService.Create(order)
+	return c.orderService.Create(order, c.actor(msg))
+}
+
+// handleBatch stores the decoded orders of a batch at once, a message that fails is handled on its own
+func (c *Consumer) handleBatch(ctx context.Context, batch []*Message) {
+	decoded := make([]*Message, 0, len(batch))
+	orders := make([]models.Order, 0, len(batch))
+	actors := make([]string, 0, len(batch))
+	for _, msg := range batch {
+		order, err := c.decode(msg)
+		if err != nil {
+			c.failed(ctx, msg, err)
+			continue
+		}
+		decoded = append(decoded, msg)
+		orders = append(orders, order)
+		actors = append(actors, c.actor(msg))
+	}
+	if len(orders) == 0 {
+		return
+	}
+
+	for i, err := range c.orderService.CreateBatch(orders, actors) {
+		if err != nil {
+			c.failed(ctx, decoded[i], err)
+		}
+	}
+}
+
+func (c *Consumer) decode(msg *Message) (models.Order, error) {
+	return Decoder{decoders: c.decoders}.Decode(msg)
+}
+
+// failed retries a transient failure in place, a message that still fails is routed to a retry topic
+// or the dead-letter topic. A message whose fate is unknown at shutdown is abandoned to be redelivered
+func (c *Consumer) failed(ctx context.Context, msg *Message, err error) {
+	if repository.IsTransient(err) {
+		if err = c.retryMessage(ctx, msg, err); err == nil {
+			return
+		}
+		if ctx.Err() != nil {
+			c.offsets.abandon(msg.TopicPartition)
+			return
+		}
+	}
+	if isDuplicate(err) {
+		log.Printf("Order of message %s exists already, the message is done\n", c.actor(msg))
+		return
+	}
+
+	log.Printf("Failed to handle message %s: %v\n", c.actor(msg), err)
+	c.stats.failed(msg.TopicPartition)
+	if c.router == nil {
+		return
+	}
+	topic, routeErr := c.router.route(msg, err)
+	if routeErr != nil {
+		log.Printf("Failed to route message %s to %s, it is read again: %v\n", c.actor(msg), topic, routeErr)
+		c.offsets.abandon(msg.TopicPartition)
+		return
+	}
+	if topic != "" {
+		log.Printf("Message %s routed to %s\n", c.actor(msg), topic)
+	}
+}
+
+// isDuplicate reports an order stored before, delivery is at least once: a message is read again after a crash,
+// a lost assignment or a seek, and a transient failure may hide a successful insert
+func isDuplicate(err error) bool {
+	return repository.IsDuplicateKey(err) || errors.Is(err, service.ErrOrderExists)
+}
+
+func header(msg *Message, key string) string {
+	for _, h := range msg.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+// actor identifies the message in the audit log, e.g. kafka:Orders/0@42
+func (c *Consumer) actor(msg *Message) string {
+	return fmt.Sprintf("%s:%s/%d@%d", c.source.Name(), msg.TopicPartition.Topic, msg.TopicPartition.Partition, msg.TopicPartition.Offset)
 }
@@ -0,0 +1,113 @@
+package eventHandler
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"orderService/internal/kafka/decoder"
+	"orderService/internal/kafka/registry"
+	"orderService/internal/models"
+	"orderService/internal/service"
+	"orderService/internal/service/mocks"
+	"orderService/rpc/orderpb"
+	"os"
+	"testing"
+)
+
+const topic = "Orders"
+
+func newConsumer(t *testing.T, orderService *mocks.IOrderService) *Consumer {
+	schemas := registry.NewFileRegistry("registry/testdata/registry")
+	validator, err := registry.NewValidator(schemas)
+	require.NoError(t, err)
+	decoders, err := decoder.NewRegistry(validator, schemas, nil)
+	require.NoError(t, err)
+	return &Consumer{source: NewMemorySource(1), orderService: orderService, decoders: decoders, offsets: newOffsetTracker(), paused: newPausedPartitions(&fakePauser{}), stats: newConsumerStats(),
+		delayed: make(map[partitionKey]*delayedPartition), due: make(chan TopicPartition)}
+}
+
+func message(value []byte, headers ...Header) *Message {
+	return &Message{
+		TopicPartition: TopicPartition{Topic: topic, Partition: 0, Offset: 42},
+		Value:          value,
+		Headers:        headers,
+	}
+}
+
+func TestConsumer_handleMessage(t *testing.T) {
+	order, err := os.ReadFile("registry/testdata/order.json")
+	require.NoError(t, err)
+	uid := uuid.MustParse("4e9ad8fb-2611-46f9-9458-20b59253086b")
+
+	t.Run("SchemaVersionHeader", func(t *testing.T) {
+		mockService := new(mocks.IOrderService)
+		mockService.On("Create", mock.MatchedBy(func(o models.Order) bool { return o.Uid == uid }), "memory:Orders/0@42").Return(nil)
+
+		err := newConsumer(t, mockService).handleMessage(message(order, Header{Key: registry.SchemaVersionHeader, Value: []byte("1")}))
+
+		assert.Nil(t, err)
+		mockService.AssertNumberOfCalls(t, "Create", 1)
+	})
+
+	t.Run("WireFormat", func(t *testing.T) {
+		mockService := new(mocks.IOrderService)
+		mockService.On("Create", mock.MatchedBy(func(o models.Order) bool { return o.Uid == uid }), "memory:Orders/0@42").Return(nil)
+
+		err := newConsumer(t, mockService).handleMessage(message(registry.WireFormat(7, order)))
+
+		assert.Nil(t, err)
+		mockService.AssertNumberOfCalls(t, "Create", 1)
+	})
+
+	t.Run("ProtobufContentType", func(t *testing.T) {
+		mockService := new(mocks.IOrderService)
+		mockService.On("Create", mock.MatchedBy(func(o models.Order) bool { return o.Uid == uid && o.TrackNumber == "WBILMTESTTRACK" }), "memory:Orders/0@42").Return(nil)
+		value, err := proto.Marshal(&orderpb.Order{OrderUid: uid.String(), TrackNumber: "WBILMTESTTRACK"})
+		require.NoError(t, err)
+
+		err = newConsumer(t, mockService).handleMessage(message(value, Header{Key: decoder.ContentTypeHeader, Value: []byte(decoder.Protobuf)}))
+
+		assert.Nil(t, err)
+		mockService.AssertNumberOfCalls(t, "Create", 1)
+	})
+
+	t.Run("UnknownVersion", func(t *testing.T) {
+		mockService := new(mocks.IOrderService)
+
+		err := newConsumer(t, mockService).handleMessage(message(order, Header{Key: registry.SchemaVersionHeader, Value: []byte("3")}))
+
+		assert.ErrorIs(t, err, registry.ErrUnknownVersion)
+		mockService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("InvalidMessage", func(t *testing.T) {
+		mockService := new(mocks.IOrderService)
+
+		err := newConsumer(t, mockService).handleMessage(message([]byte(`{"order_uid":"4e9ad8fb-2611-46f9-9458-20b59253086b"}`)))
+
+		assert.ErrorIs(t, err, registry.ErrInvalidMessage)
+		mockService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestConsumer_handleBatch(t *testing.T) {
+	order, err := os.ReadFile("registry/testdata/order.json")
+	require.NoError(t, err)
+	first := message(order)
+	invalid := message([]byte(`{}`))
+	invalid.TopicPartition.Offset = 43
+	second := message(order)
+	second.TopicPartition.Offset = 44
+
+	mockService := new(mocks.IOrderService)
+	mockService.On("CreateBatch", mock.MatchedBy(func(orders []models.Order) bool { return len(orders) == 2 }), []string{"memory:Orders/0@42", "memory:Orders/0@44"}).
+		Return([]error{nil, service.ErrOrderExists})
+
+	newConsumer(t, mockService).handleBatch(context.Background(), []*Message{first, invalid, second})
+
+	mockService.AssertNumberOfCalls(t, "CreateBatch", 1)
+	mockService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,35 @@
+package eventHandler
+
+import (
+	"orderService/configs"
+	"orderService/internal/kafka/decoder"
+	"orderService/internal/kafka/registry"
+	"orderService/internal/models"
+)
+
+// Decoder turns messages into orders the way the Consumer does, for tools that read the topics themselves
+type Decoder struct {
+	decoders *decoder.Registry
+}
+
+func NewDecoder(cnf configs.Kafka) (Decoder, error) {
+	decoders, err := newDecoders(cnf)
+	if err != nil {
+		return Decoder{}, err
+	}
+	return Decoder{decoders: decoders}, nil
+}
+
+// Decode picks the decoder configured for the topic the message was read from first,
+// the topic a retry topic delays or the one named by the headers of a dead-lettered message
+func (d Decoder) Decode(msg *Message) (models.Order, error) {
+	topic := header(msg, OriginalTopicHeader)
+	if topic == "" {
+		topic, _ = stageOf(msg.TopicPartition.Topic)
+	}
+	dec, err := d.decoders.For(topic, header(msg, decoder.ContentTypeHeader))
+	if err != nil {
+		return models.Order{}, err
+	}
+	return dec.Decode(msg.Value, header(msg, registry.SchemaVersionHeader))
+}
@@ -0,0 +1,193 @@
+package decoder
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/hamba/avro/v2"
+	"orderService/api/schema"
+	"orderService/internal/kafka/registry"
+	"orderService/internal/models"
+	"sync"
+	"time"
+)
+
+// AvroDecoder reads orders written with api/schema/order.v1.avsc. A payload in the Confluent wire format
+// is decoded with the writer schema registered under its id, resolved against order.v1.avsc
+type AvroDecoder struct {
+	schema   avro.Schema
+	registry registry.Registry
+	resolved *resolvedSchemas
+}
+
+// resolvedSchemas keeps the reader schema resolved against each writer schema id
+type resolvedSchemas struct {
+	mu      sync.Mutex
+	schemas map[int]avro.Schema
+}
+
+// NewAvroDecoder builds the decoder, registry may be nil when wire format payloads are not expected
+func NewAvroDecoder(registry registry.Registry) (AvroDecoder, error) {
+	document, err := schema.Files.ReadFile("order.v1.avsc")
+	if err != nil {
+		return AvroDecoder{}, err
+	}
+	parsed, err := avro.Parse(string(document))
+	if err != nil {
+		return AvroDecoder{}, fmt.Errorf("failed to parse avro schema: %w", err)
+	}
+	return AvroDecoder{parsed, registry, &resolvedSchemas{schemas: make(map[int]avro.Schema)}}, nil
+}
+
+func (d AvroDecoder) Decode(value []byte, _ string) (models.Order, error) {
+	readerSchema, body := d.schema, value
+	if id, payload, ok := registry.SplitWireFormat(value); ok {
+		var err error
+		if readerSchema, err = d.resolve(id); err != nil {
+			return models.Order{}, err
+		}
+		body = payload
+	}
+
+	var order avroOrder
+	if err := avro.Unmarshal(readerSchema, body, &order); err != nil {
+		return models.Order{}, fmt.Errorf("failed to decode avro order: %w", err)
+	}
+	return order.toOrder()
+}
+
+// resolve returns the schema that reads a payload written with schema id into avroOrder
+func (d AvroDecoder) resolve(id int) (avro.Schema, error) {
+	if d.registry == nil {
+		return nil, fmt.Errorf("message references schema %d but no schema registry is configured", id)
+	}
+
+	d.resolved.mu.Lock()
+	defer d.resolved.mu.Unlock()
+	if s, ok := d.resolved.schemas[id]; ok {
+		return s, nil
+	}
+	writer, err := d.registry.AvroSchema(id)
+	if err != nil {
+		return nil, err
+	}
+	s, err := avro.NewSchemaCompatibility().Resolve(d.schema, writer)
+	if err != nil {
+		return nil, fmt.Errorf("avro schema %d cannot be read as order.v1.avsc: %w", id, err)
+	}
+	d.resolved.schemas[id] = s
+	return s, nil
+}
+
+type avroDelivery struct {
+	Name    string `avro:"name"`
+	Phone   string `avro:"phone"`
+	Zip     string `avro:"zip"`
+	City    string `avro:"city"`
+	Address string `avro:"address"`
+	Region  string `avro:"region"`
+	Email   string `avro:"email"`
+}
+
+type avroPayment struct {
+	Transaction  string `avro:"transaction"`
+	RequestID    string `avro:"request_id"`
+	Currency     string `avro:"currency"`
+	Provider     string `avro:"provider"`
+	Amount       int64  `avro:"amount"`
+	PaymentDt    int64  `avro:"payment_dt"`
+	Bank         string `avro:"bank"`
+	DeliveryCost int64  `avro:"delivery_cost"`
+	GoodsTotal   int64  `avro:"goods_total"`
+	CustomFee    int64  `avro:"custom_fee"`
+}
+
+type avroItem struct {
+	ChrtID      int64  `avro:"chrt_id"`
+	TrackNumber string `avro:"track_number"`
+	Price       int64  `avro:"price"`
+	RID         string `avro:"rid"`
+	Name        string `avro:"name"`
+	Sale        int64  `avro:"sale"`
+	Size        string `avro:"size"`
+	TotalPrice  int64  `avro:"total_price"`
+	NmID        int64  `avro:"nm_id"`
+	Brand       string `avro:"brand"`
+	Status      int64  `avro:"status"`
+}
+
+type avroOrder struct {
+	Uid               string       `avro:"order_uid"`
+	TrackNumber       string       `avro:"track_number"`
+	Entry             string       `avro:"entry"`
+	Delivery          avroDelivery `avro:"delivery"`
+	Payment           avroPayment  `avro:"payment"`
+	Items             []avroItem   `avro:"items"`
+	Locale            string       `avro:"locale"`
+	InternalSignature string       `avro:"internal_signature"`
+	CustomerID        string       `avro:"customer_id"`
+	DeliveryService   string       `avro:"delivery_service"`
+	ShardKey          string       `avro:"shardkey"`
+	SmID              int64        `avro:"sm_id"`
+	DateCreated       time.Time    `avro:"date_created"`
+	OofShard          string       `avro:"oof_shard"`
+}
+
+func (o avroOrder) toOrder() (models.Order, error) {
+	uid, err := uuid.Parse(o.Uid)
+	if err != nil {
+		return models.Order{}, fmt.Errorf("invalid order_uid %q: %w", o.Uid, err)
+	}
+
+	items := make([]models.Item, 0, len(o.Items))
+	for _, item := range o.Items {
+		items = append(items, models.Item{
+			ChrtID:      int(item.ChrtID),
+			TrackNumber: item.TrackNumber,
+			Price:       int(item.Price),
+			RID:         item.RID,
+			Name:        item.Name,
+			Sale:        int(item.Sale),
+			Size:        item.Size,
+			TotalPrice:  int(item.TotalPrice),
+			NmID:        int(item.NmID),
+			Brand:       item.Brand,
+			Status:      int(item.Status),
+		})
+	}
+
+	return models.Order{
+		Uid:               uid,
+		TrackNumber:       o.TrackNumber,
+		Entry:             o.Entry,
+		Locale:            o.Locale,
+		InternalSignature: o.InternalSignature,
+		CustomerID:        o.CustomerID,
+		DeliveryService:   o.DeliveryService,
+		ShardKey:          o.ShardKey,
+		SmID:              int(o.SmID),
+		DateCreated:       o.DateCreated,
+		OofShard:          o.OofShard,
+		Delivery: models.Delivery{
+			Name:    o.Delivery.Name,
+			Phone:   o.Delivery.Phone,
+			Zip:     o.Delivery.Zip,
+			City:    o.Delivery.City,
+			Address: o.Delivery.Address,
+			Region:  o.Delivery.Region,
+			Email:   o.Delivery.Email,
+		},
+		Payment: models.Payment{
+			Transaction:  o.Payment.Transaction,
+			RequestID:    o.Payment.RequestID,
+			Currency:     o.Payment.Currency,
+			Provider:     o.Payment.Provider,
+			Amount:       int(o.Payment.Amount),
+			PaymentDt:    int(o.Payment.PaymentDt),
+			Bank:         o.Payment.Bank,
+			DeliveryCost: int(o.Payment.DeliveryCost),
+			GoodsTotal:   int(o.Payment.GoodsTotal),
+			CustomFee:    int(o.Payment.CustomFee),
+		},
+		Items: items,
+	}, nil
+}
@@ -0,0 +1,91 @@
+package decoder
+
+import (
+	"fmt"
+	"mime"
+	"orderService/internal/kafka/registry"
+	"orderService/internal/models"
+)
+
+const (
+	// ContentTypeHeader names the Kafka header with the payload encoding
+	ContentTypeHeader = "content-type"
+
+	JSON     = "application/json"
+	Protobuf = "application/x-protobuf"
+	Avro     = "application/avro"
+)
+
+// Decoder maps a message payload to an order, version is the schema-version header
+type Decoder interface {
+	Decode(value []byte, version string) (models.Order, error)
+}
+
+// Registry picks the decoder of a message by its content-type header, then by the topic
+// configuration, JSON when neither is set
+type Registry struct {
+	decoders map[string]Decoder
+	topics   map[string]string
+}
+
+// NewRegistry registers the JSON, Protobuf and Avro decoders, schemas resolves the writer schema of
+// Avro wire format payloads and may be nil, topics maps a topic to its content type
+func NewRegistry(validator registry.Validator, schemas registry.Registry, topics map[string]string) (*Registry, error) {
+	avro, err := NewAvroDecoder(schemas)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Registry{decoders: make(map[string]Decoder), topics: make(map[string]string)}
+	r.Register(NewJSONDecoder(validator), JSON)
+	r.Register(ProtobufDecoder{}, Protobuf, "application/protobuf", "application/vnd.google.protobuf")
+	r.Register(avro, Avro, "avro/binary")
+
+	for topic, contentType := range topics {
+		normalized, err := normalize(contentType)
+		if err != nil {
+			return nil, fmt.Errorf("topic %s: %w", topic, err)
+		}
+		if _, ok := r.decoders[normalized]; !ok {
+			return nil, fmt.Errorf("topic %s: unsupported content type %q", topic, contentType)
+		}
+		r.topics[topic] = normalized
+	}
+	return r, nil
+}
+
+// Register makes a decoder available under one or more content types
+func (r *Registry) Register(decoder Decoder, contentTypes ...string) {
+	for _, contentType := range contentTypes {
+		r.decoders[contentType] = decoder
+	}
+}
+
+// For returns the decoder of a message read from topic with the given content-type header
+func (r *Registry) For(topic string, contentType string) (Decoder, error) {
+	if contentType == "" {
+		contentType = r.topics[topic]
+	}
+	if contentType == "" {
+		return r.decoders[JSON], nil
+	}
+
+	normalized, err := normalize(contentType)
+	if err != nil {
+		return nil, err
+	}
+	decoder, ok := r.decoders[normalized]
+	if !ok {
+		return nil, fmt.Errorf("unsupported content type %q", contentType)
+	}
+	return decoder, nil
+}
+
+// normalize drops parameters such as charset
+func normalize(contentType string) (string, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", fmt.Errorf("invalid content type %q: %w", contentType, err)
+	}
+	return mediaType, nil
+}
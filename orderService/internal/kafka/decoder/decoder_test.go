@@ -0,0 +1,190 @@
+package decoder
+
+import (
+	"github.com/hamba/avro/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"orderService/internal/kafka/registry"
+	"orderService/internal/mapping"
+	"orderService/internal/models"
+	"os"
+	"testing"
+)
+
+func newRegistry(t *testing.T, topics map[string]string) *Registry {
+	schemas := registry.NewFileRegistry("../registry/testdata/registry")
+	validator, err := registry.NewValidator(schemas)
+	require.NoError(t, err)
+	r, err := NewRegistry(validator, schemas, topics)
+	require.NoError(t, err)
+	return r
+}
+
+// fixture is the order of ../registry/testdata/order.json
+func fixture(t *testing.T) ([]byte, models.Order) {
+	value, err := os.ReadFile("../registry/testdata/order.json")
+	require.NoError(t, err)
+	var order models.Order
+	require.NoError(t, order.UnmarshalJSON(value))
+	return value, order
+}
+
+func toAvro(order models.Order) avroOrder {
+	items := make([]avroItem, 0, len(order.Items))
+	for _, item := range order.Items {
+		items = append(items, avroItem{
+			ChrtID:      int64(item.ChrtID),
+			TrackNumber: item.TrackNumber,
+			Price:       int64(item.Price),
+			RID:         item.RID,
+			Name:        item.Name,
+			Sale:        int64(item.Sale),
+			Size:        item.Size,
+			TotalPrice:  int64(item.TotalPrice),
+			NmID:        int64(item.NmID),
+			Brand:       item.Brand,
+			Status:      int64(item.Status),
+		})
+	}
+	return avroOrder{
+		Uid:               order.Uid.String(),
+		TrackNumber:       order.TrackNumber,
+		Entry:             order.Entry,
+		Delivery:          avroDelivery{order.Delivery.Name, order.Delivery.Phone, order.Delivery.Zip, order.Delivery.City, order.Delivery.Address, order.Delivery.Region, order.Delivery.Email},
+		Payment:           avroPayment{order.Payment.Transaction, order.Payment.RequestID, order.Payment.Currency, order.Payment.Provider, int64(order.Payment.Amount), int64(order.Payment.PaymentDt), order.Payment.Bank, int64(order.Payment.DeliveryCost), int64(order.Payment.GoodsTotal), int64(order.Payment.CustomFee)},
+		Items:             items,
+		Locale:            order.Locale,
+		InternalSignature: order.InternalSignature,
+		CustomerID:        order.CustomerID,
+		DeliveryService:   order.DeliveryService,
+		ShardKey:          order.ShardKey,
+		SmID:              int64(order.SmID),
+		DateCreated:       order.DateCreated,
+		OofShard:          order.OofShard,
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	value, order := fixture(t)
+	r := newRegistry(t, nil)
+
+	protobuf, err := proto.Marshal(mapping.ToOrderPb(order))
+	require.NoError(t, err)
+	avroDecoder, err := NewAvroDecoder(nil)
+	require.NoError(t, err)
+	avroValue, err := avro.Marshal(avroDecoder.schema, toAvro(order))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		contentType string
+		value       []byte
+	}{
+		{"JSON", JSON, value},
+		{"JSONWithCharset", "application/json; charset=utf-8", value},
+		{"DefaultsToJSON", "", value},
+		{"Protobuf", Protobuf, protobuf},
+		// Confluent protobuf serializers write the message indexes after the schema id, [0] as a single 0 byte
+		{"ProtobufWireFormat", "application/protobuf", registry.WireFormat(3, append([]byte{0}, protobuf...))},
+		{"ProtobufWireFormatIndexes", Protobuf, registry.WireFormat(3, append([]byte{2, 6}, protobuf...))},
+		{"Avro", Avro, avroValue},
+		{"AvroWireFormat", "avro/binary", registry.WireFormat(5, avroValue)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d, err := r.For("Orders", test.contentType)
+			require.NoError(t, err)
+
+			actual, err := d.Decode(test.value, "")
+
+			assert.Nil(t, err)
+			assert.Equal(t, order, actual)
+		})
+	}
+}
+
+func TestAvroDecoder_WriterSchema(t *testing.T) {
+	_, order := fixture(t)
+	d, err := NewAvroDecoder(registry.NewFileRegistry("../registry/testdata/registry"))
+	require.NoError(t, err)
+
+	t.Run("EvolvedWriterSchema", func(t *testing.T) {
+		// schema 6 adds gift_message after track_number, order.v1.avsc skips it
+		writer, err := avro.ParseFiles("../registry/testdata/registry/6.avsc")
+		require.NoError(t, err)
+		value, err := avro.Marshal(writer, struct {
+			avroOrder
+			GiftMessage string `avro:"gift_message"`
+		}{toAvro(order), "Happy birthday"})
+		require.NoError(t, err)
+
+		actual, err := d.Decode(registry.WireFormat(6, value), "")
+
+		assert.Nil(t, err)
+		assert.Equal(t, order, actual)
+	})
+
+	t.Run("UnregisteredSchema", func(t *testing.T) {
+		_, err := d.Decode(registry.WireFormat(8, []byte{0}), "")
+
+		assert.ErrorContains(t, err, "schema 8 is not registered")
+	})
+
+	t.Run("NoRegistry", func(t *testing.T) {
+		d, err := NewAvroDecoder(nil)
+		require.NoError(t, err)
+
+		_, err = d.Decode(registry.WireFormat(5, []byte{0}), "")
+
+		assert.ErrorContains(t, err, "no schema registry is configured")
+	})
+}
+
+func TestRegistry_For(t *testing.T) {
+	r := newRegistry(t, map[string]string{"Orders.proto": Protobuf})
+
+	t.Run("TopicContentType", func(t *testing.T) {
+		d, err := r.For("Orders.proto", "")
+
+		assert.Nil(t, err)
+		assert.IsType(t, ProtobufDecoder{}, d)
+	})
+
+	t.Run("HeaderOverridesTopic", func(t *testing.T) {
+		d, err := r.For("Orders.proto", JSON)
+
+		assert.Nil(t, err)
+		assert.IsType(t, JSONDecoder{}, d)
+	})
+
+	t.Run("UnsupportedContentType", func(t *testing.T) {
+		_, err := r.For("Orders", "text/csv")
+
+		assert.EqualError(t, err, `unsupported content type "text/csv"`)
+	})
+
+	t.Run("UnsupportedTopicContentType", func(t *testing.T) {
+		validator, err := registry.NewValidator(nil)
+		require.NoError(t, err)
+
+		_, err = NewRegistry(validator, nil, map[string]string{"Orders.xml": "application/xml"})
+
+		assert.EqualError(t, err, `topic Orders.xml: unsupported content type "application/xml"`)
+	})
+}
+
+func TestDecoders_Invalid(t *testing.T) {
+	r := newRegistry(t, nil)
+
+	for _, contentType := range []string{JSON, Protobuf, Avro} {
+		t.Run(contentType, func(t *testing.T) {
+			d, err := r.For("Orders", contentType)
+			require.NoError(t, err)
+
+			_, err = d.Decode([]byte{0xff, 0xff, 0xff}, "")
+
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package decoder
+
+import (
+	"orderService/internal/kafka/registry"
+	"orderService/internal/models"
+)
+
+// JSONDecoder validates the payload against the order JSON Schema before unmarshalling it
+type JSONDecoder struct {
+	validator registry.Validator
+}
+
+func NewJSONDecoder(validator registry.Validator) JSONDecoder {
+	return JSONDecoder{validator}
+}
+
+func (d JSONDecoder) Decode(value []byte, version string) (models.Order, error) {
+	var order models.Order
+	body, err := d.validator.Validate(value, version)
+	if err != nil {
+		return order, err
+	}
+	err = order.UnmarshalJSON(body)
+	return order, err
+}
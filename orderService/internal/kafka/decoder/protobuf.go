@@ -0,0 +1,46 @@
+package decoder
+
+import (
+	"fmt"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"orderService/internal/kafka/registry"
+	"orderService/internal/mapping"
+	"orderService/internal/models"
+	"orderService/rpc/orderpb"
+)
+
+// ProtobufDecoder reads order.v1.Order from api/proto, plain or in the Confluent wire format
+type ProtobufDecoder struct{}
+
+func (ProtobufDecoder) Decode(value []byte, _ string) (models.Order, error) {
+	if _, body, ok := registry.SplitWireFormat(value); ok {
+		var err error
+		if value, err = skipMessageIndexes(body); err != nil {
+			return models.Order{}, err
+		}
+	}
+
+	var order orderpb.Order
+	if err := proto.Unmarshal(value, &order); err != nil {
+		return models.Order{}, fmt.Errorf("failed to decode protobuf order: %w", err)
+	}
+	return mapping.ToOrder(&order), nil
+}
+
+// skipMessageIndexes drops the zigzag varint list locating the message type in the registered
+// .proto file, the Orders topic only carries order.v1.Order so the type is not looked up
+func skipMessageIndexes(body []byte) ([]byte, error) {
+	count, n := protowire.ConsumeVarint(body)
+	if n < 0 {
+		return nil, fmt.Errorf("failed to read protobuf message indexes: %w", protowire.ParseError(n))
+	}
+	body = body[n:]
+	for range protowire.DecodeZigZag(count) {
+		if _, n = protowire.ConsumeVarint(body); n < 0 {
+			return nil, fmt.Errorf("failed to read protobuf message indexes: %w", protowire.ParseError(n))
+		}
+		body = body[n:]
+	}
+	return body, nil
+}
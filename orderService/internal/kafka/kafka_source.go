@@ -0,0 +1,231 @@
+package eventHandler
+
+import (
+	"fmt"
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"log"
+	"orderService/configs"
+	"time"
+)
+
+// kafkaRequestTimeout bounds the requests of the admin endpoints to the brokers, in milliseconds
+const kafkaRequestTimeout = 5000
+
+// KafkaSource reads the subscribed topics as a member of the configured consumer group
+type KafkaSource struct {
+	consumer *kafka.Consumer
+}
+
+func NewKafkaSource(cnf configs.Kafka) (*KafkaSource, error) {
+	consumer, err := kafka.NewConsumer(consumerConfig(cnf))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kafka consumer: %w", err)
+	}
+	return &KafkaSource{consumer: consumer}, nil
+}
+
+// NewKafkaReplaySource reads partitions assigned with Assign, it never joins or commits for the consumer group
+// of the service, so replaying a range does not move the position of the running consumers
+func NewKafkaReplaySource(cnf configs.Kafka) (*KafkaSource, error) {
+	properties := consumerConfig(cnf)
+	properties.SetKey("group.id", cnf.GroupID+"-replay")
+	// an offset below the low watermark is read from the earliest retained message, the replay checks it beforehand
+	properties.SetKey("auto.offset.reset", "earliest")
+	consumer, err := kafka.NewConsumer(properties)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kafka consumer: %w", err)
+	}
+	return &KafkaSource{consumer: consumer}, nil
+}
+
+func (s *KafkaSource) Name() string {
+	return "kafka"
+}
+
+func (s *KafkaSource) Subscribe(topics []string, revoked func(partitions []TopicPartition, lost bool)) error {
+	return s.consumer.SubscribeTopics(topics, func(consumer *kafka.Consumer, event kafka.Event) error {
+		switch e := event.(type) {
+		case kafka.AssignedPartitions:
+			log.Printf("Kafka partitions assigned: %v\n", e.Partitions)
+		case kafka.RevokedPartitions:
+			revoked(fromKafkaPartitions(e.Partitions), consumer.AssignmentLost())
+		}
+		return nil
+	})
+}
+
+// Poll returns the errors reported by the client, they are informational unless fatal
+func (s *KafkaSource) Poll(timeout time.Duration) (*Message, error) {
+	switch e := s.consumer.Poll(int(timeout.Milliseconds())).(type) {
+	case *kafka.Message:
+		if e.TopicPartition.Error != nil {
+			return nil, e.TopicPartition.Error
+		}
+		return fromKafkaMessage(e), nil
+	case kafka.Error:
+		return nil, e
+	}
+	return nil, nil
+}
+
+// Assign reads the partitions from their offsets instead of the subscribed topics
+func (s *KafkaSource) Assign(partitions []TopicPartition) error {
+	return s.consumer.Assign(toKafkaPartitions(partitions))
+}
+
+func (s *KafkaSource) Commit(offsets []TopicPartition) error {
+	_, err := s.consumer.CommitOffsets(toKafkaPartitions(offsets))
+	return err
+}
+
+func (s *KafkaSource) Pause(partitions []TopicPartition) error {
+	return s.consumer.Pause(toKafkaPartitions(partitions))
+}
+
+func (s *KafkaSource) Resume(partitions []TopicPartition) error {
+	return s.consumer.Resume(toKafkaPartitions(partitions))
+}
+
+func (s *KafkaSource) Close() error {
+	return s.consumer.Close()
+}
+
+func (s *KafkaSource) Assignment() ([]TopicPartition, error) {
+	partitions, err := s.consumer.Assignment()
+	if err != nil {
+		return nil, err
+	}
+	return fromKafkaPartitions(partitions), nil
+}
+
+func (s *KafkaSource) Committed(partitions []TopicPartition) ([]TopicPartition, error) {
+	offsets, err := s.consumer.Committed(toKafkaPartitions(partitions), kafkaRequestTimeout)
+	if err != nil {
+		return nil, err
+	}
+	committed := fromKafkaPartitions(offsets)
+	for i := range committed {
+		if committed[i].Offset < 0 {
+			committed[i].Offset = offsetUnset
+		}
+	}
+	return committed, nil
+}
+
+// LowWatermark returns the offset of the earliest message retained in the partition
+func (s *KafkaSource) LowWatermark(tp TopicPartition) (int64, error) {
+	low, _, err := s.consumer.QueryWatermarkOffsets(tp.Topic, tp.Partition, kafkaRequestTimeout)
+	return low, err
+}
+
+func (s *KafkaSource) HighWatermark(tp TopicPartition) (int64, error) {
+	_, high, err := s.consumer.QueryWatermarkOffsets(tp.Topic, tp.Partition, kafkaRequestTimeout)
+	return high, err
+}
+
+func (s *KafkaSource) OffsetForTime(tp TopicPartition, ts time.Time) (int64, error) {
+	tp.Offset = ts.UnixMilli()
+	offsets, err := s.consumer.OffsetsForTimes(toKafkaPartitions([]TopicPartition{tp}), kafkaRequestTimeout)
+	if err != nil {
+		return 0, err
+	}
+	if len(offsets) != 1 || offsets[0].Error != nil {
+		return 0, fmt.Errorf("failed to look up the offset of %s [%d] at %s: %v", tp.Topic, tp.Partition, ts.Format(time.RFC3339), offsets)
+	}
+	if offsets[0].Offset == kafka.OffsetEnd {
+		return s.HighWatermark(tp)
+	}
+	return int64(offsets[0].Offset), nil
+}
+
+func (s *KafkaSource) Seek(tp TopicPartition) error {
+	partitions, err := s.consumer.SeekPartitions(toKafkaPartitions([]TopicPartition{tp}))
+	if err != nil {
+		return err
+	}
+	for _, p := range partitions {
+		if p.Error != nil {
+			return p.Error
+		}
+	}
+	return nil
+}
+
+type producer interface {
+	Produce(msg *kafka.Message, deliveryChan chan kafka.Event) error
+	Flush(timeoutMs int) int
+	Close()
+}
+
+// KafkaSink produces republished messages with the consumer's connection settings
+type KafkaSink struct {
+	producer producer
+}
+
+func NewKafkaSink(cnf configs.Kafka) (*KafkaSink, error) {
+	p, err := kafka.NewProducer(producerConfig(cnf))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kafka producer: %w", err)
+	}
+	return &KafkaSink{producer: p}, nil
+}
+
+// Publish waits for the delivery report of the message
+func (s *KafkaSink) Publish(topic string, msg Message) error {
+	headers := make([]kafka.Header, 0, len(msg.Headers))
+	for _, h := range msg.Headers {
+		headers = append(headers, kafka.Header{Key: h.Key, Value: h.Value})
+	}
+
+	delivery := make(chan kafka.Event, 1)
+	err := s.producer.Produce(&kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: kafka.PartitionAny},
+		Key:            msg.Key,
+		Value:          msg.Value,
+		Headers:        headers,
+	}, delivery)
+	if err != nil {
+		return err
+	}
+	if report, ok := (<-delivery).(*kafka.Message); ok && report.TopicPartition.Error != nil {
+		return report.TopicPartition.Error
+	}
+	return nil
+}
+
+func (s *KafkaSink) Close() error {
+	s.producer.Flush(5000)
+	s.producer.Close()
+	return nil
+}
+
+func fromKafkaMessage(msg *kafka.Message) *Message {
+	headers := make([]Header, 0, len(msg.Headers))
+	for _, h := range msg.Headers {
+		headers = append(headers, Header{Key: h.Key, Value: h.Value})
+	}
+	return &Message{
+		TopicPartition: TopicPartition{Topic: *msg.TopicPartition.Topic, Partition: msg.TopicPartition.Partition, Offset: int64(msg.TopicPartition.Offset)},
+		Key:            msg.Key,
+		Value:          msg.Value,
+		Headers:        headers,
+		Timestamp:      msg.Timestamp,
+	}
+}
+
+func fromKafkaPartitions(partitions []kafka.TopicPartition) []TopicPartition {
+	converted := make([]TopicPartition, 0, len(partitions))
+	for _, tp := range partitions {
+		converted = append(converted, TopicPartition{Topic: *tp.Topic, Partition: tp.Partition, Offset: int64(tp.Offset)})
+	}
+	return converted
+}
+
+func toKafkaPartitions(partitions []TopicPartition) []kafka.TopicPartition {
+	converted := make([]kafka.TopicPartition, 0, len(partitions))
+	for _, tp := range partitions {
+		topic := tp.Topic
+		converted = append(converted, kafka.TopicPartition{Topic: &topic, Partition: tp.Partition, Offset: kafka.Offset(tp.Offset)})
+	}
+	return converted
+}
@@ -0,0 +1,334 @@
+package eventHandler
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"sync"
+	"time"
+)
+
+var ErrSourceClosed = errors.New("message source closed")
+
+// MemorySource is a broker kept in memory for tests and local runs without Kafka. Every topic has the same
+// number of partitions, a message goes to the partition of its key and to partition 0 without one.
+// It is its own MessageSink, so republished messages can be consumed again
+type MemorySource struct {
+	mu         sync.Mutex
+	partitions int
+	topics     map[string][][]Message
+	subscribed []string
+	// assignment replaces the partitions of the subscribed topics once Assign is called
+	assignment []TopicPartition
+	revoked    func(partitions []TopicPartition, lost bool)
+	positions  map[partitionKey]int64
+	committed  map[partitionKey]int64
+	paused     map[partitionKey]bool
+	// cursor rotates polling over the partitions so a busy one does not starve the others
+	cursor    int
+	rebalance bool
+	closed    bool
+	// arrived is closed and replaced whenever a message may have become available
+	arrived chan struct{}
+}
+
+func NewMemorySource(partitions int) *MemorySource {
+	return &MemorySource{
+		partitions: max(partitions, 1),
+		topics:     make(map[string][][]Message),
+		positions:  make(map[partitionKey]int64),
+		committed:  make(map[partitionKey]int64),
+		paused:     make(map[partitionKey]bool),
+		arrived:    make(chan struct{}),
+	}
+}
+
+func (s *MemorySource) Name() string {
+	return "memory"
+}
+
+// Produce appends the message to the topic, it returns where the message was stored
+func (s *MemorySource) Produce(topic string, msg Message) TopicPartition {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	partition := int32(0)
+	if len(msg.Key) > 0 {
+		h := fnv.New32a()
+		h.Write(msg.Key)
+		partition = int32(h.Sum32() % uint32(s.partitions))
+	}
+	log := s.topic(topic)
+	msg.TopicPartition = TopicPartition{Topic: topic, Partition: partition, Offset: int64(len(log[partition]))}
+	if msg.Timestamp.IsZero() {
+		msg.Timestamp = time.Now()
+	}
+	log[partition] = append(log[partition], msg)
+	s.wake()
+	return msg.TopicPartition
+}
+
+func (s *MemorySource) Publish(topic string, msg Message) error {
+	s.Produce(topic, msg)
+	return nil
+}
+
+// Messages returns the messages of every partition of the topic
+func (s *MemorySource) Messages(topic string) []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var messages []Message
+	for _, partition := range s.topics[topic] {
+		messages = append(messages, partition...)
+	}
+	return messages
+}
+
+// CommittedOffset returns the committed offset of the partition, -1 when none was committed
+func (s *MemorySource) CommittedOffset(topic string, partition int32) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if offset, ok := s.committed[partitionKey{topic, partition}]; ok {
+		return offset
+	}
+	return offsetUnset
+}
+
+// Rebalance revokes every partition on the next poll and assigns it back from its committed offset,
+// as a consumer group does when a member joins
+func (s *MemorySource) Rebalance() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rebalance = true
+	s.wake()
+}
+
+// Subscribe reads the topics from their committed offsets, from the beginning when none was committed
+func (s *MemorySource) Subscribe(topics []string, revoked func(partitions []TopicPartition, lost bool)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.subscribed = append([]string(nil), topics...)
+	s.revoked = revoked
+	for _, topic := range topics {
+		s.topic(topic)
+	}
+	s.assign()
+	return nil
+}
+
+// Assign reads the partitions from their offsets instead of the subscribed topics
+func (s *MemorySource) Assign(partitions []TopicPartition) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.assignment = make([]TopicPartition, 0, len(partitions))
+	clear(s.positions)
+	for _, tp := range partitions {
+		if tp.Partition < 0 || int(tp.Partition) >= s.partitions {
+			return fmt.Errorf("partition %s [%d] does not exist", tp.Topic, tp.Partition)
+		}
+		s.topic(tp.Topic)
+		s.assignment = append(s.assignment, partitionOf(tp))
+		s.positions[keyOf(tp)] = tp.Offset
+	}
+	s.wake()
+	return nil
+}
+
+func (s *MemorySource) Poll(timeout time.Duration) (*Message, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			return nil, ErrSourceClosed
+		}
+		if s.rebalance {
+			s.rebalance = false
+			revoked := s.assigned()
+			s.mu.Unlock()
+			// the consumer commits the revoked partitions, the lock must not be held meanwhile
+			s.revoked(revoked, false)
+			s.mu.Lock()
+			s.assign()
+		}
+		msg := s.next()
+		arrived := s.arrived
+		s.mu.Unlock()
+
+		if msg != nil {
+			return msg, nil
+		}
+		select {
+		case <-arrived:
+		case <-timer.C:
+			return nil, nil
+		}
+	}
+}
+
+func (s *MemorySource) Commit(offsets []TopicPartition) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, tp := range offsets {
+		s.committed[keyOf(tp)] = tp.Offset
+	}
+	return nil
+}
+
+func (s *MemorySource) Pause(partitions []TopicPartition) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, tp := range partitions {
+		s.paused[keyOf(tp)] = true
+	}
+	return nil
+}
+
+func (s *MemorySource) Resume(partitions []TopicPartition) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, tp := range partitions {
+		delete(s.paused, keyOf(tp))
+	}
+	s.wake()
+	return nil
+}
+
+func (s *MemorySource) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	s.wake()
+	return nil
+}
+
+func (s *MemorySource) Assignment() ([]TopicPartition, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.assigned(), nil
+}
+
+func (s *MemorySource) Committed(partitions []TopicPartition) ([]TopicPartition, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	committed := make([]TopicPartition, 0, len(partitions))
+	for _, tp := range partitions {
+		tp.Offset = offsetUnset
+		if offset, ok := s.committed[keyOf(tp)]; ok {
+			tp.Offset = offset
+		}
+		committed = append(committed, tp)
+	}
+	return committed, nil
+}
+
+// LowWatermark is always 0, the memory source retains every message
+func (s *MemorySource) LowWatermark(tp TopicPartition) (int64, error) {
+	return 0, nil
+}
+
+func (s *MemorySource) HighWatermark(tp TopicPartition) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return int64(len(s.partition(tp))), nil
+}
+
+func (s *MemorySource) OffsetForTime(tp TopicPartition, ts time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	log := s.partition(tp)
+	offset, _ := slices.BinarySearchFunc(log, ts, func(msg Message, ts time.Time) int {
+		return msg.Timestamp.Compare(ts)
+	})
+	return int64(offset), nil
+}
+
+func (s *MemorySource) Seek(tp TopicPartition) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := keyOf(tp)
+	if _, ok := s.positions[key]; !ok {
+		return fmt.Errorf("partition %s [%d] is not assigned", tp.Topic, tp.Partition)
+	}
+	s.positions[key] = tp.Offset
+	s.wake()
+	return nil
+}
+
+func (s *MemorySource) partition(tp TopicPartition) []Message {
+	partitions := s.topics[tp.Topic]
+	if int(tp.Partition) >= len(partitions) || tp.Partition < 0 {
+		return nil
+	}
+	return partitions[tp.Partition]
+}
+
+func (s *MemorySource) topic(name string) [][]Message {
+	log, ok := s.topics[name]
+	if !ok {
+		log = make([][]Message, s.partitions)
+		s.topics[name] = log
+	}
+	return log
+}
+
+// assigned lists the partitions of the subscribed topics in polling order
+func (s *MemorySource) assigned() []TopicPartition {
+	if s.assignment != nil {
+		return slices.Clone(s.assignment)
+	}
+	partitions := make([]TopicPartition, 0, len(s.subscribed)*s.partitions)
+	for _, topic := range s.subscribed {
+		for partition := range s.partitions {
+			partitions = append(partitions, TopicPartition{Topic: topic, Partition: int32(partition)})
+		}
+	}
+	return partitions
+}
+
+func (s *MemorySource) assign() {
+	clear(s.positions)
+	for _, tp := range s.assigned() {
+		s.positions[keyOf(tp)] = max(s.committed[keyOf(tp)], 0)
+	}
+}
+
+func (s *MemorySource) next() *Message {
+	partitions := s.assigned()
+	for i := range partitions {
+		key := keyOf(partitions[(s.cursor+i)%len(partitions)])
+		log := s.topics[key.topic][key.partition]
+		position := s.positions[key]
+		if s.paused[key] || position >= int64(len(log)) {
+			continue
+		}
+		s.positions[key] = position + 1
+		s.cursor = (s.cursor + i + 1) % len(partitions)
+		msg := log[position]
+		msg.Headers = slices.Clone(msg.Headers)
+		return &msg
+	}
+	return nil
+}
+
+func (s *MemorySource) wake() {
+	close(s.arrived)
+	s.arrived = make(chan struct{})
+}
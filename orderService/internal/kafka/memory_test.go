@@ -0,0 +1,149 @@
+package eventHandler
+
+import (
+	"bytes"
+	"context"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"orderService/configs"
+	"orderService/internal/cache"
+	"orderService/internal/events"
+	repo "orderService/internal/repository/mocks"
+	"orderService/internal/service"
+	audit "orderService/internal/service/mocks"
+	"os"
+	"testing"
+	"time"
+)
+
+func poll(t *testing.T, source *MemorySource) *Message {
+	msg, err := source.Poll(10 * time.Millisecond)
+	require.NoError(t, err)
+	return msg
+}
+
+func TestMemorySource(t *testing.T) {
+	source := NewMemorySource(1)
+	var revoked []TopicPartition
+	require.NoError(t, source.Subscribe([]string{topic}, func(partitions []TopicPartition, lost bool) {
+		revoked = append(revoked, partitions...)
+	}))
+	for _, value := range []string{"a", "b", "c"} {
+		source.Produce(topic, Message{Value: []byte(value)})
+	}
+
+	assert.Equal(t, "a", string(poll(t, source).Value))
+
+	require.NoError(t, source.Pause([]TopicPartition{tp(0, 0)}))
+	assert.Nil(t, poll(t, source))
+	require.NoError(t, source.Resume([]TopicPartition{tp(0, 0)}))
+	assert.Equal(t, "b", string(poll(t, source).Value))
+
+	require.NoError(t, source.Commit([]TopicPartition{tp(0, 1)}))
+	assert.Equal(t, int64(1), source.CommittedOffset(topic, 0))
+
+	// the rebalance rewinds the partition to the committed offset
+	source.Rebalance()
+	msg := poll(t, source)
+	assert.Equal(t, []TopicPartition{tp(0, 0)}, revoked)
+	assert.Equal(t, tp(0, 1), msg.TopicPartition)
+	assert.Equal(t, "c", string(poll(t, source).Value))
+	assert.Nil(t, poll(t, source))
+
+	require.NoError(t, source.Close())
+	_, err := source.Poll(time.Millisecond)
+	assert.ErrorIs(t, err, ErrSourceClosed)
+}
+
+// newPipelineService is the order service a deployment uses, only the database is mocked
+func newPipelineService() (service.OrderService, *repo.IOrderRepository, cache.OrderLRuCache) {
+	mockRepo := new(repo.IOrderRepository)
+	mockRepo.On("Create", mock.Anything).Return(nil)
+	mockAudit := new(audit.IAuditService)
+	mockAudit.On("Record", mock.Anything).Return()
+	lruCache := cache.NewCache(10, 60)
+	return service.NewService(mockRepo, lruCache, events.NewBus(10), mockAudit), mockRepo, lruCache
+}
+
+var pipelineConfig = configs.Kafka{
+	Topics:            []string{topic},
+	Concurrency:       2,
+	Ordering:          OrderingPartition,
+	CommitInterval:    10,
+	BatchSize:         1,
+	Retry:             1,
+	Backoff:           1,
+	DeadLetterTopic:   "Orders.dlq",
+	SchemaRegistryDir: "registry/testdata/registry",
+}
+
+var pipelineOrders = []uuid.UUID{
+	uuid.MustParse("4e9ad8fb-2611-46f9-9458-20b59253086b"),
+	uuid.MustParse("9a1f4c36-5e2b-4d1c-8f7a-0b6c2d3e4f51"),
+}
+
+// pipelineMessages are the orders of pipelineOrders followed by an invalid message
+func pipelineMessages(t *testing.T) []Message {
+	order, err := os.ReadFile("registry/testdata/order.json")
+	require.NoError(t, err)
+
+	var messages []Message
+	for _, uid := range pipelineOrders {
+		value := bytes.ReplaceAll(order, []byte(pipelineOrders[0].String()), []byte(uid.String()))
+		messages = append(messages, Message{Key: []byte(uid.String()), Value: value})
+	}
+	return append(messages, Message{Key: []byte("broken"), Value: []byte(`{"order_uid":"broken"}`)})
+}
+
+// runPipeline starts the consumer and stops it once processed reports every message done
+func runPipeline(t *testing.T, consumer *Consumer, processed func() bool) {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		consumer.Start(ctx)
+		close(stopped)
+	}()
+	require.Eventually(t, processed, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	<-stopped
+}
+
+func assertPipeline(t *testing.T, mockRepo *repo.IOrderRepository, lruCache cache.OrderLRuCache, deadLetters []Message) {
+	mockRepo.AssertNumberOfCalls(t, "Create", len(pipelineOrders))
+	for _, uid := range pipelineOrders {
+		view, ok := lruCache.Get(uid.String())
+		assert.True(t, ok, uid)
+		assert.Equal(t, uid, view.Uid)
+	}
+
+	require.Len(t, deadLetters, 1)
+	assert.Equal(t, `{"order_uid":"broken"}`, string(deadLetters[0].Value))
+	assert.Equal(t, topic, headers(deadLetters[0])[OriginalTopicHeader])
+	assert.NotEmpty(t, headers(deadLetters[0])[ErrorHeader])
+}
+
+// TestConsumer_Pipeline runs the messages through decoding, validation, storage and the cache
+func TestConsumer_Pipeline(t *testing.T) {
+	orderService, mockRepo, lruCache := newPipelineService()
+	source := NewMemorySource(2)
+	consumer, err := NewConsumer(source, source, orderService, pipelineConfig)
+	require.NoError(t, err)
+
+	var produced []TopicPartition
+	for _, msg := range pipelineMessages(t) {
+		produced = append(produced, source.Produce(topic, msg))
+	}
+
+	runPipeline(t, consumer, func() bool {
+		for _, tp := range produced {
+			if source.CommittedOffset(tp.Topic, tp.Partition) <= tp.Offset {
+				return false
+			}
+		}
+		return true
+	})
+
+	assertPipeline(t, mockRepo, lruCache, source.Messages("Orders.dlq"))
+}
@@ -0,0 +1,315 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	eventHandler "orderService/internal/kafka"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// IConsumerAdmin is an autogenerated mock type for the IConsumerAdmin type
+type IConsumerAdmin struct {
+	mock.Mock
+}
+
+type IConsumerAdmin_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *IConsumerAdmin) EXPECT() *IConsumerAdmin_Expecter {
+	return &IConsumerAdmin_Expecter{mock: &_m.Mock}
+}
+
+// Pause provides a mock function with given fields: partitions
+func (_m *IConsumerAdmin) Pause(partitions []eventHandler.TopicPartition) ([]eventHandler.TopicPartition, error) {
+	ret := _m.Called(partitions)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Pause")
+	}
+
+	var r0 []eventHandler.TopicPartition
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]eventHandler.TopicPartition) ([]eventHandler.TopicPartition, error)); ok {
+		return rf(partitions)
+	}
+	if rf, ok := ret.Get(0).(func([]eventHandler.TopicPartition) []eventHandler.TopicPartition); ok {
+		r0 = rf(partitions)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]eventHandler.TopicPartition)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]eventHandler.TopicPartition) error); ok {
+		r1 = rf(partitions)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IConsumerAdmin_Pause_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Pause'
+type IConsumerAdmin_Pause_Call struct {
+	*mock.Call
+}
+
+// Pause is a helper method to define mock.On call
+//   - partitions []eventHandler.TopicPartition
+func (_e *IConsumerAdmin_Expecter) Pause(partitions interface{}) *IConsumerAdmin_Pause_Call {
+	return &IConsumerAdmin_Pause_Call{Call: _e.mock.On("Pause", partitions)}
+}
+
+func (_c *IConsumerAdmin_Pause_Call) Run(run func(partitions []eventHandler.TopicPartition)) *IConsumerAdmin_Pause_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].([]eventHandler.TopicPartition))
+	})
+	return _c
+}
+
+func (_c *IConsumerAdmin_Pause_Call) Return(_a0 []eventHandler.TopicPartition, _a1 error) *IConsumerAdmin_Pause_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IConsumerAdmin_Pause_Call) RunAndReturn(run func([]eventHandler.TopicPartition) ([]eventHandler.TopicPartition, error)) *IConsumerAdmin_Pause_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Resume provides a mock function with given fields: partitions
+func (_m *IConsumerAdmin) Resume(partitions []eventHandler.TopicPartition) ([]eventHandler.TopicPartition, error) {
+	ret := _m.Called(partitions)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Resume")
+	}
+
+	var r0 []eventHandler.TopicPartition
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]eventHandler.TopicPartition) ([]eventHandler.TopicPartition, error)); ok {
+		return rf(partitions)
+	}
+	if rf, ok := ret.Get(0).(func([]eventHandler.TopicPartition) []eventHandler.TopicPartition); ok {
+		r0 = rf(partitions)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]eventHandler.TopicPartition)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]eventHandler.TopicPartition) error); ok {
+		r1 = rf(partitions)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IConsumerAdmin_Resume_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Resume'
+type IConsumerAdmin_Resume_Call struct {
+	*mock.Call
+}
+
+// Resume is a helper method to define mock.On call
+//   - partitions []eventHandler.TopicPartition
+func (_e *IConsumerAdmin_Expecter) Resume(partitions interface{}) *IConsumerAdmin_Resume_Call {
+	return &IConsumerAdmin_Resume_Call{Call: _e.mock.On("Resume", partitions)}
+}
+
+func (_c *IConsumerAdmin_Resume_Call) Run(run func(partitions []eventHandler.TopicPartition)) *IConsumerAdmin_Resume_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].([]eventHandler.TopicPartition))
+	})
+	return _c
+}
+
+func (_c *IConsumerAdmin_Resume_Call) Return(_a0 []eventHandler.TopicPartition, _a1 error) *IConsumerAdmin_Resume_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IConsumerAdmin_Resume_Call) RunAndReturn(run func([]eventHandler.TopicPartition) ([]eventHandler.TopicPartition, error)) *IConsumerAdmin_Resume_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Seek provides a mock function with given fields: ctx, tp
+func (_m *IConsumerAdmin) Seek(ctx context.Context, tp eventHandler.TopicPartition) error {
+	ret := _m.Called(ctx, tp)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Seek")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, eventHandler.TopicPartition) error); ok {
+		r0 = rf(ctx, tp)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// IConsumerAdmin_Seek_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Seek'
+type IConsumerAdmin_Seek_Call struct {
+	*mock.Call
+}
+
+// Seek is a helper method to define mock.On call
+//   - ctx context.Context
+//   - tp eventHandler.TopicPartition
+func (_e *IConsumerAdmin_Expecter) Seek(ctx interface{}, tp interface{}) *IConsumerAdmin_Seek_Call {
+	return &IConsumerAdmin_Seek_Call{Call: _e.mock.On("Seek", ctx, tp)}
+}
+
+func (_c *IConsumerAdmin_Seek_Call) Run(run func(ctx context.Context, tp eventHandler.TopicPartition)) *IConsumerAdmin_Seek_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(eventHandler.TopicPartition))
+	})
+	return _c
+}
+
+func (_c *IConsumerAdmin_Seek_Call) Return(_a0 error) *IConsumerAdmin_Seek_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *IConsumerAdmin_Seek_Call) RunAndReturn(run func(context.Context, eventHandler.TopicPartition) error) *IConsumerAdmin_Seek_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SeekToTime provides a mock function with given fields: ctx, tp, ts
+func (_m *IConsumerAdmin) SeekToTime(ctx context.Context, tp eventHandler.TopicPartition, ts time.Time) (eventHandler.TopicPartition, error) {
+	ret := _m.Called(ctx, tp, ts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SeekToTime")
+	}
+
+	var r0 eventHandler.TopicPartition
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, eventHandler.TopicPartition, time.Time) (eventHandler.TopicPartition, error)); ok {
+		return rf(ctx, tp, ts)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, eventHandler.TopicPartition, time.Time) eventHandler.TopicPartition); ok {
+		r0 = rf(ctx, tp, ts)
+	} else {
+		r0 = ret.Get(0).(eventHandler.TopicPartition)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, eventHandler.TopicPartition, time.Time) error); ok {
+		r1 = rf(ctx, tp, ts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IConsumerAdmin_SeekToTime_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SeekToTime'
+type IConsumerAdmin_SeekToTime_Call struct {
+	*mock.Call
+}
+
+// SeekToTime is a helper method to define mock.On call
+//   - ctx context.Context
+//   - tp eventHandler.TopicPartition
+//   - ts time.Time
+func (_e *IConsumerAdmin_Expecter) SeekToTime(ctx interface{}, tp interface{}, ts interface{}) *IConsumerAdmin_SeekToTime_Call {
+	return &IConsumerAdmin_SeekToTime_Call{Call: _e.mock.On("SeekToTime", ctx, tp, ts)}
+}
+
+func (_c *IConsumerAdmin_SeekToTime_Call) Run(run func(ctx context.Context, tp eventHandler.TopicPartition, ts time.Time)) *IConsumerAdmin_SeekToTime_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(eventHandler.TopicPartition), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *IConsumerAdmin_SeekToTime_Call) Return(_a0 eventHandler.TopicPartition, _a1 error) *IConsumerAdmin_SeekToTime_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IConsumerAdmin_SeekToTime_Call) RunAndReturn(run func(context.Context, eventHandler.TopicPartition, time.Time) (eventHandler.TopicPartition, error)) *IConsumerAdmin_SeekToTime_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Status provides a mock function with no fields
+func (_m *IConsumerAdmin) Status() (eventHandler.ConsumerStatus, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Status")
+	}
+
+	var r0 eventHandler.ConsumerStatus
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (eventHandler.ConsumerStatus, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() eventHandler.ConsumerStatus); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(eventHandler.ConsumerStatus)
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IConsumerAdmin_Status_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Status'
+type IConsumerAdmin_Status_Call struct {
+	*mock.Call
+}
+
+// Status is a helper method to define mock.On call
+func (_e *IConsumerAdmin_Expecter) Status() *IConsumerAdmin_Status_Call {
+	return &IConsumerAdmin_Status_Call{Call: _e.mock.On("Status")}
+}
+
+func (_c *IConsumerAdmin_Status_Call) Run(run func()) *IConsumerAdmin_Status_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *IConsumerAdmin_Status_Call) Return(_a0 eventHandler.ConsumerStatus, _a1 error) *IConsumerAdmin_Status_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IConsumerAdmin_Status_Call) RunAndReturn(run func() (eventHandler.ConsumerStatus, error)) *IConsumerAdmin_Status_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewIConsumerAdmin creates a new instance of IConsumerAdmin. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIConsumerAdmin(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IConsumerAdmin {
+	mock := &IConsumerAdmin{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,354 @@
+package eventHandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"log"
+	"orderService/configs"
+	"orderService/internal/service"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// natsRequestTimeout bounds the JetStream API calls and publish acknowledgements
+const natsRequestTimeout = 5 * time.Second
+
+// CreateNatsConsumer reads the configured subjects from a durable JetStream consumer, failed messages
+// are republished to JetStream. The processing settings are taken from the Kafka config, its topics being subjects
+func CreateNatsConsumer(cnf configs.Nats, processing configs.Kafka, service service.IOrderService) (*Consumer, error) {
+	options := []nats.Option{nats.Name("order-service")}
+	if cnf.CredentialsFile != "" {
+		options = append(options, nats.UserCredentials(cnf.CredentialsFile))
+	}
+	conn, err := nats.Connect(cnf.URL, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to nats: %w", err)
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create jetstream context: %w", err)
+	}
+
+	if cnf.CreateStream {
+		subjects := subscriptions(processing)
+		if processing.DeadLetterTopic != "" {
+			subjects = append(subjects, processing.DeadLetterTopic)
+		}
+		if err = createStream(js, cnf.Stream, subjects); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	var sink MessageSink
+	if processing.RetryTopics || processing.DeadLetterTopic != "" {
+		sink = NewNatsSink(js)
+	}
+	c, err := NewConsumer(NewNatsSource(conn, js, cnf), sink, service, processing)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// createStream keeps an existing stream as is and only adds the subjects it lacks
+func createStream(js jetstream.JetStream, name string, subjects []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), natsRequestTimeout)
+	defer cancel()
+
+	stream, err := js.Stream(ctx, name)
+	if errors.Is(err, jetstream.ErrStreamNotFound) {
+		_, err = js.CreateStream(ctx, jetstream.StreamConfig{Name: name, Subjects: subjects})
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to look up nats stream %s: %w", name, err)
+	}
+
+	info, err := stream.Info(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to look up nats stream %s: %w", name, err)
+	}
+	missing := slices.DeleteFunc(slices.Clone(subjects), func(subject string) bool {
+		return slices.Contains(info.Config.Subjects, subject)
+	})
+	if len(missing) == 0 {
+		return nil
+	}
+	config := info.Config
+	config.Subjects = append(config.Subjects, missing...)
+	if _, err = js.UpdateStream(ctx, config); err != nil {
+		return fmt.Errorf("failed to add subjects %s to nats stream %s: %w", strings.Join(missing, ", "), name, err)
+	}
+	return nil
+}
+
+// NatsSource reads a durable pull consumer with explicit acknowledgements. A subject is a topic with
+// the single partition 0 and the stream sequence as offset. Committing an offset acknowledges the messages
+// of the subject polled up to it, the others are kept in progress until the source is closed, then they are
+// negatively acknowledged so the server redelivers them at once
+type NatsSource struct {
+	conn    *nats.Conn
+	js      jetstream.JetStream
+	cnf     configs.Nats
+	consume jetstream.ConsumeContext
+	// received is fed by the consume callback, polling reads it
+	received chan jetstream.Msg
+
+	mu sync.Mutex
+	// unacked holds the delivered messages until they are acknowledged
+	unacked map[jetstream.Msg]bool
+	// held queues the received messages of every subject, a paused subject keeps its queue
+	held map[partitionKey][]jetstream.Msg
+	// polled lists the messages handed out of every subject in poll order, redeliveries may break the sequence order
+	polled map[partitionKey][]jetstream.Msg
+	paused map[partitionKey]bool
+	done   chan struct{}
+}
+
+func NewNatsSource(conn *nats.Conn, js jetstream.JetStream, cnf configs.Nats) *NatsSource {
+	return &NatsSource{
+		conn:     conn,
+		js:       js,
+		cnf:      cnf,
+		received: make(chan jetstream.Msg, cnf.MaxAckPending),
+		unacked:  make(map[jetstream.Msg]bool),
+		held:     make(map[partitionKey][]jetstream.Msg),
+		polled:   make(map[partitionKey][]jetstream.Msg),
+		paused:   make(map[partitionKey]bool),
+		done:     make(chan struct{}),
+	}
+}
+
+func (s *NatsSource) Name() string {
+	return "nats"
+}
+
+// Subscribe creates or updates the durable consumer, its subjects are never revoked
+func (s *NatsSource) Subscribe(topics []string, _ func(partitions []TopicPartition, lost bool)) error {
+	ctx, cancel := context.WithTimeout(context.Background(), natsRequestTimeout)
+	defer cancel()
+
+	consumer, err := s.js.CreateOrUpdateConsumer(ctx, s.cnf.Stream, jetstream.ConsumerConfig{
+		Durable:        s.cnf.Durable,
+		FilterSubjects: topics,
+		AckPolicy:      jetstream.AckExplicitPolicy,
+		AckWait:        time.Duration(s.cnf.AckWait) * time.Millisecond,
+		MaxAckPending:  s.cnf.MaxAckPending,
+		DeliverPolicy:  jetstream.DeliverAllPolicy,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create nats consumer %s on stream %s: %w", s.cnf.Durable, s.cnf.Stream, err)
+	}
+
+	s.consume, err = consumer.Consume(func(msg jetstream.Msg) {
+		s.mu.Lock()
+		s.unacked[msg] = true
+		s.mu.Unlock()
+		select {
+		case s.received <- msg:
+		case <-s.done:
+		}
+	}, jetstream.PullMaxMessages(s.cnf.MaxAckPending), jetstream.ConsumeErrHandler(func(_ jetstream.ConsumeContext, err error) {
+		log.Printf("Nats consumer error: %v\n", err)
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to consume nats consumer %s: %w", s.cnf.Durable, err)
+	}
+	go s.keepInProgress(time.Duration(s.cnf.AckWait) * time.Millisecond / 2)
+	return nil
+}
+
+// Poll hands out the held messages of resumed subjects before waiting for new ones
+func (s *NatsSource) Poll(timeout time.Duration) (*Message, error) {
+	if msg := s.resumed(); msg != nil {
+		return fromNatsMessage(msg)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case <-s.done:
+			return nil, ErrSourceClosed
+		case <-timer.C:
+			return nil, nil
+		case msg := <-s.received:
+			s.mu.Lock()
+			key := partitionKey{msg.Subject(), 0}
+			s.held[key] = append(s.held[key], msg)
+			s.mu.Unlock()
+			if next := s.resumed(); next != nil {
+				return fromNatsMessage(next)
+			}
+		}
+	}
+}
+
+func (s *NatsSource) resumed() jetstream.Msg {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, held := range s.held {
+		if !s.paused[key] {
+			if len(held) == 1 {
+				delete(s.held, key)
+			} else {
+				s.held[key] = held[1:]
+			}
+			s.polled[key] = append(s.polled[key], held[0])
+			return held[0]
+		}
+	}
+	return nil
+}
+
+// Commit acknowledges the messages of the subjects polled up to the one before each offset,
+// the offset tracker commits a position only once every message polled before it is done
+func (s *NatsSource) Commit(offsets []TopicPartition) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	for _, tp := range offsets {
+		key := keyOf(tp)
+		polled := s.polled[key]
+		last := slices.IndexFunc(polled, func(msg jetstream.Msg) bool { return sequenceOf(msg) == tp.Offset-1 })
+		for i := 0; i <= last; i++ {
+			if err := polled[i].Ack(); err != nil {
+				// the message is redelivered after the ack wait, the order was stored already
+				errs = append(errs, err)
+			}
+			delete(s.unacked, polled[i])
+		}
+		s.polled[key] = polled[last+1:]
+	}
+	return errors.Join(errs...)
+}
+
+// Pause holds back the messages of the subjects, the server keeps delivering them up to MaxAckPending
+func (s *NatsSource) Pause(partitions []TopicPartition) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, tp := range partitions {
+		s.paused[keyOf(tp)] = true
+	}
+	return nil
+}
+
+func (s *NatsSource) Resume(partitions []TopicPartition) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, tp := range partitions {
+		delete(s.paused, keyOf(tp))
+	}
+	return nil
+}
+
+// Close stops consuming and negatively acknowledges the messages left unacknowledged
+func (s *NatsSource) Close() error {
+	close(s.done)
+	if s.consume != nil {
+		s.consume.Stop()
+	}
+
+	s.mu.Lock()
+	var errs []error
+	for msg := range s.unacked {
+		if err := msg.Nak(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	clear(s.unacked)
+	s.mu.Unlock()
+
+	if err := s.conn.Flush(); err != nil {
+		errs = append(errs, err)
+	}
+	s.conn.Close()
+	return errors.Join(errs...)
+}
+
+// keepInProgress resets the redelivery timer of unacknowledged messages, which are either being handled
+// or wait for an earlier message of their subject, so they are redelivered only when the service stops
+func (s *NatsSource) keepInProgress(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			for msg := range s.unacked {
+				if err := msg.InProgress(); err != nil {
+					log.Printf("Failed to extend the ack wait of nats message %s: %v\n", msg.Subject(), err)
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// NatsSink publishes to JetStream, a subject outside of every stream is reported as an error
+type NatsSink struct {
+	js jetstream.JetStream
+}
+
+func NewNatsSink(js jetstream.JetStream) *NatsSink {
+	return &NatsSink{js: js}
+}
+
+func (s *NatsSink) Publish(topic string, msg Message) error {
+	out := nats.NewMsg(topic)
+	out.Data = msg.Value
+	for _, h := range msg.Headers {
+		out.Header.Add(h.Key, string(h.Value))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), natsRequestTimeout)
+	defer cancel()
+	_, err := s.js.PublishMsg(ctx, out)
+	return err
+}
+
+// Close leaves the connection to the source
+func (s *NatsSink) Close() error {
+	return nil
+}
+
+func fromNatsMessage(msg jetstream.Msg) (*Message, error) {
+	metadata, err := msg.Metadata()
+	if err != nil {
+		return nil, fmt.Errorf("nats message %s without metadata: %w", msg.Subject(), err)
+	}
+	var headers []Header
+	for key, values := range msg.Headers() {
+		for _, value := range values {
+			headers = append(headers, Header{Key: key, Value: []byte(value)})
+		}
+	}
+	return &Message{
+		TopicPartition: TopicPartition{Topic: msg.Subject(), Offset: int64(metadata.Sequence.Stream)},
+		Value:          msg.Data(),
+		Headers:        headers,
+		Timestamp:      metadata.Timestamp,
+	}, nil
+}
+
+func sequenceOf(msg jetstream.Msg) int64 {
+	metadata, err := msg.Metadata()
+	if err != nil {
+		return -1
+	}
+	return int64(metadata.Sequence.Stream)
+}
@@ -0,0 +1,134 @@
+package eventHandler
+
+import (
+	"context"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"orderService/configs"
+	"testing"
+	"time"
+)
+
+var natsConfig = configs.Nats{Stream: "ORDERS", CreateStream: true, Durable: "order-service", AckWait: 30000, MaxAckPending: 100}
+
+// runNats starts an embedded JetStream server for the test
+func runNats(t *testing.T) *server.Server {
+	s, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: server.RANDOM_PORT, JetStream: true, StoreDir: t.TempDir(), NoLog: true, NoSigs: true})
+	require.NoError(t, err)
+	go s.Start()
+	require.True(t, s.ReadyForConnections(5*time.Second), "nats server did not start")
+	t.Cleanup(s.Shutdown)
+	return s
+}
+
+func connectJetStream(t *testing.T, s *server.Server) (*nats.Conn, jetstream.JetStream) {
+	conn, err := nats.Connect(s.ClientURL())
+	require.NoError(t, err)
+	t.Cleanup(conn.Close)
+	js, err := jetstream.New(conn)
+	require.NoError(t, err)
+	return conn, js
+}
+
+func publish(t *testing.T, js jetstream.JetStream, subject string, value string) uint64 {
+	ack, err := js.Publish(context.Background(), subject, []byte(value))
+	require.NoError(t, err)
+	return ack.Sequence
+}
+
+func pollNats(t *testing.T, source *NatsSource) *Message {
+	msg, err := source.Poll(time.Second)
+	require.NoError(t, err)
+	require.NotNil(t, msg)
+	return msg
+}
+
+func TestNatsConsumer_Pipeline(t *testing.T) {
+	s := runNats(t)
+	_, js := connectJetStream(t, s)
+	orderService, mockRepo, lruCache := newPipelineService()
+	cnf := natsConfig
+	cnf.URL = s.ClientURL()
+
+	consumer, err := CreateNatsConsumer(cnf, pipelineConfig, orderService)
+	require.NoError(t, err)
+	for _, msg := range pipelineMessages(t) {
+		publish(t, js, topic, string(msg.Value))
+	}
+
+	runPipeline(t, consumer, func() bool {
+		info, err := js.Consumer(context.Background(), cnf.Stream, cnf.Durable)
+		if err != nil {
+			return false
+		}
+		state := info.CachedInfo()
+		return state.NumPending == 0 && state.NumAckPending == 0 && state.AckFloor.Stream == 3
+	})
+
+	stream, err := js.Stream(context.Background(), cnf.Stream)
+	require.NoError(t, err)
+	raw, err := stream.GetLastMsgForSubject(context.Background(), "Orders.dlq")
+	require.NoError(t, err)
+	deadLetter := Message{Value: raw.Data}
+	for key, values := range raw.Header {
+		deadLetter.Headers = append(deadLetter.Headers, Header{Key: key, Value: []byte(values[0])})
+	}
+	assertPipeline(t, mockRepo, lruCache, []Message{deadLetter})
+}
+
+func TestNatsSource(t *testing.T) {
+	s := runNats(t)
+	_, js := connectJetStream(t, s)
+	require.NoError(t, createStream(js, natsConfig.Stream, []string{topic}))
+	open := func() *NatsSource {
+		conn, js := connectJetStream(t, s)
+		source := NewNatsSource(conn, js, natsConfig)
+		require.NoError(t, source.Subscribe([]string{topic}, nil))
+		return source
+	}
+
+	first := publish(t, js, topic, "first")
+	publish(t, js, topic, "second")
+
+	t.Run("UnacknowledgedIsRedeliveredOnClose", func(t *testing.T) {
+		source := open()
+		msg := pollNats(t, source)
+		assert.Equal(t, TopicPartition{Topic: topic, Partition: 0, Offset: int64(first)}, msg.TopicPartition)
+		require.NoError(t, source.Close())
+
+		// the ack wait is 30s, only the nak on close redelivers the messages within the poll timeout,
+		// redeliveries may come in any order
+		source = open()
+		redelivered, last := pollNats(t, source), pollNats(t, source)
+		assert.ElementsMatch(t, []string{"first", "second"}, []string{string(redelivered.Value), string(last.Value)})
+
+		last.TopicPartition.Offset++
+		require.NoError(t, source.Commit([]TopicPartition{last.TopicPartition}))
+		require.NoError(t, source.Close())
+	})
+
+	t.Run("CommittedIsAcknowledged", func(t *testing.T) {
+		source := open()
+		defer source.Close()
+		msg, err := source.Poll(100 * time.Millisecond)
+		require.NoError(t, err)
+		assert.Nil(t, msg)
+	})
+
+	t.Run("PausedSubjectIsHeldBack", func(t *testing.T) {
+		source := open()
+		defer source.Close()
+		require.NoError(t, source.Pause([]TopicPartition{{Topic: topic}}))
+		third := publish(t, js, topic, "third")
+
+		msg, err := source.Poll(100 * time.Millisecond)
+		require.NoError(t, err)
+		assert.Nil(t, msg)
+
+		require.NoError(t, source.Resume([]TopicPartition{{Topic: topic}}))
+		assert.Equal(t, int64(third), pollNats(t, source).TopicPartition.Offset)
+	})
+}
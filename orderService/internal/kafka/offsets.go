@@ -0,0 +1,199 @@
+package eventHandler
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+)
+
+type partitionKey struct {
+	topic     string
+	partition int32
+}
+
+func keyOf(tp TopicPartition) partitionKey {
+	return partitionKey{tp.Topic, tp.Partition}
+}
+
+// offsetUnset is the position of a partition nothing was processed or committed for yet
+const offsetUnset int64 = -1
+
+type partitionOffsets struct {
+	// pending holds polled offsets in order until every offset before them is done too
+	pending   []int64
+	done      map[int64]bool
+	next      int64
+	committed int64
+	// ceiling is the lowest abandoned offset, next never passes it until the partition is read from it again
+	ceiling int64
+	// rewind asks the polling goroutine to read the partition again from ceiling
+	rewind bool
+}
+
+// offsetTracker finds the offset of every partition below which all messages were processed,
+// so workers finishing out of order never commit past an unprocessed message
+type offsetTracker struct {
+	mu         sync.Mutex
+	drained    *sync.Cond
+	partitions map[partitionKey]*partitionOffsets
+}
+
+func newOffsetTracker() *offsetTracker {
+	t := &offsetTracker{partitions: make(map[partitionKey]*partitionOffsets)}
+	t.drained = sync.NewCond(&t.mu)
+	return t
+}
+
+// track registers a polled message, it must be called in poll order
+func (t *offsetTracker) track(tp TopicPartition) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := keyOf(tp)
+	p, ok := t.partitions[key]
+	if !ok {
+		p = &partitionOffsets{done: make(map[int64]bool), next: offsetUnset, committed: offsetUnset, ceiling: offsetUnset}
+		t.partitions[key] = p
+	}
+	p.pending = append(p.pending, tp.Offset)
+}
+
+// done marks a message processed, whatever the outcome
+func (t *offsetTracker) done(tp TopicPartition) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.partitions[keyOf(tp)]
+	if !ok {
+		return
+	}
+	p.done[tp.Offset] = true
+	for len(p.pending) > 0 && p.done[p.pending[0]] {
+		delete(p.done, p.pending[0])
+		p.next = p.pending[0] + 1
+		if p.ceiling != offsetUnset {
+			p.next = min(p.next, p.ceiling)
+		}
+		p.pending = p.pending[1:]
+	}
+	if len(p.pending) == 0 {
+		t.drained.Broadcast()
+	}
+}
+
+// abandon keeps the commit position of the partition at a message left unprocessed until the partition
+// is read from it again, the message must still be marked done so the partition can be drained
+func (t *offsetTracker) abandon(tp TopicPartition) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if p, ok := t.partitions[keyOf(tp)]; ok {
+		if p.ceiling == offsetUnset || tp.Offset < p.ceiling {
+			p.ceiling = tp.Offset
+		}
+		p.rewind = true
+	}
+}
+
+// abandoned returns the partitions to read again from their lowest abandoned offset
+func (t *offsetTracker) abandoned() []TopicPartition {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var partitions []TopicPartition
+	for key, p := range t.partitions {
+		if p.rewind {
+			partitions = append(partitions, TopicPartition{Topic: key.topic, Partition: key.partition, Offset: p.ceiling})
+		}
+	}
+	slices.SortFunc(partitions, func(a, b TopicPartition) int {
+		return cmp.Or(cmp.Compare(a.Topic, b.Topic), cmp.Compare(a.Partition, b.Partition))
+	})
+	return partitions
+}
+
+// committable returns the offsets to commit for the given partitions, all partitions when nil
+func (t *offsetTracker) committable(partitions []TopicPartition) []TopicPartition {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var offsets []TopicPartition
+	for key, p := range t.partitions {
+		if p.next > p.committed && (partitions == nil || contains(partitions, key)) {
+			offsets = append(offsets, TopicPartition{Topic: key.topic, Partition: key.partition, Offset: p.next})
+		}
+	}
+	slices.SortFunc(offsets, func(a, b TopicPartition) int {
+		return cmp.Or(cmp.Compare(a.Topic, b.Topic), cmp.Compare(a.Partition, b.Partition))
+	})
+	return offsets
+}
+
+// markCommitted records offsets acknowledged by the broker
+func (t *offsetTracker) markCommitted(offsets []TopicPartition) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, tp := range offsets {
+		if p, ok := t.partitions[keyOf(tp)]; ok && tp.Offset > p.committed {
+			p.committed = tp.Offset
+		}
+	}
+}
+
+// drain blocks until every tracked message of the given partitions is processed
+func (t *offsetTracker) drain(partitions []TopicPartition) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for t.inFlight(partitions) {
+		t.drained.Wait()
+	}
+}
+
+// drainWithin is drain giving up after timeout, it reports whether the partitions were drained
+func (t *offsetTracker) drainWithin(partitions []TopicPartition, timeout time.Duration) bool {
+	expired := false
+	timer := time.AfterFunc(timeout, func() {
+		t.mu.Lock()
+		expired = true
+		t.mu.Unlock()
+		t.drained.Broadcast()
+	})
+	defer timer.Stop()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for t.inFlight(partitions) {
+		if expired {
+			return false
+		}
+		t.drained.Wait()
+	}
+	return true
+}
+
+func (t *offsetTracker) inFlight(partitions []TopicPartition) bool {
+	for _, tp := range partitions {
+		if p, ok := t.partitions[keyOf(tp)]; ok && len(p.pending) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// forget drops revoked partitions, a late done for them is ignored
+func (t *offsetTracker) forget(partitions []TopicPartition) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, tp := range partitions {
+		delete(t.partitions, keyOf(tp))
+	}
+}
+
+func contains(partitions []TopicPartition, key partitionKey) bool {
+	return slices.ContainsFunc(partitions, func(tp TopicPartition) bool { return keyOf(tp) == key })
+}
@@ -0,0 +1,107 @@
+package eventHandler
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func tp(partition int32, offset int64) TopicPartition {
+	return TopicPartition{Topic: topic, Partition: partition, Offset: offset}
+}
+
+func TestOffsetTracker_committable(t *testing.T) {
+	t.Run("OnlyContiguousOffsets", func(t *testing.T) {
+		tracker := newOffsetTracker()
+		for _, offset := range []int64{10, 11, 12, 15} {
+			tracker.track(tp(0, offset))
+		}
+
+		tracker.done(tp(0, 11))
+		tracker.done(tp(0, 15))
+		assert.Empty(t, tracker.committable(nil))
+
+		tracker.done(tp(0, 10))
+		assert.Equal(t, []TopicPartition{tp(0, 12)}, tracker.committable(nil))
+
+		// offsets 13 and 14 were compacted away, the gap must not block the commit
+		tracker.done(tp(0, 12))
+		assert.Equal(t, []TopicPartition{tp(0, 16)}, tracker.committable(nil))
+	})
+
+	t.Run("CommittedOffsetsAreSkipped", func(t *testing.T) {
+		tracker := newOffsetTracker()
+		tracker.track(tp(0, 1))
+		tracker.track(tp(1, 7))
+		tracker.done(tp(0, 1))
+		tracker.done(tp(1, 7))
+
+		assert.Equal(t, []TopicPartition{tp(0, 2), tp(1, 8)}, tracker.committable(nil))
+		tracker.markCommitted([]TopicPartition{tp(0, 2)})
+
+		assert.Equal(t, []TopicPartition{tp(1, 8)}, tracker.committable(nil))
+		assert.Empty(t, tracker.committable([]TopicPartition{tp(0, offsetUnset)}))
+	})
+
+	t.Run("AbandonedOffsetIsTheCeiling", func(t *testing.T) {
+		tracker := newOffsetTracker()
+		for _, offset := range []int64{10, 11, 12} {
+			tracker.track(tp(0, offset))
+		}
+		tracker.track(tp(1, 3))
+
+		tracker.abandon(tp(0, 11))
+		for _, offset := range []int64{10, 11, 12} {
+			tracker.done(tp(0, offset))
+		}
+		tracker.done(tp(1, 3))
+
+		assert.Equal(t, []TopicPartition{tp(0, 11), tp(1, 4)}, tracker.committable(nil))
+		assert.Equal(t, []TopicPartition{tp(0, 11)}, tracker.abandoned())
+
+		// reading the partition again from the abandoned offset lifts the ceiling
+		tracker.forget([]TopicPartition{tp(0, offsetUnset)})
+		tracker.track(tp(0, 11))
+		tracker.track(tp(0, 12))
+		tracker.done(tp(0, 11))
+		tracker.done(tp(0, 12))
+		assert.Equal(t, []TopicPartition{tp(0, 13)}, tracker.committable([]TopicPartition{tp(0, offsetUnset)}))
+		assert.Empty(t, tracker.abandoned())
+	})
+}
+
+func TestOffsetTracker_drain(t *testing.T) {
+	tracker := newOffsetTracker()
+	tracker.track(tp(0, 1))
+	tracker.track(tp(1, 1))
+
+	drained := make(chan struct{})
+	go func() {
+		tracker.drain([]TopicPartition{tp(0, offsetUnset)})
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		t.Fatal("drain returned with a message in flight")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	tracker.done(tp(0, 1))
+	select {
+	case <-drained:
+	case <-time.After(time.Second):
+		t.Fatal("drain did not return after the partition was processed")
+	}
+
+	tracker.forget([]TopicPartition{tp(0, offsetUnset)})
+	tracker.done(tp(0, 2))
+	assert.Empty(t, tracker.committable([]TopicPartition{tp(0, offsetUnset)}))
+
+	t.Run("Within", func(t *testing.T) {
+		assert.False(t, tracker.drainWithin([]TopicPartition{tp(1, offsetUnset)}, 20*time.Millisecond))
+
+		tracker.done(tp(1, 1))
+		assert.True(t, tracker.drainWithin([]TopicPartition{tp(1, offsetUnset)}, 20*time.Millisecond))
+	})
+}
@@ -0,0 +1,93 @@
+package eventHandler
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+const (
+	// OrderingPartition processes the messages of a partition one by one
+	OrderingPartition = "partition"
+	// OrderingKey processes the messages of a key (the order uid) one by one, a partition in parallel
+	OrderingKey = "key"
+)
+
+// queueSize bounds the messages polled ahead of each worker
+const queueSize = 64
+
+// workerPool routes each message to a fixed worker by its ordering key, so messages sharing
+// the key are handled in poll order while different keys run in parallel.
+// A worker hands over up to batchSize queued messages at once, waiting at most batchWait to fill the batch
+type workerPool struct {
+	queues    []chan *Message
+	ordering  string
+	batchSize int
+	batchWait time.Duration
+	wg        sync.WaitGroup
+}
+
+func newWorkerPool(concurrency int, ordering string, batchSize int, batchWait time.Duration, handle func([]*Message)) *workerPool {
+	p := &workerPool{queues: make([]chan *Message, concurrency), ordering: ordering, batchSize: batchSize, batchWait: batchWait}
+	for i := range p.queues {
+		queue := make(chan *Message, max(queueSize, batchSize))
+		p.queues[i] = queue
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			for msg := range queue {
+				handle(p.fill(queue, msg))
+			}
+		}()
+	}
+	return p
+}
+
+// fill collects the batch started by first
+func (p *workerPool) fill(queue chan *Message, first *Message) []*Message {
+	batch := make([]*Message, 1, p.batchSize)
+	batch[0] = first
+	if p.batchSize == 1 {
+		return batch
+	}
+
+	timer := time.NewTimer(p.batchWait)
+	defer timer.Stop()
+	for len(batch) < p.batchSize {
+		select {
+		case msg, ok := <-queue:
+			if !ok {
+				return batch
+			}
+			batch = append(batch, msg)
+		case <-timer.C:
+			return batch
+		}
+	}
+	return batch
+}
+
+// dispatch blocks while the worker queue is full
+func (p *workerPool) dispatch(msg *Message) {
+	p.queues[p.worker(msg)] <- msg
+}
+
+func (p *workerPool) worker(msg *Message) int {
+	h := fnv.New32a()
+	if p.ordering == OrderingKey && len(msg.Key) > 0 {
+		h.Write(msg.Key)
+	} else {
+		h.Write([]byte(msg.TopicPartition.Topic))
+		h.Write(binary.BigEndian.AppendUint32(nil, uint32(msg.TopicPartition.Partition)))
+	}
+	return int(h.Sum32() % uint32(len(p.queues)))
+}
+
+// close waits for the queued messages to be handled
+func (p *workerPool) close() {
+	for _, queue := range p.queues {
+		close(queue)
+	}
+	p.wg.Wait()
+}
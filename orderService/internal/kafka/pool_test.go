@@ -0,0 +1,101 @@
+package eventHandler
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWorkerPool(t *testing.T) {
+	tests := []struct {
+		ordering string
+		// key groups the messages that must stay in order
+		key func(msg *Message) string
+	}{
+		{OrderingPartition, func(msg *Message) string { return fmt.Sprint(msg.TopicPartition.Partition) }},
+		{OrderingKey, func(msg *Message) string { return string(msg.Key) }},
+	}
+	for _, test := range tests {
+		t.Run(test.ordering, func(t *testing.T) {
+			var mu sync.Mutex
+			handled := make(map[string][]int64)
+			var running, maxRunning atomic.Int32
+			pool := newWorkerPool(4, test.ordering, 1, 0, func(batch []*Message) {
+				maxRunning.Store(max(maxRunning.Load(), running.Add(1)))
+				time.Sleep(time.Duration(rand.IntN(200)) * time.Microsecond)
+				running.Add(-1)
+
+				mu.Lock()
+				defer mu.Unlock()
+				key := test.key(batch[0])
+				handled[key] = append(handled[key], batch[0].TopicPartition.Offset)
+			})
+
+			for offset := range 400 {
+				partition := int32(offset % 8)
+				pool.dispatch(&Message{
+					TopicPartition: tp(partition, int64(offset)),
+					Key:            []byte(fmt.Sprintf("order-%d", offset%16)),
+				})
+			}
+			pool.close()
+
+			total := 0
+			for key, offsets := range handled {
+				total += len(offsets)
+				assert.IsIncreasing(t, offsets, key)
+			}
+			assert.Equal(t, 400, total)
+			assert.Greater(t, maxRunning.Load(), int32(1))
+		})
+	}
+}
+
+func TestWorkerPool_Batches(t *testing.T) {
+	t.Run("UpToBatchSize", func(t *testing.T) {
+		var batches [][]int64
+		pool := newWorkerPool(1, OrderingPartition, 10, time.Second, func(batch []*Message) {
+			offsets := make([]int64, 0, len(batch))
+			for _, msg := range batch {
+				offsets = append(offsets, msg.TopicPartition.Offset)
+			}
+			batches = append(batches, offsets)
+		})
+
+		for offset := range 25 {
+			pool.dispatch(&Message{TopicPartition: tp(0, int64(offset))})
+		}
+		pool.close()
+
+		var all []int64
+		for _, batch := range batches {
+			assert.LessOrEqual(t, len(batch), 10)
+			all = append(all, batch...)
+		}
+		assert.Len(t, all, 25)
+		assert.IsIncreasing(t, all)
+		assert.Less(t, len(batches), 25)
+	})
+
+	t.Run("FlushesAfterBatchWait", func(t *testing.T) {
+		handled := make(chan []*Message, 1)
+		pool := newWorkerPool(1, OrderingPartition, 10, 10*time.Millisecond, func(batch []*Message) {
+			handled <- batch
+		})
+		defer pool.close()
+
+		pool.dispatch(&Message{TopicPartition: tp(0, 1)})
+		pool.dispatch(&Message{TopicPartition: tp(0, 2)})
+
+		select {
+		case batch := <-handled:
+			assert.Len(t, batch, 2)
+		case <-time.After(time.Second):
+			t.Fatal("incomplete batch was not handled after the batch wait")
+		}
+	})
+}
@@ -0,0 +1,199 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/hamba/avro/v2"
+	"golang.org/x/sync/singleflight"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Registry resolves the schema id carried by a Confluent wire format payload
+type Registry interface {
+	// Schema returns the JSON Schema registered under id
+	Schema(id int) (*Schema, error)
+	// AvroSchema returns the Avro schema registered under id, the writer schema of an Avro payload
+	AvroSchema(id int) (avro.Schema, error)
+}
+
+// failureBackoff is how long a failed lookup is answered with its error before the registry is asked again
+const failureBackoff = 5 * time.Second
+
+// cache keeps parsed schemas, ids of a schema registry are immutable. Concurrent lookups of an id share
+// one load, which runs outside the lock so a slow registry only delays the callers of that id
+type cache[T any] struct {
+	mu       sync.Mutex
+	schemas  map[int]T
+	failures map[int]failure
+	loads    singleflight.Group
+}
+
+type failure struct {
+	err   error
+	until time.Time
+}
+
+func newCache[T any]() *cache[T] {
+	return &cache[T]{schemas: make(map[int]T), failures: make(map[int]failure)}
+}
+
+func (c *cache[T]) get(id int, load func() (T, error)) (T, error) {
+	if s, err, ok := c.lookup(id); ok {
+		return s, err
+	}
+	s, err, _ := c.loads.Do(strconv.Itoa(id), func() (any, error) {
+		// the previous load of the id may have finished between lookup and Do
+		if s, err, ok := c.lookup(id); ok {
+			return s, err
+		}
+		s, err := load()
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if err != nil {
+			c.failures[id] = failure{err, time.Now().Add(failureBackoff)}
+			return nil, err
+		}
+		c.schemas[id] = s
+		delete(c.failures, id)
+		return s, nil
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return s.(T), nil
+}
+
+// lookup returns the cached schema or the failure of a recent load, ok is false when the id has to be loaded
+func (c *cache[T]) lookup(id int) (T, error, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if s, ok := c.schemas[id]; ok {
+		return s, nil, true
+	}
+	var zero T
+	if f, ok := c.failures[id]; ok && time.Now().Before(f.until) {
+		return zero, f.err, true
+	}
+	return zero, nil, false
+}
+
+// FileRegistry is a local stand-in for a schema registry, it reads the JSON Schema <id> from <dir>/<id>.json
+// and the Avro schema <id> from <dir>/<id>.avsc
+type FileRegistry struct {
+	dir   string
+	json  *cache[*Schema]
+	avros *cache[avro.Schema]
+}
+
+func NewFileRegistry(dir string) FileRegistry {
+	return FileRegistry{dir, newCache[*Schema](), newCache[avro.Schema]()}
+}
+
+func (r FileRegistry) Schema(id int) (*Schema, error) {
+	return r.json.get(id, func() (*Schema, error) {
+		document, err := r.read(id, "json")
+		if err != nil {
+			return nil, err
+		}
+		return Compile(fmt.Sprintf("registry:%d", id), document)
+	})
+}
+
+func (r FileRegistry) AvroSchema(id int) (avro.Schema, error) {
+	return r.avros.get(id, func() (avro.Schema, error) {
+		document, err := r.read(id, "avsc")
+		if err != nil {
+			return nil, err
+		}
+		return parseAvro(id, string(document))
+	})
+}
+
+func (r FileRegistry) read(id int, extension string) ([]byte, error) {
+	document, err := os.ReadFile(filepath.Join(r.dir, fmt.Sprintf("%d.%s", id, extension)))
+	if err != nil {
+		return nil, fmt.Errorf("schema %d is not registered: %w", id, err)
+	}
+	return document, nil
+}
+
+// HTTPRegistry fetches schemas from the Confluent Schema Registry REST API
+type HTTPRegistry struct {
+	url    string
+	client *http.Client
+	json   *cache[*Schema]
+	avros  *cache[avro.Schema]
+}
+
+func NewHTTPRegistry(url string) HTTPRegistry {
+	return HTTPRegistry{strings.TrimRight(url, "/"), &http.Client{Timeout: 5 * time.Second}, newCache[*Schema](), newCache[avro.Schema]()}
+}
+
+type registeredSchema struct {
+	Schema     string `json:"schema"`
+	SchemaType string `json:"schemaType"`
+}
+
+func (r HTTPRegistry) Schema(id int) (*Schema, error) {
+	return r.json.get(id, func() (*Schema, error) {
+		registered, err := r.fetch(id, "JSON")
+		if err != nil {
+			return nil, err
+		}
+		return Compile(fmt.Sprintf("registry:%d", id), []byte(registered.Schema))
+	})
+}
+
+func (r HTTPRegistry) AvroSchema(id int) (avro.Schema, error) {
+	return r.avros.get(id, func() (avro.Schema, error) {
+		// the registry omits schemaType for Avro, its default
+		registered, err := r.fetch(id, "")
+		if err != nil {
+			return nil, err
+		}
+		return parseAvro(id, registered.Schema)
+	})
+}
+
+func (r HTTPRegistry) fetch(id int, schemaType string) (registeredSchema, error) {
+	resp, err := r.client.Get(fmt.Sprintf("%s/schemas/ids/%d", r.url, id))
+	if err != nil {
+		return registeredSchema{}, fmt.Errorf("failed to fetch schema %d: %w", id, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return registeredSchema{}, fmt.Errorf("failed to fetch schema %d: %s %s", id, resp.Status, body)
+	}
+	var registered registeredSchema
+	if err = json.NewDecoder(resp.Body).Decode(&registered); err != nil {
+		return registeredSchema{}, fmt.Errorf("failed to decode schema %d: %w", id, err)
+	}
+	if registered.SchemaType == "AVRO" {
+		registered.SchemaType = ""
+	}
+	if registered.SchemaType != schemaType {
+		return registeredSchema{}, fmt.Errorf("schema %d has unsupported type %q", id, registered.SchemaType)
+	}
+	return registered, nil
+}
+
+func parseAvro(id int, document string) (avro.Schema, error) {
+	// a cache of its own, writer schemas of different ids share the full name of the order record
+	schema, err := avro.ParseBytesWithCache([]byte(document), "", &avro.SchemaCache{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse avro schema %d: %w", id, err)
+	}
+	return schema, nil
+}
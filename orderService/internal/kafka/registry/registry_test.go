@@ -0,0 +1,210 @@
+package registry
+
+import (
+	"fmt"
+	"github.com/hamba/avro/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func fixture(t *testing.T) []byte {
+	order, err := os.ReadFile("testdata/order.json")
+	require.NoError(t, err)
+	return order
+}
+
+func TestValidator_Validate(t *testing.T) {
+	validator, err := NewValidator(NewFileRegistry("testdata/registry"))
+	require.NoError(t, err)
+	order := fixture(t)
+
+	t.Run("CurrentVersionByDefault", func(t *testing.T) {
+		body, err := validator.Validate(order, "")
+
+		assert.Nil(t, err)
+		assert.Equal(t, order, body)
+	})
+
+	t.Run("VersionHeader", func(t *testing.T) {
+		_, err := validator.Validate(order, "1")
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("UnknownVersion", func(t *testing.T) {
+		_, err := validator.Validate(order, "2")
+
+		assert.ErrorIs(t, err, ErrUnknownVersion)
+	})
+
+	t.Run("RenamedField", func(t *testing.T) {
+		renamed := []byte(strings.Replace(string(order), `"track_number": "WBILMTESTTRACK",
+  "entry"`, `"trackNumber": "WBILMTESTTRACK",
+  "entry"`, 1))
+
+		_, err := validator.Validate(renamed, "")
+
+		assert.ErrorIs(t, err, ErrInvalidMessage)
+		assert.Contains(t, err.Error(), "track_number")
+	})
+
+	t.Run("WrongType", func(t *testing.T) {
+		wrong := []byte(strings.Replace(string(order), `"sm_id": 99`, `"sm_id": "99"`, 1))
+
+		_, err := validator.Validate(wrong, "")
+
+		assert.ErrorIs(t, err, ErrInvalidMessage)
+	})
+
+	t.Run("NotJSON", func(t *testing.T) {
+		_, err := validator.Validate([]byte("order"), "")
+
+		assert.ErrorIs(t, err, ErrInvalidMessage)
+	})
+
+	t.Run("WireFormat", func(t *testing.T) {
+		body, err := validator.Validate(WireFormat(7, order), "")
+
+		assert.Nil(t, err)
+		assert.Equal(t, order, body)
+	})
+
+	t.Run("WireFormatUnregisteredSchema", func(t *testing.T) {
+		_, err := validator.Validate(WireFormat(8, order), "")
+
+		assert.ErrorContains(t, err, "schema 8 is not registered")
+	})
+
+	t.Run("WireFormatWithoutRegistry", func(t *testing.T) {
+		withoutRegistry, err := NewValidator(nil)
+		require.NoError(t, err)
+
+		_, err = withoutRegistry.Validate(WireFormat(7, order), "")
+
+		assert.ErrorContains(t, err, "no schema registry is configured")
+	})
+}
+
+func TestSplitWireFormat(t *testing.T) {
+	id, body, ok := SplitWireFormat([]byte{0, 0, 0, 1, 2, '{', '}'})
+	assert.True(t, ok)
+	assert.Equal(t, 258, id)
+	assert.Equal(t, []byte("{}"), body)
+
+	_, body, ok = SplitWireFormat([]byte("{}"))
+	assert.False(t, ok)
+	assert.Equal(t, []byte("{}"), body)
+}
+
+func TestHTTPRegistry_Schema(t *testing.T) {
+	document, err := os.ReadFile("testdata/registry/7.json")
+	require.NoError(t, err)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/schemas/ids/7":
+			w.Header().Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+			_, _ = fmt.Fprintf(w, `{"schemaType":"JSON","schema":%q}`, document)
+		case "/schemas/ids/9":
+			_, _ = fmt.Fprint(w, `{"schema":"{\"type\":\"record\",\"name\":\"Order\",\"fields\":[]}"}`)
+		default:
+			http.Error(w, `{"error_code":40403,"message":"Schema not found"}`, http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	registry := NewHTTPRegistry(server.URL + "/")
+
+	t.Run("CachesSchema", func(t *testing.T) {
+		for range 2 {
+			schema, err := registry.Schema(7)
+			require.NoError(t, err)
+			assert.Nil(t, schema.Validate(fixture(t)))
+		}
+		assert.Equal(t, 1, requests)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		_, err := registry.Schema(8)
+
+		assert.ErrorContains(t, err, "404")
+	})
+
+	t.Run("NotJSONSchema", func(t *testing.T) {
+		_, err := registry.Schema(9)
+
+		assert.ErrorContains(t, err, `unsupported type ""`)
+	})
+
+	t.Run("AvroSchema", func(t *testing.T) {
+		schema, err := registry.AvroSchema(9)
+
+		require.NoError(t, err)
+		assert.Equal(t, avro.Record, schema.Type())
+	})
+
+	t.Run("NotAvroSchema", func(t *testing.T) {
+		_, err := registry.AvroSchema(7)
+
+		assert.ErrorContains(t, err, `unsupported type "JSON"`)
+	})
+}
+
+func TestHTTPRegistry_ConcurrentLookups(t *testing.T) {
+	document, err := os.ReadFile("testdata/registry/7.json")
+	require.NoError(t, err)
+	var requests atomic.Int32
+	arrived, release := make(chan struct{}), make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/schemas/ids/7":
+			close(arrived)
+			<-release
+			_, _ = fmt.Fprintf(w, `{"schemaType":"JSON","schema":%q}`, document)
+		case "/schemas/ids/9":
+			_, _ = fmt.Fprint(w, `{"schema":"{\"type\":\"record\",\"name\":\"Order\",\"fields\":[]}"}`)
+		default:
+			http.Error(w, `{"error_code":50001,"message":"Store error"}`, http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+	registry := NewHTTPRegistry(server.URL)
+
+	t.Run("ShareOneFetch", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := registry.Schema(7)
+				assert.Nil(t, err)
+			}()
+		}
+		<-arrived
+
+		// a slow fetch does not hold back other ids
+		_, err := registry.AvroSchema(9)
+		assert.Nil(t, err)
+
+		close(release)
+		wg.Wait()
+		assert.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("FailureIsCachedBriefly", func(t *testing.T) {
+		requests.Store(0)
+		for range 3 {
+			_, err := registry.Schema(8)
+			assert.ErrorContains(t, err, "500")
+		}
+		assert.Equal(t, int32(1), requests.Load())
+	})
+}
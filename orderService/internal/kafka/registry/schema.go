@@ -0,0 +1,78 @@
+package registry
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"io/fs"
+	"orderService/api/schema"
+)
+
+const (
+	// SchemaVersionHeader names the Kafka header with the version of the order schema the producer used
+	SchemaVersionHeader = "schema-version"
+	// CurrentVersion is the order schema version written by this service
+	CurrentVersion = 1
+)
+
+var ErrInvalidMessage = errors.New("message does not match the order schema")
+
+// Schema validates order messages against one compiled JSON Schema
+type Schema struct {
+	compiled *jsonschema.Schema
+}
+
+// Compile parses a JSON Schema document, name only identifies it in error messages
+func Compile(name string, document []byte) (*Schema, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(document))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema %s: %w", name, err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.AssertFormat()
+	if err = compiler.AddResource(name, doc); err != nil {
+		return nil, fmt.Errorf("failed to load schema %s: %w", name, err)
+	}
+	compiled, err := compiler.Compile(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile schema %s: %w", name, err)
+	}
+	return &Schema{compiled}, nil
+}
+
+// Validate checks a JSON payload, the returned error wraps ErrInvalidMessage
+func (s *Schema) Validate(payload []byte) error {
+	value, err := jsonschema.UnmarshalJSON(bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+	}
+	if err = s.compiled.Validate(value); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+	}
+	return nil
+}
+
+// Published compiles every order schema version shipped in api/schema
+func Published() (map[int]*Schema, error) {
+	versions := make(map[int]*Schema)
+	files, err := fs.Glob(schema.Files, "order.v*.json")
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		var version int
+		if _, err = fmt.Sscanf(file, "order.v%d.json", &version); err != nil {
+			return nil, fmt.Errorf("unexpected schema file name %s", file)
+		}
+		document, err := schema.Files.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if versions[version], err = Compile(file, document); err != nil {
+			return nil, err
+		}
+	}
+	return versions, nil
+}
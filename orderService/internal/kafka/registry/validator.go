@@ -0,0 +1,61 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+var ErrUnknownVersion = errors.New("unknown order schema version")
+
+// Validator checks an order message against the schema it declares: the schema id of a Confluent
+// wire format payload or the schema-version header, the current version when neither is set
+type Validator struct {
+	versions map[int]*Schema
+	registry Registry
+}
+
+// NewValidator builds a validator of the published versions, registry may be nil when
+// wire format payloads are not expected
+func NewValidator(registry Registry) (Validator, error) {
+	versions, err := Published()
+	if err != nil {
+		return Validator{}, err
+	}
+	return Validator{versions, registry}, nil
+}
+
+// Validate returns the JSON body of a valid message
+func (v Validator) Validate(payload []byte, version string) ([]byte, error) {
+	schema, body, err := v.resolve(payload, version)
+	if err != nil {
+		return nil, err
+	}
+	if err = schema.Validate(body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func (v Validator) resolve(payload []byte, version string) (*Schema, []byte, error) {
+	if id, body, ok := SplitWireFormat(payload); ok {
+		if v.registry == nil {
+			return nil, nil, fmt.Errorf("message references schema %d but no schema registry is configured", id)
+		}
+		schema, err := v.registry.Schema(id)
+		return schema, body, err
+	}
+
+	number := CurrentVersion
+	if version != "" {
+		var err error
+		if number, err = strconv.Atoi(version); err != nil {
+			return nil, nil, fmt.Errorf("%w: %q", ErrUnknownVersion, version)
+		}
+	}
+	schema, ok := v.versions[number]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %d", ErrUnknownVersion, number)
+	}
+	return schema, payload, nil
+}
@@ -0,0 +1,23 @@
+package registry
+
+import "encoding/binary"
+
+// magicByte opens every payload in the Confluent wire format, followed by a 4-byte big-endian schema id
+const magicByte = 0x0
+
+// SplitWireFormat returns the schema id and the body of a Confluent wire format payload,
+// ok is false for a plain payload
+func SplitWireFormat(payload []byte) (id int, body []byte, ok bool) {
+	if len(payload) < 5 || payload[0] != magicByte {
+		return 0, payload, false
+	}
+	return int(binary.BigEndian.Uint32(payload[1:5])), payload[5:], true
+}
+
+// WireFormat prepends the Confluent wire format header to a payload
+func WireFormat(id int, body []byte) []byte {
+	payload := make([]byte, 5, 5+len(body))
+	payload[0] = magicByte
+	binary.BigEndian.PutUint32(payload[1:5], uint32(id))
+	return append(payload, body...)
+}
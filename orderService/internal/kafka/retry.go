@@ -0,0 +1,251 @@
+package eventHandler
+
+import (
+	"context"
+	"log"
+	"math/rand/v2"
+	"orderService/internal/repository"
+	"strings"
+	"sync"
+	"time"
+)
+
+// retryStages are the delayed topics a message goes through, after its in-place retries failed,
+// before it is dead-lettered. A message of a stage is handled once its delay since being produced passed
+var retryStages = []struct {
+	suffix string
+	delay  time.Duration
+}{
+	{".retry.1m", time.Minute},
+	{".retry.10m", 10 * time.Minute},
+}
+
+// stageOf returns the topic a retry topic delays and the 1-based stage, 0 for a topic that is not a retry topic
+func stageOf(topic string) (string, int) {
+	for i, stage := range retryStages {
+		if base, ok := strings.CutSuffix(topic, stage.suffix); ok {
+			return base, i + 1
+		}
+	}
+	return topic, 0
+}
+
+type pauser interface {
+	Pause(partitions []TopicPartition) error
+	Resume(partitions []TopicPartition) error
+}
+
+// pausedPartitions stops fetching a partition while a worker holds one of its messages back,
+// with key ordering several workers may hold the same partition, it is resumed when the last one is done.
+// A partition paused from the admin endpoint is held until it is resumed there
+type pausedPartitions struct {
+	mu      sync.Mutex
+	pauser  pauser
+	holders map[partitionKey]int
+	byAdmin map[partitionKey]bool
+}
+
+func newPausedPartitions(p pauser) *pausedPartitions {
+	return &pausedPartitions{pauser: p, holders: make(map[partitionKey]int), byAdmin: make(map[partitionKey]bool)}
+}
+
+func (p *pausedPartitions) pause(tp TopicPartition) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.hold(tp)
+}
+
+func (p *pausedPartitions) resume(tp TopicPartition) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.release(tp)
+}
+
+// pauseByAdmin returns false when the partition is paused from the admin endpoint already
+func (p *pausedPartitions) pauseByAdmin(tp TopicPartition) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := keyOf(tp)
+	if p.byAdmin[key] {
+		return false
+	}
+	p.byAdmin[key] = true
+	p.hold(tp)
+	return true
+}
+
+// resumeByAdmin returns false when the partition is not paused from the admin endpoint,
+// it stays paused while a worker holds one of its messages back
+func (p *pausedPartitions) resumeByAdmin(tp TopicPartition) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := keyOf(tp)
+	if !p.byAdmin[key] {
+		return false
+	}
+	delete(p.byAdmin, key)
+	p.release(tp)
+	return true
+}
+
+// forget drops the pauses of revoked partitions, a partition assigned again is fetched unpaused
+func (p *pausedPartitions) forget(partitions []TopicPartition) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, tp := range partitions {
+		delete(p.holders, keyOf(tp))
+		delete(p.byAdmin, keyOf(tp))
+	}
+}
+
+// state reports whether the partition is paused at all and whether from the admin endpoint
+func (p *pausedPartitions) state(tp TopicPartition) (paused bool, byAdmin bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := keyOf(tp)
+	return p.holders[key] > 0, p.byAdmin[key]
+}
+
+func (p *pausedPartitions) hold(tp TopicPartition) {
+	key := keyOf(tp)
+	p.holders[key]++
+	if p.holders[key] == 1 {
+		if err := p.pauser.Pause([]TopicPartition{partitionOf(tp)}); err != nil {
+			log.Printf("Failed to pause %s [%d]: %v\n", key.topic, key.partition, err)
+		}
+	}
+}
+
+func (p *pausedPartitions) release(tp TopicPartition) {
+	key := keyOf(tp)
+	if p.holders[key] == 0 {
+		// the partition was revoked meanwhile
+		return
+	}
+	p.holders[key]--
+	if p.holders[key] == 0 {
+		delete(p.holders, key)
+		if err := p.pauser.Resume([]TopicPartition{partitionOf(tp)}); err != nil {
+			log.Printf("Failed to resume %s [%d]: %v\n", key.topic, key.partition, err)
+		}
+	}
+}
+
+func partitionOf(tp TopicPartition) TopicPartition {
+	return TopicPartition{Topic: tp.Topic, Partition: tp.Partition}
+}
+
+// backoffDelay doubles base with every attempt and adds up to half of it as jitter,
+// so workers hitting the same outage do not retry in lockstep
+func backoffDelay(base time.Duration, attempt int) time.Duration {
+	delay := base << (attempt - 1)
+	return delay + rand.N(delay/2+1)
+}
+
+// retryMessage handles the message again while the failure is transient and attempts remain,
+// the partition is paused meanwhile
+func (c *Consumer) retryMessage(ctx context.Context, msg *Message, err error) error {
+	c.paused.pause(msg.TopicPartition)
+	defer c.paused.resume(msg.TopicPartition)
+
+	for attempt := 1; attempt <= c.retry && repository.IsTransient(err); attempt++ {
+		delay := backoffDelay(c.backoff, attempt)
+		log.Printf("Transient failure of message %s: %v. %dth attempt out of %d, wait %v\n", c.actor(msg), err, attempt, c.retry, delay)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		err = c.handleMessage(msg)
+	}
+	return err
+}
+
+// delayedPartition is a partition of a retry topic held back until its next message is due. A seekable source
+// is rewound to that message, which is fetched again once the partition resumes. Other sources deliver nothing
+// more of a paused partition, the message is held here instead
+type delayedPartition struct {
+	held    []*Message
+	rewound bool
+}
+
+// dueIn returns how long a message of a retry topic must still wait, 0 for a message of another topic
+func dueIn(msg *Message) time.Duration {
+	_, stage := stageOf(msg.TopicPartition.Topic)
+	if stage == 0 {
+		return 0
+	}
+	return max(time.Until(msg.Timestamp.Add(retryStages[stage-1].delay)), 0)
+}
+
+// delay runs on the polling goroutine and reports whether the message is held back, workers never wait
+// for a message to become due. The partition is paused until then, the other partitions keep being read
+func (c *Consumer) delay(ctx context.Context, msg *Message) bool {
+	key := keyOf(msg.TopicPartition)
+	if d, ok := c.delayed[key]; ok {
+		if !d.rewound {
+			d.held = append(d.held, msg)
+		}
+		return true
+	}
+	wait := dueIn(msg)
+	if wait <= 0 {
+		return false
+	}
+
+	c.paused.pause(msg.TopicPartition)
+	d := &delayedPartition{}
+	if source, ok := c.source.(SeekableSource); ok {
+		if err := source.Seek(msg.TopicPartition); err != nil {
+			log.Printf("Failed to rewind %s, the message is held until due: %v\n", msg.TopicPartition, err)
+		} else {
+			d.rewound = true
+		}
+	}
+	if !d.rewound {
+		d.held = []*Message{msg}
+	}
+	c.delayed[key] = d
+
+	tp := partitionOf(msg.TopicPartition)
+	time.AfterFunc(wait, func() {
+		select {
+		case c.due <- tp:
+		case <-ctx.Done():
+		}
+	})
+	return true
+}
+
+// release resumes a delayed partition once its message is due, held messages that are not due yet are delayed again.
+// A partition revoked or seeked meanwhile is not delayed anymore and is left alone
+func (c *Consumer) release(ctx context.Context, pool *workerPool, tp TopicPartition) {
+	d, ok := c.delayed[keyOf(tp)]
+	if !ok {
+		return
+	}
+	delete(c.delayed, keyOf(tp))
+	c.paused.resume(tp)
+	for _, msg := range d.held {
+		if !c.delay(ctx, msg) {
+			c.offsets.track(msg.TopicPartition)
+			pool.dispatch(msg)
+		}
+	}
+}
+
+// undelay drops the delayed state of partitions that were revoked or seeked, the held messages are read again
+func (c *Consumer) undelay(partitions []TopicPartition) {
+	for _, tp := range partitions {
+		if _, ok := c.delayed[keyOf(tp)]; ok {
+			delete(c.delayed, keyOf(tp))
+			c.paused.resume(tp)
+		}
+	}
+}
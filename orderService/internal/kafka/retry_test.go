@@ -0,0 +1,346 @@
+package eventHandler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"orderService/internal/service"
+	"orderService/internal/service/mocks"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakePauser struct {
+	mu      sync.Mutex
+	paused  []TopicPartition
+	resumed []TopicPartition
+}
+
+func (p *fakePauser) Pause(partitions []TopicPartition) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.paused = append(p.paused, partitions...)
+	return nil
+}
+
+func (p *fakePauser) Resume(partitions []TopicPartition) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.resumed = append(p.resumed, partitions...)
+	return nil
+}
+
+// fakeSink keeps the published messages with the topic they were published to
+type fakeSink struct {
+	published []Message
+	err       error
+}
+
+func (s *fakeSink) Publish(topic string, msg Message) error {
+	msg.TopicPartition.Topic = topic
+	s.published = append(s.published, msg)
+	return s.err
+}
+
+func (s *fakeSink) Close() error { return nil }
+
+var (
+	connectionLost = &pgconn.PgError{Code: "08006", Message: "connection failure"}
+	duplicateKey   = &pgconn.PgError{Code: "23505", Message: "duplicate key value violates unique constraint"}
+	invalidOrder   = errors.New("order_uid: must be a valid UUID")
+)
+
+func retryingConsumer(t *testing.T, orderService *mocks.IOrderService, p *fakePauser, r *router) *Consumer {
+	c := newConsumer(t, orderService)
+	c.paused = newPausedPartitions(p)
+	c.router = r
+	c.retry = 2
+	c.backoff = time.Millisecond
+	return c
+}
+
+func headers(msg Message) map[string]string {
+	values := make(map[string]string)
+	for _, h := range msg.Headers {
+		values[h.Key] = string(h.Value)
+	}
+	return values
+}
+
+func TestConsumer_failed(t *testing.T) {
+	order, err := os.ReadFile("registry/testdata/order.json")
+	require.NoError(t, err)
+
+	t.Run("TransientFailureRecovers", func(t *testing.T) {
+		mockService := new(mocks.IOrderService)
+		mockService.On("Create", mock.Anything, "memory:Orders/0@42").Return(connectionLost).Once()
+		mockService.On("Create", mock.Anything, "memory:Orders/0@42").Return(nil).Once()
+		p := &fakePauser{}
+		sink := &fakeSink{}
+
+		retryingConsumer(t, mockService, p, &router{sink: sink, retryTopics: true, deadLetter: "Orders.dlq"}).
+			failed(context.Background(), message(order), connectionLost)
+
+		mockService.AssertNumberOfCalls(t, "Create", 2)
+		assert.Equal(t, []TopicPartition{{Topic: topic, Partition: 0}}, p.paused)
+		assert.Equal(t, p.paused, p.resumed)
+		assert.Empty(t, sink.published)
+	})
+
+	t.Run("TransientFailureGoesToRetryTopic", func(t *testing.T) {
+		mockService := new(mocks.IOrderService)
+		mockService.On("Create", mock.Anything, mock.Anything).Return(connectionLost)
+		sink := &fakeSink{}
+		msg := message(order)
+		msg.Key = []byte("4e9ad8fb-2611-46f9-9458-20b59253086b")
+
+		retryingConsumer(t, mockService, &fakePauser{}, &router{sink: sink, retryTopics: true, deadLetter: "Orders.dlq"}).
+			failed(context.Background(), msg, connectionLost)
+
+		mockService.AssertNumberOfCalls(t, "Create", 2)
+		require.Len(t, sink.published, 1)
+		routed := sink.published[0]
+		assert.Equal(t, "Orders.retry.1m", routed.TopicPartition.Topic)
+		assert.Equal(t, msg.Key, routed.Key)
+		assert.Equal(t, order, routed.Value)
+		assert.Equal(t, map[string]string{
+			ErrorHeader:             connectionLost.Error(),
+			OriginalTopicHeader:     "Orders",
+			OriginalPartitionHeader: "0",
+			OriginalOffsetHeader:    "42",
+		}, headers(routed))
+	})
+
+	t.Run("LastRetryStageGoesToDeadLetter", func(t *testing.T) {
+		mockService := new(mocks.IOrderService)
+		mockService.On("Create", mock.Anything, mock.Anything).Return(connectionLost)
+		sink := &fakeSink{}
+		retryTopic := "Orders.retry.10m"
+		msg := message(order,
+			Header{Key: ErrorHeader, Value: []byte("timeout")},
+			Header{Key: OriginalTopicHeader, Value: []byte("Orders")},
+			Header{Key: OriginalPartitionHeader, Value: []byte("3")},
+			Header{Key: OriginalOffsetHeader, Value: []byte("7")},
+		)
+		msg.TopicPartition.Topic = retryTopic
+
+		retryingConsumer(t, mockService, &fakePauser{}, &router{sink: sink, retryTopics: true, deadLetter: "Orders.dlq"}).
+			failed(context.Background(), msg, connectionLost)
+
+		require.Len(t, sink.published, 1)
+		assert.Equal(t, "Orders.dlq", sink.published[0].TopicPartition.Topic)
+		assert.Equal(t, map[string]string{
+			ErrorHeader:             connectionLost.Error(),
+			OriginalTopicHeader:     "Orders",
+			OriginalPartitionHeader: "3",
+			OriginalOffsetHeader:    "7",
+		}, headers(sink.published[0]))
+	})
+
+	t.Run("PermanentFailureIsNotRetried", func(t *testing.T) {
+		mockService := new(mocks.IOrderService)
+		p := &fakePauser{}
+		sink := &fakeSink{}
+
+		retryingConsumer(t, mockService, p, &router{sink: sink, retryTopics: true, deadLetter: "Orders.dlq"}).
+			failed(context.Background(), message(order), invalidOrder)
+
+		mockService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		assert.Empty(t, p.paused)
+		require.Len(t, sink.published, 1)
+		assert.Equal(t, "Orders.dlq", sink.published[0].TopicPartition.Topic)
+	})
+
+	t.Run("WithoutDeadLetterTopic", func(t *testing.T) {
+		sink := &fakeSink{}
+
+		retryingConsumer(t, new(mocks.IOrderService), &fakePauser{}, &router{sink: sink, retryTopics: true}).
+			failed(context.Background(), message(order), invalidOrder)
+
+		assert.Empty(t, sink.published)
+	})
+
+	t.Run("DuplicateIsDone", func(t *testing.T) {
+		for _, err := range []error{duplicateKey, fmt.Errorf("%w: %s", service.ErrOrderExists, "4e9ad8fb-2611-46f9-9458-20b59253086b")} {
+			sink := &fakeSink{}
+			c := retryingConsumer(t, new(mocks.IOrderService), &fakePauser{}, &router{sink: sink, deadLetter: "Orders.dlq"})
+			msg := message(order)
+			c.offsets.track(msg.TopicPartition)
+
+			c.failed(context.Background(), msg, err)
+			c.offsets.done(msg.TopicPartition)
+
+			assert.Empty(t, sink.published, err)
+			assert.Equal(t, []TopicPartition{tp(0, 43)}, c.offsets.committable(nil), err)
+			stats, _ := c.stats.of(msg.TopicPartition)
+			assert.Zero(t, stats.errors, err)
+		}
+	})
+
+	t.Run("TransientFailureHidingAnInsertIsDone", func(t *testing.T) {
+		mockService := new(mocks.IOrderService)
+		mockService.On("Create", mock.Anything, mock.Anything).Return(duplicateKey)
+		sink := &fakeSink{}
+
+		retryingConsumer(t, mockService, &fakePauser{}, &router{sink: sink, retryTopics: true, deadLetter: "Orders.dlq"}).
+			failed(context.Background(), message(order), connectionLost)
+
+		mockService.AssertNumberOfCalls(t, "Create", 1)
+		assert.Empty(t, sink.published)
+	})
+
+	t.Run("CancelledRetryIsRedelivered", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		c := retryingConsumer(t, new(mocks.IOrderService), &fakePauser{}, nil)
+		msg := message(order)
+		c.offsets.track(msg.TopicPartition)
+
+		c.failed(ctx, msg, connectionLost)
+		c.offsets.done(msg.TopicPartition)
+
+		assert.Equal(t, []TopicPartition{msg.TopicPartition}, c.offsets.committable(nil))
+	})
+
+	t.Run("RouteFailureIsRedelivered", func(t *testing.T) {
+		c := retryingConsumer(t, new(mocks.IOrderService), &fakePauser{}, &router{sink: &fakeSink{err: fmt.Errorf("broker down")}, deadLetter: "Orders.dlq"})
+		msg := message(order)
+		c.offsets.track(msg.TopicPartition)
+
+		c.failed(context.Background(), msg, invalidOrder)
+		c.offsets.done(msg.TopicPartition)
+
+		assert.Equal(t, []TopicPartition{msg.TopicPartition}, c.offsets.committable(nil))
+		assert.Equal(t, []TopicPartition{msg.TopicPartition}, c.offsets.abandoned())
+	})
+}
+
+func TestConsumer_delay(t *testing.T) {
+	retryTopic := "Orders.retry.1m"
+	// almost due, so the test does not wait for the whole stage delay
+	soon := func() time.Time { return time.Now().Add(-time.Minute + 50*time.Millisecond) }
+	dispatched := func(t *testing.T) (*workerPool, chan *Message) {
+		handled := make(chan *Message, 10)
+		pool := newWorkerPool(1, OrderingPartition, 1, 0, func(batch []*Message) { handled <- batch[0] })
+		t.Cleanup(pool.close)
+		return pool, handled
+	}
+	awaitDue := func(t *testing.T, c *Consumer) TopicPartition {
+		select {
+		case tp := <-c.due:
+			return tp
+		case <-time.After(time.Second):
+			t.Fatal("the delayed partition did not become due")
+			return TopicPartition{}
+		}
+	}
+
+	t.Run("DueMessage", func(t *testing.T) {
+		p := &fakePauser{}
+		c := retryingConsumer(t, new(mocks.IOrderService), p, nil)
+		msg := &Message{TopicPartition: TopicPartition{Topic: retryTopic}, Timestamp: time.Now().Add(-2 * time.Minute)}
+
+		assert.False(t, c.delay(context.Background(), msg))
+		assert.False(t, c.delay(context.Background(), &Message{TopicPartition: tp(0, 1), Timestamp: time.Now()}), "not a retry topic")
+		assert.Empty(t, p.paused)
+	})
+
+	t.Run("SeekableSourceIsRewound", func(t *testing.T) {
+		source := NewMemorySource(1)
+		require.NoError(t, source.Subscribe([]string{retryTopic}, nil))
+		source.Produce(retryTopic, Message{Value: []byte("retried"), Timestamp: soon()})
+		c := newConsumer(t, new(mocks.IOrderService))
+		c.source, c.paused = source, newPausedPartitions(source)
+		pool, handled := dispatched(t)
+
+		msg := poll(t, source)
+		require.True(t, c.delay(context.Background(), msg))
+		assert.Nil(t, poll(t, source), "the partition is paused until the message is due")
+
+		c.release(context.Background(), pool, awaitDue(t, c))
+		assert.Empty(t, handled, "the rewound message is fetched again")
+		again := poll(t, source)
+		require.NotNil(t, again)
+		assert.Equal(t, msg.TopicPartition, again.TopicPartition)
+		assert.False(t, c.delay(context.Background(), again))
+	})
+
+	t.Run("OtherSourcesHoldTheMessages", func(t *testing.T) {
+		p := &fakePauser{}
+		c := retryingConsumer(t, new(mocks.IOrderService), p, nil)
+		// embedding hides the seek methods of the memory source
+		c.source = struct{ MessageSource }{NewMemorySource(1)}
+		pool, handled := dispatched(t)
+		first := &Message{TopicPartition: TopicPartition{Topic: retryTopic, Offset: 1}, Timestamp: soon()}
+		second := &Message{TopicPartition: TopicPartition{Topic: retryTopic, Offset: 2}, Timestamp: time.Now()}
+
+		require.True(t, c.delay(context.Background(), first))
+		require.True(t, c.delay(context.Background(), second))
+		c.release(context.Background(), pool, awaitDue(t, c))
+
+		assert.Equal(t, first, <-handled)
+		assert.Equal(t, []*Message{second}, c.delayed[keyOf(second.TopicPartition)].held, "the second message is not due yet")
+		assert.Len(t, p.paused, 2)
+		assert.Len(t, p.resumed, 1)
+	})
+}
+
+func TestPausedPartitions(t *testing.T) {
+	p := &fakePauser{}
+	paused := newPausedPartitions(p)
+
+	paused.pause(tp(0, 1))
+	paused.pause(tp(0, 2))
+	paused.resume(tp(0, 1))
+	assert.Empty(t, p.resumed)
+
+	paused.resume(tp(0, 2))
+	assert.Len(t, p.paused, 1)
+	assert.Len(t, p.resumed, 1)
+
+	t.Run("RevokedPartitionIsForgotten", func(t *testing.T) {
+		p := &fakePauser{}
+		paused := newPausedPartitions(p)
+		paused.pauseByAdmin(tp(0, 0))
+		paused.pause(tp(0, 1))
+
+		paused.forget([]TopicPartition{tp(0, offsetUnset)})
+		paused.resume(tp(0, 1))
+
+		isPaused, byAdmin := paused.state(tp(0, 0))
+		assert.False(t, isPaused)
+		assert.False(t, byAdmin)
+		assert.Empty(t, p.resumed, "the revoked partition is not resumed")
+		assert.True(t, paused.pauseByAdmin(tp(0, 0)), "the partition assigned again can be paused")
+		assert.Len(t, p.paused, 2)
+	})
+}
+
+func TestStageOf(t *testing.T) {
+	for topic, expected := range map[string]struct {
+		base  string
+		stage int
+	}{
+		"Orders":           {"Orders", 0},
+		"Orders.retry.1m":  {"Orders", 1},
+		"Orders.retry.10m": {"Orders", 2},
+	} {
+		base, stage := stageOf(topic)
+		assert.Equal(t, expected.base, base, topic)
+		assert.Equal(t, expected.stage, stage, topic)
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	for attempt, base := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 400 * time.Millisecond} {
+		delay := backoffDelay(100*time.Millisecond, attempt)
+		assert.GreaterOrEqual(t, delay, base)
+		assert.LessOrEqual(t, delay, base+base/2)
+	}
+}
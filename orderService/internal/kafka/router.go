@@ -0,0 +1,60 @@
+package eventHandler
+
+import (
+	"orderService/internal/repository"
+	"strconv"
+)
+
+// Headers describing why and where from a message was republished
+const (
+	ErrorHeader             = "x-error"
+	OriginalTopicHeader     = "x-original-topic"
+	OriginalPartitionHeader = "x-original-partition"
+	OriginalOffsetHeader    = "x-original-offset"
+)
+
+// router republishes messages that failed for good: transient failures go to the next retry topic
+// when retry topics are enabled, everything else and the last stage to the dead-letter topic
+type router struct {
+	sink        MessageSink
+	retryTopics bool
+	deadLetter  string
+}
+
+// destination returns an empty topic when the message is to be dropped
+func (r router) destination(topic string, cause error) string {
+	base, stage := stageOf(topic)
+	if r.retryTopics && repository.IsTransient(cause) && stage < len(retryStages) {
+		return base + retryStages[stage].suffix
+	}
+	return r.deadLetter
+}
+
+// route waits for the broker to acknowledge the republished message
+func (r router) route(msg *Message, cause error) (string, error) {
+	topic := r.destination(msg.TopicPartition.Topic, cause)
+	if topic == "" {
+		return "", nil
+	}
+
+	return topic, r.sink.Publish(topic, Message{Key: msg.Key, Value: msg.Value, Headers: routedHeaders(msg, cause)})
+}
+
+// routedHeaders keeps the headers of the message and the origin of its first failure
+func routedHeaders(msg *Message, cause error) []Header {
+	headers := make([]Header, 0, len(msg.Headers)+4)
+	for _, h := range msg.Headers {
+		if h.Key != ErrorHeader {
+			headers = append(headers, h)
+		}
+	}
+	headers = append(headers, Header{Key: ErrorHeader, Value: []byte(cause.Error())})
+	if header(msg, OriginalTopicHeader) == "" {
+		headers = append(headers,
+			Header{Key: OriginalTopicHeader, Value: []byte(msg.TopicPartition.Topic)},
+			Header{Key: OriginalPartitionHeader, Value: []byte(strconv.Itoa(int(msg.TopicPartition.Partition)))},
+			Header{Key: OriginalOffsetHeader, Value: []byte(strconv.FormatInt(msg.TopicPartition.Offset, 10))},
+		)
+	}
+	return headers
+}
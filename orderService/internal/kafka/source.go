@@ -0,0 +1,73 @@
+package eventHandler
+
+import (
+	"fmt"
+	"time"
+)
+
+// TopicPartition is a partition of a topic, Offset is the position of a message in it
+// or, when committed, the offset of the next message to read
+type TopicPartition struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+}
+
+func (tp TopicPartition) String() string {
+	return fmt.Sprintf("%s[%d]@%d", tp.Topic, tp.Partition, tp.Offset)
+}
+
+type Header struct {
+	Key   string
+	Value []byte
+}
+
+// Message is a record read from a MessageSource, Timestamp is the time it was produced
+type Message struct {
+	TopicPartition TopicPartition
+	Key            []byte
+	Value          []byte
+	Headers        []Header
+	Timestamp      time.Time
+}
+
+// MessageSource is the broker the Consumer reads orders from. Messages of a partition are delivered
+// in offset order, a broker without partitions delivers every topic as partition 0
+type MessageSource interface {
+	// Name identifies the broker in the audit actor of its messages
+	Name() string
+	// Subscribe starts reading the topics. revoked runs on the polling goroutine before partitions move
+	// to another member, lost reports that they moved already and their offsets must not be committed
+	Subscribe(topics []string, revoked func(partitions []TopicPartition, lost bool)) error
+	// Poll returns a nil message when none arrived within timeout
+	Poll(timeout time.Duration) (*Message, error)
+	// Commit stores the offsets of the next messages to read
+	Commit(offsets []TopicPartition) error
+	Pause(partitions []TopicPartition) error
+	Resume(partitions []TopicPartition) error
+	Close() error
+}
+
+// MessageSink republishes messages to the retry and dead-letter topics
+type MessageSink interface {
+	// Publish returns once the broker acknowledged the message, the partition of msg is ignored
+	Publish(topic string, msg Message) error
+	Close() error
+}
+
+// SeekableSource is a MessageSource that reports its partitions and offsets and can be rewound,
+// the admin endpoints of the consumer need it
+type SeekableSource interface {
+	MessageSource
+	// Assignment lists the partitions currently read
+	Assignment() ([]TopicPartition, error)
+	// Committed returns the committed offsets of the partitions, offsetUnset when none was committed
+	Committed(partitions []TopicPartition) ([]TopicPartition, error)
+	// HighWatermark returns the offset the next message produced to the partition gets
+	HighWatermark(tp TopicPartition) (int64, error)
+	// OffsetForTime returns the offset of the first message produced at or after ts,
+	// the high watermark when there is none
+	OffsetForTime(tp TopicPartition, ts time.Time) (int64, error)
+	// Seek makes the next poll of the partition return the message at its offset
+	Seek(tp TopicPartition) error
+}
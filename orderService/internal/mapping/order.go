@@ -0,0 +1,131 @@
+// Package mapping converts orders between the models and the protobuf messages of api/proto,
+// which the gRPC server and the Kafka protobuf decoder share
+package mapping
+
+import (
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"orderService/internal/models"
+	"orderService/rpc/orderpb"
+)
+
+// ToOrder maps the protobuf order message to the model. The uid is left empty if it is not a UUID,
+// so Order.Validate rejects it
+func ToOrder(order *orderpb.Order) models.Order {
+	uid, _ := uuid.Parse(order.GetOrderUid())
+
+	items := make([]models.Item, 0, len(order.GetItems()))
+	for _, item := range order.GetItems() {
+		items = append(items, models.Item{
+			ChrtID:      int(item.GetChrtId()),
+			TrackNumber: item.GetTrackNumber(),
+			Price:       int(item.GetPrice()),
+			RID:         item.GetRid(),
+			Name:        item.GetName(),
+			Sale:        int(item.GetSale()),
+			Size:        item.GetSize(),
+			TotalPrice:  int(item.GetTotalPrice()),
+			NmID:        int(item.GetNmId()),
+			Brand:       item.GetBrand(),
+			Status:      int(item.GetStatus()),
+		})
+	}
+
+	delivery := order.GetDelivery()
+	payment := order.GetPayment()
+	result := models.Order{
+		Uid:               uid,
+		TrackNumber:       order.GetTrackNumber(),
+		Entry:             order.GetEntry(),
+		Locale:            order.GetLocale(),
+		InternalSignature: order.GetInternalSignature(),
+		CustomerID:        order.GetCustomerId(),
+		DeliveryService:   order.GetDeliveryService(),
+		ShardKey:          order.GetShardkey(),
+		SmID:              int(order.GetSmId()),
+		OofShard:          order.GetOofShard(),
+		Delivery: models.Delivery{
+			Name:    delivery.GetName(),
+			Phone:   delivery.GetPhone(),
+			Zip:     delivery.GetZip(),
+			City:    delivery.GetCity(),
+			Address: delivery.GetAddress(),
+			Region:  delivery.GetRegion(),
+			Email:   delivery.GetEmail(),
+		},
+		Payment: models.Payment{
+			Transaction:  payment.GetTransaction(),
+			RequestID:    payment.GetRequestId(),
+			Currency:     payment.GetCurrency(),
+			Provider:     payment.GetProvider(),
+			Amount:       int(payment.GetAmount()),
+			PaymentDt:    int(payment.GetPaymentDt()),
+			Bank:         payment.GetBank(),
+			DeliveryCost: int(payment.GetDeliveryCost()),
+			GoodsTotal:   int(payment.GetGoodsTotal()),
+			CustomFee:    int(payment.GetCustomFee()),
+		},
+		Items: items,
+	}
+	if order.GetDateCreated() != nil {
+		result.DateCreated = order.GetDateCreated().AsTime()
+	}
+
+	return result
+}
+
+// ToOrderPb maps the model to the protobuf order message
+func ToOrderPb(order models.Order) *orderpb.Order {
+	items := make([]*orderpb.Item, 0, len(order.Items))
+	for _, item := range order.Items {
+		items = append(items, &orderpb.Item{
+			ChrtId:      int64(item.ChrtID),
+			TrackNumber: item.TrackNumber,
+			Price:       int64(item.Price),
+			Rid:         item.RID,
+			Name:        item.Name,
+			Sale:        int64(item.Sale),
+			Size:        item.Size,
+			TotalPrice:  int64(item.TotalPrice),
+			NmId:        int64(item.NmID),
+			Brand:       item.Brand,
+			Status:      int32(item.Status),
+		})
+	}
+
+	return &orderpb.Order{
+		OrderUid:          order.Uid.String(),
+		TrackNumber:       order.TrackNumber,
+		Entry:             order.Entry,
+		Locale:            order.Locale,
+		InternalSignature: order.InternalSignature,
+		CustomerId:        order.CustomerID,
+		DeliveryService:   order.DeliveryService,
+		Shardkey:          order.ShardKey,
+		SmId:              int64(order.SmID),
+		DateCreated:       timestamppb.New(order.DateCreated),
+		OofShard:          order.OofShard,
+		Delivery: &orderpb.Delivery{
+			Name:    order.Delivery.Name,
+			Phone:   order.Delivery.Phone,
+			Zip:     order.Delivery.Zip,
+			City:    order.Delivery.City,
+			Address: order.Delivery.Address,
+			Region:  order.Delivery.Region,
+			Email:   order.Delivery.Email,
+		},
+		Payment: &orderpb.Payment{
+			Transaction:  order.Payment.Transaction,
+			RequestId:    order.Payment.RequestID,
+			Currency:     order.Payment.Currency,
+			Provider:     order.Payment.Provider,
+			Amount:       int64(order.Payment.Amount),
+			PaymentDt:    int64(order.Payment.PaymentDt),
+			Bank:         order.Payment.Bank,
+			DeliveryCost: int64(order.Payment.DeliveryCost),
+			GoodsTotal:   int64(order.Payment.GoodsTotal),
+			CustomFee:    int64(order.Payment.CustomFee),
+		},
+		Items: items,
+	}
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+type ApiKey struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"column:name"`
+	KeyHash   string `gorm:"column:key_hash"`
+	Scopes    string `gorm:"column:scopes"`
+	Roles     string `gorm:"column:roles"`
+	CreatedAt time.Time
+	RevokedAt *time.Time
+}
+
+func (k *ApiKey) TableName() string {
+	return "api_key"
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	AuditOrderCreated       = "order.created"
+	AuditOrderStatusUpdated = "order.status_updated"
+	AuditOrderAmended       = "order.amended"
+	AuditOrderViewed        = "order.personal_data_viewed"
+	AuditOrderSoftDeleted   = "order.soft_deleted"
+	AuditOrderPurged        = "order.purged"
+	AuditPersonalDataErased = "customer.personal_data_erased"
+	AuditActorRetentionJob  = "system:retention"
+)
+
+// AuditEntry records who did what to an order or a customer. Diff maps every changed field path to its old and new value
+type AuditEntry struct {
+	ID         uint64          `gorm:"primaryKey" json:"id"`
+	OccurredAt time.Time       `gorm:"column:occurred_at" json:"occurred_at"`
+	Actor      string          `gorm:"column:actor" json:"actor"`
+	Action     string          `gorm:"column:action" json:"action"`
+	OrderUid   *uuid.UUID      `gorm:"column:order_uid" json:"order_uid,omitempty"`
+	CustomerID string          `gorm:"column:customer_id" json:"customer_id,omitempty"`
+	Details    json.RawMessage `gorm:"column:details;type:jsonb" json:"details,omitempty" swaggertype:"object"`
+	Diff       json.RawMessage `gorm:"column:diff;type:jsonb" json:"diff,omitempty" swaggertype:"object"`
+}
+
+func (a *AuditEntry) TableName() string {
+	return "audit_log"
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/base64"
+	"errors"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+// OrderCursor points to an order in the (date_created DESC, uid DESC) ordering used for pagination
+type OrderCursor struct {
+	DateCreated time.Time
+	Uid         uuid.UUID
+}
+
+func NewOrderCursor(order Order) OrderCursor {
+	return OrderCursor{DateCreated: order.DateCreated, Uid: order.Uid}
+}
+
+func (c OrderCursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.DateCreated.UTC().Format(time.RFC3339Nano) + "|" + c.Uid.String()))
+}
+
+func DecodeOrderCursor(cursor string) (OrderCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return OrderCursor{}, errors.New("cursor is not valid")
+	}
+
+	dateStr, uidStr, ok := strings.Cut(string(data), "|")
+	if !ok {
+		return OrderCursor{}, errors.New("cursor is not valid")
+	}
+
+	dateCreated, err := time.Parse(time.RFC3339Nano, dateStr)
+	if err != nil {
+		return OrderCursor{}, errors.New("cursor is not valid")
+	}
+	uid, err := uuid.Parse(uidStr)
+	if err != nil {
+		return OrderCursor{}, errors.New("cursor is not valid")
+	}
+
+	return OrderCursor{DateCreated: dateCreated, Uid: uid}, nil
+}
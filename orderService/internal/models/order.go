@@ -1,13 +1,16 @@
 package models
 
 import (
+	"errors"
+	"fmt"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 	"time"
 )
 
 type Delivery struct {
-	ID      uint   `gorm:"primaryKey"`
+	ID      uint   `gorm:"primaryKey" json:"-"`
 	Name    string `json:"name" validate:"required"`
 	Phone   string `json:"phone" validate:"required_without=Email,omitempty,e164"`
 	Zip     string `json:"zip" validate:"numeric"`
@@ -22,7 +25,7 @@ func (d *Delivery) TableName() string {
 }
 
 type Payment struct {
-	ID           uint   `gorm:"primaryKey"`
+	ID           uint   `gorm:"primaryKey" json:"-"`
 	Transaction  string `json:"transaction" validate:"alphanum"`
 	RequestID    string `json:"request_id"`
 	Currency     string `json:"currency" validate:"alpha"`
@@ -41,7 +44,7 @@ func (p *Payment) TableName() string {
 
 type Item struct {
 	Id          uint32    `gorm:"primaryKey" json:"-"`
-	OrderUid    uuid.UUID `json:"_" gorm:"column:order_uid"`
+	OrderUid    uuid.UUID `json:"-" gorm:"column:order_uid"`
 	ChrtID      int       `json:"chrt_id" validate:"required"`
 	TrackNumber string    `json:"track_number" validate:"alphanum"`
 	Price       int       `json:"price" validate:"required"`
@@ -76,6 +79,11 @@ type Order struct {
 	PaymentID         uint      `json:"-" gorm:"column:payment_id"`
 	Payment           Payment   `json:"payment"`
 	Items             []Item    `gorm:"foreignKey:OrderUid;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"items"`
+	// Version is incremented by every amendment and is sent to clients as the ETag
+	Version   int        `gorm:"column:version" json:"-"`
+	AmendedAt *time.Time `gorm:"column:amended_at" json:"-"`
+	// Soft deleted orders are hidden from every query, they are removed for good by the retention job
+	DeletedAt gorm.DeletedAt `json:"-"`
 }
 
 func (o *Order) TableName() string {
@@ -98,6 +106,27 @@ func (o *Order) Validate() error {
 	return nil
 }
 
+// ErrPaymentMismatch is returned when the payment totals do not add up with the items
+var ErrPaymentMismatch = errors.New("payment does not match the order")
+
+// CheckPayment verifies that goods_total is the sum of the items total prices and
+// amount is goods_total plus delivery_cost and custom_fee
+func (o *Order) CheckPayment() error {
+	goodsTotal := 0
+	for _, item := range o.Items {
+		goodsTotal += item.TotalPrice
+	}
+	if goodsTotal != o.Payment.GoodsTotal {
+		return fmt.Errorf("%w: goods_total is %d, items total is %d", ErrPaymentMismatch, o.Payment.GoodsTotal, goodsTotal)
+	}
+
+	amount := o.Payment.GoodsTotal + o.Payment.DeliveryCost + o.Payment.CustomFee
+	if amount != o.Payment.Amount {
+		return fmt.Errorf("%w: amount is %d, goods_total with delivery_cost and custom_fee is %d", ErrPaymentMismatch, o.Payment.Amount, amount)
+	}
+	return nil
+}
+
 func (o *Order) ToOrderView() OrderView {
 	viewItems := make([]ItemView, 0, len(o.Items))
 	for _, item := range o.Items {
@@ -107,7 +136,14 @@ func (o *Order) ToOrderView() OrderView {
 			Brand:      item.Brand,
 		})
 	}
+	lastModified := o.DateCreated
+	if o.AmendedAt != nil {
+		lastModified = *o.AmendedAt
+	}
 	return OrderView{
+		Uid:             o.Uid,
+		TrackNumber:     o.TrackNumber,
+		CustomerID:      o.CustomerID,
 		DeliveryService: o.DeliveryService,
 		DateCreated:     o.DateCreated,
 		Delivery: DeliveryView{
@@ -126,6 +162,8 @@ func (o *Order) ToOrderView() OrderView {
 			DeliveryCost: o.Payment.DeliveryCost,
 			GoodsTotal:   o.Payment.GoodsTotal,
 		},
-		Items: viewItems,
+		Items:        viewItems,
+		Version:      o.Version,
+		LastModified: lastModified,
 	}
 }
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// OrderFilter selects orders created in [From, To) of a customer. Zero values are not applied
+type OrderFilter struct {
+	From       time.Time
+	To         time.Time
+	CustomerID string
+}
+
+// ParseOrderFilter reads RFC3339 times or YYYY-MM-DD dates, empty strings leave the bound open
+func ParseOrderFilter(from, to, customerID string) (OrderFilter, error) {
+	filter := OrderFilter{CustomerID: customerID}
+
+	var err error
+	if filter.From, err = parseFilterTime(from); err != nil {
+		return OrderFilter{}, errors.New("from must be RFC3339 time or YYYY-MM-DD date")
+	}
+	if filter.To, err = parseFilterTime(to); err != nil {
+		return OrderFilter{}, errors.New("to must be RFC3339 time or YYYY-MM-DD date")
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return OrderFilter{}, errors.New("from must be before to")
+	}
+
+	return filter, nil
+}
+
+func parseFilterTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if date, err := time.Parse(time.DateOnly, value); err == nil {
+		return date, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
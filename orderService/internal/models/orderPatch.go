@@ -0,0 +1,31 @@
+package models
+
+// DeliveryPatch holds the delivery fields to change, omitted fields are kept
+type DeliveryPatch struct {
+	Name    *string `json:"name"`
+	Phone   *string `json:"phone"`
+	Zip     *string `json:"zip"`
+	City    *string `json:"city"`
+	Address *string `json:"address"`
+	Region  *string `json:"region"`
+	Email   *string `json:"email"`
+}
+
+func (p DeliveryPatch) Apply(delivery *Delivery) {
+	for _, field := range []struct {
+		value  *string
+		target *string
+	}{
+		{p.Name, &delivery.Name},
+		{p.Phone, &delivery.Phone},
+		{p.Zip, &delivery.Zip},
+		{p.City, &delivery.City},
+		{p.Address, &delivery.Address},
+		{p.Region, &delivery.Region},
+		{p.Email, &delivery.Email},
+	} {
+		if field.value != nil {
+			*field.target = *field.value
+		}
+	}
+}
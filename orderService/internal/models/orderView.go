@@ -1,6 +1,13 @@
 package models
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -30,9 +37,37 @@ type ItemView struct {
 
 //easyjson:json
 type OrderView struct {
+	Uid             uuid.UUID
+	TrackNumber     string
+	CustomerID      string
 	DeliveryService string
 	DateCreated     time.Time
 	Delivery        DeliveryView
 	Payment         PaymentView
 	Items           []ItemView
+	// Version is not serialized, it is sent in the ETag header
+	Version int `json:"-"`
+	// LastModified is the time of the last amendment or the creation time
+	LastModified time.Time `json:"-"`
+	// ETag is computed once by WithETag and cached with the view
+	ETag string `json:"-"`
+}
+
+// WithETag returns the view with its ETag "<version>-<content hash>". A view that already has one is returned as is
+func (v OrderView) WithETag() OrderView {
+	if v.ETag != "" {
+		return v
+	}
+
+	content, _ := json.Marshal(v)
+	sum := sha256.Sum256(content)
+	v.ETag = fmt.Sprintf(`"%d-%s"`, v.Version, hex.EncodeToString(sum[:8]))
+	return v
+}
+
+// ParseETagVersion returns the order version of an ETag made by WithETag, a weak ETag is accepted
+func ParseETagVersion(etag string) (int, error) {
+	etag = strings.Trim(strings.TrimPrefix(etag, "W/"), `"`)
+	version, _, _ := strings.Cut(etag, "-")
+	return strconv.Atoi(version)
 }
@@ -17,7 +17,7 @@ var (
 	_ easyjson.Marshaler
 )
 
-func easyjsonC80ae7adDecodeOrderServiceInternalModels(in *jlexer.Lexer, out *Payment) {
+func easyjson120d1ca2DecodeOrderServiceInternalModels(in *jlexer.Lexer, out *Payment) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -36,8 +36,6 @@ func easyjsonC80ae7adDecodeOrderServiceInternalModels(in *jlexer.Lexer, out *Pay
 			continue
 		}
 		switch key {
-		case "ID":
-			out.ID = uint(in.Uint())
 		case "transaction":
 			out.Transaction = string(in.String())
 		case "request_id":
@@ -68,18 +66,18 @@ func easyjsonC80ae7adDecodeOrderServiceInternalModels(in *jlexer.Lexer, out *Pay
 		in.Consumed()
 	}
 }
-func easyjsonC80ae7adEncodeOrderServiceInternalModels(out *jwriter.Writer, in Payment) {
+func easyjson120d1ca2EncodeOrderServiceInternalModels(out *jwriter.Writer, in Payment) {
 	out.RawByte('{')
 	first := true
 	_ = first
-	{
-		const prefix string = ",\"ID\":"
-		out.RawString(prefix[1:])
-		out.Uint(uint(in.ID))
-	}
 	{
 		const prefix string = ",\"transaction\":"
-		out.RawString(prefix)
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
 		out.String(string(in.Transaction))
 	}
 	{
@@ -133,27 +131,27 @@ func easyjsonC80ae7adEncodeOrderServiceInternalModels(out *jwriter.Writer, in Pa
 // MarshalJSON supports json.Marshaler interface
 func (v Payment) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonC80ae7adEncodeOrderServiceInternalModels(&w, v)
+	easyjson120d1ca2EncodeOrderServiceInternalModels(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Payment) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonC80ae7adEncodeOrderServiceInternalModels(w, v)
+	easyjson120d1ca2EncodeOrderServiceInternalModels(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Payment) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonC80ae7adDecodeOrderServiceInternalModels(&r, v)
+	easyjson120d1ca2DecodeOrderServiceInternalModels(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Payment) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonC80ae7adDecodeOrderServiceInternalModels(l, v)
+	easyjson120d1ca2DecodeOrderServiceInternalModels(l, v)
 }
-func easyjsonC80ae7adDecodeOrderServiceInternalModels1(in *jlexer.Lexer, out *Order) {
+func easyjson120d1ca2DecodeOrderServiceInternalModels1(in *jlexer.Lexer, out *Order) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -235,7 +233,7 @@ func easyjsonC80ae7adDecodeOrderServiceInternalModels1(in *jlexer.Lexer, out *Or
 		in.Consumed()
 	}
 }
-func easyjsonC80ae7adEncodeOrderServiceInternalModels1(out *jwriter.Writer, in Order) {
+func easyjson120d1ca2EncodeOrderServiceInternalModels1(out *jwriter.Writer, in Order) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -326,27 +324,27 @@ func easyjsonC80ae7adEncodeOrderServiceInternalModels1(out *jwriter.Writer, in O
 // MarshalJSON supports json.Marshaler interface
 func (v Order) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonC80ae7adEncodeOrderServiceInternalModels1(&w, v)
+	easyjson120d1ca2EncodeOrderServiceInternalModels1(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Order) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonC80ae7adEncodeOrderServiceInternalModels1(w, v)
+	easyjson120d1ca2EncodeOrderServiceInternalModels1(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Order) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonC80ae7adDecodeOrderServiceInternalModels1(&r, v)
+	easyjson120d1ca2DecodeOrderServiceInternalModels1(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Order) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonC80ae7adDecodeOrderServiceInternalModels1(l, v)
+	easyjson120d1ca2DecodeOrderServiceInternalModels1(l, v)
 }
-func easyjsonC80ae7adDecodeOrderServiceInternalModels2(in *jlexer.Lexer, out *Item) {
+func easyjson120d1ca2DecodeOrderServiceInternalModels2(in *jlexer.Lexer, out *Item) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -365,10 +363,6 @@ func easyjsonC80ae7adDecodeOrderServiceInternalModels2(in *jlexer.Lexer, out *It
 			continue
 		}
 		switch key {
-		case "_":
-			if data := in.UnsafeBytes(); in.Ok() {
-				in.AddError((out.OrderUid).UnmarshalText(data))
-			}
 		case "chrt_id":
 			out.ChrtID = int(in.Int())
 		case "track_number":
@@ -401,23 +395,18 @@ func easyjsonC80ae7adDecodeOrderServiceInternalModels2(in *jlexer.Lexer, out *It
 		in.Consumed()
 	}
 }
-func easyjsonC80ae7adEncodeOrderServiceInternalModels2(out *jwriter.Writer, in Item) {
+func easyjson120d1ca2EncodeOrderServiceInternalModels2(out *jwriter.Writer, in Item) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"_\":"
+		const prefix string = ",\"chrt_id\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		out.RawText((in.OrderUid).MarshalText())
-	}
-	{
-		const prefix string = ",\"chrt_id\":"
-		out.RawString(prefix)
 		out.Int(int(in.ChrtID))
 	}
 	{
@@ -476,27 +465,27 @@ func easyjsonC80ae7adEncodeOrderServiceInternalModels2(out *jwriter.Writer, in I
 // MarshalJSON supports json.Marshaler interface
 func (v Item) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonC80ae7adEncodeOrderServiceInternalModels2(&w, v)
+	easyjson120d1ca2EncodeOrderServiceInternalModels2(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Item) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonC80ae7adEncodeOrderServiceInternalModels2(w, v)
+	easyjson120d1ca2EncodeOrderServiceInternalModels2(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Item) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonC80ae7adDecodeOrderServiceInternalModels2(&r, v)
+	easyjson120d1ca2DecodeOrderServiceInternalModels2(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Item) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonC80ae7adDecodeOrderServiceInternalModels2(l, v)
+	easyjson120d1ca2DecodeOrderServiceInternalModels2(l, v)
 }
-func easyjsonC80ae7adDecodeOrderServiceInternalModels3(in *jlexer.Lexer, out *Delivery) {
+func easyjson120d1ca2DecodeOrderServiceInternalModels3(in *jlexer.Lexer, out *Delivery) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -515,8 +504,6 @@ func easyjsonC80ae7adDecodeOrderServiceInternalModels3(in *jlexer.Lexer, out *De
 			continue
 		}
 		switch key {
-		case "ID":
-			out.ID = uint(in.Uint())
 		case "name":
 			out.Name = string(in.String())
 		case "phone":
@@ -541,18 +528,18 @@ func easyjsonC80ae7adDecodeOrderServiceInternalModels3(in *jlexer.Lexer, out *De
 		in.Consumed()
 	}
 }
-func easyjsonC80ae7adEncodeOrderServiceInternalModels3(out *jwriter.Writer, in Delivery) {
+func easyjson120d1ca2EncodeOrderServiceInternalModels3(out *jwriter.Writer, in Delivery) {
 	out.RawByte('{')
 	first := true
 	_ = first
-	{
-		const prefix string = ",\"ID\":"
-		out.RawString(prefix[1:])
-		out.Uint(uint(in.ID))
-	}
 	{
 		const prefix string = ",\"name\":"
-		out.RawString(prefix)
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
 		out.String(string(in.Name))
 	}
 	{
@@ -591,23 +578,23 @@ func easyjsonC80ae7adEncodeOrderServiceInternalModels3(out *jwriter.Writer, in D
 // MarshalJSON supports json.Marshaler interface
 func (v Delivery) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonC80ae7adEncodeOrderServiceInternalModels3(&w, v)
+	easyjson120d1ca2EncodeOrderServiceInternalModels3(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v Delivery) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonC80ae7adEncodeOrderServiceInternalModels3(w, v)
+	easyjson120d1ca2EncodeOrderServiceInternalModels3(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *Delivery) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonC80ae7adDecodeOrderServiceInternalModels3(&r, v)
+	easyjson120d1ca2DecodeOrderServiceInternalModels3(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *Delivery) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonC80ae7adDecodeOrderServiceInternalModels3(l, v)
+	easyjson120d1ca2DecodeOrderServiceInternalModels3(l, v)
 }
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+type Period string
+
+const (
+	PeriodDay  Period = "day"
+	PeriodWeek Period = "week"
+)
+
+type PeriodCount struct {
+	Period time.Time `json:"period"`
+	Orders int64     `json:"orders"`
+}
+
+type CurrencyRevenue struct {
+	Currency string  `json:"currency"`
+	Orders   int64   `json:"orders"`
+	Revenue  float64 `json:"revenue"`
+}
+
+type TopBrand struct {
+	Brand    string  `json:"brand"`
+	Quantity int64   `json:"quantity"`
+	Revenue  float64 `json:"revenue"`
+}
+
+type TopItem struct {
+	Name     string  `json:"name"`
+	Brand    string  `json:"brand"`
+	Quantity int64   `json:"quantity"`
+	Revenue  float64 `json:"revenue"`
+}
+
+type DeliveryServiceCount struct {
+	DeliveryService string `json:"delivery_service"`
+	Orders          int64  `json:"orders"`
+}
+
+type LocationCount struct {
+	Region string `json:"region"`
+	City   string `json:"city"`
+	Orders int64  `json:"orders"`
+}
+
+// BasketStats is per currency because amounts in different currencies can not be averaged together
+type BasketStats struct {
+	Currency  string  `json:"currency"`
+	Orders    int64   `json:"orders"`
+	AvgAmount float64 `json:"avg_amount"`
+	AvgItems  float64 `json:"avg_items"`
+}
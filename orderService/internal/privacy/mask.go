@@ -0,0 +1,54 @@
+package privacy
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const maskRune = '*'
+
+// MaskPhone keeps the country code prefix and the last two digits: +9720000000 -> +972*****00
+func MaskPhone(phone string) string {
+	return maskMiddle(phone, 4, 2)
+}
+
+// MaskEmail keeps the first letter of the local part and the domain: test@gmail.com -> t***@gmail.com
+func MaskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return maskMiddle(email, 1, 0)
+	}
+
+	first, _ := utf8.DecodeRuneInString(email)
+	return string(first) + "***" + email[at:]
+}
+
+// MaskWords keeps the first letter of every word: Prospekt Mira 15 -> P******* M*** 1*
+func MaskWords(value string) string {
+	words := strings.Fields(value)
+	for i, word := range words {
+		words[i] = maskMiddle(word, 1, 0)
+	}
+	return strings.Join(words, " ")
+}
+
+// MaskZip keeps the first two digits of the postal code: 2639809 -> 26*****
+func MaskZip(zip string) string {
+	return maskMiddle(zip, 2, 0)
+}
+
+func maskMiddle(value string, keepStart, keepEnd int) string {
+	runes := []rune(value)
+	if len(runes) == 0 {
+		return value
+	}
+
+	if len(runes) <= keepStart+keepEnd {
+		return strings.Repeat(string(maskRune), len(runes))
+	}
+
+	for i := keepStart; i < len(runes)-keepEnd; i++ {
+		runes[i] = maskRune
+	}
+	return string(runes)
+}
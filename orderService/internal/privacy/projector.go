@@ -0,0 +1,165 @@
+package privacy
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"orderService/configs"
+	"orderService/internal/models"
+	"slices"
+)
+
+type Field string
+
+const (
+	FieldName    Field = "name"
+	FieldPhone   Field = "phone"
+	FieldZip     Field = "zip"
+	FieldAddress Field = "address"
+	FieldEmail   Field = "email"
+)
+
+// Policy maps every personal data field to the roles allowed to see it unmasked
+type Policy map[Field][]string
+
+func NewPolicy(cnf configs.Privacy) Policy {
+	return Policy{
+		FieldName:    cnf.NameRoles,
+		FieldPhone:   cnf.PhoneRoles,
+		FieldZip:     cnf.ZipRoles,
+		FieldAddress: cnf.AddressRoles,
+		FieldEmail:   cnf.EmailRoles,
+	}
+}
+
+func (p Policy) Allows(field Field, roles []string) bool {
+	for _, role := range roles {
+		if slices.Contains(p[field], role) {
+			return true
+		}
+	}
+	return false
+}
+
+// fields lists the personal data fields in a stable order
+var fields = []Field{FieldName, FieldPhone, FieldZip, FieldAddress, FieldEmail}
+
+// Auditor records the reads of personal data, service.IAuditService is one
+type Auditor interface {
+	Record(entries ...models.AuditEntry)
+}
+
+// Viewer is the caller orders are projected for
+type Viewer struct {
+	Actor string
+	Roles []string
+}
+
+// Projector masks personal data for every transport and audits the reads that leave some of it unmasked
+type Projector struct {
+	policy Policy
+	audit  Auditor
+}
+
+// NewProjector builds the projector, audit may be nil when reads are not audited
+func NewProjector(policy Policy, audit Auditor) Projector {
+	return Projector{policy: policy, audit: audit}
+}
+
+// Project masks the view for the viewer and audits the read when a personal data field is left unmasked
+func (p Projector) Project(view models.OrderView, viewer Viewer) models.OrderView {
+	projected := p.Mask(view, viewer.Roles)
+	p.Viewed(viewer, view.Uid)
+	return projected
+}
+
+// ProjectViews masks the views in place, the reads are audited with one Record call
+func (p Projector) ProjectViews(views []models.OrderView, viewer Viewer) {
+	uids := make([]uuid.UUID, len(views))
+	for i := range views {
+		views[i] = p.Mask(views[i], viewer.Roles)
+		uids[i] = views[i].Uid
+	}
+	p.Viewed(viewer, uids...)
+}
+
+// ProjectOrders masks full orders in place, the reads are audited with one Record call
+func (p Projector) ProjectOrders(orders []models.Order, viewer Viewer) {
+	uids := make([]uuid.UUID, len(orders))
+	for i := range orders {
+		orders[i] = p.MaskOrder(orders[i], viewer.Roles)
+		uids[i] = orders[i].Uid
+	}
+	p.Viewed(viewer, uids...)
+}
+
+// Mask returns a copy of the view where every personal data field the roles are not allowed to see is masked.
+// The ETag of a masked view is computed over the masked body, so a client never revalidates one body with the other.
+// It does not audit, use it for validators of a response that is not sent
+func (p Projector) Mask(view models.OrderView, roles []string) models.OrderView {
+	delivery := view.Delivery
+	p.mask(roles, &delivery.Name, &delivery.Phone, &delivery.Zip, &delivery.Address, &delivery.Email)
+	if delivery == view.Delivery {
+		return view
+	}
+
+	view.Delivery = delivery
+	if view.ETag != "" {
+		view.ETag = ""
+		view = view.WithETag()
+	}
+	return view
+}
+
+// MaskOrder masks the delivery of a full order the same way as Mask masks a view
+func (p Projector) MaskOrder(order models.Order, roles []string) models.Order {
+	delivery := order.Delivery
+	p.mask(roles, &delivery.Name, &delivery.Phone, &delivery.Zip, &delivery.Address, &delivery.Email)
+
+	order.Delivery = delivery
+	return order
+}
+
+// Viewed audits that the viewer read the orders with the personal data fields the roles unmask.
+// Nothing is recorded when every field is masked for the viewer
+func (p Projector) Viewed(viewer Viewer, uids ...uuid.UUID) {
+	unmasked := p.Unmasked(viewer.Roles)
+	if p.audit == nil || len(unmasked) == 0 || len(uids) == 0 {
+		return
+	}
+
+	details, _ := json.Marshal(map[string][]Field{"unmasked": unmasked})
+	entries := make([]models.AuditEntry, len(uids))
+	for i := range uids {
+		entries[i] = models.AuditEntry{Actor: viewer.Actor, Action: models.AuditOrderViewed, OrderUid: &uids[i], Details: details}
+	}
+	p.audit.Record(entries...)
+}
+
+// Unmasked returns the personal data fields the roles are allowed to see
+func (p Projector) Unmasked(roles []string) []Field {
+	unmasked := make([]Field, 0, len(fields))
+	for _, field := range fields {
+		if p.policy.Allows(field, roles) {
+			unmasked = append(unmasked, field)
+		}
+	}
+	return unmasked
+}
+
+func (p Projector) mask(roles []string, name, phone, zip, address, email *string) {
+	if !p.policy.Allows(FieldName, roles) {
+		*name = MaskWords(*name)
+	}
+	if !p.policy.Allows(FieldPhone, roles) {
+		*phone = MaskPhone(*phone)
+	}
+	if !p.policy.Allows(FieldZip, roles) {
+		*zip = MaskZip(*zip)
+	}
+	if !p.policy.Allows(FieldAddress, roles) {
+		*address = MaskWords(*address)
+	}
+	if !p.policy.Allows(FieldEmail, roles) {
+		*email = MaskEmail(*email)
+	}
+}
@@ -0,0 +1,154 @@
+package privacy
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"orderService/configs"
+	"orderService/internal/models"
+	"testing"
+)
+
+var delivery = models.DeliveryView{
+	Name:    "Test Testov",
+	Phone:   "+9720000000",
+	Zip:     "2639809",
+	City:    "Moscow",
+	Address: "Prospekt Mira 15",
+	Region:  "Moscow",
+	Email:   "test@gmail.com",
+}
+
+func TestMask(t *testing.T) {
+	tableData := []struct {
+		name     string
+		mask     func(string) string
+		value    string
+		expected string
+	}{
+		{name: "Phone", mask: MaskPhone, value: "+9720000000", expected: "+972*****00"},
+		{name: "ShortPhone", mask: MaskPhone, value: "+972", expected: "****"},
+		{name: "Email", mask: MaskEmail, value: "test@gmail.com", expected: "t***@gmail.com"},
+		{name: "EmailWithoutAt", mask: MaskEmail, value: "test", expected: "t***"},
+		{name: "Words", mask: MaskWords, value: "Prospekt Mira 15", expected: "P******* M*** 1*"},
+		{name: "Cyrillic", mask: MaskWords, value: "Иван Петров", expected: "И*** П*****"},
+		{name: "Zip", mask: MaskZip, value: "2639809", expected: "26*****"},
+		{name: "Empty", mask: MaskPhone, value: "", expected: ""},
+	}
+
+	for _, td := range tableData {
+		t.Run(td.name, func(t *testing.T) {
+			assert.Equal(t, td.expected, td.mask(td.value))
+		})
+	}
+}
+
+var policy = NewPolicy(configs.Privacy{
+	NameRoles:    []string{"admin"},
+	PhoneRoles:   []string{"admin", "support"},
+	ZipRoles:     []string{"admin"},
+	AddressRoles: []string{"admin"},
+	EmailRoles:   []string{"admin", "support"},
+})
+
+func TestProjector_Mask(t *testing.T) {
+	projector := NewProjector(policy, nil)
+	view := models.OrderView{DeliveryService: "meest", Delivery: delivery}
+
+	t.Run("MaskedByDefault", func(t *testing.T) {
+		actual := projector.Mask(view, nil)
+
+		assert.Equal(t, models.DeliveryView{
+			Name:    "T*** T*****",
+			Phone:   "+972*****00",
+			Zip:     "26*****",
+			City:    "Moscow",
+			Address: "P******* M*** 1*",
+			Region:  "Moscow",
+			Email:   "t***@gmail.com",
+		}, actual.Delivery)
+		assert.Equal(t, "meest", actual.DeliveryService)
+		assert.Equal(t, delivery, view.Delivery)
+	})
+
+	t.Run("PartialAccess", func(t *testing.T) {
+		actual := projector.Mask(view, []string{"support"})
+
+		assert.Equal(t, "T*** T*****", actual.Delivery.Name)
+		assert.Equal(t, "+9720000000", actual.Delivery.Phone)
+		assert.Equal(t, "test@gmail.com", actual.Delivery.Email)
+		assert.Equal(t, "P******* M*** 1*", actual.Delivery.Address)
+	})
+
+	t.Run("FullAccess", func(t *testing.T) {
+		actual := projector.Mask(view, []string{"viewer", "admin"})
+
+		assert.Equal(t, delivery, actual.Delivery)
+	})
+
+	t.Run("ETagOfTheProjectedBody", func(t *testing.T) {
+		view := view.WithETag()
+
+		masked := projector.Mask(view, nil)
+		partial := projector.Mask(view, []string{"support"})
+
+		assert.Equal(t, view.ETag, projector.Mask(view, []string{"admin"}).ETag)
+		assert.NotEqual(t, view.ETag, masked.ETag)
+		assert.NotEqual(t, masked.ETag, partial.ETag)
+		assert.Equal(t, masked.ETag, projector.Mask(view, []string{"viewer"}).ETag)
+	})
+}
+
+type recorder struct {
+	entries []models.AuditEntry
+	calls   int
+}
+
+func (r *recorder) Record(entries ...models.AuditEntry) {
+	r.entries = append(r.entries, entries...)
+	r.calls++
+}
+
+func TestProjector_Project(t *testing.T) {
+	uid := uuid.MustParse("1e9ad4fb-2615-46f9-9458-20b59253086b")
+	view := models.OrderView{Uid: uid, Delivery: delivery}
+
+	t.Run("MaskedReadIsNotAudited", func(t *testing.T) {
+		audit := &recorder{}
+
+		NewProjector(policy, audit).Project(view, Viewer{Actor: "api_key:reader"})
+
+		assert.Zero(t, audit.calls)
+	})
+
+	t.Run("UnmaskedReadIsAudited", func(t *testing.T) {
+		audit := &recorder{}
+
+		actual := NewProjector(policy, audit).Project(view, Viewer{Actor: "api_key:support", Roles: []string{"support"}})
+
+		assert.Equal(t, "+9720000000", actual.Delivery.Phone)
+		require.Len(t, audit.entries, 1)
+		assert.Equal(t, models.AuditOrderViewed, audit.entries[0].Action)
+		assert.Equal(t, "api_key:support", audit.entries[0].Actor)
+		assert.Equal(t, uid, *audit.entries[0].OrderUid)
+		assert.JSONEq(t, `{"unmasked":["phone","email"]}`, string(audit.entries[0].Details))
+	})
+
+	t.Run("BatchIsAuditedWithOneCall", func(t *testing.T) {
+		audit := &recorder{}
+		second := uuid.MustParse("2e9ad4fb-2615-46f9-9458-20b59253086b")
+		orders := []models.Order{{Uid: uid}, {Uid: second}}
+
+		NewProjector(policy, audit).ProjectOrders(orders, Viewer{Roles: []string{"admin"}})
+
+		assert.Equal(t, 1, audit.calls)
+		require.Len(t, audit.entries, 2)
+		assert.Equal(t, second, *audit.entries[1].OrderUid)
+	})
+
+	t.Run("WithoutAuditor", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			NewProjector(policy, nil).Project(view, Viewer{Roles: []string{"admin"}})
+		})
+	})
+}
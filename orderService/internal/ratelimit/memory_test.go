@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore_Take(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore(time.Minute)
+	store.now = func() time.Time { return now }
+	limit := Limit{Rate: 2, Burst: 3}
+
+	t.Run("BurstIsAllowed", func(t *testing.T) {
+		for remaining := 2; remaining >= 0; remaining-- {
+			result, err := store.Take("client", limit)
+
+			assert.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, remaining, result.Remaining)
+			assert.Equal(t, 3, result.Limit)
+		}
+	})
+
+	t.Run("EmptyBucketIsRejected", func(t *testing.T) {
+		result, err := store.Take("client", limit)
+
+		assert.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 500*time.Millisecond, result.RetryAfter)
+		assert.Equal(t, 1500*time.Millisecond, result.ResetAfter)
+	})
+
+	t.Run("OtherKeysAreIndependent", func(t *testing.T) {
+		result, err := store.Take("other", limit)
+
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+	})
+
+	t.Run("BucketIsRefilled", func(t *testing.T) {
+		now = now.Add(500 * time.Millisecond)
+
+		result, err := store.Take("client", limit)
+
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 0, result.Remaining)
+	})
+
+	t.Run("IdleBucketsAreRemoved", func(t *testing.T) {
+		now = now.Add(2 * time.Minute)
+
+		_, err := store.Take("client", limit)
+
+		assert.NoError(t, err)
+		assert.Len(t, store.buckets, 1)
+	})
+}
@@ -0,0 +1,27 @@
+package ratelimit
+
+import (
+	"time"
+)
+
+// Limit is a token bucket refilled with Rate tokens per second up to Burst tokens
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is the time until the next token is available, zero when the request is allowed
+	RetryAfter time.Duration
+	// ResetAfter is the time until the bucket is full again
+	ResetAfter time.Duration
+}
+
+// Store takes tokens from the bucket identified by key. Implementations shared between
+// instances (e.g. Redis) must apply the refill and take atomically
+type Store interface {
+	Take(key string, limit Limit) (Result, error)
+}
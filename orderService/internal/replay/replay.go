@@ -0,0 +1,288 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	"log"
+	eventHandler "orderService/internal/kafka"
+	"orderService/internal/models"
+	"orderService/internal/repository"
+	"orderService/internal/service"
+	"time"
+)
+
+const (
+	ResultCreated  = "created"
+	ResultExisting = "existing"
+	ResultInvalid  = "invalid"
+	ResultFailed   = "failed"
+)
+
+const (
+	// pollTimeout is the longest a poll blocks, so a cancelled replay stops quickly
+	pollTimeout = 100 * time.Millisecond
+	// maxPollErrors consecutive poll errors end the replay
+	maxPollErrors = 10
+)
+
+var (
+	ErrIdle     = errors.New("no message arrived before the end of the range")
+	ErrRetained = errors.New("the start of the range is no longer retained")
+)
+
+// Source reads the assigned partitions, eventHandler.KafkaSource and eventHandler.MemorySource implement it
+type Source interface {
+	Name() string
+	Assign(partitions []eventHandler.TopicPartition) error
+	Poll(timeout time.Duration) (*eventHandler.Message, error)
+	LowWatermark(tp eventHandler.TopicPartition) (int64, error)
+	HighWatermark(tp eventHandler.TopicPartition) (int64, error)
+	OffsetForTime(tp eventHandler.TopicPartition, ts time.Time) (int64, error)
+}
+
+// Range selects the messages of a partition. The start is FromOffset or the first message produced at or after
+// From, the earliest retained message when neither is set. The end is exclusive: ToOffset or the first message
+// produced at or after To, the high watermark when the replay starts when neither is set
+type Range struct {
+	Topic      string
+	Partition  int32
+	FromOffset *int64
+	ToOffset   *int64
+	From       time.Time
+	To         time.Time
+}
+
+type Options struct {
+	// DryRun decodes, validates and looks up the orders without creating them
+	DryRun bool
+	// Idle fails the replay when no message arrives for this long before the end of the range,
+	// e.g. when the last offsets are transaction markers
+	Idle time.Duration
+}
+
+type Summary struct {
+	Topic     string
+	Partition int32
+	// Start and End are the offsets of the replayed range, End is exclusive
+	Start int64
+	End   int64
+	Read  int
+	// Created counts the orders created, or that would be created on a dry run
+	Created  int
+	Existing int
+	Invalid  int
+	Failed   int
+}
+
+func (s Summary) String() string {
+	return fmt.Sprintf("%s [%d] offsets %d-%d, read: %d, created: %d, existing: %d, invalid: %d, failed: %d",
+		s.Topic, s.Partition, s.Start, s.End, s.Read, s.Created, s.Existing, s.Invalid, s.Failed)
+}
+
+// Outcome is a line of the report
+type Outcome struct {
+	Topic     string    `json:"topic"`
+	Partition int32     `json:"partition"`
+	Offset    int64     `json:"offset"`
+	OrderUid  uuid.UUID `json:"order_uid"`
+	Result    string    `json:"result"`
+	Error     string    `json:"error,omitempty"`
+}
+
+type Replayer struct {
+	source       Source
+	decoder      eventHandler.Decoder
+	orderService service.IOrderService
+	options      Options
+	report       *json.Encoder
+	// planned are the orders a dry run would create, a later message with the same uid is existing
+	planned map[uuid.UUID]bool
+}
+
+// NewReplayer writes the outcome of every message to report as JSON lines
+func NewReplayer(source Source, decoder eventHandler.Decoder, orderService service.IOrderService, options Options, report io.Writer) *Replayer {
+	if options.Idle <= 0 {
+		options.Idle = 10 * time.Second
+	}
+	return &Replayer{
+		source:       source,
+		decoder:      decoder,
+		orderService: orderService,
+		options:      options,
+		report:       json.NewEncoder(report),
+		planned:      make(map[uuid.UUID]bool),
+	}
+}
+
+// Run replays the range until its end or until the context is cancelled. Orders are created through
+// OrderService.Create like the consumer does, orders that exist already are left as they are
+func (r *Replayer) Run(ctx context.Context, rng Range) (Summary, error) {
+	summary := Summary{Topic: rng.Topic, Partition: rng.Partition}
+	tp := eventHandler.TopicPartition{Topic: rng.Topic, Partition: rng.Partition}
+
+	var err error
+	if summary.Start, summary.End, err = r.resolve(tp, rng); err != nil {
+		return summary, err
+	}
+	if summary.Start >= summary.End {
+		log.Printf("Nothing to replay in %s [%d] between offsets %d and %d\n", tp.Topic, tp.Partition, summary.Start, summary.End)
+		return summary, nil
+	}
+
+	tp.Offset = summary.Start
+	if err = r.source.Assign([]eventHandler.TopicPartition{tp}); err != nil {
+		return summary, fmt.Errorf("failed to assign %s: %w", tp, err)
+	}
+	log.Printf("Replaying %s [%d] offsets %d-%d from %s, dry run: %t\n", tp.Topic, tp.Partition, summary.Start, summary.End, r.source.Name(), r.options.DryRun)
+
+	lastMessageAt := time.Now()
+	pollErrors := 0
+	for ctx.Err() == nil {
+		msg, err := r.source.Poll(pollTimeout)
+		if err != nil {
+			if pollErrors++; pollErrors >= maxPollErrors {
+				return summary, fmt.Errorf("failed to read %s [%d] %d times in a row: %w", tp.Topic, tp.Partition, pollErrors, err)
+			}
+			log.Printf("Replay error: %v\n", err)
+			continue
+		}
+		pollErrors = 0
+		if msg == nil {
+			if time.Since(lastMessageAt) > r.options.Idle {
+				return summary, fmt.Errorf("%w: none for %v, offsets %d-%d of %s [%d] are left unread", ErrIdle, r.options.Idle, tp.Offset, summary.End, tp.Topic, tp.Partition)
+			}
+			continue
+		}
+		lastMessageAt = time.Now()
+		if msg.TopicPartition.Offset >= summary.End {
+			break
+		}
+
+		tp.Offset = msg.TopicPartition.Offset + 1
+		if err = r.replay(msg, &summary); err != nil {
+			return summary, err
+		}
+		if tp.Offset >= summary.End {
+			break
+		}
+	}
+	return summary, ctx.Err()
+}
+
+func (r *Replayer) resolve(tp eventHandler.TopicPartition, rng Range) (int64, int64, error) {
+	high, err := r.source.HighWatermark(tp)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get the high watermark of %s [%d]: %w", tp.Topic, tp.Partition, err)
+	}
+
+	// the first message produced since the epoch is the earliest retained one
+	start, err := r.offset(tp, rng.FromOffset, rng.From, time.UnixMilli(0))
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := r.offset(tp, rng.ToOffset, rng.To, time.Time{})
+	if err != nil {
+		return 0, 0, err
+	}
+	if end < 0 || end > high {
+		end = high
+	}
+
+	low, err := r.source.LowWatermark(tp)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get the low watermark of %s [%d]: %w", tp.Topic, tp.Partition, err)
+	}
+	if start < low && start < end {
+		return 0, 0, fmt.Errorf("%w: %s [%d] starts at offset %d, not %d", ErrRetained, tp.Topic, tp.Partition, low, start)
+	}
+	return start, end, nil
+}
+
+// offset returns the given offset, the offset of the first message produced at or after ts or at or after
+// fallback when neither is set. It returns -1 when nothing is set
+func (r *Replayer) offset(tp eventHandler.TopicPartition, offset *int64, ts time.Time, fallback time.Time) (int64, error) {
+	if offset != nil {
+		return *offset, nil
+	}
+	if ts.IsZero() {
+		ts = fallback
+	}
+	if ts.IsZero() {
+		return -1, nil
+	}
+	found, err := r.source.OffsetForTime(tp, ts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up the offset of %s [%d] at %s: %w", tp.Topic, tp.Partition, ts.Format(time.RFC3339), err)
+	}
+	return found, nil
+}
+
+// replay counts the outcome of the message, only a failed report is returned
+func (r *Replayer) replay(msg *eventHandler.Message, summary *Summary) error {
+	summary.Read++
+	order, result, err := r.create(msg)
+	switch result {
+	case ResultCreated:
+		summary.Created++
+	case ResultExisting:
+		summary.Existing++
+	case ResultInvalid:
+		summary.Invalid++
+	case ResultFailed:
+		summary.Failed++
+		log.Printf("Failed to replay %s: %v\n", msg.TopicPartition, err)
+	}
+
+	outcome := Outcome{
+		Topic:     msg.TopicPartition.Topic,
+		Partition: msg.TopicPartition.Partition,
+		Offset:    msg.TopicPartition.Offset,
+		OrderUid:  order.Uid,
+		Result:    result,
+	}
+	if err != nil {
+		outcome.Error = err.Error()
+	}
+	return r.report.Encode(outcome)
+}
+
+func (r *Replayer) create(msg *eventHandler.Message) (models.Order, string, error) {
+	order, err := r.decoder.Decode(msg)
+	if err != nil {
+		return order, ResultInvalid, err
+	}
+	if err = order.Validate(); err != nil {
+		return order, ResultInvalid, err
+	}
+
+	_, missing, err := r.orderService.GetByIds([]uuid.UUID{order.Uid})
+	if err != nil {
+		return order, ResultFailed, err
+	}
+	if len(missing) == 0 || r.planned[order.Uid] {
+		return order, ResultExisting, nil
+	}
+	if r.options.DryRun {
+		r.planned[order.Uid] = true
+		return order, ResultCreated, nil
+	}
+
+	err = r.orderService.Create(order, actor(msg))
+	if repository.IsDuplicateKey(err) {
+		// the consumer created the order after the lookup
+		return order, ResultExisting, nil
+	}
+	if err != nil {
+		return order, ResultFailed, err
+	}
+	return order, ResultCreated, nil
+}
+
+// actor identifies the replayed message in the audit log, e.g. replay:Orders/0@42
+func actor(msg *eventHandler.Message) string {
+	return fmt.Sprintf("replay:%s/%d@%d", msg.TopicPartition.Topic, msg.TopicPartition.Partition, msg.TopicPartition.Offset)
+}
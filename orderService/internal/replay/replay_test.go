@@ -0,0 +1,219 @@
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"orderService/configs"
+	eventHandler "orderService/internal/kafka"
+	"orderService/internal/models"
+	"orderService/internal/service/mocks"
+	"os"
+	"testing"
+	"time"
+)
+
+const topic = "Orders"
+
+var (
+	created  = uuid.MustParse("4e9ad8fb-2611-46f9-9458-20b59253086b")
+	existing = uuid.MustParse("9a1f4c36-5e2b-4d1c-8f7a-0b6c2d3e4f51")
+	produced = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+)
+
+// newSource holds an invalid message, then the orders existing and created, a minute apart
+func newSource(t *testing.T) *eventHandler.MemorySource {
+	order, err := os.ReadFile("../kafka/registry/testdata/order.json")
+	require.NoError(t, err)
+
+	source := eventHandler.NewMemorySource(1)
+	values := [][]byte{
+		[]byte(`{"order_uid":"broken"}`),
+		bytes.ReplaceAll(order, []byte(created.String()), []byte(existing.String())),
+		order,
+	}
+	for i, value := range values {
+		source.Produce(topic, eventHandler.Message{Value: value, Timestamp: produced.Add(time.Duration(i) * time.Minute)})
+	}
+	return source
+}
+
+// lossySource lost the messages below low to retention, reports unread messages after the stored ones
+// and fails every poll with pollErr when it is set
+type lossySource struct {
+	*eventHandler.MemorySource
+	low     int64
+	unread  int64
+	pollErr error
+}
+
+func (s lossySource) LowWatermark(eventHandler.TopicPartition) (int64, error) {
+	return s.low, nil
+}
+
+func (s lossySource) HighWatermark(tp eventHandler.TopicPartition) (int64, error) {
+	high, err := s.MemorySource.HighWatermark(tp)
+	return high + s.unread, err
+}
+
+func (s lossySource) Poll(timeout time.Duration) (*eventHandler.Message, error) {
+	if s.pollErr != nil {
+		return nil, s.pollErr
+	}
+	return s.MemorySource.Poll(timeout)
+}
+
+func newDecoder(t *testing.T) eventHandler.Decoder {
+	decoder, err := eventHandler.NewDecoder(configs.Kafka{SchemaRegistryDir: "../kafka/registry/testdata/registry"})
+	require.NoError(t, err)
+	return decoder
+}
+
+// newOrderService reports the order existing as stored already
+func newOrderService() *mocks.IOrderService {
+	mockService := new(mocks.IOrderService)
+	mockService.On("GetByIds", []uuid.UUID{existing}).Return([]models.OrderView{{Uid: existing}}, []uuid.UUID{}, nil)
+	mockService.On("GetByIds", []uuid.UUID{created}).Return([]models.OrderView{}, []uuid.UUID{created}, nil)
+	return mockService
+}
+
+func outcomes(t *testing.T, report *bytes.Buffer) []Outcome {
+	var lines []Outcome
+	scanner := bufio.NewScanner(report)
+	for scanner.Scan() {
+		var outcome Outcome
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &outcome))
+		outcome.Error = ""
+		lines = append(lines, outcome)
+	}
+	return lines
+}
+
+func TestReplayer_Run(t *testing.T) {
+	t.Run("WholePartition", func(t *testing.T) {
+		mockService := newOrderService()
+		mockService.On("Create", mock.MatchedBy(func(o models.Order) bool { return o.Uid == created }), "replay:Orders/0@2").Return(nil)
+		var report bytes.Buffer
+
+		summary, err := NewReplayer(newSource(t), newDecoder(t), mockService, Options{}, &report).Run(context.Background(), Range{Topic: topic})
+
+		require.NoError(t, err)
+		assert.Equal(t, Summary{Topic: topic, Start: 0, End: 3, Read: 3, Created: 1, Existing: 1, Invalid: 1}, summary)
+		assert.Equal(t, []Outcome{
+			{Topic: topic, Offset: 0, Result: ResultInvalid},
+			{Topic: topic, Offset: 1, OrderUid: existing, Result: ResultExisting},
+			{Topic: topic, Offset: 2, OrderUid: created, Result: ResultCreated},
+		}, outcomes(t, &report))
+		mockService.AssertNumberOfCalls(t, "Create", 1)
+	})
+
+	t.Run("DryRun", func(t *testing.T) {
+		mockService := newOrderService()
+		var report bytes.Buffer
+
+		summary, err := NewReplayer(newSource(t), newDecoder(t), mockService, Options{DryRun: true}, &report).Run(context.Background(), Range{Topic: topic})
+
+		require.NoError(t, err)
+		assert.Equal(t, Summary{Topic: topic, Start: 0, End: 3, Read: 3, Created: 1, Existing: 1, Invalid: 1}, summary)
+		mockService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("OffsetRange", func(t *testing.T) {
+		from, to := int64(1), int64(2)
+		var report bytes.Buffer
+
+		summary, err := NewReplayer(newSource(t), newDecoder(t), newOrderService(), Options{}, &report).
+			Run(context.Background(), Range{Topic: topic, FromOffset: &from, ToOffset: &to})
+
+		require.NoError(t, err)
+		assert.Equal(t, Summary{Topic: topic, Start: 1, End: 2, Read: 1, Existing: 1}, summary)
+	})
+
+	t.Run("TimeRange", func(t *testing.T) {
+		var report bytes.Buffer
+
+		summary, err := NewReplayer(newSource(t), newDecoder(t), newOrderService(), Options{DryRun: true}, &report).
+			Run(context.Background(), Range{Topic: topic, From: produced.Add(30 * time.Second), To: produced.Add(90 * time.Second)})
+
+		require.NoError(t, err)
+		assert.Equal(t, Summary{Topic: topic, Start: 1, End: 2, Read: 1, Existing: 1}, summary)
+	})
+
+	t.Run("FailedCreateIsReported", func(t *testing.T) {
+		mockService := newOrderService()
+		mockService.On("Create", mock.Anything, mock.Anything).Return(errors.New("connection refused"))
+		var report bytes.Buffer
+
+		summary, err := NewReplayer(newSource(t), newDecoder(t), mockService, Options{}, &report).Run(context.Background(), Range{Topic: topic})
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, summary.Failed)
+		assert.Contains(t, report.String(), `"result":"failed","error":"connection refused"`)
+	})
+
+	t.Run("DeadLetteredMessage", func(t *testing.T) {
+		order, err := os.ReadFile("../kafka/registry/testdata/order.json")
+		require.NoError(t, err)
+		source := eventHandler.NewMemorySource(1)
+		source.Produce("Orders.dlq", eventHandler.Message{Value: order, Headers: []eventHandler.Header{
+			{Key: eventHandler.OriginalTopicHeader, Value: []byte(topic)},
+			{Key: eventHandler.ErrorHeader, Value: []byte("connection refused")},
+		}})
+		mockService := newOrderService()
+		mockService.On("Create", mock.Anything, "replay:Orders.dlq/0@0").Return(nil)
+		var report bytes.Buffer
+
+		summary, err := NewReplayer(source, newDecoder(t), mockService, Options{}, &report).Run(context.Background(), Range{Topic: "Orders.dlq"})
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, summary.Created)
+	})
+
+	t.Run("StartIsNoLongerRetained", func(t *testing.T) {
+		from := int64(0)
+		var report bytes.Buffer
+
+		_, err := NewReplayer(lossySource{MemorySource: newSource(t), low: 1}, newDecoder(t), newOrderService(), Options{DryRun: true}, &report).
+			Run(context.Background(), Range{Topic: topic, FromOffset: &from})
+
+		assert.ErrorIs(t, err, ErrRetained)
+		assert.Empty(t, report.String())
+	})
+
+	t.Run("IdleBeforeTheEnd", func(t *testing.T) {
+		var report bytes.Buffer
+
+		summary, err := NewReplayer(lossySource{MemorySource: newSource(t), unread: 2}, newDecoder(t), newOrderService(), Options{DryRun: true, Idle: 50 * time.Millisecond}, &report).
+			Run(context.Background(), Range{Topic: topic})
+
+		assert.ErrorIs(t, err, ErrIdle)
+		assert.ErrorContains(t, err, "offsets 3-5 of Orders [0] are left unread")
+		assert.Equal(t, 3, summary.Read)
+	})
+
+	t.Run("PollErrors", func(t *testing.T) {
+		var report bytes.Buffer
+
+		_, err := NewReplayer(lossySource{MemorySource: newSource(t), pollErr: errors.New("broker down")}, newDecoder(t), newOrderService(), Options{}, &report).
+			Run(context.Background(), Range{Topic: topic})
+
+		assert.EqualError(t, err, "failed to read Orders [0] 10 times in a row: broker down")
+	})
+
+	t.Run("EmptyRange", func(t *testing.T) {
+		var report bytes.Buffer
+
+		summary, err := NewReplayer(newSource(t), newDecoder(t), newOrderService(), Options{}, &report).
+			Run(context.Background(), Range{Topic: topic, From: produced.Add(time.Hour)})
+
+		require.NoError(t, err)
+		assert.Equal(t, Summary{Topic: topic, Start: 3, End: 3}, summary)
+		assert.Empty(t, report.String())
+	})
+}
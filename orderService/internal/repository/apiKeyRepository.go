@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"orderService/internal/models"
+)
+
+//go:generate mockery --name=IApiKeyRepository --output=mocks --outpkg=mocks --case=snake --with-expecter
+type IApiKeyRepository interface {
+	GetByHash(hash string) (models.ApiKey, error)
+}
+
+type ApiKeyRepository struct {
+	DB *gorm.DB
+}
+
+func NewApiKeyRepository(db *gorm.DB) ApiKeyRepository {
+	return ApiKeyRepository{DB: db}
+}
+
+func (r ApiKeyRepository) GetByHash(hash string) (models.ApiKey, error) {
+	var key models.ApiKey
+	if err := r.DB.Take(&key, "key_hash = ?", hash).Error; err != nil {
+		return models.ApiKey{}, err
+	}
+
+	return key, nil
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"log"
+	"orderService/internal/models"
+)
+
+//go:generate mockery --name=IAuditRepository --output=mocks --outpkg=mocks --case=snake --with-expecter
+type IAuditRepository interface {
+	Append(entries ...models.AuditEntry) error
+	FindByOrder(uid uuid.UUID, limit int) ([]models.AuditEntry, error)
+}
+
+// AuditRepository only inserts and reads, the audit_log table rejects updates and deletes
+type AuditRepository struct {
+	DB *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return AuditRepository{DB: db}
+}
+
+func (r AuditRepository) Append(entries ...models.AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	if err := r.DB.Create(&entries).Error; err != nil {
+		log.Printf("Error writing audit log: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+// FindByOrder returns the newest entries of the order first
+func (r AuditRepository) FindByOrder(uid uuid.UUID, limit int) ([]models.AuditEntry, error) {
+	var entries []models.AuditEntry
+	err := r.DB.Where("order_uid = ?", uid).Order("occurred_at DESC, id DESC").Limit(limit).Find(&entries).Error
+	if err != nil {
+		log.Printf("Error reading audit log of order %s: %v\n", uid.String(), err)
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"testing"
+)
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		transient bool
+	}{
+		{"ConnectionException", &pgconn.PgError{Code: "08006"}, true},
+		{"Deadlock", fmt.Errorf("create order: %w", &pgconn.PgError{Code: "40P01"}), true},
+		{"AdminShutdown", &pgconn.PgError{Code: "57P01"}, true},
+		{"ConnectionRefused", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, true},
+		{"DeadlineExceeded", context.DeadlineExceeded, true},
+		{"BadConn", driver.ErrBadConn, true},
+		{"UniqueViolation", &pgconn.PgError{Code: uniqueViolationCode}, false},
+		{"NotNullViolation", &pgconn.PgError{Code: "23502"}, false},
+		{"Validation", validator.ValidationErrors{}, false},
+		{"Unmarshal", errors.New("parse error: syntax error near offset 0"), false},
+		{"Nil", nil, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.transient, IsTransient(test.err))
+		})
+	}
+}
@@ -0,0 +1,92 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "orderService/internal/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IApiKeyRepository is an autogenerated mock type for the IApiKeyRepository type
+type IApiKeyRepository struct {
+	mock.Mock
+}
+
+type IApiKeyRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *IApiKeyRepository) EXPECT() *IApiKeyRepository_Expecter {
+	return &IApiKeyRepository_Expecter{mock: &_m.Mock}
+}
+
+// GetByHash provides a mock function with given fields: hash
+func (_m *IApiKeyRepository) GetByHash(hash string) (models.ApiKey, error) {
+	ret := _m.Called(hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByHash")
+	}
+
+	var r0 models.ApiKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (models.ApiKey, error)); ok {
+		return rf(hash)
+	}
+	if rf, ok := ret.Get(0).(func(string) models.ApiKey); ok {
+		r0 = rf(hash)
+	} else {
+		r0 = ret.Get(0).(models.ApiKey)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IApiKeyRepository_GetByHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByHash'
+type IApiKeyRepository_GetByHash_Call struct {
+	*mock.Call
+}
+
+// GetByHash is a helper method to define mock.On call
+//   - hash string
+func (_e *IApiKeyRepository_Expecter) GetByHash(hash interface{}) *IApiKeyRepository_GetByHash_Call {
+	return &IApiKeyRepository_GetByHash_Call{Call: _e.mock.On("GetByHash", hash)}
+}
+
+func (_c *IApiKeyRepository_GetByHash_Call) Run(run func(hash string)) *IApiKeyRepository_GetByHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *IApiKeyRepository_GetByHash_Call) Return(_a0 models.ApiKey, _a1 error) *IApiKeyRepository_GetByHash_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IApiKeyRepository_GetByHash_Call) RunAndReturn(run func(string) (models.ApiKey, error)) *IApiKeyRepository_GetByHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewIApiKeyRepository creates a new instance of IApiKeyRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIApiKeyRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IApiKeyRepository {
+	mock := &IApiKeyRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
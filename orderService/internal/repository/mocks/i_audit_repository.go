@@ -0,0 +1,156 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "orderService/internal/models"
+
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// IAuditRepository is an autogenerated mock type for the IAuditRepository type
+type IAuditRepository struct {
+	mock.Mock
+}
+
+type IAuditRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *IAuditRepository) EXPECT() *IAuditRepository_Expecter {
+	return &IAuditRepository_Expecter{mock: &_m.Mock}
+}
+
+// Append provides a mock function with given fields: entries
+func (_m *IAuditRepository) Append(entries ...models.AuditEntry) error {
+	_va := make([]interface{}, len(entries))
+	for _i := range entries {
+		_va[_i] = entries[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Append")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(...models.AuditEntry) error); ok {
+		r0 = rf(entries...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// IAuditRepository_Append_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Append'
+type IAuditRepository_Append_Call struct {
+	*mock.Call
+}
+
+// Append is a helper method to define mock.On call
+//   - entries ...models.AuditEntry
+func (_e *IAuditRepository_Expecter) Append(entries ...interface{}) *IAuditRepository_Append_Call {
+	return &IAuditRepository_Append_Call{Call: _e.mock.On("Append",
+		append([]interface{}{}, entries...)...)}
+}
+
+func (_c *IAuditRepository_Append_Call) Run(run func(entries ...models.AuditEntry)) *IAuditRepository_Append_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]models.AuditEntry, len(args)-0)
+		for i, a := range args[0:] {
+			if a != nil {
+				variadicArgs[i] = a.(models.AuditEntry)
+			}
+		}
+		run(variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *IAuditRepository_Append_Call) Return(_a0 error) *IAuditRepository_Append_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *IAuditRepository_Append_Call) RunAndReturn(run func(...models.AuditEntry) error) *IAuditRepository_Append_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindByOrder provides a mock function with given fields: uid, limit
+func (_m *IAuditRepository) FindByOrder(uid uuid.UUID, limit int) ([]models.AuditEntry, error) {
+	ret := _m.Called(uid, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByOrder")
+	}
+
+	var r0 []models.AuditEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int) ([]models.AuditEntry, error)); ok {
+		return rf(uid, limit)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int) []models.AuditEntry); ok {
+		r0 = rf(uid, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.AuditEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, int) error); ok {
+		r1 = rf(uid, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IAuditRepository_FindByOrder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindByOrder'
+type IAuditRepository_FindByOrder_Call struct {
+	*mock.Call
+}
+
+// FindByOrder is a helper method to define mock.On call
+//   - uid uuid.UUID
+//   - limit int
+func (_e *IAuditRepository_Expecter) FindByOrder(uid interface{}, limit interface{}) *IAuditRepository_FindByOrder_Call {
+	return &IAuditRepository_FindByOrder_Call{Call: _e.mock.On("FindByOrder", uid, limit)}
+}
+
+func (_c *IAuditRepository_FindByOrder_Call) Run(run func(uid uuid.UUID, limit int)) *IAuditRepository_FindByOrder_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *IAuditRepository_FindByOrder_Call) Return(_a0 []models.AuditEntry, _a1 error) *IAuditRepository_FindByOrder_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IAuditRepository_FindByOrder_Call) RunAndReturn(run func(uuid.UUID, int) ([]models.AuditEntry, error)) *IAuditRepository_FindByOrder_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewIAuditRepository creates a new instance of IAuditRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIAuditRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IAuditRepository {
+	mock := &IAuditRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
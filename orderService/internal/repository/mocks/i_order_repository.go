@@ -3,10 +3,13 @@
 package mocks
 
 import (
+	context "context"
 	models "orderService/internal/models"
 
 	mock "github.com/stretchr/testify/mock"
 
+	time "time"
+
 	uuid "github.com/google/uuid"
 )
 
@@ -23,6 +26,111 @@ func (_m *IOrderRepository) EXPECT() *IOrderRepository_Expecter {
 	return &IOrderRepository_Expecter{mock: &_m.Mock}
 }
 
+// Amend provides a mock function with given fields: before, amended
+func (_m *IOrderRepository) Amend(before models.Order, amended models.Order) error {
+	ret := _m.Called(before, amended)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Amend")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(models.Order, models.Order) error); ok {
+		r0 = rf(before, amended)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// IOrderRepository_Amend_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Amend'
+type IOrderRepository_Amend_Call struct {
+	*mock.Call
+}
+
+// Amend is a helper method to define mock.On call
+//   - before models.Order
+//   - amended models.Order
+func (_e *IOrderRepository_Expecter) Amend(before interface{}, amended interface{}) *IOrderRepository_Amend_Call {
+	return &IOrderRepository_Amend_Call{Call: _e.mock.On("Amend", before, amended)}
+}
+
+func (_c *IOrderRepository_Amend_Call) Run(run func(before models.Order, amended models.Order)) *IOrderRepository_Amend_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(models.Order), args[1].(models.Order))
+	})
+	return _c
+}
+
+func (_c *IOrderRepository_Amend_Call) Return(_a0 error) *IOrderRepository_Amend_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *IOrderRepository_Amend_Call) RunAndReturn(run func(models.Order, models.Order) error) *IOrderRepository_Amend_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// AnonymizeCustomer provides a mock function with given fields: customerID
+func (_m *IOrderRepository) AnonymizeCustomer(customerID string) ([]uuid.UUID, error) {
+	ret := _m.Called(customerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AnonymizeCustomer")
+	}
+
+	var r0 []uuid.UUID
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]uuid.UUID, error)); ok {
+		return rf(customerID)
+	}
+	if rf, ok := ret.Get(0).(func(string) []uuid.UUID); ok {
+		r0 = rf(customerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]uuid.UUID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(customerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IOrderRepository_AnonymizeCustomer_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AnonymizeCustomer'
+type IOrderRepository_AnonymizeCustomer_Call struct {
+	*mock.Call
+}
+
+// AnonymizeCustomer is a helper method to define mock.On call
+//   - customerID string
+func (_e *IOrderRepository_Expecter) AnonymizeCustomer(customerID interface{}) *IOrderRepository_AnonymizeCustomer_Call {
+	return &IOrderRepository_AnonymizeCustomer_Call{Call: _e.mock.On("AnonymizeCustomer", customerID)}
+}
+
+func (_c *IOrderRepository_AnonymizeCustomer_Call) Run(run func(customerID string)) *IOrderRepository_AnonymizeCustomer_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *IOrderRepository_AnonymizeCustomer_Call) Return(_a0 []uuid.UUID, _a1 error) *IOrderRepository_AnonymizeCustomer_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IOrderRepository_AnonymizeCustomer_Call) RunAndReturn(run func(string) ([]uuid.UUID, error)) *IOrderRepository_AnonymizeCustomer_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Create provides a mock function with given fields: order
 func (_m *IOrderRepository) Create(order models.Order) error {
 	ret := _m.Called(order)
@@ -69,6 +177,168 @@ func (_c *IOrderRepository_Create_Call) RunAndReturn(run func(models.Order) erro
 	return _c
 }
 
+// CreateBatch provides a mock function with given fields: orders
+func (_m *IOrderRepository) CreateBatch(orders []models.Order) error {
+	ret := _m.Called(orders)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateBatch")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]models.Order) error); ok {
+		r0 = rf(orders)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// IOrderRepository_CreateBatch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateBatch'
+type IOrderRepository_CreateBatch_Call struct {
+	*mock.Call
+}
+
+// CreateBatch is a helper method to define mock.On call
+//   - orders []models.Order
+func (_e *IOrderRepository_Expecter) CreateBatch(orders interface{}) *IOrderRepository_CreateBatch_Call {
+	return &IOrderRepository_CreateBatch_Call{Call: _e.mock.On("CreateBatch", orders)}
+}
+
+func (_c *IOrderRepository_CreateBatch_Call) Run(run func(orders []models.Order)) *IOrderRepository_CreateBatch_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].([]models.Order))
+	})
+	return _c
+}
+
+func (_c *IOrderRepository_CreateBatch_Call) Return(_a0 error) *IOrderRepository_CreateBatch_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *IOrderRepository_CreateBatch_Call) RunAndReturn(run func([]models.Order) error) *IOrderRepository_CreateBatch_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindByCustomer provides a mock function with given fields: customerID, after, limit
+func (_m *IOrderRepository) FindByCustomer(customerID string, after *models.OrderCursor, limit int) ([]models.Order, error) {
+	ret := _m.Called(customerID, after, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByCustomer")
+	}
+
+	var r0 []models.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, *models.OrderCursor, int) ([]models.Order, error)); ok {
+		return rf(customerID, after, limit)
+	}
+	if rf, ok := ret.Get(0).(func(string, *models.OrderCursor, int) []models.Order); ok {
+		r0 = rf(customerID, after, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, *models.OrderCursor, int) error); ok {
+		r1 = rf(customerID, after, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IOrderRepository_FindByCustomer_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindByCustomer'
+type IOrderRepository_FindByCustomer_Call struct {
+	*mock.Call
+}
+
+// FindByCustomer is a helper method to define mock.On call
+//   - customerID string
+//   - after *models.OrderCursor
+//   - limit int
+func (_e *IOrderRepository_Expecter) FindByCustomer(customerID interface{}, after interface{}, limit interface{}) *IOrderRepository_FindByCustomer_Call {
+	return &IOrderRepository_FindByCustomer_Call{Call: _e.mock.On("FindByCustomer", customerID, after, limit)}
+}
+
+func (_c *IOrderRepository_FindByCustomer_Call) Run(run func(customerID string, after *models.OrderCursor, limit int)) *IOrderRepository_FindByCustomer_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(*models.OrderCursor), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *IOrderRepository_FindByCustomer_Call) Return(_a0 []models.Order, _a1 error) *IOrderRepository_FindByCustomer_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IOrderRepository_FindByCustomer_Call) RunAndReturn(run func(string, *models.OrderCursor, int) ([]models.Order, error)) *IOrderRepository_FindByCustomer_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByTrackNumber provides a mock function with given fields: trackNumber
+func (_m *IOrderRepository) GetByTrackNumber(trackNumber string) (models.Order, error) {
+	ret := _m.Called(trackNumber)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByTrackNumber")
+	}
+
+	var r0 models.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (models.Order, error)); ok {
+		return rf(trackNumber)
+	}
+	if rf, ok := ret.Get(0).(func(string) models.Order); ok {
+		r0 = rf(trackNumber)
+	} else {
+		r0 = ret.Get(0).(models.Order)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(trackNumber)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IOrderRepository_GetByTrackNumber_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByTrackNumber'
+type IOrderRepository_GetByTrackNumber_Call struct {
+	*mock.Call
+}
+
+// GetByTrackNumber is a helper method to define mock.On call
+//   - trackNumber string
+func (_e *IOrderRepository_Expecter) GetByTrackNumber(trackNumber interface{}) *IOrderRepository_GetByTrackNumber_Call {
+	return &IOrderRepository_GetByTrackNumber_Call{Call: _e.mock.On("GetByTrackNumber", trackNumber)}
+}
+
+func (_c *IOrderRepository_GetByTrackNumber_Call) Run(run func(trackNumber string)) *IOrderRepository_GetByTrackNumber_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *IOrderRepository_GetByTrackNumber_Call) Return(_a0 models.Order, _a1 error) *IOrderRepository_GetByTrackNumber_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IOrderRepository_GetByTrackNumber_Call) RunAndReturn(run func(string) (models.Order, error)) *IOrderRepository_GetByTrackNumber_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetByUid provides a mock function with given fields: _a0
 func (_m *IOrderRepository) GetByUid(_a0 uuid.UUID) (models.Order, error) {
 	ret := _m.Called(_a0)
@@ -125,6 +395,180 @@ func (_c *IOrderRepository_GetByUid_Call) RunAndReturn(run func(uuid.UUID) (mode
 	return _c
 }
 
+// GetByUids provides a mock function with given fields: uids
+func (_m *IOrderRepository) GetByUids(uids []uuid.UUID) ([]models.Order, error) {
+	ret := _m.Called(uids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByUids")
+	}
+
+	var r0 []models.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) ([]models.Order, error)); ok {
+		return rf(uids)
+	}
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) []models.Order); ok {
+		r0 = rf(uids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]uuid.UUID) error); ok {
+		r1 = rf(uids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IOrderRepository_GetByUids_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByUids'
+type IOrderRepository_GetByUids_Call struct {
+	*mock.Call
+}
+
+// GetByUids is a helper method to define mock.On call
+//   - uids []uuid.UUID
+func (_e *IOrderRepository_Expecter) GetByUids(uids interface{}) *IOrderRepository_GetByUids_Call {
+	return &IOrderRepository_GetByUids_Call{Call: _e.mock.On("GetByUids", uids)}
+}
+
+func (_c *IOrderRepository_GetByUids_Call) Run(run func(uids []uuid.UUID)) *IOrderRepository_GetByUids_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].([]uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *IOrderRepository_GetByUids_Call) Return(_a0 []models.Order, _a1 error) *IOrderRepository_GetByUids_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IOrderRepository_GetByUids_Call) RunAndReturn(run func([]uuid.UUID) ([]models.Order, error)) *IOrderRepository_GetByUids_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetExistingUids provides a mock function with given fields: uids
+func (_m *IOrderRepository) GetExistingUids(uids []uuid.UUID) ([]uuid.UUID, error) {
+	ret := _m.Called(uids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetExistingUids")
+	}
+
+	var r0 []uuid.UUID
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) ([]uuid.UUID, error)); ok {
+		return rf(uids)
+	}
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) []uuid.UUID); ok {
+		r0 = rf(uids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]uuid.UUID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]uuid.UUID) error); ok {
+		r1 = rf(uids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IOrderRepository_GetExistingUids_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetExistingUids'
+type IOrderRepository_GetExistingUids_Call struct {
+	*mock.Call
+}
+
+// GetExistingUids is a helper method to define mock.On call
+//   - uids []uuid.UUID
+func (_e *IOrderRepository_Expecter) GetExistingUids(uids interface{}) *IOrderRepository_GetExistingUids_Call {
+	return &IOrderRepository_GetExistingUids_Call{Call: _e.mock.On("GetExistingUids", uids)}
+}
+
+func (_c *IOrderRepository_GetExistingUids_Call) Run(run func(uids []uuid.UUID)) *IOrderRepository_GetExistingUids_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].([]uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *IOrderRepository_GetExistingUids_Call) Return(_a0 []uuid.UUID, _a1 error) *IOrderRepository_GetExistingUids_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IOrderRepository_GetExistingUids_Call) RunAndReturn(run func([]uuid.UUID) ([]uuid.UUID, error)) *IOrderRepository_GetExistingUids_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetItemsByOrderUids provides a mock function with given fields: uids
+func (_m *IOrderRepository) GetItemsByOrderUids(uids []uuid.UUID) ([]models.Item, error) {
+	ret := _m.Called(uids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetItemsByOrderUids")
+	}
+
+	var r0 []models.Item
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) ([]models.Item, error)); ok {
+		return rf(uids)
+	}
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) []models.Item); ok {
+		r0 = rf(uids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Item)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]uuid.UUID) error); ok {
+		r1 = rf(uids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IOrderRepository_GetItemsByOrderUids_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetItemsByOrderUids'
+type IOrderRepository_GetItemsByOrderUids_Call struct {
+	*mock.Call
+}
+
+// GetItemsByOrderUids is a helper method to define mock.On call
+//   - uids []uuid.UUID
+func (_e *IOrderRepository_Expecter) GetItemsByOrderUids(uids interface{}) *IOrderRepository_GetItemsByOrderUids_Call {
+	return &IOrderRepository_GetItemsByOrderUids_Call{Call: _e.mock.On("GetItemsByOrderUids", uids)}
+}
+
+func (_c *IOrderRepository_GetItemsByOrderUids_Call) Run(run func(uids []uuid.UUID)) *IOrderRepository_GetItemsByOrderUids_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].([]uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *IOrderRepository_GetItemsByOrderUids_Call) Return(_a0 []models.Item, _a1 error) *IOrderRepository_GetItemsByOrderUids_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IOrderRepository_GetItemsByOrderUids_Call) RunAndReturn(run func([]uuid.UUID) ([]models.Item, error)) *IOrderRepository_GetItemsByOrderUids_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetRecentOrders provides a mock function with given fields: limit
 func (_m *IOrderRepository) GetRecentOrders(limit int) ([]models.Order, error) {
 	ret := _m.Called(limit)
@@ -183,6 +627,207 @@ func (_c *IOrderRepository_GetRecentOrders_Call) RunAndReturn(run func(int) ([]m
 	return _c
 }
 
+// PurgeCreatedBefore provides a mock function with given fields: before, limit
+func (_m *IOrderRepository) PurgeCreatedBefore(before time.Time, limit int) ([]uuid.UUID, error) {
+	ret := _m.Called(before, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PurgeCreatedBefore")
+	}
+
+	var r0 []uuid.UUID
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time, int) ([]uuid.UUID, error)); ok {
+		return rf(before, limit)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time, int) []uuid.UUID); ok {
+		r0 = rf(before, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]uuid.UUID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time, int) error); ok {
+		r1 = rf(before, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IOrderRepository_PurgeCreatedBefore_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PurgeCreatedBefore'
+type IOrderRepository_PurgeCreatedBefore_Call struct {
+	*mock.Call
+}
+
+// PurgeCreatedBefore is a helper method to define mock.On call
+//   - before time.Time
+//   - limit int
+func (_e *IOrderRepository_Expecter) PurgeCreatedBefore(before interface{}, limit interface{}) *IOrderRepository_PurgeCreatedBefore_Call {
+	return &IOrderRepository_PurgeCreatedBefore_Call{Call: _e.mock.On("PurgeCreatedBefore", before, limit)}
+}
+
+func (_c *IOrderRepository_PurgeCreatedBefore_Call) Run(run func(before time.Time, limit int)) *IOrderRepository_PurgeCreatedBefore_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(time.Time), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *IOrderRepository_PurgeCreatedBefore_Call) Return(_a0 []uuid.UUID, _a1 error) *IOrderRepository_PurgeCreatedBefore_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IOrderRepository_PurgeCreatedBefore_Call) RunAndReturn(run func(time.Time, int) ([]uuid.UUID, error)) *IOrderRepository_PurgeCreatedBefore_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SoftDelete provides a mock function with given fields: uid
+func (_m *IOrderRepository) SoftDelete(uid uuid.UUID) error {
+	ret := _m.Called(uid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SoftDelete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID) error); ok {
+		r0 = rf(uid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// IOrderRepository_SoftDelete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SoftDelete'
+type IOrderRepository_SoftDelete_Call struct {
+	*mock.Call
+}
+
+// SoftDelete is a helper method to define mock.On call
+//   - uid uuid.UUID
+func (_e *IOrderRepository_Expecter) SoftDelete(uid interface{}) *IOrderRepository_SoftDelete_Call {
+	return &IOrderRepository_SoftDelete_Call{Call: _e.mock.On("SoftDelete", uid)}
+}
+
+func (_c *IOrderRepository_SoftDelete_Call) Run(run func(uid uuid.UUID)) *IOrderRepository_SoftDelete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *IOrderRepository_SoftDelete_Call) Return(_a0 error) *IOrderRepository_SoftDelete_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *IOrderRepository_SoftDelete_Call) RunAndReturn(run func(uuid.UUID) error) *IOrderRepository_SoftDelete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// StreamOrders provides a mock function with given fields: ctx, filter, chunkSize, fn
+func (_m *IOrderRepository) StreamOrders(ctx context.Context, filter models.OrderFilter, chunkSize int, fn func([]models.Order) error) error {
+	ret := _m.Called(ctx, filter, chunkSize, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StreamOrders")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.OrderFilter, int, func([]models.Order) error) error); ok {
+		r0 = rf(ctx, filter, chunkSize, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// IOrderRepository_StreamOrders_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StreamOrders'
+type IOrderRepository_StreamOrders_Call struct {
+	*mock.Call
+}
+
+// StreamOrders is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter models.OrderFilter
+//   - chunkSize int
+//   - fn func([]models.Order) error
+func (_e *IOrderRepository_Expecter) StreamOrders(ctx interface{}, filter interface{}, chunkSize interface{}, fn interface{}) *IOrderRepository_StreamOrders_Call {
+	return &IOrderRepository_StreamOrders_Call{Call: _e.mock.On("StreamOrders", ctx, filter, chunkSize, fn)}
+}
+
+func (_c *IOrderRepository_StreamOrders_Call) Run(run func(ctx context.Context, filter models.OrderFilter, chunkSize int, fn func([]models.Order) error)) *IOrderRepository_StreamOrders_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.OrderFilter), args[2].(int), args[3].(func([]models.Order) error))
+	})
+	return _c
+}
+
+func (_c *IOrderRepository_StreamOrders_Call) Return(_a0 error) *IOrderRepository_StreamOrders_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *IOrderRepository_StreamOrders_Call) RunAndReturn(run func(context.Context, models.OrderFilter, int, func([]models.Order) error) error) *IOrderRepository_StreamOrders_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateItemsStatus provides a mock function with given fields: uid, status
+func (_m *IOrderRepository) UpdateItemsStatus(uid uuid.UUID, status int) error {
+	ret := _m.Called(uid, status)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateItemsStatus")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int) error); ok {
+		r0 = rf(uid, status)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// IOrderRepository_UpdateItemsStatus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateItemsStatus'
+type IOrderRepository_UpdateItemsStatus_Call struct {
+	*mock.Call
+}
+
+// UpdateItemsStatus is a helper method to define mock.On call
+//   - uid uuid.UUID
+//   - status int
+func (_e *IOrderRepository_Expecter) UpdateItemsStatus(uid interface{}, status interface{}) *IOrderRepository_UpdateItemsStatus_Call {
+	return &IOrderRepository_UpdateItemsStatus_Call{Call: _e.mock.On("UpdateItemsStatus", uid, status)}
+}
+
+func (_c *IOrderRepository_UpdateItemsStatus_Call) Run(run func(uid uuid.UUID, status int)) *IOrderRepository_UpdateItemsStatus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *IOrderRepository_UpdateItemsStatus_Call) Return(_a0 error) *IOrderRepository_UpdateItemsStatus_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *IOrderRepository_UpdateItemsStatus_Call) RunAndReturn(run func(uuid.UUID, int) error) *IOrderRepository_UpdateItemsStatus_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewIOrderRepository creates a new instance of IOrderRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewIOrderRepository(t interface {
@@ -0,0 +1,492 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "orderService/internal/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IStatsRepository is an autogenerated mock type for the IStatsRepository type
+type IStatsRepository struct {
+	mock.Mock
+}
+
+type IStatsRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *IStatsRepository) EXPECT() *IStatsRepository_Expecter {
+	return &IStatsRepository_Expecter{mock: &_m.Mock}
+}
+
+// Basket provides a mock function with given fields: filter
+func (_m *IStatsRepository) Basket(filter models.OrderFilter) ([]models.BasketStats, error) {
+	ret := _m.Called(filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Basket")
+	}
+
+	var r0 []models.BasketStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.OrderFilter) ([]models.BasketStats, error)); ok {
+		return rf(filter)
+	}
+	if rf, ok := ret.Get(0).(func(models.OrderFilter) []models.BasketStats); ok {
+		r0 = rf(filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.BasketStats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.OrderFilter) error); ok {
+		r1 = rf(filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IStatsRepository_Basket_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Basket'
+type IStatsRepository_Basket_Call struct {
+	*mock.Call
+}
+
+// Basket is a helper method to define mock.On call
+//   - filter models.OrderFilter
+func (_e *IStatsRepository_Expecter) Basket(filter interface{}) *IStatsRepository_Basket_Call {
+	return &IStatsRepository_Basket_Call{Call: _e.mock.On("Basket", filter)}
+}
+
+func (_c *IStatsRepository_Basket_Call) Run(run func(filter models.OrderFilter)) *IStatsRepository_Basket_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(models.OrderFilter))
+	})
+	return _c
+}
+
+func (_c *IStatsRepository_Basket_Call) Return(_a0 []models.BasketStats, _a1 error) *IStatsRepository_Basket_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IStatsRepository_Basket_Call) RunAndReturn(run func(models.OrderFilter) ([]models.BasketStats, error)) *IStatsRepository_Basket_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// OrdersByDeliveryService provides a mock function with given fields: filter
+func (_m *IStatsRepository) OrdersByDeliveryService(filter models.OrderFilter) ([]models.DeliveryServiceCount, error) {
+	ret := _m.Called(filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for OrdersByDeliveryService")
+	}
+
+	var r0 []models.DeliveryServiceCount
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.OrderFilter) ([]models.DeliveryServiceCount, error)); ok {
+		return rf(filter)
+	}
+	if rf, ok := ret.Get(0).(func(models.OrderFilter) []models.DeliveryServiceCount); ok {
+		r0 = rf(filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.DeliveryServiceCount)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.OrderFilter) error); ok {
+		r1 = rf(filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IStatsRepository_OrdersByDeliveryService_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'OrdersByDeliveryService'
+type IStatsRepository_OrdersByDeliveryService_Call struct {
+	*mock.Call
+}
+
+// OrdersByDeliveryService is a helper method to define mock.On call
+//   - filter models.OrderFilter
+func (_e *IStatsRepository_Expecter) OrdersByDeliveryService(filter interface{}) *IStatsRepository_OrdersByDeliveryService_Call {
+	return &IStatsRepository_OrdersByDeliveryService_Call{Call: _e.mock.On("OrdersByDeliveryService", filter)}
+}
+
+func (_c *IStatsRepository_OrdersByDeliveryService_Call) Run(run func(filter models.OrderFilter)) *IStatsRepository_OrdersByDeliveryService_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(models.OrderFilter))
+	})
+	return _c
+}
+
+func (_c *IStatsRepository_OrdersByDeliveryService_Call) Return(_a0 []models.DeliveryServiceCount, _a1 error) *IStatsRepository_OrdersByDeliveryService_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IStatsRepository_OrdersByDeliveryService_Call) RunAndReturn(run func(models.OrderFilter) ([]models.DeliveryServiceCount, error)) *IStatsRepository_OrdersByDeliveryService_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// OrdersByLocation provides a mock function with given fields: filter
+func (_m *IStatsRepository) OrdersByLocation(filter models.OrderFilter) ([]models.LocationCount, error) {
+	ret := _m.Called(filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for OrdersByLocation")
+	}
+
+	var r0 []models.LocationCount
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.OrderFilter) ([]models.LocationCount, error)); ok {
+		return rf(filter)
+	}
+	if rf, ok := ret.Get(0).(func(models.OrderFilter) []models.LocationCount); ok {
+		r0 = rf(filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.LocationCount)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.OrderFilter) error); ok {
+		r1 = rf(filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IStatsRepository_OrdersByLocation_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'OrdersByLocation'
+type IStatsRepository_OrdersByLocation_Call struct {
+	*mock.Call
+}
+
+// OrdersByLocation is a helper method to define mock.On call
+//   - filter models.OrderFilter
+func (_e *IStatsRepository_Expecter) OrdersByLocation(filter interface{}) *IStatsRepository_OrdersByLocation_Call {
+	return &IStatsRepository_OrdersByLocation_Call{Call: _e.mock.On("OrdersByLocation", filter)}
+}
+
+func (_c *IStatsRepository_OrdersByLocation_Call) Run(run func(filter models.OrderFilter)) *IStatsRepository_OrdersByLocation_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(models.OrderFilter))
+	})
+	return _c
+}
+
+func (_c *IStatsRepository_OrdersByLocation_Call) Return(_a0 []models.LocationCount, _a1 error) *IStatsRepository_OrdersByLocation_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IStatsRepository_OrdersByLocation_Call) RunAndReturn(run func(models.OrderFilter) ([]models.LocationCount, error)) *IStatsRepository_OrdersByLocation_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// OrdersPerPeriod provides a mock function with given fields: filter, period
+func (_m *IStatsRepository) OrdersPerPeriod(filter models.OrderFilter, period models.Period) ([]models.PeriodCount, error) {
+	ret := _m.Called(filter, period)
+
+	if len(ret) == 0 {
+		panic("no return value specified for OrdersPerPeriod")
+	}
+
+	var r0 []models.PeriodCount
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.OrderFilter, models.Period) ([]models.PeriodCount, error)); ok {
+		return rf(filter, period)
+	}
+	if rf, ok := ret.Get(0).(func(models.OrderFilter, models.Period) []models.PeriodCount); ok {
+		r0 = rf(filter, period)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.PeriodCount)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.OrderFilter, models.Period) error); ok {
+		r1 = rf(filter, period)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IStatsRepository_OrdersPerPeriod_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'OrdersPerPeriod'
+type IStatsRepository_OrdersPerPeriod_Call struct {
+	*mock.Call
+}
+
+// OrdersPerPeriod is a helper method to define mock.On call
+//   - filter models.OrderFilter
+//   - period models.Period
+func (_e *IStatsRepository_Expecter) OrdersPerPeriod(filter interface{}, period interface{}) *IStatsRepository_OrdersPerPeriod_Call {
+	return &IStatsRepository_OrdersPerPeriod_Call{Call: _e.mock.On("OrdersPerPeriod", filter, period)}
+}
+
+func (_c *IStatsRepository_OrdersPerPeriod_Call) Run(run func(filter models.OrderFilter, period models.Period)) *IStatsRepository_OrdersPerPeriod_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(models.OrderFilter), args[1].(models.Period))
+	})
+	return _c
+}
+
+func (_c *IStatsRepository_OrdersPerPeriod_Call) Return(_a0 []models.PeriodCount, _a1 error) *IStatsRepository_OrdersPerPeriod_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IStatsRepository_OrdersPerPeriod_Call) RunAndReturn(run func(models.OrderFilter, models.Period) ([]models.PeriodCount, error)) *IStatsRepository_OrdersPerPeriod_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RefreshViews provides a mock function with given fields: ctx
+func (_m *IStatsRepository) RefreshViews(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RefreshViews")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// IStatsRepository_RefreshViews_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RefreshViews'
+type IStatsRepository_RefreshViews_Call struct {
+	*mock.Call
+}
+
+// RefreshViews is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *IStatsRepository_Expecter) RefreshViews(ctx interface{}) *IStatsRepository_RefreshViews_Call {
+	return &IStatsRepository_RefreshViews_Call{Call: _e.mock.On("RefreshViews", ctx)}
+}
+
+func (_c *IStatsRepository_RefreshViews_Call) Run(run func(ctx context.Context)) *IStatsRepository_RefreshViews_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *IStatsRepository_RefreshViews_Call) Return(_a0 error) *IStatsRepository_RefreshViews_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *IStatsRepository_RefreshViews_Call) RunAndReturn(run func(context.Context) error) *IStatsRepository_RefreshViews_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevenueByCurrency provides a mock function with given fields: filter
+func (_m *IStatsRepository) RevenueByCurrency(filter models.OrderFilter) ([]models.CurrencyRevenue, error) {
+	ret := _m.Called(filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevenueByCurrency")
+	}
+
+	var r0 []models.CurrencyRevenue
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.OrderFilter) ([]models.CurrencyRevenue, error)); ok {
+		return rf(filter)
+	}
+	if rf, ok := ret.Get(0).(func(models.OrderFilter) []models.CurrencyRevenue); ok {
+		r0 = rf(filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.CurrencyRevenue)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.OrderFilter) error); ok {
+		r1 = rf(filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IStatsRepository_RevenueByCurrency_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevenueByCurrency'
+type IStatsRepository_RevenueByCurrency_Call struct {
+	*mock.Call
+}
+
+// RevenueByCurrency is a helper method to define mock.On call
+//   - filter models.OrderFilter
+func (_e *IStatsRepository_Expecter) RevenueByCurrency(filter interface{}) *IStatsRepository_RevenueByCurrency_Call {
+	return &IStatsRepository_RevenueByCurrency_Call{Call: _e.mock.On("RevenueByCurrency", filter)}
+}
+
+func (_c *IStatsRepository_RevenueByCurrency_Call) Run(run func(filter models.OrderFilter)) *IStatsRepository_RevenueByCurrency_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(models.OrderFilter))
+	})
+	return _c
+}
+
+func (_c *IStatsRepository_RevenueByCurrency_Call) Return(_a0 []models.CurrencyRevenue, _a1 error) *IStatsRepository_RevenueByCurrency_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IStatsRepository_RevenueByCurrency_Call) RunAndReturn(run func(models.OrderFilter) ([]models.CurrencyRevenue, error)) *IStatsRepository_RevenueByCurrency_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TopBrands provides a mock function with given fields: filter, limit
+func (_m *IStatsRepository) TopBrands(filter models.OrderFilter, limit int) ([]models.TopBrand, error) {
+	ret := _m.Called(filter, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TopBrands")
+	}
+
+	var r0 []models.TopBrand
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.OrderFilter, int) ([]models.TopBrand, error)); ok {
+		return rf(filter, limit)
+	}
+	if rf, ok := ret.Get(0).(func(models.OrderFilter, int) []models.TopBrand); ok {
+		r0 = rf(filter, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.TopBrand)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.OrderFilter, int) error); ok {
+		r1 = rf(filter, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IStatsRepository_TopBrands_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TopBrands'
+type IStatsRepository_TopBrands_Call struct {
+	*mock.Call
+}
+
+// TopBrands is a helper method to define mock.On call
+//   - filter models.OrderFilter
+//   - limit int
+func (_e *IStatsRepository_Expecter) TopBrands(filter interface{}, limit interface{}) *IStatsRepository_TopBrands_Call {
+	return &IStatsRepository_TopBrands_Call{Call: _e.mock.On("TopBrands", filter, limit)}
+}
+
+func (_c *IStatsRepository_TopBrands_Call) Run(run func(filter models.OrderFilter, limit int)) *IStatsRepository_TopBrands_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(models.OrderFilter), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *IStatsRepository_TopBrands_Call) Return(_a0 []models.TopBrand, _a1 error) *IStatsRepository_TopBrands_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IStatsRepository_TopBrands_Call) RunAndReturn(run func(models.OrderFilter, int) ([]models.TopBrand, error)) *IStatsRepository_TopBrands_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TopItems provides a mock function with given fields: filter, limit
+func (_m *IStatsRepository) TopItems(filter models.OrderFilter, limit int) ([]models.TopItem, error) {
+	ret := _m.Called(filter, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TopItems")
+	}
+
+	var r0 []models.TopItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.OrderFilter, int) ([]models.TopItem, error)); ok {
+		return rf(filter, limit)
+	}
+	if rf, ok := ret.Get(0).(func(models.OrderFilter, int) []models.TopItem); ok {
+		r0 = rf(filter, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.TopItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.OrderFilter, int) error); ok {
+		r1 = rf(filter, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IStatsRepository_TopItems_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TopItems'
+type IStatsRepository_TopItems_Call struct {
+	*mock.Call
+}
+
+// TopItems is a helper method to define mock.On call
+//   - filter models.OrderFilter
+//   - limit int
+func (_e *IStatsRepository_Expecter) TopItems(filter interface{}, limit interface{}) *IStatsRepository_TopItems_Call {
+	return &IStatsRepository_TopItems_Call{Call: _e.mock.On("TopItems", filter, limit)}
+}
+
+func (_c *IStatsRepository_TopItems_Call) Run(run func(filter models.OrderFilter, limit int)) *IStatsRepository_TopItems_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(models.OrderFilter), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *IStatsRepository_TopItems_Call) Return(_a0 []models.TopItem, _a1 error) *IStatsRepository_TopItems_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IStatsRepository_TopItems_Call) RunAndReturn(run func(models.OrderFilter, int) ([]models.TopItem, error)) *IStatsRepository_TopItems_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewIStatsRepository creates a new instance of IStatsRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIStatsRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IStatsRepository {
+	mock := &IStatsRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
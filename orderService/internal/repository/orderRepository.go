@@ -1,17 +1,45 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"io"
 	"log"
+	"net"
 	"orderService/internal/models"
+	"slices"
+	"strings"
+	"time"
 )
 
+const uniqueViolationCode = "23505"
+
+// ErrVersionConflict is returned when the order was changed after the caller read it
+var ErrVersionConflict = errors.New("order was modified, fetch it again and retry")
+
 //go:generate mockery --name=IOrderRepository --output=mocks --outpkg=mocks --case=snake --with-expecter
 type IOrderRepository interface {
 	GetByUid(uuid uuid.UUID) (models.Order, error)
+	GetByUids(uids []uuid.UUID) ([]models.Order, error)
 	Create(order models.Order) error
+	CreateBatch(orders []models.Order) error
+	GetExistingUids(uids []uuid.UUID) ([]uuid.UUID, error)
 	GetRecentOrders(limit int) ([]models.Order, error)
+	GetByTrackNumber(trackNumber string) (models.Order, error)
+	FindByCustomer(customerID string, after *models.OrderCursor, limit int) ([]models.Order, error)
+	GetItemsByOrderUids(uids []uuid.UUID) ([]models.Item, error)
+	UpdateItemsStatus(uid uuid.UUID, status int) error
+	StreamOrders(ctx context.Context, filter models.OrderFilter, chunkSize int, fn func([]models.Order) error) error
+	SoftDelete(uid uuid.UUID) error
+	AnonymizeCustomer(customerID string) ([]uuid.UUID, error)
+	PurgeCreatedBefore(before time.Time, limit int) ([]uuid.UUID, error)
+	Amend(before, amended models.Order) error
 }
 
 type Repository struct {
@@ -32,6 +60,19 @@ func (r Repository) GetByUid(uuid uuid.UUID) (models.Order, error) {
 	return order, nil
 }
 
+// GetByUids returns the existing orders among uids with a single query per table. Unknown uids are skipped
+func (r Repository) GetByUids(uids []uuid.UUID) ([]models.Order, error) {
+	var orders []models.Order
+	if err := r.DB.Preload("Items").Preload("Delivery").Preload("Payment").
+		Where("uid IN ?", uids).
+		Find(&orders).Error; err != nil {
+		log.Printf("Error fetching orders: %v\n", err)
+		return nil, err
+	}
+
+	return orders, nil
+}
+
 func (r Repository) GetRecentOrders(limit int) ([]models.Order, error) {
 	var orders []models.Order
 	if err := r.DB.Preload("Items").Preload("Delivery").Preload("Payment").
@@ -45,6 +86,45 @@ func (r Repository) GetRecentOrders(limit int) ([]models.Order, error) {
 	return orders, nil
 }
 
+// GetByTrackNumber returns the latest order with the track number. Items are not loaded
+func (r Repository) GetByTrackNumber(trackNumber string) (models.Order, error) {
+	var order models.Order
+	if err := r.DB.Preload("Delivery").Preload("Payment").
+		Order("date_created DESC").
+		Take(&order, "track_number = ?", trackNumber).Error; err != nil {
+		log.Printf("Error fetching order by track number: %v\n", err)
+		return models.Order{}, err
+	}
+
+	return order, nil
+}
+
+// FindByCustomer returns a page of customer orders, newest first, starting after the cursor. Items are not loaded
+func (r Repository) FindByCustomer(customerID string, after *models.OrderCursor, limit int) ([]models.Order, error) {
+	query := r.DB.Preload("Delivery").Preload("Payment").Where("customer_id = ?", customerID)
+	if after != nil {
+		query = query.Where("(date_created, uid) < (?, ?)", after.DateCreated, after.Uid.String())
+	}
+
+	var orders []models.Order
+	if err := query.Order("date_created DESC, uid DESC").Limit(limit).Find(&orders).Error; err != nil {
+		log.Printf("Error fetching customer orders: %v\n", err)
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+func (r Repository) GetItemsByOrderUids(uids []uuid.UUID) ([]models.Item, error) {
+	var items []models.Item
+	if err := r.DB.Where("order_uid IN ?", uids).Order("id").Find(&items).Error; err != nil {
+		log.Printf("Error fetching order items: %v\n", err)
+		return nil, err
+	}
+
+	return items, nil
+}
+
 func (r Repository) Create(order models.Order) error {
 	if err := r.DB.Create(&order).Error; err != nil {
 		log.Printf("Error create order: %v\n", err)
@@ -52,3 +132,350 @@ func (r Repository) Create(order models.Order) error {
 	}
 	return nil
 }
+
+// CreateBatch inserts the orders with their delivery, payment and items in one transaction
+func (r Repository) CreateBatch(orders []models.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	if err := r.DB.Create(&orders).Error; err != nil {
+		log.Printf("Error create orders batch: %v\n", err)
+		return err
+	}
+	return nil
+}
+
+// GetExistingUids returns the uids among uids that are already stored, soft deleted orders included
+func (r Repository) GetExistingUids(uids []uuid.UUID) ([]uuid.UUID, error) {
+	existing := make([]uuid.UUID, 0)
+	if err := r.DB.Unscoped().Model(&models.Order{}).Where("uid IN ?", uids).Pluck("uid", &existing).Error; err != nil {
+		log.Printf("Error fetching existing order uids: %v\n", err)
+		return nil, err
+	}
+
+	return existing, nil
+}
+
+// UpdateItemsStatus sets the status of every item of the order and increments its version
+func (r Repository) UpdateItemsStatus(uid uuid.UUID, status int) error {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Order{}).Where("uid = ?", uid).Updates(amendment())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Model(&models.Item{}).Where("order_uid = ?", uid).Update("status", status).Error
+	})
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("Error updating order status: %v\n", err)
+	}
+	return err
+}
+
+// amendment increments the version of the changed order and sets its amendment time, so a concurrent amendment
+// of the version read before fails and cached representations are invalidated
+func amendment() map[string]any {
+	return map[string]any{"version": gorm.Expr("version + 1"), "amended_at": gorm.Expr("now()")}
+}
+
+// SoftDelete hides the order from every query, the rows stay until the retention job purges them
+func (r Repository) SoftDelete(uid uuid.UUID) error {
+	result := r.DB.Delete(&models.Order{}, "uid = ?", uid)
+	if result.Error != nil {
+		log.Printf("Error deleting order: %v\n", result.Error)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// AnonymizeCustomer blanks the personal fields of the deliveries of every customer order, soft deleted included,
+// and increments the versions of the orders. City and region are kept for statistics, payments and items
+// are financial records and are not touched
+func (r Repository) AnonymizeCustomer(customerID string) ([]uuid.UUID, error) {
+	var orders []models.Order
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&orders).Clauses(clause.Returning{Columns: []clause.Column{{Name: "uid"}, {Name: "delivery_id"}}}).
+			Where("customer_id = ?", customerID).Updates(amendment()).Error; err != nil {
+			return err
+		}
+		if len(orders) == 0 {
+			return nil
+		}
+
+		deliveryIDs := make([]uint, 0, len(orders))
+		for _, order := range orders {
+			deliveryIDs = append(deliveryIDs, order.DeliveryID)
+		}
+		return tx.Model(&models.Delivery{}).Where("id IN ?", deliveryIDs).Updates(map[string]any{
+			"name":    "",
+			"phone":   "",
+			"zip":     "",
+			"address": "",
+			"email":   "",
+		}).Error
+	})
+	if err != nil {
+		log.Printf("Error anonymizing customer: %v\n", err)
+		return nil, err
+	}
+
+	uids := make([]uuid.UUID, 0, len(orders))
+	for _, order := range orders {
+		uids = append(uids, order.Uid)
+	}
+	return uids, nil
+}
+
+// PurgeCreatedBefore hard deletes up to limit of the oldest orders created before the time, soft deleted included,
+// with their items, delivery and payment. Rows locked by other transactions are skipped until the next batch
+func (r Repository) PurgeCreatedBefore(before time.Time, limit int) ([]uuid.UUID, error) {
+	var orders []models.Order
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Select("uid", "delivery_id", "payment_id").
+			Where("date_created < ?", before).
+			Order("date_created").Limit(limit).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Find(&orders).Error; err != nil {
+			return err
+		}
+		if len(orders) == 0 {
+			return nil
+		}
+
+		uids := make([]uuid.UUID, 0, len(orders))
+		deliveryIDs := make([]uint, 0, len(orders))
+		paymentIDs := make([]uint, 0, len(orders))
+		for _, order := range orders {
+			uids = append(uids, order.Uid)
+			deliveryIDs = append(deliveryIDs, order.DeliveryID)
+			paymentIDs = append(paymentIDs, order.PaymentID)
+		}
+
+		if err := tx.Where("order_uid IN ?", uids).Delete(&models.Item{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("uid IN ?", uids).Delete(&models.Order{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id IN ?", deliveryIDs).Delete(&models.Delivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", paymentIDs).Delete(&models.Payment{}).Error
+	})
+	if err != nil {
+		log.Printf("Error purging orders: %v\n", err)
+		return nil, err
+	}
+
+	uids := make([]uuid.UUID, 0, len(orders))
+	for _, order := range orders {
+		uids = append(uids, order.Uid)
+	}
+	return uids, nil
+}
+
+// Amend stores what changed between the order read as before and the amended one if the order still has
+// the version of before, and increments the version. Only the changed delivery columns are written,
+// new items have a zero id, stored items missing from the amended order are deleted
+func (r Repository) Amend(before, amended models.Order) error {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Order{}).
+			Where("uid = ? AND version = ?", amended.Uid, before.Version).
+			Updates(map[string]any{"version": gorm.Expr("version + 1"), "amended_at": amended.AmendedAt})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+
+		if changes := deliveryChanges(before.Delivery, amended.Delivery); len(changes) > 0 {
+			if err := tx.Model(&amended.Delivery).Updates(changes).Error; err != nil {
+				return err
+			}
+		}
+		if before.Payment != amended.Payment {
+			if err := tx.Save(&amended.Payment).Error; err != nil {
+				return err
+			}
+		}
+
+		kept := make([]uint32, 0, len(amended.Items))
+		added := make([]models.Item, 0)
+		for _, item := range amended.Items {
+			if item.Id == 0 {
+				item.OrderUid = amended.Uid
+				added = append(added, item)
+			} else {
+				kept = append(kept, item.Id)
+			}
+		}
+
+		removed := tx.Where("order_uid = ?", amended.Uid)
+		if len(kept) > 0 {
+			removed = removed.Where("id NOT IN ?", kept)
+		}
+		if err := removed.Delete(&models.Item{}).Error; err != nil {
+			return err
+		}
+		if len(added) > 0 {
+			return tx.Create(&added).Error
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrVersionConflict) {
+		log.Printf("Error amending order: %v\n", err)
+	}
+	return err
+}
+
+// deliveryChanges returns the delivery columns whose values differ
+func deliveryChanges(before, after models.Delivery) map[string]any {
+	changes := make(map[string]any)
+	for _, field := range []struct {
+		column        string
+		before, after string
+	}{
+		{"name", before.Name, after.Name},
+		{"phone", before.Phone, after.Phone},
+		{"zip", before.Zip, after.Zip},
+		{"city", before.City, after.City},
+		{"address", before.Address, after.Address},
+		{"region", before.Region, after.Region},
+		{"email", before.Email, after.Email},
+	} {
+		if field.before != field.after {
+			changes[field.column] = field.after
+		}
+	}
+	return changes
+}
+
+// StreamOrders reads the filtered orders oldest first and passes them to fn in chunks of chunkSize with delivery,
+// payment and items loaded, so the whole result is never held in memory. The chunks are read page by page after
+// the last order of the previous one in a single read-only transaction: the export sees one snapshot and holds
+// exactly one pool connection, a chunk is fully read before its associations are queried on the same connection
+func (r Repository) StreamOrders(ctx context.Context, filter models.OrderFilter, chunkSize int, fn func([]models.Order) error) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var after *models.OrderCursor
+		for {
+			query := r.filtered(tx.Model(&models.Order{}), filter)
+			if after != nil {
+				query = query.Where("(date_created, uid) > (?, ?)", after.DateCreated, after.Uid.String())
+			}
+
+			var chunk []models.Order
+			if err := query.Order("date_created, uid").Limit(chunkSize).Find(&chunk).Error; err != nil {
+				log.Printf("Error streaming orders: %v\n", err)
+				return err
+			}
+			if len(chunk) == 0 {
+				return nil
+			}
+
+			last := chunk[len(chunk)-1]
+			after = &models.OrderCursor{DateCreated: last.DateCreated, Uid: last.Uid}
+			if err := emitChunk(tx, chunk, fn); err != nil {
+				return err
+			}
+			if len(chunk) < chunkSize {
+				return nil
+			}
+		}
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+}
+
+// emitChunk loads the associations of the chunk with one query per table on the transaction of the stream
+func emitChunk(db *gorm.DB, chunk []models.Order, fn func([]models.Order) error) error {
+	if len(chunk) == 0 {
+		return nil
+	}
+
+	uids := make([]uuid.UUID, 0, len(chunk))
+	deliveryIDs := make([]uint, 0, len(chunk))
+	paymentIDs := make([]uint, 0, len(chunk))
+	for _, order := range chunk {
+		uids = append(uids, order.Uid)
+		deliveryIDs = append(deliveryIDs, order.DeliveryID)
+		paymentIDs = append(paymentIDs, order.PaymentID)
+	}
+
+	var deliveries []models.Delivery
+	if err := db.Where("id IN ?", deliveryIDs).Find(&deliveries).Error; err != nil {
+		return err
+	}
+	var payments []models.Payment
+	if err := db.Where("id IN ?", paymentIDs).Find(&payments).Error; err != nil {
+		return err
+	}
+	var items []models.Item
+	if err := db.Where("order_uid IN ?", uids).Order("id").Find(&items).Error; err != nil {
+		return err
+	}
+
+	deliveryByID := make(map[uint]models.Delivery, len(deliveries))
+	for _, delivery := range deliveries {
+		deliveryByID[delivery.ID] = delivery
+	}
+	paymentByID := make(map[uint]models.Payment, len(payments))
+	for _, payment := range payments {
+		paymentByID[payment.ID] = payment
+	}
+	itemsByOrder := make(map[uuid.UUID][]models.Item, len(chunk))
+	for _, item := range items {
+		itemsByOrder[item.OrderUid] = append(itemsByOrder[item.OrderUid], item)
+	}
+
+	for i := range chunk {
+		chunk[i].Delivery = deliveryByID[chunk[i].DeliveryID]
+		chunk[i].Payment = paymentByID[chunk[i].PaymentID]
+		chunk[i].Items = itemsByOrder[chunk[i].Uid]
+	}
+
+	return fn(chunk)
+}
+
+func (r Repository) filtered(query *gorm.DB, filter models.OrderFilter) *gorm.DB {
+	if !filter.From.IsZero() {
+		query = query.Where("date_created >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("date_created < ?", filter.To)
+	}
+	if filter.CustomerID != "" {
+		query = query.Where("customer_id = ?", filter.CustomerID)
+	}
+	return query
+}
+
+// IsDuplicateKey reports whether the error is a unique constraint violation, e.g. an order with the same uid already exists
+func IsDuplicateKey(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
+
+// transientCodes are Postgres errors that go away on retry: serialization failure, deadlock,
+// too many connections, statement timeout and server shutdown or startup
+var transientCodes = []string{"40001", "40P01", "53300", "57014", "57P01", "57P02", "57P03"}
+
+// IsTransient reports whether the operation may succeed on retry: the database is unreachable,
+// restarting or overloaded, the transaction lost a deadlock or timed out
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// class 08 is connection exception
+		return strings.HasPrefix(pgErr.Code, "08") || slices.Contains(transientCodes, pgErr.Code)
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || pgconn.Timeout(err) || pgconn.SafeToRetry(err) ||
+		errors.Is(err, context.DeadlineExceeded) || errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.ErrUnexpectedEOF)
+}
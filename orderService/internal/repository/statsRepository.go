@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"log"
+	"orderService/internal/models"
+	"time"
+)
+
+const dayDuration = 24 * time.Hour
+
+//go:generate mockery --name=IStatsRepository --output=mocks --outpkg=mocks --case=snake --with-expecter
+type IStatsRepository interface {
+	OrdersPerPeriod(filter models.OrderFilter, period models.Period) ([]models.PeriodCount, error)
+	RevenueByCurrency(filter models.OrderFilter) ([]models.CurrencyRevenue, error)
+	TopBrands(filter models.OrderFilter, limit int) ([]models.TopBrand, error)
+	TopItems(filter models.OrderFilter, limit int) ([]models.TopItem, error)
+	OrdersByDeliveryService(filter models.OrderFilter) ([]models.DeliveryServiceCount, error)
+	OrdersByLocation(filter models.OrderFilter) ([]models.LocationCount, error)
+	Basket(filter models.OrderFilter) ([]models.BasketStats, error)
+	RefreshViews(ctx context.Context) error
+}
+
+// StatsRepository aggregates daily rows of orders and items. The rows come either from the
+// order_stats_daily and item_stats_daily materialized views or are computed from the tables on every request
+type StatsRepository struct {
+	DB      *gorm.DB
+	useView bool
+}
+
+func NewStatsRepository(db *gorm.DB, useView bool) StatsRepository {
+	return StatsRepository{DB: db, useView: useView}
+}
+
+func (r StatsRepository) OrdersPerPeriod(filter models.OrderFilter, period models.Period) ([]models.PeriodCount, error) {
+	var counts []models.PeriodCount
+	err := r.DB.Table("(?) AS s", r.orderSource(filter)).
+		Select("date_trunc(?, s.day)::date AS period, sum(s.orders) AS orders", string(period)).
+		Group("period").Order("period").
+		Scan(&counts).Error
+	return counts, r.logError("orders per period", err)
+}
+
+func (r StatsRepository) RevenueByCurrency(filter models.OrderFilter) ([]models.CurrencyRevenue, error) {
+	var revenue []models.CurrencyRevenue
+	err := r.DB.Table("(?) AS s", r.orderSource(filter)).
+		Select("s.currency, sum(s.orders) AS orders, sum(s.revenue) AS revenue").
+		Group("s.currency").Order("revenue DESC").
+		Scan(&revenue).Error
+	return revenue, r.logError("revenue by currency", err)
+}
+
+func (r StatsRepository) TopBrands(filter models.OrderFilter, limit int) ([]models.TopBrand, error) {
+	var brands []models.TopBrand
+	err := r.DB.Table("(?) AS s", r.itemSource(filter)).
+		Select("s.brand, sum(s.quantity) AS quantity, sum(s.revenue) AS revenue").
+		Group("s.brand").Order("revenue DESC, s.brand").Limit(limit).
+		Scan(&brands).Error
+	return brands, r.logError("top brands", err)
+}
+
+func (r StatsRepository) TopItems(filter models.OrderFilter, limit int) ([]models.TopItem, error) {
+	var items []models.TopItem
+	err := r.DB.Table("(?) AS s", r.itemSource(filter)).
+		Select("s.name, s.brand, sum(s.quantity) AS quantity, sum(s.revenue) AS revenue").
+		Group("s.name, s.brand").Order("revenue DESC, s.name").Limit(limit).
+		Scan(&items).Error
+	return items, r.logError("top items", err)
+}
+
+func (r StatsRepository) OrdersByDeliveryService(filter models.OrderFilter) ([]models.DeliveryServiceCount, error) {
+	var counts []models.DeliveryServiceCount
+	err := r.DB.Table("(?) AS s", r.orderSource(filter)).
+		Select("s.delivery_service, sum(s.orders) AS orders").
+		Group("s.delivery_service").Order("orders DESC").
+		Scan(&counts).Error
+	return counts, r.logError("orders by delivery service", err)
+}
+
+func (r StatsRepository) OrdersByLocation(filter models.OrderFilter) ([]models.LocationCount, error) {
+	var counts []models.LocationCount
+	err := r.DB.Table("(?) AS s", r.orderSource(filter)).
+		Select("s.region, s.city, sum(s.orders) AS orders").
+		Group("s.region, s.city").Order("orders DESC").
+		Scan(&counts).Error
+	return counts, r.logError("orders by location", err)
+}
+
+func (r StatsRepository) Basket(filter models.OrderFilter) ([]models.BasketStats, error) {
+	var basket []models.BasketStats
+	err := r.DB.Table("(?) AS s", r.orderSource(filter)).
+		Select("s.currency, sum(s.orders) AS orders, sum(s.revenue) / sum(s.orders) AS avg_amount, sum(s.items)::float8 / sum(s.orders) AS avg_items").
+		Group("s.currency").Order("orders DESC").
+		Scan(&basket).Error
+	return basket, r.logError("basket", err)
+}
+
+// RefreshViews recomputes the materialized views without blocking readers
+func (r StatsRepository) RefreshViews(ctx context.Context) error {
+	db := r.DB.WithContext(ctx)
+	for _, view := range []string{"order_stats_daily", "item_stats_daily"} {
+		if err := db.Exec("REFRESH MATERIALIZED VIEW CONCURRENTLY " + view).Error; err != nil {
+			return r.logError("refresh "+view, err)
+		}
+	}
+	return nil
+}
+
+// orderSource returns rows of (day, currency, delivery_service, region, city, orders, revenue, items)
+func (r StatsRepository) orderSource(filter models.OrderFilter) *gorm.DB {
+	if r.useView {
+		return r.viewDays(r.DB.Table("order_stats_daily"), filter)
+	}
+
+	return r.tableDates(r.DB.Table(`"order" o`), filter).
+		Select(`(o.date_created AT TIME ZONE 'UTC')::date AS day, p.currency,
+			COALESCE(o.delivery_service, '') AS delivery_service, COALESCE(d.region, '') AS region, d.city,
+			count(*) AS orders, sum(p.amount) AS revenue, sum(COALESCE(i.items, 0)) AS items`).
+		Joins("JOIN payment p ON p.id = o.payment_id").
+		Joins("JOIN delivery d ON d.id = o.delivery_id").
+		Joins("LEFT JOIN (SELECT order_uid, count(*) AS items FROM item GROUP BY order_uid) i ON i.order_uid = o.uid").
+		Group("1, 2, 3, 4, 5")
+}
+
+// itemSource returns rows of (day, brand, name, quantity, revenue)
+func (r StatsRepository) itemSource(filter models.OrderFilter) *gorm.DB {
+	if r.useView {
+		return r.viewDays(r.DB.Table("item_stats_daily"), filter)
+	}
+
+	return r.tableDates(r.DB.Table("item i"), filter).
+		Select(`(o.date_created AT TIME ZONE 'UTC')::date AS day, COALESCE(i.brand, '') AS brand, i.name,
+			count(*) AS quantity, sum(i.total_price) AS revenue`).
+		Joins(`JOIN "order" o ON o.uid = i.order_uid`).
+		Group("1, 2, 3")
+}
+
+func (r StatsRepository) tableDates(query *gorm.DB, filter models.OrderFilter) *gorm.DB {
+	query = query.Where("o.deleted_at IS NULL")
+	if !filter.From.IsZero() {
+		query = query.Where("o.date_created >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("o.date_created < ?", filter.To)
+	}
+	return query
+}
+
+// viewDays filters the daily rows of a view, a bound inside a day includes the whole day
+func (r StatsRepository) viewDays(query *gorm.DB, filter models.OrderFilter) *gorm.DB {
+	if !filter.From.IsZero() {
+		query = query.Where("day >= ?", filter.From.UTC().Truncate(dayDuration).Format(time.DateOnly))
+	}
+	if !filter.To.IsZero() {
+		to := filter.To.UTC()
+		if !to.Equal(to.Truncate(dayDuration)) {
+			to = to.Truncate(dayDuration).Add(dayDuration)
+		}
+		query = query.Where("day < ?", to.Format(time.DateOnly))
+	}
+	return query
+}
+
+func (r StatsRepository) logError(stat string, err error) error {
+	if err != nil {
+		log.Printf("Error computing %s stats: %v\n", stat, err)
+	}
+	return err
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"log"
+	"orderService/internal/models"
+	"orderService/internal/repository"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// redacted replaces personal data in diffs, the audit log must not keep a copy of the data it was erased from
+const redacted = "[redacted]"
+
+var personalDataPaths = map[string]bool{
+	"delivery.name":    true,
+	"delivery.phone":   true,
+	"delivery.zip":     true,
+	"delivery.address": true,
+	"delivery.email":   true,
+}
+
+//go:generate mockery --name=IAuditService --output=mocks --outpkg=mocks --case=snake --with-expecter
+type IAuditService interface {
+	Record(entries ...models.AuditEntry)
+	FindByOrder(uid uuid.UUID, limit int) ([]models.AuditEntry, error)
+}
+
+type AuditService struct {
+	repo repository.IAuditRepository
+	now  func() time.Time
+}
+
+func NewAuditService(r repository.IAuditRepository) AuditService {
+	return AuditService{
+		repo: r,
+		now:  time.Now,
+	}
+}
+
+// Record stamps and writes the entries. It does not fail the caller, the action is already done when it is audited
+func (s AuditService) Record(entries ...models.AuditEntry) {
+	now := s.now()
+	for i := range entries {
+		entries[i].OccurredAt = now
+	}
+
+	if err := s.repo.Append(entries...); err != nil {
+		log.Printf("Error recording audit entries: %s\n", err.Error())
+	}
+}
+
+func (s AuditService) FindByOrder(uid uuid.UUID, limit int) ([]models.AuditEntry, error) {
+	return s.repo.FindByOrder(uid, limit)
+}
+
+// Change is the old and the new value of a field, a missing value is omitted
+type Change struct {
+	Old any `json:"old,omitempty"`
+	New any `json:"new,omitempty"`
+}
+
+// Diff compares the JSON forms of before and after field by field. Nested fields are joined with dots,
+// list elements by index, e.g. items.0.status. A nil before or after diffs against nothing
+func Diff(before, after any) json.RawMessage {
+	old, err := flatten(before)
+	if err != nil {
+		log.Printf("Error computing audit diff: %s\n", err.Error())
+		return nil
+	}
+	updated, err := flatten(after)
+	if err != nil {
+		log.Printf("Error computing audit diff: %s\n", err.Error())
+		return nil
+	}
+
+	changes := make(map[string]Change)
+	for path, value := range old {
+		if newValue, ok := updated[path]; !ok || !reflect.DeepEqual(value, newValue) {
+			changes[path] = Change{Old: redact(path, value), New: redact(path, newValue)}
+		}
+	}
+	for path, value := range updated {
+		if _, ok := old[path]; !ok {
+			changes[path] = Change{New: redact(path, value)}
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	diff, err := json.Marshal(changes)
+	if err != nil {
+		log.Printf("Error computing audit diff: %s\n", err.Error())
+		return nil
+	}
+	return diff
+}
+
+func flatten(value any) (map[string]any, error) {
+	fields := make(map[string]any)
+	if value == nil {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var decoded any
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+
+	flattenInto(fields, "", decoded)
+	return fields, nil
+}
+
+func flattenInto(fields map[string]any, path string, value any) {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, nested := range value {
+			flattenInto(fields, join(path, key), nested)
+		}
+	case []any:
+		for i, nested := range value {
+			flattenInto(fields, join(path, strconv.Itoa(i)), nested)
+		}
+	default:
+		fields[path] = value
+	}
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func redact(path string, value any) any {
+	if text, ok := value.(string); ok && text != "" && personalDataPaths[path] {
+		return redacted
+	}
+	return value
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"orderService/internal/models"
+	repo "orderService/internal/repository/mocks"
+	"testing"
+	"time"
+)
+
+func newAuditService(r *repo.IAuditRepository) AuditService {
+	service := NewAuditService(r)
+	service.now = func() time.Time { return dateCreated }
+	return service
+}
+
+func TestAuditService_Record(t *testing.T) {
+	t.Run("StampsEntries", func(t *testing.T) {
+		mockRepo := new(repo.IAuditRepository)
+		mockRepo.On("Append", models.AuditEntry{OccurredAt: dateCreated, Actor: "api_key:reports", Action: models.AuditOrderSoftDeleted, OrderUid: &uid}).Return(nil)
+
+		newAuditService(mockRepo).Record(models.AuditEntry{Actor: "api_key:reports", Action: models.AuditOrderSoftDeleted, OrderUid: &uid})
+
+		mockRepo.AssertNumberOfCalls(t, "Append", 1)
+	})
+
+	t.Run("FailureIsNotReturned", func(t *testing.T) {
+		mockRepo := new(repo.IAuditRepository)
+		mockRepo.On("Append", mock.Anything).Return(fmt.Errorf("connection refused"))
+
+		assert.NotPanics(t, func() {
+			newAuditService(mockRepo).Record(models.AuditEntry{Actor: "jwt:dpo", Action: models.AuditPersonalDataErased, CustomerID: "100900"})
+		})
+	})
+}
+
+func TestAuditService_FindByOrder(t *testing.T) {
+	mockRepo := new(repo.IAuditRepository)
+	entries := []models.AuditEntry{{ID: 2, Actor: "jwt:courier", Action: models.AuditOrderStatusUpdated, OrderUid: &uid}}
+	mockRepo.On("FindByOrder", uid, 50).Return(entries, nil)
+
+	actual, err := newAuditService(mockRepo).FindByOrder(uid, 50)
+
+	assert.Nil(t, err)
+	assert.Equal(t, entries, actual)
+}
+
+func TestDiff(t *testing.T) {
+	t.Run("ChangedFieldsOnly", func(t *testing.T) {
+		before := map[string]any{"track_number": "WBILMTESTTRACK", "items": []map[string]any{{"status": 100}, {"status": 202}}}
+		after := map[string]any{"track_number": "WBILMTESTTRACK", "items": []map[string]any{{"status": 202}, {"status": 202}}}
+
+		assert.JSONEq(t, `{"items.0.status":{"old":100,"new":202}}`, string(Diff(before, after)))
+	})
+
+	t.Run("AddedAndRemovedFields", func(t *testing.T) {
+		before := map[string]any{"sm_id": 99}
+		after := map[string]any{"shardkey": "9"}
+
+		assert.JSONEq(t, `{"sm_id":{"old":99},"shardkey":{"new":"9"}}`, string(Diff(before, after)))
+	})
+
+	t.Run("RedactsPersonalData", func(t *testing.T) {
+		before := models.Order{Uid: uid, Delivery: validDelivery}
+		after := before
+		after.Delivery.Phone = "+9721111111"
+		after.Delivery.City = "Kazan"
+
+		diff := string(Diff(before, after))
+
+		assert.JSONEq(t, `{"delivery.phone":{"old":"[redacted]","new":"[redacted]"},"delivery.city":{"old":"Moscow","new":"Kazan"}}`, diff)
+	})
+
+	t.Run("NoChanges", func(t *testing.T) {
+		order := models.Order{Uid: uuid.New()}
+
+		assert.Nil(t, Diff(order, order))
+	})
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"log"
+	"orderService/configs"
+	"orderService/internal/cache"
+	"orderService/internal/events"
+	"orderService/internal/models"
+	"orderService/internal/repository"
+	"time"
+)
+
+//go:generate mockery --name=ILifecycleService --output=mocks --outpkg=mocks --case=snake --with-expecter
+type ILifecycleService interface {
+	Delete(uid uuid.UUID, actor string) error
+	ErasePersonalData(customerID string, actor string) (int, error)
+	Purge(ctx context.Context, before time.Time, batchSize int) (int, error)
+}
+
+// LifecycleService removes orders and personal data. Every change is written to the audit log
+// and the affected orders are dropped from the cache and the event history so stale personal data is not served
+type LifecycleService struct {
+	repo    repository.IOrderRepository
+	cache   cache.ILruCache
+	history events.History
+	audit   IAuditService
+	now     func() time.Time
+}
+
+func NewLifecycleService(r repository.IOrderRepository, c cache.ILruCache, h events.History, a IAuditService) LifecycleService {
+	return LifecycleService{
+		repo:    r,
+		cache:   c,
+		history: h,
+		audit:   a,
+		now:     time.Now,
+	}
+}
+
+func (s LifecycleService) Delete(uid uuid.UUID, actor string) error {
+	if err := s.repo.SoftDelete(uid); err != nil {
+		return err
+	}
+
+	s.cache.Remove(uid.String())
+	s.history.Forget([]uuid.UUID{uid})
+	diff := Diff(map[string]any{"deleted_at": nil}, map[string]any{"deleted_at": s.now()})
+	s.audit.Record(models.AuditEntry{Actor: actor, Action: models.AuditOrderSoftDeleted, OrderUid: &uid, Diff: diff})
+	return nil
+}
+
+// ErasePersonalData anonymizes the deliveries of all customer orders and returns the number of orders
+func (s LifecycleService) ErasePersonalData(customerID string, actor string) (int, error) {
+	uids, err := s.repo.AnonymizeCustomer(customerID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, uid := range uids {
+		s.cache.Remove(uid.String())
+	}
+	s.history.Forget(uids)
+
+	details, _ := json.Marshal(map[string]int{"orders": len(uids)})
+	s.audit.Record(models.AuditEntry{Actor: actor, Action: models.AuditPersonalDataErased, CustomerID: customerID, Details: details})
+	return len(uids), nil
+}
+
+// Purge hard deletes the orders created before the time in batches until none are left or the context is done
+func (s LifecycleService) Purge(ctx context.Context, before time.Time, batchSize int) (int, error) {
+	purged := 0
+	for ctx.Err() == nil {
+		uids, err := s.repo.PurgeCreatedBefore(before, batchSize)
+		if err != nil {
+			return purged, err
+		}
+
+		entries := make([]models.AuditEntry, 0, len(uids))
+		for _, uid := range uids {
+			s.cache.Remove(uid.String())
+			entries = append(entries, models.AuditEntry{Actor: models.AuditActorRetentionJob, Action: models.AuditOrderPurged, OrderUid: &uid})
+		}
+		if len(uids) > 0 {
+			s.history.Forget(uids)
+		}
+		s.audit.Record(entries...)
+
+		purged += len(uids)
+		if len(uids) < batchSize {
+			break
+		}
+	}
+	return purged, ctx.Err()
+}
+
+// RunRetention purges the expired orders right away and then every interval until the context is done
+func (s LifecycleService) RunRetention(ctx context.Context, cnf configs.Retention) {
+	ticker := time.NewTicker(time.Duration(cnf.Interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		before := s.now().AddDate(0, 0, -cnf.Days)
+		purged, err := s.Purge(ctx, before, cnf.BatchSize)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Error purging orders created before %s: %s\n", before.Format(time.RFC3339), err.Error())
+		}
+		if purged > 0 {
+			log.Printf("Retention purged %d orders created before %s\n", purged, before.Format(time.RFC3339))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+	"orderService/internal/cache/mocks"
+	"orderService/internal/events"
+	history "orderService/internal/events/mocks"
+	"orderService/internal/models"
+	repo "orderService/internal/repository/mocks"
+	audit "orderService/internal/service/mocks"
+	"testing"
+	"time"
+)
+
+func newLifecycleService(orders *repo.IOrderRepository, cache *mocks.ILruCache, h events.History, auditService *audit.IAuditService) LifecycleService {
+	service := NewLifecycleService(orders, cache, h, auditService)
+	service.now = func() time.Time { return dateCreated }
+	return service
+}
+
+func TestLifecycleService_Delete(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(repo.IOrderRepository)
+		mockCache := new(mocks.ILruCache)
+		mockHistory := new(history.History)
+		mockAudit := new(audit.IAuditService)
+
+		mockRepo.On("SoftDelete", uid).Return(nil)
+		mockCache.On("Remove", uid.String()).Return(true)
+		mockHistory.On("Forget", []uuid.UUID{uid}).Return()
+		mockAudit.On("Record", mock.Anything).Return()
+
+		err := newLifecycleService(mockRepo, mockCache, mockHistory, mockAudit).Delete(uid, "api_key:reports")
+
+		assert.Nil(t, err)
+		mockCache.AssertCalled(t, "Remove", uid.String())
+		mockHistory.AssertCalled(t, "Forget", []uuid.UUID{uid})
+		entry := mockAudit.Calls[0].Arguments.Get(0).(models.AuditEntry)
+		assert.Equal(t, "api_key:reports", entry.Actor)
+		assert.Equal(t, models.AuditOrderSoftDeleted, entry.Action)
+		assert.Equal(t, uid, *entry.OrderUid)
+		assert.JSONEq(t, `{"deleted_at":{"new":"2021-11-26T06:22:19Z"}}`, string(entry.Diff))
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockRepo := new(repo.IOrderRepository)
+		mockCache := new(mocks.ILruCache)
+		mockHistory := new(history.History)
+		mockAudit := new(audit.IAuditService)
+
+		mockRepo.On("SoftDelete", uid).Return(gorm.ErrRecordNotFound)
+
+		err := newLifecycleService(mockRepo, mockCache, mockHistory, mockAudit).Delete(uid, "anonymous")
+
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+		mockCache.AssertNotCalled(t, "Remove", mock.Anything)
+		mockHistory.AssertNotCalled(t, "Forget", mock.Anything)
+		mockAudit.AssertNotCalled(t, "Record", mock.Anything)
+	})
+}
+
+func TestLifecycleService_ErasePersonalData(t *testing.T) {
+	t.Run("InvalidatesCacheAndRecords", func(t *testing.T) {
+		second := uuid.MustParse("2e9ad4fb-2615-46f9-9458-20b59253086b")
+		mockRepo := new(repo.IOrderRepository)
+		mockCache := new(mocks.ILruCache)
+		mockAudit := new(audit.IAuditService)
+
+		mockRepo.On("AnonymizeCustomer", "100900").Return([]uuid.UUID{uid, second}, nil)
+		mockCache.On("Remove", mock.Anything).Return(true)
+		mockAudit.On("Record", mock.Anything).Return()
+		bus := events.NewBus(10)
+		bus.Publish(events.OrderCreated, models.OrderView{Uid: uid, CustomerID: "100900"})
+		bus.Publish(events.OrderCreated, models.OrderView{Uid: uuid.New(), CustomerID: "other"})
+		service := newLifecycleService(mockRepo, mockCache, bus, mockAudit)
+
+		orders, err := service.ErasePersonalData("100900", "jwt:dpo")
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2, orders)
+		_, retained := bus.SubscribeSince(0, 10, events.Filter{})
+		assert.Len(t, retained, 1, "events of erased orders are not replayed")
+		assert.Equal(t, "other", retained[0].Order.CustomerID)
+		mockCache.AssertCalled(t, "Remove", uid.String())
+		mockCache.AssertCalled(t, "Remove", second.String())
+		entry := mockAudit.Calls[0].Arguments.Get(0).(models.AuditEntry)
+		assert.Equal(t, models.AuditPersonalDataErased, entry.Action)
+		assert.Equal(t, "100900", entry.CustomerID)
+		assert.Equal(t, "jwt:dpo", entry.Actor)
+		assert.JSONEq(t, `{"orders":2}`, string(entry.Details))
+	})
+}
+
+func TestLifecycleService_Purge(t *testing.T) {
+	t.Run("DeletesInBatchesUntilDone", func(t *testing.T) {
+		before := dateCreated.AddDate(0, 0, -30)
+		second := uuid.MustParse("2e9ad4fb-2615-46f9-9458-20b59253086b")
+		third := uuid.MustParse("3e9ad4fb-2615-46f9-9458-20b59253086b")
+		mockRepo := new(repo.IOrderRepository)
+		mockCache := new(mocks.ILruCache)
+		mockHistory := new(history.History)
+		mockAudit := new(audit.IAuditService)
+
+		mockRepo.On("PurgeCreatedBefore", before, 2).Return([]uuid.UUID{uid, second}, nil).Once()
+		mockRepo.On("PurgeCreatedBefore", before, 2).Return([]uuid.UUID{third}, nil).Once()
+		mockCache.On("Remove", mock.Anything).Return(true)
+		mockHistory.On("Forget", mock.Anything).Return()
+		mockAudit.On("Record", mock.Anything, mock.Anything).Return()
+		mockAudit.On("Record", mock.Anything).Return()
+
+		purged, err := newLifecycleService(mockRepo, mockCache, mockHistory, mockAudit).Purge(context.Background(), before, 2)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 3, purged)
+		mockRepo.AssertNumberOfCalls(t, "PurgeCreatedBefore", 2)
+		mockCache.AssertNumberOfCalls(t, "Remove", 3)
+		mockHistory.AssertCalled(t, "Forget", []uuid.UUID{uid, second})
+		mockHistory.AssertCalled(t, "Forget", []uuid.UUID{third})
+	})
+
+	t.Run("StopsOnError", func(t *testing.T) {
+		mockRepo := new(repo.IOrderRepository)
+		mockCache := new(mocks.ILruCache)
+		mockHistory := new(history.History)
+		mockAudit := new(audit.IAuditService)
+
+		mockRepo.On("PurgeCreatedBefore", mock.Anything, 10).Return(nil, fmt.Errorf("connection refused"))
+
+		purged, err := newLifecycleService(mockRepo, mockCache, mockHistory, mockAudit).Purge(context.Background(), dateCreated, 10)
+
+		assert.EqualError(t, err, "connection refused")
+		assert.Equal(t, 0, purged)
+		mockHistory.AssertNotCalled(t, "Forget", mock.Anything)
+	})
+}
@@ -0,0 +1,143 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "orderService/internal/models"
+
+	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
+)
+
+// IAuditService is an autogenerated mock type for the IAuditService type
+type IAuditService struct {
+	mock.Mock
+}
+
+type IAuditService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *IAuditService) EXPECT() *IAuditService_Expecter {
+	return &IAuditService_Expecter{mock: &_m.Mock}
+}
+
+// FindByOrder provides a mock function with given fields: uid, limit
+func (_m *IAuditService) FindByOrder(uid uuid.UUID, limit int) ([]models.AuditEntry, error) {
+	ret := _m.Called(uid, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByOrder")
+	}
+
+	var r0 []models.AuditEntry
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int) ([]models.AuditEntry, error)); ok {
+		return rf(uid, limit)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int) []models.AuditEntry); ok {
+		r0 = rf(uid, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.AuditEntry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, int) error); ok {
+		r1 = rf(uid, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IAuditService_FindByOrder_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindByOrder'
+type IAuditService_FindByOrder_Call struct {
+	*mock.Call
+}
+
+// FindByOrder is a helper method to define mock.On call
+//   - uid uuid.UUID
+//   - limit int
+func (_e *IAuditService_Expecter) FindByOrder(uid interface{}, limit interface{}) *IAuditService_FindByOrder_Call {
+	return &IAuditService_FindByOrder_Call{Call: _e.mock.On("FindByOrder", uid, limit)}
+}
+
+func (_c *IAuditService_FindByOrder_Call) Run(run func(uid uuid.UUID, limit int)) *IAuditService_FindByOrder_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *IAuditService_FindByOrder_Call) Return(_a0 []models.AuditEntry, _a1 error) *IAuditService_FindByOrder_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IAuditService_FindByOrder_Call) RunAndReturn(run func(uuid.UUID, int) ([]models.AuditEntry, error)) *IAuditService_FindByOrder_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Record provides a mock function with given fields: entries
+func (_m *IAuditService) Record(entries ...models.AuditEntry) {
+	_va := make([]interface{}, len(entries))
+	for _i := range entries {
+		_va[_i] = entries[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _va...)
+	_m.Called(_ca...)
+}
+
+// IAuditService_Record_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Record'
+type IAuditService_Record_Call struct {
+	*mock.Call
+}
+
+// Record is a helper method to define mock.On call
+//   - entries ...models.AuditEntry
+func (_e *IAuditService_Expecter) Record(entries ...interface{}) *IAuditService_Record_Call {
+	return &IAuditService_Record_Call{Call: _e.mock.On("Record",
+		append([]interface{}{}, entries...)...)}
+}
+
+func (_c *IAuditService_Record_Call) Run(run func(entries ...models.AuditEntry)) *IAuditService_Record_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]models.AuditEntry, len(args)-0)
+		for i, a := range args[0:] {
+			if a != nil {
+				variadicArgs[i] = a.(models.AuditEntry)
+			}
+		}
+		run(variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *IAuditService_Record_Call) Return() *IAuditService_Record_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *IAuditService_Record_Call) RunAndReturn(run func(...models.AuditEntry)) *IAuditService_Record_Call {
+	_c.Run(run)
+	return _c
+}
+
+// NewIAuditService creates a new instance of IAuditService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIAuditService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IAuditService {
+	mock := &IAuditService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
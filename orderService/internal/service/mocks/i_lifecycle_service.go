@@ -0,0 +1,202 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+
+	uuid "github.com/google/uuid"
+)
+
+// ILifecycleService is an autogenerated mock type for the ILifecycleService type
+type ILifecycleService struct {
+	mock.Mock
+}
+
+type ILifecycleService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *ILifecycleService) EXPECT() *ILifecycleService_Expecter {
+	return &ILifecycleService_Expecter{mock: &_m.Mock}
+}
+
+// Delete provides a mock function with given fields: uid, actor
+func (_m *ILifecycleService) Delete(uid uuid.UUID, actor string) error {
+	ret := _m.Called(uid, actor)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, string) error); ok {
+		r0 = rf(uid, actor)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ILifecycleService_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type ILifecycleService_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - uid uuid.UUID
+//   - actor string
+func (_e *ILifecycleService_Expecter) Delete(uid interface{}, actor interface{}) *ILifecycleService_Delete_Call {
+	return &ILifecycleService_Delete_Call{Call: _e.mock.On("Delete", uid, actor)}
+}
+
+func (_c *ILifecycleService_Delete_Call) Run(run func(uid uuid.UUID, actor string)) *ILifecycleService_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *ILifecycleService_Delete_Call) Return(_a0 error) *ILifecycleService_Delete_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *ILifecycleService_Delete_Call) RunAndReturn(run func(uuid.UUID, string) error) *ILifecycleService_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ErasePersonalData provides a mock function with given fields: customerID, actor
+func (_m *ILifecycleService) ErasePersonalData(customerID string, actor string) (int, error) {
+	ret := _m.Called(customerID, actor)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ErasePersonalData")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (int, error)); ok {
+		return rf(customerID, actor)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) int); ok {
+		r0 = rf(customerID, actor)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(customerID, actor)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ILifecycleService_ErasePersonalData_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ErasePersonalData'
+type ILifecycleService_ErasePersonalData_Call struct {
+	*mock.Call
+}
+
+// ErasePersonalData is a helper method to define mock.On call
+//   - customerID string
+//   - actor string
+func (_e *ILifecycleService_Expecter) ErasePersonalData(customerID interface{}, actor interface{}) *ILifecycleService_ErasePersonalData_Call {
+	return &ILifecycleService_ErasePersonalData_Call{Call: _e.mock.On("ErasePersonalData", customerID, actor)}
+}
+
+func (_c *ILifecycleService_ErasePersonalData_Call) Run(run func(customerID string, actor string)) *ILifecycleService_ErasePersonalData_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *ILifecycleService_ErasePersonalData_Call) Return(_a0 int, _a1 error) *ILifecycleService_ErasePersonalData_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *ILifecycleService_ErasePersonalData_Call) RunAndReturn(run func(string, string) (int, error)) *ILifecycleService_ErasePersonalData_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Purge provides a mock function with given fields: ctx, before, batchSize
+func (_m *ILifecycleService) Purge(ctx context.Context, before time.Time, batchSize int) (int, error) {
+	ret := _m.Called(ctx, before, batchSize)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Purge")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) (int, error)); ok {
+		return rf(ctx, before, batchSize)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) int); ok {
+		r0 = rf(ctx, before, batchSize)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int) error); ok {
+		r1 = rf(ctx, before, batchSize)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ILifecycleService_Purge_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Purge'
+type ILifecycleService_Purge_Call struct {
+	*mock.Call
+}
+
+// Purge is a helper method to define mock.On call
+//   - ctx context.Context
+//   - before time.Time
+//   - batchSize int
+func (_e *ILifecycleService_Expecter) Purge(ctx interface{}, before interface{}, batchSize interface{}) *ILifecycleService_Purge_Call {
+	return &ILifecycleService_Purge_Call{Call: _e.mock.On("Purge", ctx, before, batchSize)}
+}
+
+func (_c *ILifecycleService_Purge_Call) Run(run func(ctx context.Context, before time.Time, batchSize int)) *ILifecycleService_Purge_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(time.Time), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *ILifecycleService_Purge_Call) Return(_a0 int, _a1 error) *ILifecycleService_Purge_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *ILifecycleService_Purge_Call) RunAndReturn(run func(context.Context, time.Time, int) (int, error)) *ILifecycleService_Purge_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewILifecycleService creates a new instance of ILifecycleService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewILifecycleService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ILifecycleService {
+	mock := &ILifecycleService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
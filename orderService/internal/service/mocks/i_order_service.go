@@ -3,6 +3,7 @@
 package mocks
 
 import (
+	context "context"
 	models "orderService/internal/models"
 
 	mock "github.com/stretchr/testify/mock"
@@ -23,17 +24,76 @@ func (_m *IOrderService) EXPECT() *IOrderService_Expecter {
 	return &IOrderService_Expecter{mock: &_m.Mock}
 }
 
-// Create provides a mock function with given fields: order
-func (_m *IOrderService) Create(order models.Order) error {
-	ret := _m.Called(order)
+// AddItem provides a mock function with given fields: uid, version, item, actor
+func (_m *IOrderService) AddItem(uid uuid.UUID, version int, item models.Item, actor string) (models.OrderView, error) {
+	ret := _m.Called(uid, version, item, actor)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddItem")
+	}
+
+	var r0 models.OrderView
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int, models.Item, string) (models.OrderView, error)); ok {
+		return rf(uid, version, item, actor)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int, models.Item, string) models.OrderView); ok {
+		r0 = rf(uid, version, item, actor)
+	} else {
+		r0 = ret.Get(0).(models.OrderView)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, int, models.Item, string) error); ok {
+		r1 = rf(uid, version, item, actor)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IOrderService_AddItem_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddItem'
+type IOrderService_AddItem_Call struct {
+	*mock.Call
+}
+
+// AddItem is a helper method to define mock.On call
+//   - uid uuid.UUID
+//   - version int
+//   - item models.Item
+//   - actor string
+func (_e *IOrderService_Expecter) AddItem(uid interface{}, version interface{}, item interface{}, actor interface{}) *IOrderService_AddItem_Call {
+	return &IOrderService_AddItem_Call{Call: _e.mock.On("AddItem", uid, version, item, actor)}
+}
+
+func (_c *IOrderService_AddItem_Call) Run(run func(uid uuid.UUID, version int, item models.Item, actor string)) *IOrderService_AddItem_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID), args[1].(int), args[2].(models.Item), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *IOrderService_AddItem_Call) Return(_a0 models.OrderView, _a1 error) *IOrderService_AddItem_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IOrderService_AddItem_Call) RunAndReturn(run func(uuid.UUID, int, models.Item, string) (models.OrderView, error)) *IOrderService_AddItem_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Create provides a mock function with given fields: order, actor
+func (_m *IOrderService) Create(order models.Order, actor string) error {
+	ret := _m.Called(order, actor)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Create")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(models.Order) error); ok {
-		r0 = rf(order)
+	if rf, ok := ret.Get(0).(func(models.Order, string) error); ok {
+		r0 = rf(order, actor)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -48,13 +108,14 @@ type IOrderService_Create_Call struct {
 
 // Create is a helper method to define mock.On call
 //   - order models.Order
-func (_e *IOrderService_Expecter) Create(order interface{}) *IOrderService_Create_Call {
-	return &IOrderService_Create_Call{Call: _e.mock.On("Create", order)}
+//   - actor string
+func (_e *IOrderService_Expecter) Create(order interface{}, actor interface{}) *IOrderService_Create_Call {
+	return &IOrderService_Create_Call{Call: _e.mock.On("Create", order, actor)}
 }
 
-func (_c *IOrderService_Create_Call) Run(run func(order models.Order)) *IOrderService_Create_Call {
+func (_c *IOrderService_Create_Call) Run(run func(order models.Order, actor string)) *IOrderService_Create_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(models.Order))
+		run(args[0].(models.Order), args[1].(string))
 	})
 	return _c
 }
@@ -64,7 +125,56 @@ func (_c *IOrderService_Create_Call) Return(_a0 error) *IOrderService_Create_Cal
 	return _c
 }
 
-func (_c *IOrderService_Create_Call) RunAndReturn(run func(models.Order) error) *IOrderService_Create_Call {
+func (_c *IOrderService_Create_Call) RunAndReturn(run func(models.Order, string) error) *IOrderService_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateBatch provides a mock function with given fields: orders, actors
+func (_m *IOrderService) CreateBatch(orders []models.Order, actors []string) []error {
+	ret := _m.Called(orders, actors)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateBatch")
+	}
+
+	var r0 []error
+	if rf, ok := ret.Get(0).(func([]models.Order, []string) []error); ok {
+		r0 = rf(orders, actors)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]error)
+		}
+	}
+
+	return r0
+}
+
+// IOrderService_CreateBatch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateBatch'
+type IOrderService_CreateBatch_Call struct {
+	*mock.Call
+}
+
+// CreateBatch is a helper method to define mock.On call
+//   - orders []models.Order
+//   - actors []string
+func (_e *IOrderService_Expecter) CreateBatch(orders interface{}, actors interface{}) *IOrderService_CreateBatch_Call {
+	return &IOrderService_CreateBatch_Call{Call: _e.mock.On("CreateBatch", orders, actors)}
+}
+
+func (_c *IOrderService_CreateBatch_Call) Run(run func(orders []models.Order, actors []string)) *IOrderService_CreateBatch_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].([]models.Order), args[1].([]string))
+	})
+	return _c
+}
+
+func (_c *IOrderService_CreateBatch_Call) Return(_a0 []error) *IOrderService_CreateBatch_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *IOrderService_CreateBatch_Call) RunAndReturn(run func([]models.Order, []string) []error) *IOrderService_CreateBatch_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -125,17 +235,198 @@ func (_c *IOrderService_GetById_Call) RunAndReturn(run func(uuid.UUID) (models.O
 	return _c
 }
 
-// HandleMessage provides a mock function with given fields: message
-func (_m *IOrderService) HandleMessage(message []byte) error {
-	ret := _m.Called(message)
+// GetByIds provides a mock function with given fields: uids
+func (_m *IOrderService) GetByIds(uids []uuid.UUID) ([]models.OrderView, []uuid.UUID, error) {
+	ret := _m.Called(uids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByIds")
+	}
+
+	var r0 []models.OrderView
+	var r1 []uuid.UUID
+	var r2 error
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) ([]models.OrderView, []uuid.UUID, error)); ok {
+		return rf(uids)
+	}
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) []models.OrderView); ok {
+		r0 = rf(uids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.OrderView)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]uuid.UUID) []uuid.UUID); ok {
+		r1 = rf(uids)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]uuid.UUID)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func([]uuid.UUID) error); ok {
+		r2 = rf(uids)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// IOrderService_GetByIds_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByIds'
+type IOrderService_GetByIds_Call struct {
+	*mock.Call
+}
+
+// GetByIds is a helper method to define mock.On call
+//   - uids []uuid.UUID
+func (_e *IOrderService_Expecter) GetByIds(uids interface{}) *IOrderService_GetByIds_Call {
+	return &IOrderService_GetByIds_Call{Call: _e.mock.On("GetByIds", uids)}
+}
+
+func (_c *IOrderService_GetByIds_Call) Run(run func(uids []uuid.UUID)) *IOrderService_GetByIds_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].([]uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *IOrderService_GetByIds_Call) Return(_a0 []models.OrderView, _a1 []uuid.UUID, _a2 error) *IOrderService_GetByIds_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *IOrderService_GetByIds_Call) RunAndReturn(run func([]uuid.UUID) ([]models.OrderView, []uuid.UUID, error)) *IOrderService_GetByIds_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetByTrackNumber provides a mock function with given fields: trackNumber
+func (_m *IOrderService) GetByTrackNumber(trackNumber string) (models.Order, error) {
+	ret := _m.Called(trackNumber)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByTrackNumber")
+	}
+
+	var r0 models.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (models.Order, error)); ok {
+		return rf(trackNumber)
+	}
+	if rf, ok := ret.Get(0).(func(string) models.Order); ok {
+		r0 = rf(trackNumber)
+	} else {
+		r0 = ret.Get(0).(models.Order)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(trackNumber)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IOrderService_GetByTrackNumber_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetByTrackNumber'
+type IOrderService_GetByTrackNumber_Call struct {
+	*mock.Call
+}
+
+// GetByTrackNumber is a helper method to define mock.On call
+//   - trackNumber string
+func (_e *IOrderService_Expecter) GetByTrackNumber(trackNumber interface{}) *IOrderService_GetByTrackNumber_Call {
+	return &IOrderService_GetByTrackNumber_Call{Call: _e.mock.On("GetByTrackNumber", trackNumber)}
+}
+
+func (_c *IOrderService_GetByTrackNumber_Call) Run(run func(trackNumber string)) *IOrderService_GetByTrackNumber_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *IOrderService_GetByTrackNumber_Call) Return(_a0 models.Order, _a1 error) *IOrderService_GetByTrackNumber_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IOrderService_GetByTrackNumber_Call) RunAndReturn(run func(string) (models.Order, error)) *IOrderService_GetByTrackNumber_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetItems provides a mock function with given fields: uids
+func (_m *IOrderService) GetItems(uids []uuid.UUID) (map[uuid.UUID][]models.Item, error) {
+	ret := _m.Called(uids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetItems")
+	}
+
+	var r0 map[uuid.UUID][]models.Item
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) (map[uuid.UUID][]models.Item, error)); ok {
+		return rf(uids)
+	}
+	if rf, ok := ret.Get(0).(func([]uuid.UUID) map[uuid.UUID][]models.Item); ok {
+		r0 = rf(uids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[uuid.UUID][]models.Item)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]uuid.UUID) error); ok {
+		r1 = rf(uids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IOrderService_GetItems_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetItems'
+type IOrderService_GetItems_Call struct {
+	*mock.Call
+}
+
+// GetItems is a helper method to define mock.On call
+//   - uids []uuid.UUID
+func (_e *IOrderService_Expecter) GetItems(uids interface{}) *IOrderService_GetItems_Call {
+	return &IOrderService_GetItems_Call{Call: _e.mock.On("GetItems", uids)}
+}
+
+func (_c *IOrderService_GetItems_Call) Run(run func(uids []uuid.UUID)) *IOrderService_GetItems_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].([]uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *IOrderService_GetItems_Call) Return(_a0 map[uuid.UUID][]models.Item, _a1 error) *IOrderService_GetItems_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IOrderService_GetItems_Call) RunAndReturn(run func([]uuid.UUID) (map[uuid.UUID][]models.Item, error)) *IOrderService_GetItems_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// HandleMessage provides a mock function with given fields: message, actor
+func (_m *IOrderService) HandleMessage(message []byte, actor string) error {
+	ret := _m.Called(message, actor)
 
 	if len(ret) == 0 {
 		panic("no return value specified for HandleMessage")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func([]byte) error); ok {
-		r0 = rf(message)
+	if rf, ok := ret.Get(0).(func([]byte, string) error); ok {
+		r0 = rf(message, actor)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -150,13 +441,14 @@ type IOrderService_HandleMessage_Call struct {
 
 // HandleMessage is a helper method to define mock.On call
 //   - message []byte
-func (_e *IOrderService_Expecter) HandleMessage(message interface{}) *IOrderService_HandleMessage_Call {
-	return &IOrderService_HandleMessage_Call{Call: _e.mock.On("HandleMessage", message)}
+//   - actor string
+func (_e *IOrderService_Expecter) HandleMessage(message interface{}, actor interface{}) *IOrderService_HandleMessage_Call {
+	return &IOrderService_HandleMessage_Call{Call: _e.mock.On("HandleMessage", message, actor)}
 }
 
-func (_c *IOrderService_HandleMessage_Call) Run(run func(message []byte)) *IOrderService_HandleMessage_Call {
+func (_c *IOrderService_HandleMessage_Call) Run(run func(message []byte, actor string)) *IOrderService_HandleMessage_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].([]byte))
+		run(args[0].([]byte), args[1].(string))
 	})
 	return _c
 }
@@ -166,7 +458,291 @@ func (_c *IOrderService_HandleMessage_Call) Return(_a0 error) *IOrderService_Han
 	return _c
 }
 
-func (_c *IOrderService_HandleMessage_Call) RunAndReturn(run func([]byte) error) *IOrderService_HandleMessage_Call {
+func (_c *IOrderService_HandleMessage_Call) RunAndReturn(run func([]byte, string) error) *IOrderService_HandleMessage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListByCustomer provides a mock function with given fields: customerID, after, limit
+func (_m *IOrderService) ListByCustomer(customerID string, after *models.OrderCursor, limit int) ([]models.Order, error) {
+	ret := _m.Called(customerID, after, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListByCustomer")
+	}
+
+	var r0 []models.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, *models.OrderCursor, int) ([]models.Order, error)); ok {
+		return rf(customerID, after, limit)
+	}
+	if rf, ok := ret.Get(0).(func(string, *models.OrderCursor, int) []models.Order); ok {
+		r0 = rf(customerID, after, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, *models.OrderCursor, int) error); ok {
+		r1 = rf(customerID, after, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IOrderService_ListByCustomer_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListByCustomer'
+type IOrderService_ListByCustomer_Call struct {
+	*mock.Call
+}
+
+// ListByCustomer is a helper method to define mock.On call
+//   - customerID string
+//   - after *models.OrderCursor
+//   - limit int
+func (_e *IOrderService_Expecter) ListByCustomer(customerID interface{}, after interface{}, limit interface{}) *IOrderService_ListByCustomer_Call {
+	return &IOrderService_ListByCustomer_Call{Call: _e.mock.On("ListByCustomer", customerID, after, limit)}
+}
+
+func (_c *IOrderService_ListByCustomer_Call) Run(run func(customerID string, after *models.OrderCursor, limit int)) *IOrderService_ListByCustomer_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(*models.OrderCursor), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *IOrderService_ListByCustomer_Call) Return(_a0 []models.Order, _a1 error) *IOrderService_ListByCustomer_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IOrderService_ListByCustomer_Call) RunAndReturn(run func(string, *models.OrderCursor, int) ([]models.Order, error)) *IOrderService_ListByCustomer_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RemoveItem provides a mock function with given fields: uid, version, rid, actor
+func (_m *IOrderService) RemoveItem(uid uuid.UUID, version int, rid string, actor string) (models.OrderView, error) {
+	ret := _m.Called(uid, version, rid, actor)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveItem")
+	}
+
+	var r0 models.OrderView
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int, string, string) (models.OrderView, error)); ok {
+		return rf(uid, version, rid, actor)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int, string, string) models.OrderView); ok {
+		r0 = rf(uid, version, rid, actor)
+	} else {
+		r0 = ret.Get(0).(models.OrderView)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, int, string, string) error); ok {
+		r1 = rf(uid, version, rid, actor)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IOrderService_RemoveItem_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RemoveItem'
+type IOrderService_RemoveItem_Call struct {
+	*mock.Call
+}
+
+// RemoveItem is a helper method to define mock.On call
+//   - uid uuid.UUID
+//   - version int
+//   - rid string
+//   - actor string
+func (_e *IOrderService_Expecter) RemoveItem(uid interface{}, version interface{}, rid interface{}, actor interface{}) *IOrderService_RemoveItem_Call {
+	return &IOrderService_RemoveItem_Call{Call: _e.mock.On("RemoveItem", uid, version, rid, actor)}
+}
+
+func (_c *IOrderService_RemoveItem_Call) Run(run func(uid uuid.UUID, version int, rid string, actor string)) *IOrderService_RemoveItem_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID), args[1].(int), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *IOrderService_RemoveItem_Call) Return(_a0 models.OrderView, _a1 error) *IOrderService_RemoveItem_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IOrderService_RemoveItem_Call) RunAndReturn(run func(uuid.UUID, int, string, string) (models.OrderView, error)) *IOrderService_RemoveItem_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// StreamOrders provides a mock function with given fields: ctx, filter, fn
+func (_m *IOrderService) StreamOrders(ctx context.Context, filter models.OrderFilter, fn func([]models.Order) error) error {
+	ret := _m.Called(ctx, filter, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StreamOrders")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.OrderFilter, func([]models.Order) error) error); ok {
+		r0 = rf(ctx, filter, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// IOrderService_StreamOrders_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StreamOrders'
+type IOrderService_StreamOrders_Call struct {
+	*mock.Call
+}
+
+// StreamOrders is a helper method to define mock.On call
+//   - ctx context.Context
+//   - filter models.OrderFilter
+//   - fn func([]models.Order) error
+func (_e *IOrderService_Expecter) StreamOrders(ctx interface{}, filter interface{}, fn interface{}) *IOrderService_StreamOrders_Call {
+	return &IOrderService_StreamOrders_Call{Call: _e.mock.On("StreamOrders", ctx, filter, fn)}
+}
+
+func (_c *IOrderService_StreamOrders_Call) Run(run func(ctx context.Context, filter models.OrderFilter, fn func([]models.Order) error)) *IOrderService_StreamOrders_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.OrderFilter), args[2].(func([]models.Order) error))
+	})
+	return _c
+}
+
+func (_c *IOrderService_StreamOrders_Call) Return(_a0 error) *IOrderService_StreamOrders_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *IOrderService_StreamOrders_Call) RunAndReturn(run func(context.Context, models.OrderFilter, func([]models.Order) error) error) *IOrderService_StreamOrders_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateDelivery provides a mock function with given fields: uid, version, patch, actor
+func (_m *IOrderService) UpdateDelivery(uid uuid.UUID, version int, patch models.DeliveryPatch, actor string) (models.OrderView, error) {
+	ret := _m.Called(uid, version, patch, actor)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateDelivery")
+	}
+
+	var r0 models.OrderView
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int, models.DeliveryPatch, string) (models.OrderView, error)); ok {
+		return rf(uid, version, patch, actor)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int, models.DeliveryPatch, string) models.OrderView); ok {
+		r0 = rf(uid, version, patch, actor)
+	} else {
+		r0 = ret.Get(0).(models.OrderView)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, int, models.DeliveryPatch, string) error); ok {
+		r1 = rf(uid, version, patch, actor)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IOrderService_UpdateDelivery_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateDelivery'
+type IOrderService_UpdateDelivery_Call struct {
+	*mock.Call
+}
+
+// UpdateDelivery is a helper method to define mock.On call
+//   - uid uuid.UUID
+//   - version int
+//   - patch models.DeliveryPatch
+//   - actor string
+func (_e *IOrderService_Expecter) UpdateDelivery(uid interface{}, version interface{}, patch interface{}, actor interface{}) *IOrderService_UpdateDelivery_Call {
+	return &IOrderService_UpdateDelivery_Call{Call: _e.mock.On("UpdateDelivery", uid, version, patch, actor)}
+}
+
+func (_c *IOrderService_UpdateDelivery_Call) Run(run func(uid uuid.UUID, version int, patch models.DeliveryPatch, actor string)) *IOrderService_UpdateDelivery_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID), args[1].(int), args[2].(models.DeliveryPatch), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *IOrderService_UpdateDelivery_Call) Return(_a0 models.OrderView, _a1 error) *IOrderService_UpdateDelivery_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IOrderService_UpdateDelivery_Call) RunAndReturn(run func(uuid.UUID, int, models.DeliveryPatch, string) (models.OrderView, error)) *IOrderService_UpdateDelivery_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateStatus provides a mock function with given fields: uid, status, actor
+func (_m *IOrderService) UpdateStatus(uid uuid.UUID, status int, actor string) (models.OrderView, error) {
+	ret := _m.Called(uid, status, actor)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateStatus")
+	}
+
+	var r0 models.OrderView
+	var r1 error
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int, string) (models.OrderView, error)); ok {
+		return rf(uid, status, actor)
+	}
+	if rf, ok := ret.Get(0).(func(uuid.UUID, int, string) models.OrderView); ok {
+		r0 = rf(uid, status, actor)
+	} else {
+		r0 = ret.Get(0).(models.OrderView)
+	}
+
+	if rf, ok := ret.Get(1).(func(uuid.UUID, int, string) error); ok {
+		r1 = rf(uid, status, actor)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IOrderService_UpdateStatus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateStatus'
+type IOrderService_UpdateStatus_Call struct {
+	*mock.Call
+}
+
+// UpdateStatus is a helper method to define mock.On call
+//   - uid uuid.UUID
+//   - status int
+//   - actor string
+func (_e *IOrderService_Expecter) UpdateStatus(uid interface{}, status interface{}, actor interface{}) *IOrderService_UpdateStatus_Call {
+	return &IOrderService_UpdateStatus_Call{Call: _e.mock.On("UpdateStatus", uid, status, actor)}
+}
+
+func (_c *IOrderService_UpdateStatus_Call) Run(run func(uid uuid.UUID, status int, actor string)) *IOrderService_UpdateStatus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(uuid.UUID), args[1].(int), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *IOrderService_UpdateStatus_Call) Return(_a0 models.OrderView, _a1 error) *IOrderService_UpdateStatus_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IOrderService_UpdateStatus_Call) RunAndReturn(run func(uuid.UUID, int, string) (models.OrderView, error)) *IOrderService_UpdateStatus_Call {
 	_c.Call.Return(run)
 	return _c
 }
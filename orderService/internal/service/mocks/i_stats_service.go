@@ -0,0 +1,445 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	models "orderService/internal/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// IStatsService is an autogenerated mock type for the IStatsService type
+type IStatsService struct {
+	mock.Mock
+}
+
+type IStatsService_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *IStatsService) EXPECT() *IStatsService_Expecter {
+	return &IStatsService_Expecter{mock: &_m.Mock}
+}
+
+// Basket provides a mock function with given fields: filter
+func (_m *IStatsService) Basket(filter models.OrderFilter) ([]models.BasketStats, error) {
+	ret := _m.Called(filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Basket")
+	}
+
+	var r0 []models.BasketStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.OrderFilter) ([]models.BasketStats, error)); ok {
+		return rf(filter)
+	}
+	if rf, ok := ret.Get(0).(func(models.OrderFilter) []models.BasketStats); ok {
+		r0 = rf(filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.BasketStats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.OrderFilter) error); ok {
+		r1 = rf(filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IStatsService_Basket_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Basket'
+type IStatsService_Basket_Call struct {
+	*mock.Call
+}
+
+// Basket is a helper method to define mock.On call
+//   - filter models.OrderFilter
+func (_e *IStatsService_Expecter) Basket(filter interface{}) *IStatsService_Basket_Call {
+	return &IStatsService_Basket_Call{Call: _e.mock.On("Basket", filter)}
+}
+
+func (_c *IStatsService_Basket_Call) Run(run func(filter models.OrderFilter)) *IStatsService_Basket_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(models.OrderFilter))
+	})
+	return _c
+}
+
+func (_c *IStatsService_Basket_Call) Return(_a0 []models.BasketStats, _a1 error) *IStatsService_Basket_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IStatsService_Basket_Call) RunAndReturn(run func(models.OrderFilter) ([]models.BasketStats, error)) *IStatsService_Basket_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// OrdersByDeliveryService provides a mock function with given fields: filter
+func (_m *IStatsService) OrdersByDeliveryService(filter models.OrderFilter) ([]models.DeliveryServiceCount, error) {
+	ret := _m.Called(filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for OrdersByDeliveryService")
+	}
+
+	var r0 []models.DeliveryServiceCount
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.OrderFilter) ([]models.DeliveryServiceCount, error)); ok {
+		return rf(filter)
+	}
+	if rf, ok := ret.Get(0).(func(models.OrderFilter) []models.DeliveryServiceCount); ok {
+		r0 = rf(filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.DeliveryServiceCount)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.OrderFilter) error); ok {
+		r1 = rf(filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IStatsService_OrdersByDeliveryService_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'OrdersByDeliveryService'
+type IStatsService_OrdersByDeliveryService_Call struct {
+	*mock.Call
+}
+
+// OrdersByDeliveryService is a helper method to define mock.On call
+//   - filter models.OrderFilter
+func (_e *IStatsService_Expecter) OrdersByDeliveryService(filter interface{}) *IStatsService_OrdersByDeliveryService_Call {
+	return &IStatsService_OrdersByDeliveryService_Call{Call: _e.mock.On("OrdersByDeliveryService", filter)}
+}
+
+func (_c *IStatsService_OrdersByDeliveryService_Call) Run(run func(filter models.OrderFilter)) *IStatsService_OrdersByDeliveryService_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(models.OrderFilter))
+	})
+	return _c
+}
+
+func (_c *IStatsService_OrdersByDeliveryService_Call) Return(_a0 []models.DeliveryServiceCount, _a1 error) *IStatsService_OrdersByDeliveryService_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IStatsService_OrdersByDeliveryService_Call) RunAndReturn(run func(models.OrderFilter) ([]models.DeliveryServiceCount, error)) *IStatsService_OrdersByDeliveryService_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// OrdersByLocation provides a mock function with given fields: filter
+func (_m *IStatsService) OrdersByLocation(filter models.OrderFilter) ([]models.LocationCount, error) {
+	ret := _m.Called(filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for OrdersByLocation")
+	}
+
+	var r0 []models.LocationCount
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.OrderFilter) ([]models.LocationCount, error)); ok {
+		return rf(filter)
+	}
+	if rf, ok := ret.Get(0).(func(models.OrderFilter) []models.LocationCount); ok {
+		r0 = rf(filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.LocationCount)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.OrderFilter) error); ok {
+		r1 = rf(filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IStatsService_OrdersByLocation_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'OrdersByLocation'
+type IStatsService_OrdersByLocation_Call struct {
+	*mock.Call
+}
+
+// OrdersByLocation is a helper method to define mock.On call
+//   - filter models.OrderFilter
+func (_e *IStatsService_Expecter) OrdersByLocation(filter interface{}) *IStatsService_OrdersByLocation_Call {
+	return &IStatsService_OrdersByLocation_Call{Call: _e.mock.On("OrdersByLocation", filter)}
+}
+
+func (_c *IStatsService_OrdersByLocation_Call) Run(run func(filter models.OrderFilter)) *IStatsService_OrdersByLocation_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(models.OrderFilter))
+	})
+	return _c
+}
+
+func (_c *IStatsService_OrdersByLocation_Call) Return(_a0 []models.LocationCount, _a1 error) *IStatsService_OrdersByLocation_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IStatsService_OrdersByLocation_Call) RunAndReturn(run func(models.OrderFilter) ([]models.LocationCount, error)) *IStatsService_OrdersByLocation_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// OrdersPerPeriod provides a mock function with given fields: filter, period
+func (_m *IStatsService) OrdersPerPeriod(filter models.OrderFilter, period models.Period) ([]models.PeriodCount, error) {
+	ret := _m.Called(filter, period)
+
+	if len(ret) == 0 {
+		panic("no return value specified for OrdersPerPeriod")
+	}
+
+	var r0 []models.PeriodCount
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.OrderFilter, models.Period) ([]models.PeriodCount, error)); ok {
+		return rf(filter, period)
+	}
+	if rf, ok := ret.Get(0).(func(models.OrderFilter, models.Period) []models.PeriodCount); ok {
+		r0 = rf(filter, period)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.PeriodCount)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.OrderFilter, models.Period) error); ok {
+		r1 = rf(filter, period)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IStatsService_OrdersPerPeriod_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'OrdersPerPeriod'
+type IStatsService_OrdersPerPeriod_Call struct {
+	*mock.Call
+}
+
+// OrdersPerPeriod is a helper method to define mock.On call
+//   - filter models.OrderFilter
+//   - period models.Period
+func (_e *IStatsService_Expecter) OrdersPerPeriod(filter interface{}, period interface{}) *IStatsService_OrdersPerPeriod_Call {
+	return &IStatsService_OrdersPerPeriod_Call{Call: _e.mock.On("OrdersPerPeriod", filter, period)}
+}
+
+func (_c *IStatsService_OrdersPerPeriod_Call) Run(run func(filter models.OrderFilter, period models.Period)) *IStatsService_OrdersPerPeriod_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(models.OrderFilter), args[1].(models.Period))
+	})
+	return _c
+}
+
+func (_c *IStatsService_OrdersPerPeriod_Call) Return(_a0 []models.PeriodCount, _a1 error) *IStatsService_OrdersPerPeriod_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IStatsService_OrdersPerPeriod_Call) RunAndReturn(run func(models.OrderFilter, models.Period) ([]models.PeriodCount, error)) *IStatsService_OrdersPerPeriod_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevenueByCurrency provides a mock function with given fields: filter
+func (_m *IStatsService) RevenueByCurrency(filter models.OrderFilter) ([]models.CurrencyRevenue, error) {
+	ret := _m.Called(filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevenueByCurrency")
+	}
+
+	var r0 []models.CurrencyRevenue
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.OrderFilter) ([]models.CurrencyRevenue, error)); ok {
+		return rf(filter)
+	}
+	if rf, ok := ret.Get(0).(func(models.OrderFilter) []models.CurrencyRevenue); ok {
+		r0 = rf(filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.CurrencyRevenue)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.OrderFilter) error); ok {
+		r1 = rf(filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IStatsService_RevenueByCurrency_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevenueByCurrency'
+type IStatsService_RevenueByCurrency_Call struct {
+	*mock.Call
+}
+
+// RevenueByCurrency is a helper method to define mock.On call
+//   - filter models.OrderFilter
+func (_e *IStatsService_Expecter) RevenueByCurrency(filter interface{}) *IStatsService_RevenueByCurrency_Call {
+	return &IStatsService_RevenueByCurrency_Call{Call: _e.mock.On("RevenueByCurrency", filter)}
+}
+
+func (_c *IStatsService_RevenueByCurrency_Call) Run(run func(filter models.OrderFilter)) *IStatsService_RevenueByCurrency_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(models.OrderFilter))
+	})
+	return _c
+}
+
+func (_c *IStatsService_RevenueByCurrency_Call) Return(_a0 []models.CurrencyRevenue, _a1 error) *IStatsService_RevenueByCurrency_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IStatsService_RevenueByCurrency_Call) RunAndReturn(run func(models.OrderFilter) ([]models.CurrencyRevenue, error)) *IStatsService_RevenueByCurrency_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TopBrands provides a mock function with given fields: filter, limit
+func (_m *IStatsService) TopBrands(filter models.OrderFilter, limit int) ([]models.TopBrand, error) {
+	ret := _m.Called(filter, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TopBrands")
+	}
+
+	var r0 []models.TopBrand
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.OrderFilter, int) ([]models.TopBrand, error)); ok {
+		return rf(filter, limit)
+	}
+	if rf, ok := ret.Get(0).(func(models.OrderFilter, int) []models.TopBrand); ok {
+		r0 = rf(filter, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.TopBrand)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.OrderFilter, int) error); ok {
+		r1 = rf(filter, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IStatsService_TopBrands_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TopBrands'
+type IStatsService_TopBrands_Call struct {
+	*mock.Call
+}
+
+// TopBrands is a helper method to define mock.On call
+//   - filter models.OrderFilter
+//   - limit int
+func (_e *IStatsService_Expecter) TopBrands(filter interface{}, limit interface{}) *IStatsService_TopBrands_Call {
+	return &IStatsService_TopBrands_Call{Call: _e.mock.On("TopBrands", filter, limit)}
+}
+
+func (_c *IStatsService_TopBrands_Call) Run(run func(filter models.OrderFilter, limit int)) *IStatsService_TopBrands_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(models.OrderFilter), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *IStatsService_TopBrands_Call) Return(_a0 []models.TopBrand, _a1 error) *IStatsService_TopBrands_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IStatsService_TopBrands_Call) RunAndReturn(run func(models.OrderFilter, int) ([]models.TopBrand, error)) *IStatsService_TopBrands_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TopItems provides a mock function with given fields: filter, limit
+func (_m *IStatsService) TopItems(filter models.OrderFilter, limit int) ([]models.TopItem, error) {
+	ret := _m.Called(filter, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TopItems")
+	}
+
+	var r0 []models.TopItem
+	var r1 error
+	if rf, ok := ret.Get(0).(func(models.OrderFilter, int) ([]models.TopItem, error)); ok {
+		return rf(filter, limit)
+	}
+	if rf, ok := ret.Get(0).(func(models.OrderFilter, int) []models.TopItem); ok {
+		r0 = rf(filter, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.TopItem)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(models.OrderFilter, int) error); ok {
+		r1 = rf(filter, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IStatsService_TopItems_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TopItems'
+type IStatsService_TopItems_Call struct {
+	*mock.Call
+}
+
+// TopItems is a helper method to define mock.On call
+//   - filter models.OrderFilter
+//   - limit int
+func (_e *IStatsService_Expecter) TopItems(filter interface{}, limit interface{}) *IStatsService_TopItems_Call {
+	return &IStatsService_TopItems_Call{Call: _e.mock.On("TopItems", filter, limit)}
+}
+
+func (_c *IStatsService_TopItems_Call) Run(run func(filter models.OrderFilter, limit int)) *IStatsService_TopItems_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(models.OrderFilter), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *IStatsService_TopItems_Call) Return(_a0 []models.TopItem, _a1 error) *IStatsService_TopItems_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *IStatsService_TopItems_Call) RunAndReturn(run func(models.OrderFilter, int) ([]models.TopItem, error)) *IStatsService_TopItems_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewIStatsService creates a new instance of IStatsService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIStatsService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *IStatsService {
+	mock := &IStatsService{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
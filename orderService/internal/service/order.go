@@ -1,32 +1,62 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/google/uuid"
 	"log"
 	"orderService/internal/cache"
+	"orderService/internal/events"
 	"orderService/internal/models"
 	"orderService/internal/repository"
+	"slices"
+	"time"
 )
 
 //go:generate mockery --name=IOrderService --output=mocks --outpkg=mocks --case=snake --with-expecter
 type IOrderService interface {
 	GetById(uid uuid.UUID) (models.OrderView, error)
-	Create(order models.Order) error
-	HandleMessage(message []byte) error
+	GetByIds(uids []uuid.UUID) ([]models.OrderView, []uuid.UUID, error)
+	Create(order models.Order, actor string) error
+	CreateBatch(orders []models.Order, actors []string) []error
+	HandleMessage(message []byte, actor string) error
+	GetByTrackNumber(trackNumber string) (models.Order, error)
+	ListByCustomer(customerID string, after *models.OrderCursor, limit int) ([]models.Order, error)
+	GetItems(uids []uuid.UUID) (map[uuid.UUID][]models.Item, error)
+	UpdateStatus(uid uuid.UUID, status int, actor string) (models.OrderView, error)
+	UpdateDelivery(uid uuid.UUID, version int, patch models.DeliveryPatch, actor string) (models.OrderView, error)
+	AddItem(uid uuid.UUID, version int, item models.Item, actor string) (models.OrderView, error)
+	RemoveItem(uid uuid.UUID, version int, rid string, actor string) (models.OrderView, error)
+	StreamOrders(ctx context.Context, filter models.OrderFilter, fn func([]models.Order) error) error
 }
 
+// streamChunkSize is the number of orders loaded with their associations at once while streaming
+const streamChunkSize = 500
+
+var ErrItemNotFound = errors.New("item not found")
+
+var ErrOrderExists = errors.New("order already exists")
+
 type OrderService struct {
-	repo  repository.IOrderRepository
-	cache cache.ILruCache
+	repo      repository.IOrderRepository
+	cache     cache.ILruCache
+	publisher events.Publisher
+	audit     IAuditService
+	now       func() time.Time
 }
 
-func NewService(r repository.IOrderRepository, c cache.ILruCache) OrderService {
+func NewService(r repository.IOrderRepository, c cache.ILruCache, p events.Publisher, a IAuditService) OrderService {
 	return OrderService{
-		repo:  r,
-		cache: c,
+		repo:      r,
+		cache:     c,
+		publisher: p,
+		audit:     a,
+		now:       time.Now,
 	}
 }
 
+// GetById returns the view with its ETag, cached views have it already
 func (s OrderService) GetById(uid uuid.UUID) (models.OrderView, error) {
 	orderInCache, ok := s.cache.Get(uid.String())
 	if ok {
@@ -39,10 +69,79 @@ func (s OrderService) GetById(uid uuid.UUID) (models.OrderView, error) {
 		return models.OrderView{}, err
 	}
 
-	return order.ToOrderView(), nil
+	return order.ToOrderView().WithETag(), nil
+}
+
+// GetByIds returns the found orders in the order of uids and the uids that do not exist.
+// Cached orders are served from the cache, the rest are fetched with one repository call
+func (s OrderService) GetByIds(uids []uuid.UUID) ([]models.OrderView, []uuid.UUID, error) {
+	unique := make([]uuid.UUID, 0, len(uids))
+	found := make(map[uuid.UUID]models.OrderView, len(uids))
+	misses := make([]uuid.UUID, 0)
+	seen := make(map[uuid.UUID]bool, len(uids))
+	for _, uid := range uids {
+		if seen[uid] {
+			continue
+		}
+		seen[uid] = true
+		unique = append(unique, uid)
+
+		if view, ok := s.cache.Get(uid.String()); ok {
+			found[uid] = view
+			continue
+		}
+		misses = append(misses, uid)
+	}
+	log.Printf("Batch get: %d from cache, %d from repository\n", len(found), len(misses))
+
+	if len(misses) > 0 {
+		orders, err := s.repo.GetByUids(misses)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, order := range orders {
+			found[order.Uid] = order.ToOrderView()
+		}
+	}
+
+	views := make([]models.OrderView, 0, len(found))
+	missing := make([]uuid.UUID, 0)
+	for _, uid := range unique {
+		if view, ok := found[uid]; ok {
+			views = append(views, view)
+		} else {
+			missing = append(missing, uid)
+		}
+	}
+
+	return views, missing, nil
+}
+
+// GetByTrackNumber returns the order without items, they are loaded in batches with GetItems
+func (s OrderService) GetByTrackNumber(trackNumber string) (models.Order, error) {
+	return s.repo.GetByTrackNumber(trackNumber)
+}
+
+// ListByCustomer returns orders without items, they are loaded in batches with GetItems
+func (s OrderService) ListByCustomer(customerID string, after *models.OrderCursor, limit int) ([]models.Order, error) {
+	return s.repo.FindByCustomer(customerID, after, limit)
+}
+
+func (s OrderService) GetItems(uids []uuid.UUID) (map[uuid.UUID][]models.Item, error) {
+	items, err := s.repo.GetItemsByOrderUids(uids)
+	if err != nil {
+		return nil, err
+	}
+
+	itemsByOrder := make(map[uuid.UUID][]models.Item, len(uids))
+	for _, item := range items {
+		itemsByOrder[item.OrderUid] = append(itemsByOrder[item.OrderUid], item)
+	}
+	return itemsByOrder, nil
 }
 
-func (s OrderService) Create(order models.Order) error {
+// Create stores a valid order and records it in the audit log on behalf of the actor
+func (s OrderService) Create(order models.Order, actor string) error {
 	if err := order.Validate(); err != nil {
 		return err
 	}
@@ -51,15 +150,190 @@ func (s OrderService) Create(order models.Order) error {
 		return err
 	}
 
-	s.cache.Add(order.Uid.String(), order.ToOrderView())
+	s.audit.Record(models.AuditEntry{Actor: actor, Action: models.AuditOrderCreated, OrderUid: &order.Uid, Diff: Diff(nil, order)})
+
+	view := order.ToOrderView()
+	s.cache.Add(order.Uid.String(), view)
+	s.publisher.Publish(events.OrderCreated, view)
 	return nil
 }
 
-func (s OrderService) HandleMessage(message []byte) error {
+// CreateBatch stores the valid orders in one transaction, orders[i] is created on behalf of actors[i]
+// and its outcome is errs[i]. When the transaction fails the orders are stored one by one,
+// so a single failing order does not reject the rest of the batch
+func (s OrderService) CreateBatch(orders []models.Order, actors []string) (errs []error) {
+	errs = make([]error, len(orders))
+	pending := make([]int, 0, len(orders))
+	uids := make([]uuid.UUID, 0, len(orders))
+	seen := make(map[uuid.UUID]bool, len(orders))
+	for i, order := range orders {
+		if errs[i] = order.Validate(); errs[i] != nil {
+			continue
+		}
+		if seen[order.Uid] {
+			errs[i] = fmt.Errorf("%w: %s", ErrOrderExists, order.Uid)
+			continue
+		}
+		seen[order.Uid] = true
+		pending = append(pending, i)
+		uids = append(uids, order.Uid)
+	}
+	if len(pending) == 0 {
+		return errs
+	}
+
+	existing, err := s.repo.GetExistingUids(uids)
+	if err != nil {
+		for _, i := range pending {
+			errs[i] = err
+		}
+		return errs
+	}
+	pending = slices.DeleteFunc(pending, func(i int) bool {
+		if slices.Contains(existing, orders[i].Uid) {
+			errs[i] = fmt.Errorf("%w: %s", ErrOrderExists, orders[i].Uid)
+			return true
+		}
+		return false
+	})
+
+	batch := make([]models.Order, 0, len(pending))
+	for _, i := range pending {
+		batch = append(batch, orders[i])
+	}
+	stored := pending
+	if err = s.repo.CreateBatch(batch); err != nil {
+		log.Printf("Batch of %d orders failed, storing them one by one: %v\n", len(batch), err)
+		stored = make([]int, 0, len(pending))
+		for _, i := range pending {
+			if errs[i] = s.repo.Create(orders[i]); errs[i] == nil {
+				stored = append(stored, i)
+			}
+		}
+	}
+
+	entries := make([]models.AuditEntry, 0, len(stored))
+	for _, i := range stored {
+		entries = append(entries, models.AuditEntry{Actor: actors[i], Action: models.AuditOrderCreated, OrderUid: &orders[i].Uid, Diff: Diff(nil, orders[i])})
+	}
+	if len(entries) > 0 {
+		s.audit.Record(entries...)
+	}
+
+	for _, i := range stored {
+		view := orders[i].ToOrderView()
+		s.cache.Add(orders[i].Uid.String(), view)
+		s.publisher.Publish(events.OrderCreated, view)
+	}
+	return errs
+}
+
+// UpdateStatus sets the status of all order items, refreshes the cached view, audits and publishes the change
+func (s OrderService) UpdateStatus(uid uuid.UUID, status int, actor string) (models.OrderView, error) {
+	before, err := s.repo.GetByUid(uid)
+	if err != nil {
+		return models.OrderView{}, err
+	}
+
+	if err = s.repo.UpdateItemsStatus(uid, status); err != nil {
+		return models.OrderView{}, err
+	}
+
+	order, err := s.repo.GetByUid(uid)
+	if err != nil {
+		return models.OrderView{}, err
+	}
+	s.audit.Record(models.AuditEntry{Actor: actor, Action: models.AuditOrderStatusUpdated, OrderUid: &uid, Diff: Diff(before, order)})
+
+	view := order.ToOrderView()
+	s.cache.Add(uid.String(), view)
+	s.publisher.Publish(events.OrderStatusChanged, view)
+	return view, nil
+}
+
+// UpdateDelivery changes the given delivery fields of the order if it still has the version
+func (s OrderService) UpdateDelivery(uid uuid.UUID, version int, patch models.DeliveryPatch, actor string) (models.OrderView, error) {
+	return s.amend(uid, version, actor, func(order *models.Order) error {
+		patch.Apply(&order.Delivery)
+		return nil
+	})
+}
+
+// AddItem adds the item to the order if it still has the version. The item total price is added to the payment goods total and amount
+func (s OrderService) AddItem(uid uuid.UUID, version int, item models.Item, actor string) (models.OrderView, error) {
+	return s.amend(uid, version, actor, func(order *models.Order) error {
+		item.Id = 0
+		item.OrderUid = uid
+		order.Items = append(order.Items, item)
+		order.Payment.GoodsTotal += item.TotalPrice
+		order.Payment.Amount += item.TotalPrice
+		return nil
+	})
+}
+
+// RemoveItem removes the item with the rid from the order if it still has the version. The item total price
+// is subtracted from the payment goods total and amount
+func (s OrderService) RemoveItem(uid uuid.UUID, version int, rid string, actor string) (models.OrderView, error) {
+	return s.amend(uid, version, actor, func(order *models.Order) error {
+		i := slices.IndexFunc(order.Items, func(item models.Item) bool { return item.RID == rid })
+		if i < 0 {
+			return ErrItemNotFound
+		}
+		order.Payment.GoodsTotal -= order.Items[i].TotalPrice
+		order.Payment.Amount -= order.Items[i].TotalPrice
+		order.Items = slices.Delete(order.Items, i, i+1)
+		return nil
+	})
+}
+
+// amend applies the change to a copy of the order, validates the result and stores it with the next version.
+// The cached view is replaced, the change is audited and published
+func (s OrderService) amend(uid uuid.UUID, version int, actor string, change func(order *models.Order) error) (models.OrderView, error) {
+	order, err := s.repo.GetByUid(uid)
+	if err != nil {
+		return models.OrderView{}, err
+	}
+	if order.Version != version {
+		return models.OrderView{}, repository.ErrVersionConflict
+	}
+
+	amended := order
+	amended.Items = slices.Clone(order.Items)
+	if err = change(&amended); err != nil {
+		return models.OrderView{}, err
+	}
+	if err = amended.Validate(); err != nil {
+		return models.OrderView{}, err
+	}
+	if err = amended.CheckPayment(); err != nil {
+		return models.OrderView{}, err
+	}
+
+	now := s.now()
+	amended.AmendedAt = &now
+	if err = s.repo.Amend(order, amended); err != nil {
+		return models.OrderView{}, err
+	}
+	amended.Version = version + 1
+
+	s.audit.Record(models.AuditEntry{Actor: actor, Action: models.AuditOrderAmended, OrderUid: &uid, Diff: Diff(order, amended)})
+
+	view := amended.ToOrderView().WithETag()
+	s.cache.Add(uid.String(), view)
+	s.publisher.Publish(events.OrderAmended, view)
+	return view, nil
+}
+
+// StreamOrders passes the filtered orders oldest first to fn in chunks, bypassing the cache
+func (s OrderService) StreamOrders(ctx context.Context, filter models.OrderFilter, fn func([]models.Order) error) error {
+	return s.repo.StreamOrders(ctx, filter, streamChunkSize, fn)
+}
+
+func (s OrderService) HandleMessage(message []byte, actor string) error {
 	var order models.Order
 	if err := order.UnmarshalJSON(message); err != nil {
 		return err
 	}
 
-	return s.Create(order)
+	return s.Create(order, actor)
 }
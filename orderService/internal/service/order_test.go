@@ -4,10 +4,14 @@ import (
 	"fmt"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"log"
 	cache "orderService/internal/cache/mocks"
+	"orderService/internal/events"
 	"orderService/internal/models"
+	"orderService/internal/repository"
 	repo "orderService/internal/repository/mocks"
+	audit "orderService/internal/service/mocks"
 	"testing"
 	"time"
 )
@@ -79,6 +83,9 @@ func init() {
 		Items:           validItems,
 	}
 	orderView = models.OrderView{
+		Uid:             uid,
+		TrackNumber:     "WBILMTESTTRACK",
+		CustomerID:      "100900",
 		DeliveryService: "meest",
 		DateCreated:     dateCreated,
 		Delivery: models.DeliveryView{
@@ -102,7 +109,9 @@ func init() {
 			TotalPrice: 317,
 			Brand:      "Vivienne Sabo",
 		},
-		}}
+		},
+		LastModified: dateCreated,
+	}
 }
 
 func TestHandler_GetById(t *testing.T) {
@@ -113,11 +122,12 @@ func TestHandler_GetById(t *testing.T) {
 		mockCache.On("Get", uid.String()).Return(models.OrderView{}, false)
 		mockRepo.On("GetByUid", uid).Return(validOrder, nil)
 
-		service := NewService(mockRepo, mockCache)
+		service := NewService(mockRepo, mockCache, events.NewBus(0), new(audit.IAuditService))
 
 		actualOrder, actualErr := service.GetById(uid)
 
-		assert.Equal(t, actualOrder, orderView)
+		assert.Equal(t, actualOrder, orderView.WithETag())
+		assert.NotEmpty(t, actualOrder.ETag)
 		assert.Nil(t, actualErr)
 		mockCache.AssertCalled(t, "Get", uid.String())
 		mockRepo.AssertCalled(t, "GetByUid", uid)
@@ -129,7 +139,7 @@ func TestHandler_GetById(t *testing.T) {
 
 		mockCache.On("Get", uid.String()).Return(orderView, true)
 
-		service := NewService(mockRepo, mockCache)
+		service := NewService(mockRepo, mockCache, events.NewBus(0), new(audit.IAuditService))
 
 		actualOrder, actualErr := service.GetById(uid)
 
@@ -146,7 +156,7 @@ func TestHandler_GetById(t *testing.T) {
 		mockCache.On("Get", uid.String()).Return(models.OrderView{}, false)
 		mockRepo.On("GetByUid", uid).Return(models.Order{}, fmt.Errorf("record not found"))
 
-		service := NewService(mockRepo, mockCache)
+		service := NewService(mockRepo, mockCache, events.NewBus(0), new(audit.IAuditService))
 
 		actualOrder, actualErr := service.GetById(uid)
 
@@ -157,21 +167,91 @@ func TestHandler_GetById(t *testing.T) {
 	})
 }
 
+func TestHandler_GetByIds(t *testing.T) {
+	cachedUid := uuid.MustParse("2e9ad4fb-2615-46f9-9458-20b59253086b")
+	missingUid := uuid.MustParse("3e9ad4fb-2615-46f9-9458-20b59253086b")
+	cachedView := models.OrderView{Uid: cachedUid, TrackNumber: "CACHED"}
+
+	t.Run("CacheHitsAndSingleRepoCall", func(t *testing.T) {
+		mockRepo := new(repo.IOrderRepository)
+		mockCache := new(cache.ILruCache)
+
+		mockCache.On("Get", cachedUid.String()).Return(cachedView, true)
+		mockCache.On("Get", uid.String()).Return(models.OrderView{}, false)
+		mockCache.On("Get", missingUid.String()).Return(models.OrderView{}, false)
+		mockRepo.On("GetByUids", []uuid.UUID{uid, missingUid}).Return([]models.Order{validOrder}, nil)
+
+		service := NewService(mockRepo, mockCache, events.NewBus(0), new(audit.IAuditService))
+
+		orders, missing, actualErr := service.GetByIds([]uuid.UUID{cachedUid, uid, missingUid, uid})
+
+		assert.Nil(t, actualErr)
+		assert.Equal(t, []models.OrderView{cachedView, orderView}, orders)
+		assert.Equal(t, []uuid.UUID{missingUid}, missing)
+		mockRepo.AssertNumberOfCalls(t, "GetByUids", 1)
+	})
+
+	t.Run("AllCached", func(t *testing.T) {
+		mockRepo := new(repo.IOrderRepository)
+		mockCache := new(cache.ILruCache)
+
+		mockCache.On("Get", cachedUid.String()).Return(cachedView, true)
+
+		service := NewService(mockRepo, mockCache, events.NewBus(0), new(audit.IAuditService))
+
+		orders, missing, actualErr := service.GetByIds([]uuid.UUID{cachedUid})
+
+		assert.Nil(t, actualErr)
+		assert.Equal(t, []models.OrderView{cachedView}, orders)
+		assert.Empty(t, missing)
+		mockRepo.AssertNotCalled(t, "GetByUids")
+	})
+
+	t.Run("FailedInRepo", func(t *testing.T) {
+		mockRepo := new(repo.IOrderRepository)
+		mockCache := new(cache.ILruCache)
+
+		mockCache.On("Get", uid.String()).Return(models.OrderView{}, false)
+		mockRepo.On("GetByUids", []uuid.UUID{uid}).Return(nil, fmt.Errorf("connection refused"))
+
+		service := NewService(mockRepo, mockCache, events.NewBus(0), new(audit.IAuditService))
+
+		_, _, actualErr := service.GetByIds([]uuid.UUID{uid})
+
+		assert.Equal(t, "connection refused", actualErr.Error())
+	})
+}
+
 func TestHandler_Create(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		mockRepo := new(repo.IOrderRepository)
 		mockCache := new(cache.ILruCache)
 
+		mockAudit := new(audit.IAuditService)
+
 		mockRepo.On("Create", validOrder).Return(nil)
 		mockCache.On("Add", uid.String(), orderView).Return(true)
+		mockAudit.On("Record", mock.Anything).Return()
+		bus := events.NewBus(0)
+		subscription := bus.Subscribe(1, events.Filter{})
 
-		service := NewService(mockRepo, mockCache)
+		service := NewService(mockRepo, mockCache, bus, mockAudit)
 
-		actualErr := service.Create(validOrder)
+		actualErr := service.Create(validOrder, "kafka:Orders/0@42")
 
 		assert.Nil(t, actualErr)
 		mockRepo.AssertCalled(t, "Create", validOrder)
 		mockCache.AssertCalled(t, "Add", uid.String(), orderView)
+		entry := mockAudit.Calls[0].Arguments.Get(0).(models.AuditEntry)
+		assert.Equal(t, "kafka:Orders/0@42", entry.Actor)
+		assert.Equal(t, models.AuditOrderCreated, entry.Action)
+		assert.Equal(t, uid, *entry.OrderUid)
+		assert.Contains(t, string(entry.Diff), `"items.0.status":{"new":202}`)
+		assert.Contains(t, string(entry.Diff), `"delivery.phone":{"new":"[redacted]"}`)
+		assert.NotContains(t, string(entry.Diff), validDelivery.Phone)
+		event := <-subscription.Events()
+		assert.Equal(t, events.OrderCreated, event.Type)
+		assert.Equal(t, orderView, event.Order)
 
 	})
 
@@ -181,9 +261,9 @@ func TestHandler_Create(t *testing.T) {
 
 		mockRepo.On("Create", validOrder).Return(fmt.Errorf("key (%s)=(%s) already exists", "uid", uid.String()))
 
-		service := NewService(mockRepo, mockCache)
+		service := NewService(mockRepo, mockCache, events.NewBus(0), new(audit.IAuditService))
 
-		actualErr := service.Create(validOrder)
+		actualErr := service.Create(validOrder, "anonymous")
 
 		assert.Equal(t, actualErr.Error(), fmt.Sprintf("key (%s)=(%s) already exists", "uid", uid.String()))
 		mockRepo.AssertCalled(t, "Create", validOrder)
@@ -416,9 +496,9 @@ func TestHandler_Create(t *testing.T) {
 			mockRepo := new(repo.IOrderRepository)
 			mockCache := new(cache.ILruCache)
 
-			service := NewService(mockRepo, mockCache)
+			service := NewService(mockRepo, mockCache, events.NewBus(0), new(audit.IAuditService))
 
-			actualErr := service.Create(td.order)
+			actualErr := service.Create(td.order, "anonymous")
 
 			assert.Equal(t, actualErr.Error(), td.errorMsg)
 			mockRepo.AssertNotCalled(t, "Create")
@@ -426,3 +506,283 @@ func TestHandler_Create(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_CreateBatch(t *testing.T) {
+	second := validOrder
+	second.Uid = uuid.MustParse("2e9ad4fb-2615-46f9-9458-20b59253086b")
+	third := validOrder
+	third.Uid = uuid.MustParse("3e9ad4fb-2615-46f9-9458-20b59253086b")
+	invalid := validOrder
+	invalid.TrackNumber = ""
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(repo.IOrderRepository)
+		mockCache := new(cache.ILruCache)
+		mockAudit := new(audit.IAuditService)
+
+		mockRepo.On("GetExistingUids", []uuid.UUID{uid, second.Uid}).Return([]uuid.UUID{}, nil)
+		mockRepo.On("CreateBatch", []models.Order{validOrder, second}).Return(nil)
+		mockCache.On("Add", mock.Anything, mock.Anything).Return(true)
+		mockAudit.On("Record", mock.Anything, mock.Anything).Return()
+
+		errs := NewService(mockRepo, mockCache, events.NewBus(0), mockAudit).CreateBatch([]models.Order{validOrder, second}, []string{"kafka:Orders/0@1", "kafka:Orders/0@2"})
+
+		assert.Equal(t, []error{nil, nil}, errs)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+		mockCache.AssertCalled(t, "Add", uid.String(), orderView)
+		mockCache.AssertCalled(t, "Add", second.Uid.String(), mock.Anything)
+		mockAudit.AssertNumberOfCalls(t, "Record", 1)
+		assert.Equal(t, "kafka:Orders/0@2", mockAudit.Calls[0].Arguments.Get(1).(models.AuditEntry).Actor)
+	})
+
+	t.Run("FailuresAreIsolated", func(t *testing.T) {
+		mockRepo := new(repo.IOrderRepository)
+		mockCache := new(cache.ILruCache)
+		mockAudit := new(audit.IAuditService)
+
+		mockRepo.On("GetExistingUids", []uuid.UUID{uid, second.Uid, third.Uid}).Return([]uuid.UUID{uid}, nil)
+		mockRepo.On("CreateBatch", []models.Order{second, third}).Return(fmt.Errorf("value too long for type character varying(255)"))
+		mockRepo.On("Create", second).Return(nil)
+		mockRepo.On("Create", third).Return(fmt.Errorf("value too long for type character varying(255)"))
+		mockCache.On("Add", second.Uid.String(), mock.Anything).Return(true)
+		mockAudit.On("Record", mock.Anything).Return()
+
+		errs := NewService(mockRepo, mockCache, events.NewBus(0), mockAudit).CreateBatch(
+			[]models.Order{invalid, validOrder, second, second, third},
+			[]string{"kafka:Orders/0@1", "kafka:Orders/0@2", "kafka:Orders/0@3", "kafka:Orders/0@4", "kafka:Orders/0@5"},
+		)
+
+		assert.Len(t, errs, 5)
+		assert.Error(t, errs[0])
+		assert.ErrorIs(t, errs[1], ErrOrderExists)
+		assert.Nil(t, errs[2])
+		assert.ErrorIs(t, errs[3], ErrOrderExists)
+		assert.EqualError(t, errs[4], "value too long for type character varying(255)")
+		mockCache.AssertNumberOfCalls(t, "Add", 1)
+		entry := mockAudit.Calls[0].Arguments.Get(0).(models.AuditEntry)
+		assert.Equal(t, "kafka:Orders/0@3", entry.Actor)
+		assert.Equal(t, second.Uid, *entry.OrderUid)
+	})
+
+	t.Run("LookupFailureFailsValidOrders", func(t *testing.T) {
+		mockRepo := new(repo.IOrderRepository)
+		mockAudit := new(audit.IAuditService)
+
+		mockRepo.On("GetExistingUids", []uuid.UUID{uid}).Return(nil, fmt.Errorf("connection refused"))
+
+		errs := NewService(mockRepo, new(cache.ILruCache), events.NewBus(0), mockAudit).CreateBatch([]models.Order{validOrder, invalid}, []string{"anonymous", "anonymous"})
+
+		assert.EqualError(t, errs[0], "connection refused")
+		assert.Error(t, errs[1])
+		assert.NotEqual(t, "connection refused", errs[1].Error())
+		mockRepo.AssertNotCalled(t, "CreateBatch", mock.Anything)
+		mockAudit.AssertNotCalled(t, "Record")
+	})
+}
+
+func TestHandler_UpdateStatus(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(repo.IOrderRepository)
+		mockCache := new(cache.ILruCache)
+		mockAudit := new(audit.IAuditService)
+
+		before := validOrder
+		before.Items = []models.Item{validItems[0]}
+		before.Items[0].Status = 100
+		mockRepo.On("GetByUid", uid).Return(before, nil).Once()
+		mockRepo.On("UpdateItemsStatus", uid, 202).Return(nil)
+		mockRepo.On("GetByUid", uid).Return(validOrder, nil).Once()
+		mockCache.On("Add", uid.String(), orderView).Return(true)
+		mockAudit.On("Record", mock.Anything).Return()
+		bus := events.NewBus(0)
+		subscription := bus.Subscribe(1, events.Filter{})
+
+		service := NewService(mockRepo, mockCache, bus, mockAudit)
+
+		actualOrder, actualErr := service.UpdateStatus(uid, 202, "jwt:courier")
+
+		assert.Nil(t, actualErr)
+		assert.Equal(t, orderView, actualOrder)
+		mockCache.AssertCalled(t, "Add", uid.String(), orderView)
+		event := <-subscription.Events()
+		assert.Equal(t, events.OrderStatusChanged, event.Type)
+		entry := mockAudit.Calls[0].Arguments.Get(0).(models.AuditEntry)
+		assert.Equal(t, "jwt:courier", entry.Actor)
+		assert.Equal(t, models.AuditOrderStatusUpdated, entry.Action)
+		assert.JSONEq(t, `{"items.0.status":{"old":100,"new":202}}`, string(entry.Diff))
+	})
+
+	t.Run("NotFoundInRepo", func(t *testing.T) {
+		mockRepo := new(repo.IOrderRepository)
+		mockCache := new(cache.ILruCache)
+		mockAudit := new(audit.IAuditService)
+
+		mockRepo.On("GetByUid", uid).Return(models.Order{}, fmt.Errorf("record not found"))
+
+		service := NewService(mockRepo, mockCache, events.NewBus(0), mockAudit)
+
+		_, actualErr := service.UpdateStatus(uid, 202, "jwt:courier")
+
+		assert.Equal(t, "record not found", actualErr.Error())
+		mockRepo.AssertNotCalled(t, "UpdateItemsStatus", uid, 202)
+		mockCache.AssertNotCalled(t, "Add")
+		mockAudit.AssertNotCalled(t, "Record", mock.Anything)
+	})
+}
+
+func TestHandler_UpdateDelivery(t *testing.T) {
+	stored := validOrder
+	stored.Version = 3
+	city := "Kazan"
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(repo.IOrderRepository)
+		mockCache := new(cache.ILruCache)
+		mockAudit := new(audit.IAuditService)
+
+		amendedAt := dateCreated.Add(time.Hour)
+		amended := stored
+		amended.Delivery.City = city
+		amended.AmendedAt = &amendedAt
+		expected := amended
+		expected.Version = 4
+		mockRepo.On("GetByUid", uid).Return(stored, nil)
+		mockRepo.On("Amend", stored, amended).Return(nil)
+		mockCache.On("Add", uid.String(), expected.ToOrderView().WithETag()).Return(true)
+		mockAudit.On("Record", mock.Anything).Return()
+		bus := events.NewBus(0)
+		subscription := bus.Subscribe(1, events.Filter{})
+
+		service := NewService(mockRepo, mockCache, bus, mockAudit)
+		service.now = func() time.Time { return amendedAt }
+
+		view, err := service.UpdateDelivery(uid, 3, models.DeliveryPatch{City: &city}, "jwt:100900")
+
+		assert.Nil(t, err)
+		assert.Equal(t, 4, view.Version)
+		assert.Equal(t, city, view.Delivery.City)
+		assert.Equal(t, amendedAt, view.LastModified)
+		assert.Regexp(t, `^"4-[0-9a-f]{16}"$`, view.ETag)
+		mockCache.AssertCalled(t, "Add", uid.String(), expected.ToOrderView().WithETag())
+		event := <-subscription.Events()
+		assert.Equal(t, events.OrderAmended, event.Type)
+		entry := mockAudit.Calls[0].Arguments.Get(0).(models.AuditEntry)
+		assert.Equal(t, models.AuditOrderAmended, entry.Action)
+		assert.JSONEq(t, `{"delivery.city":{"old":"Moscow","new":"Kazan"}}`, string(entry.Diff))
+	})
+
+	t.Run("VersionConflict", func(t *testing.T) {
+		mockRepo := new(repo.IOrderRepository)
+		mockCache := new(cache.ILruCache)
+
+		mockRepo.On("GetByUid", uid).Return(stored, nil)
+
+		service := NewService(mockRepo, mockCache, events.NewBus(0), new(audit.IAuditService))
+
+		_, err := service.UpdateDelivery(uid, 2, models.DeliveryPatch{City: &city}, "jwt:100900")
+
+		assert.ErrorIs(t, err, repository.ErrVersionConflict)
+		mockRepo.AssertNotCalled(t, "Amend", mock.Anything, mock.Anything)
+	})
+
+	t.Run("ConcurrentAmendment", func(t *testing.T) {
+		mockRepo := new(repo.IOrderRepository)
+		mockCache := new(cache.ILruCache)
+
+		mockRepo.On("GetByUid", uid).Return(stored, nil)
+		mockRepo.On("Amend", stored, mock.Anything).Return(repository.ErrVersionConflict)
+
+		service := NewService(mockRepo, mockCache, events.NewBus(0), new(audit.IAuditService))
+
+		_, err := service.UpdateDelivery(uid, 3, models.DeliveryPatch{City: &city}, "jwt:100900")
+
+		assert.ErrorIs(t, err, repository.ErrVersionConflict)
+		mockCache.AssertNotCalled(t, "Add")
+	})
+
+	t.Run("InvalidMergedOrder", func(t *testing.T) {
+		mockRepo := new(repo.IOrderRepository)
+		invalid := "not an email"
+
+		mockRepo.On("GetByUid", uid).Return(stored, nil)
+
+		service := NewService(mockRepo, new(cache.ILruCache), events.NewBus(0), new(audit.IAuditService))
+
+		_, err := service.UpdateDelivery(uid, 3, models.DeliveryPatch{Email: &invalid}, "jwt:100900")
+
+		assert.EqualError(t, err, "Key: 'Order.Delivery.Email' Error:Field validation for 'Email' failed on the 'email' tag")
+		mockRepo.AssertNotCalled(t, "Amend", mock.Anything, mock.Anything)
+	})
+}
+
+func TestHandler_AddItem(t *testing.T) {
+	item := validItems[0]
+	item.RID = "cd4219087a764ae0btest"
+	item.TotalPrice = 100
+
+	t.Run("RepricesPayment", func(t *testing.T) {
+		mockRepo := new(repo.IOrderRepository)
+		mockCache := new(cache.ILruCache)
+		mockAudit := new(audit.IAuditService)
+
+		mockRepo.On("GetByUid", uid).Return(validOrder, nil)
+		mockRepo.On("Amend", validOrder, mock.Anything).Return(nil)
+		mockCache.On("Add", uid.String(), mock.Anything).Return(true)
+		mockAudit.On("Record", mock.Anything).Return()
+
+		service := NewService(mockRepo, mockCache, events.NewBus(0), mockAudit)
+
+		view, err := service.AddItem(uid, 0, item, "jwt:100900")
+
+		assert.Nil(t, err)
+		assert.Len(t, view.Items, 2)
+		assert.Equal(t, 417, view.Payment.GoodsTotal)
+		assert.Equal(t, 1917, view.Payment.Amount)
+		amended := mockRepo.Calls[1].Arguments.Get(1).(models.Order)
+		assert.Equal(t, uid, amended.Items[1].OrderUid)
+		assert.Len(t, validOrder.Items, 1)
+	})
+
+	t.Run("InconsistentPayment", func(t *testing.T) {
+		mockRepo := new(repo.IOrderRepository)
+		stored := validOrder
+		stored.Payment.Amount = 1000
+
+		mockRepo.On("GetByUid", uid).Return(stored, nil)
+
+		service := NewService(mockRepo, new(cache.ILruCache), events.NewBus(0), new(audit.IAuditService))
+
+		_, err := service.AddItem(uid, 0, item, "jwt:100900")
+
+		assert.ErrorIs(t, err, models.ErrPaymentMismatch)
+		mockRepo.AssertNotCalled(t, "Amend", mock.Anything, mock.Anything)
+	})
+}
+
+func TestHandler_RemoveItem(t *testing.T) {
+	t.Run("ItemNotFound", func(t *testing.T) {
+		mockRepo := new(repo.IOrderRepository)
+
+		mockRepo.On("GetByUid", uid).Return(validOrder, nil)
+
+		service := NewService(mockRepo, new(cache.ILruCache), events.NewBus(0), new(audit.IAuditService))
+
+		_, err := service.RemoveItem(uid, 0, "unknown", "jwt:100900")
+
+		assert.ErrorIs(t, err, ErrItemNotFound)
+	})
+
+	t.Run("LastItemCannotBeRemoved", func(t *testing.T) {
+		mockRepo := new(repo.IOrderRepository)
+
+		mockRepo.On("GetByUid", uid).Return(validOrder, nil)
+
+		service := NewService(mockRepo, new(cache.ILruCache), events.NewBus(0), new(audit.IAuditService))
+
+		_, err := service.RemoveItem(uid, 0, validItems[0].RID, "jwt:100900")
+
+		assert.EqualError(t, err, "Key: 'Order.Payment.GoodsTotal' Error:Field validation for 'GoodsTotal' failed on the 'required' tag")
+		mockRepo.AssertNotCalled(t, "Amend", mock.Anything, mock.Anything)
+		assert.Len(t, validOrder.Items, 1)
+	})
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"log"
+	"orderService/internal/models"
+	"orderService/internal/repository"
+	"time"
+)
+
+//go:generate mockery --name=IStatsService --output=mocks --outpkg=mocks --case=snake --with-expecter
+type IStatsService interface {
+	OrdersPerPeriod(filter models.OrderFilter, period models.Period) ([]models.PeriodCount, error)
+	RevenueByCurrency(filter models.OrderFilter) ([]models.CurrencyRevenue, error)
+	TopBrands(filter models.OrderFilter, limit int) ([]models.TopBrand, error)
+	TopItems(filter models.OrderFilter, limit int) ([]models.TopItem, error)
+	OrdersByDeliveryService(filter models.OrderFilter) ([]models.DeliveryServiceCount, error)
+	OrdersByLocation(filter models.OrderFilter) ([]models.LocationCount, error)
+	Basket(filter models.OrderFilter) ([]models.BasketStats, error)
+}
+
+type StatsService struct {
+	repo repository.IStatsRepository
+}
+
+func NewStatsService(r repository.IStatsRepository) StatsService {
+	return StatsService{repo: r}
+}
+
+func (s StatsService) OrdersPerPeriod(filter models.OrderFilter, period models.Period) ([]models.PeriodCount, error) {
+	return s.repo.OrdersPerPeriod(filter, period)
+}
+
+func (s StatsService) RevenueByCurrency(filter models.OrderFilter) ([]models.CurrencyRevenue, error) {
+	return s.repo.RevenueByCurrency(filter)
+}
+
+func (s StatsService) TopBrands(filter models.OrderFilter, limit int) ([]models.TopBrand, error) {
+	return s.repo.TopBrands(filter, limit)
+}
+
+func (s StatsService) TopItems(filter models.OrderFilter, limit int) ([]models.TopItem, error) {
+	return s.repo.TopItems(filter, limit)
+}
+
+func (s StatsService) OrdersByDeliveryService(filter models.OrderFilter) ([]models.DeliveryServiceCount, error) {
+	return s.repo.OrdersByDeliveryService(filter)
+}
+
+func (s StatsService) OrdersByLocation(filter models.OrderFilter) ([]models.LocationCount, error) {
+	return s.repo.OrdersByLocation(filter)
+}
+
+func (s StatsService) Basket(filter models.OrderFilter) ([]models.BasketStats, error) {
+	return s.repo.Basket(filter)
+}
+
+// RefreshViews refreshes the materialized views right away and then every interval until the context is done.
+// A failed refresh keeps the previous data and is retried on the next tick
+func (s StatsService) RefreshViews(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.repo.RefreshViews(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Error refreshing stats views: %s\n", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/mock"
+	repo "orderService/internal/repository/mocks"
+	"testing"
+	"time"
+)
+
+func TestStatsService_RefreshViews(t *testing.T) {
+	t.Run("RefreshesUntilCancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		refreshed := make(chan struct{}, 10)
+
+		mockRepo := new(repo.IStatsRepository)
+		mockRepo.On("RefreshViews", mock.Anything).Run(func(mock.Arguments) {
+			refreshed <- struct{}{}
+		}).Return(nil)
+
+		done := make(chan struct{})
+		go func() {
+			NewStatsService(mockRepo).RefreshViews(ctx, time.Millisecond)
+			close(done)
+		}()
+
+		<-refreshed
+		<-refreshed
+		cancel()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("refresh loop did not stop")
+		}
+	})
+
+	t.Run("KeepsRefreshingAfterError", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		refreshed := make(chan struct{}, 10)
+
+		mockRepo := new(repo.IStatsRepository)
+		mockRepo.On("RefreshViews", mock.Anything).Run(func(mock.Arguments) {
+			refreshed <- struct{}{}
+		}).Return(fmt.Errorf("connection refused"))
+
+		go NewStatsService(mockRepo).RefreshViews(ctx, time.Millisecond)
+
+		<-refreshed
+		<-refreshed
+	})
+}
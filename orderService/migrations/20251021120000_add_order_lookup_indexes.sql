@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS order_customer_id_date_created_idx ON "order" (customer_id, date_created DESC, uid DESC);
+CREATE INDEX IF NOT EXISTS order_track_number_idx ON "order" (track_number);
+CREATE INDEX IF NOT EXISTS item_order_uid_idx ON item (order_uid);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS item_order_uid_idx;
+DROP INDEX IF EXISTS order_track_number_idx;
+DROP INDEX IF EXISTS order_customer_id_date_created_idx;
+-- +goose StatementEnd
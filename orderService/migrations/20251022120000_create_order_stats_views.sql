@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE MATERIALIZED VIEW IF NOT EXISTS order_stats_daily AS
+SELECT (o.date_created AT TIME ZONE 'UTC')::date AS day,
+       p.currency,
+       COALESCE(o.delivery_service, '') AS delivery_service,
+       COALESCE(d.region, '') AS region,
+       d.city,
+       count(*) AS orders,
+       sum(p.amount) AS revenue,
+       sum(COALESCE(i.items, 0)) AS items
+FROM "order" o
+    JOIN payment p ON p.id = o.payment_id
+    JOIN delivery d ON d.id = o.delivery_id
+    LEFT JOIN (SELECT order_uid, count(*) AS items FROM item GROUP BY order_uid) i ON i.order_uid = o.uid
+GROUP BY 1, 2, 3, 4, 5;
+
+CREATE UNIQUE INDEX IF NOT EXISTS order_stats_daily_key ON order_stats_daily (day, currency, delivery_service, region, city);
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS item_stats_daily AS
+SELECT (o.date_created AT TIME ZONE 'UTC')::date AS day,
+       COALESCE(i.brand, '') AS brand,
+       i.name,
+       count(*) AS quantity,
+       sum(i.total_price) AS revenue
+FROM item i
+    JOIN "order" o ON o.uid = i.order_uid
+GROUP BY 1, 2, 3;
+
+CREATE UNIQUE INDEX IF NOT EXISTS item_stats_daily_key ON item_stats_daily (day, brand, name);
+CREATE INDEX IF NOT EXISTS order_date_created_idx ON "order" (date_created);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS order_date_created_idx;
+DROP MATERIALIZED VIEW IF EXISTS item_stats_daily;
+DROP MATERIALIZED VIEW IF EXISTS order_stats_daily;
+-- +goose StatementEnd
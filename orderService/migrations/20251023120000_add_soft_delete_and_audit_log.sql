@@ -0,0 +1,84 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE "order" ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS order_deleted_at_idx ON "order" (deleted_at);
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    actor VARCHAR(255) NOT NULL,
+    action VARCHAR(100) NOT NULL,
+    order_uid UUID,
+    customer_id VARCHAR(255),
+    details JSONB
+    );
+CREATE INDEX IF NOT EXISTS audit_log_order_uid_idx ON audit_log (order_uid, occurred_at);
+
+-- Soft deleted orders are excluded from the statistics
+DROP MATERIALIZED VIEW IF EXISTS order_stats_daily;
+CREATE MATERIALIZED VIEW order_stats_daily AS
+SELECT (o.date_created AT TIME ZONE 'UTC')::date AS day,
+       p.currency,
+       COALESCE(o.delivery_service, '') AS delivery_service,
+       COALESCE(d.region, '') AS region,
+       d.city,
+       count(*) AS orders,
+       sum(p.amount) AS revenue,
+       sum(COALESCE(i.items, 0)) AS items
+FROM "order" o
+    JOIN payment p ON p.id = o.payment_id
+    JOIN delivery d ON d.id = o.delivery_id
+    LEFT JOIN (SELECT order_uid, count(*) AS items FROM item GROUP BY order_uid) i ON i.order_uid = o.uid
+WHERE o.deleted_at IS NULL
+GROUP BY 1, 2, 3, 4, 5;
+CREATE UNIQUE INDEX order_stats_daily_key ON order_stats_daily (day, currency, delivery_service, region, city);
+
+DROP MATERIALIZED VIEW IF EXISTS item_stats_daily;
+CREATE MATERIALIZED VIEW item_stats_daily AS
+SELECT (o.date_created AT TIME ZONE 'UTC')::date AS day,
+       COALESCE(i.brand, '') AS brand,
+       i.name,
+       count(*) AS quantity,
+       sum(i.total_price) AS revenue
+FROM item i
+    JOIN "order" o ON o.uid = i.order_uid
+WHERE o.deleted_at IS NULL
+GROUP BY 1, 2, 3;
+CREATE UNIQUE INDEX item_stats_daily_key ON item_stats_daily (day, brand, name);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP MATERIALIZED VIEW IF EXISTS item_stats_daily;
+CREATE MATERIALIZED VIEW item_stats_daily AS
+SELECT (o.date_created AT TIME ZONE 'UTC')::date AS day,
+       COALESCE(i.brand, '') AS brand,
+       i.name,
+       count(*) AS quantity,
+       sum(i.total_price) AS revenue
+FROM item i
+    JOIN "order" o ON o.uid = i.order_uid
+GROUP BY 1, 2, 3;
+CREATE UNIQUE INDEX item_stats_daily_key ON item_stats_daily (day, brand, name);
+
+DROP MATERIALIZED VIEW IF EXISTS order_stats_daily;
+CREATE MATERIALIZED VIEW order_stats_daily AS
+SELECT (o.date_created AT TIME ZONE 'UTC')::date AS day,
+       p.currency,
+       COALESCE(o.delivery_service, '') AS delivery_service,
+       COALESCE(d.region, '') AS region,
+       d.city,
+       count(*) AS orders,
+       sum(p.amount) AS revenue,
+       sum(COALESCE(i.items, 0)) AS items
+FROM "order" o
+    JOIN payment p ON p.id = o.payment_id
+    JOIN delivery d ON d.id = o.delivery_id
+    LEFT JOIN (SELECT order_uid, count(*) AS items FROM item GROUP BY order_uid) i ON i.order_uid = o.uid
+GROUP BY 1, 2, 3, 4, 5;
+CREATE UNIQUE INDEX order_stats_daily_key ON order_stats_daily (day, currency, delivery_service, region, city);
+
+DROP TABLE IF EXISTS audit_log;
+DROP INDEX IF EXISTS order_deleted_at_idx;
+ALTER TABLE "order" DROP COLUMN IF EXISTS deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,99 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"log"
+	"orderService/internal/auth"
+	"orderService/internal/privacy"
+	"orderService/rpc/orderpb"
+)
+
+type principalKey struct{}
+
+// methodScopes lists the scope required by every OrderService method. Other services (health, reflection) are public
+var methodScopes = map[string]string{
+	orderpb.OrderService_GetOrder_FullMethodName:    auth.ScopeOrdersRead,
+	orderpb.OrderService_ListOrders_FullMethodName:  auth.ScopeOrdersRead,
+	orderpb.OrderService_WatchOrders_FullMethodName: auth.ScopeOrdersRead,
+	orderpb.OrderService_CreateOrder_FullMethodName: auth.ScopeOrdersWrite,
+}
+
+func authenticate(ctx context.Context, authenticator auth.Authenticator, method string) (context.Context, error) {
+	scope, ok := methodScopes[method]
+	if !ok {
+		return ctx, nil
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	principal, err := authenticator.Authenticate(first(md.Get("x-api-key")), first(md.Get("authorization")))
+	if err != nil {
+		if errors.Is(err, auth.ErrUnauthenticated) {
+			return nil, status.Error(codes.Unauthenticated, auth.ErrUnauthenticated.Error())
+		}
+		log.Printf("Authentication failed: %v", err)
+		return nil, status.Error(codes.Internal, "authentication failed")
+	}
+
+	if !principal.HasScope(scope) {
+		return nil, status.Errorf(codes.PermissionDenied, "scope %s is required", scope)
+	}
+
+	return context.WithValue(ctx, principalKey{}, principal), nil
+}
+
+func unaryAuthInterceptor(authenticator auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, authenticator, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func streamAuthInterceptor(authenticator auth.Authenticator) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), authenticator, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+func roles(ctx context.Context) []string {
+	principal, _ := ctx.Value(principalKey{}).(auth.Principal)
+	return principal.Roles
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func viewer(ctx context.Context) privacy.Viewer {
+	return privacy.Viewer{Actor: actor(ctx), Roles: roles(ctx)}
+}
+
+func actor(ctx context.Context) string {
+	if principal, ok := ctx.Value(principalKey{}).(auth.Principal); ok {
+		return principal.Actor()
+	}
+	return auth.Anonymous.Actor()
+}
@@ -0,0 +1,53 @@
+package rpc
+
+import (
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"orderService/internal/events"
+	"orderService/internal/models"
+	"orderService/rpc/orderpb"
+)
+
+func toOrderViewPb(view models.OrderView) *orderpb.OrderView {
+	items := make([]*orderpb.ItemView, 0, len(view.Items))
+	for _, item := range view.Items {
+		items = append(items, &orderpb.ItemView{
+			Name:       item.Name,
+			TotalPrice: int64(item.TotalPrice),
+			Brand:      item.Brand,
+		})
+	}
+
+	return &orderpb.OrderView{
+		Uid:             view.Uid.String(),
+		TrackNumber:     view.TrackNumber,
+		CustomerId:      view.CustomerID,
+		DeliveryService: view.DeliveryService,
+		DateCreated:     timestamppb.New(view.DateCreated),
+		Delivery: &orderpb.DeliveryView{
+			Name:    view.Delivery.Name,
+			Phone:   view.Delivery.Phone,
+			Zip:     view.Delivery.Zip,
+			City:    view.Delivery.City,
+			Address: view.Delivery.Address,
+			Region:  view.Delivery.Region,
+			Email:   view.Delivery.Email,
+		},
+		Payment: &orderpb.PaymentView{
+			Currency:     view.Payment.Currency,
+			Provider:     view.Payment.Provider,
+			Amount:       int64(view.Payment.Amount),
+			DeliveryCost: int64(view.Payment.DeliveryCost),
+			GoodsTotal:   int64(view.Payment.GoodsTotal),
+		},
+		Items: items,
+	}
+}
+
+func toOrderEventPb(event events.Event, view models.OrderView) *orderpb.OrderEvent {
+	return &orderpb.OrderEvent{
+		Id:    event.ID,
+		Type:  string(event.Type),
+		Order: toOrderViewPb(view),
+		Time:  timestamppb.New(event.Time),
+	}
+}
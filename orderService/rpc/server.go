@@ -0,0 +1,170 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+	"log"
+	"orderService/internal/auth"
+	"orderService/internal/events"
+	"orderService/internal/mapping"
+	"orderService/internal/models"
+	"orderService/internal/privacy"
+	"orderService/internal/repository"
+	"orderService/internal/service"
+	"orderService/rpc/orderpb"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+	watchBuffer     = 64
+)
+
+type OrderServer struct {
+	orderpb.UnimplementedOrderServiceServer
+	service   service.IOrderService
+	projector privacy.Projector
+	bus       *events.Bus
+}
+
+// NewServer creates the gRPC server with the OrderService, health and reflection services registered
+func NewServer(service service.IOrderService, projector privacy.Projector, bus *events.Bus, authenticator auth.Authenticator) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unaryAuthInterceptor(authenticator)),
+		grpc.ChainStreamInterceptor(streamAuthInterceptor(authenticator)),
+	)
+
+	orderpb.RegisterOrderServiceServer(server, &OrderServer{service: service, projector: projector, bus: bus})
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(orderpb.OrderService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	reflection.Register(server)
+	return server
+}
+
+func (s *OrderServer) GetOrder(ctx context.Context, req *orderpb.GetOrderRequest) (*orderpb.OrderView, error) {
+	uid, err := uuid.Parse(req.GetUid())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "uid is not UUID format")
+	}
+
+	order, err := s.service.GetById(uid)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toOrderViewPb(s.projector.Project(order, viewer(ctx))), nil
+}
+
+func (s *OrderServer) ListOrders(ctx context.Context, req *orderpb.ListOrdersRequest) (*orderpb.ListOrdersResponse, error) {
+	if req.GetCustomerId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "customer_id is required")
+	}
+
+	pageSize := int(req.GetPageSize())
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize < 0 || pageSize > maxPageSize {
+		return nil, status.Errorf(codes.InvalidArgument, "page_size must be between 1 and %d", maxPageSize)
+	}
+
+	var after *models.OrderCursor
+	if req.GetPageToken() != "" {
+		cursor, err := models.DecodeOrderCursor(req.GetPageToken())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "page_token is not valid")
+		}
+		after = &cursor
+	}
+
+	orders, err := s.service.ListByCustomer(req.GetCustomerId(), after, pageSize+1)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	response := &orderpb.ListOrdersResponse{}
+	if len(orders) > pageSize {
+		orders = orders[:pageSize]
+		response.NextPageToken = models.NewOrderCursor(orders[pageSize-1]).Encode()
+	}
+
+	uids := make([]uuid.UUID, 0, len(orders))
+	for _, order := range orders {
+		uids = append(uids, order.Uid)
+	}
+	items, err := s.service.GetItems(uids)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	views := make([]models.OrderView, 0, len(orders))
+	for _, order := range orders {
+		order.Items = items[order.Uid]
+		views = append(views, order.ToOrderView())
+	}
+	s.projector.ProjectViews(views, viewer(ctx))
+	for _, view := range views {
+		response.Orders = append(response.Orders, toOrderViewPb(view))
+	}
+	return response, nil
+}
+
+func (s *OrderServer) CreateOrder(ctx context.Context, req *orderpb.CreateOrderRequest) (*orderpb.CreateOrderResponse, error) {
+	if req.GetOrder() == nil {
+		return nil, status.Error(codes.InvalidArgument, "order is required")
+	}
+
+	order := mapping.ToOrder(req.GetOrder())
+	if err := s.service.Create(order, actor(ctx)); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &orderpb.CreateOrderResponse{Uid: order.Uid.String()}, nil
+}
+
+func (s *OrderServer) WatchOrders(req *orderpb.WatchOrdersRequest, stream grpc.ServerStreamingServer[orderpb.OrderEvent]) error {
+	subscription := s.bus.Subscribe(watchBuffer, events.Filter{CustomerID: req.GetCustomerId(), DeliveryService: req.GetDeliveryService()})
+	defer subscription.Close()
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "subscriber is too slow, resubscribe")
+			}
+			if err := stream.Send(toOrderEventPb(event, s.projector.Project(event.Order, viewer(ctx)))); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func toStatus(err error) error {
+	var validationErrors validator.ValidationErrors
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.As(err, &validationErrors):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case repository.IsDuplicateKey(err):
+		return status.Error(codes.AlreadyExists, "order already exists")
+	default:
+		log.Printf("gRPC request failed: %v", err)
+		return status.Error(codes.Internal, "internal error")
+	}
+}
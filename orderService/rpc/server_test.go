@@ -0,0 +1,255 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"gorm.io/gorm"
+	"net"
+	"orderService/configs"
+	"orderService/internal/auth"
+	"orderService/internal/events"
+	"orderService/internal/mapping"
+	"orderService/internal/models"
+	"orderService/internal/privacy"
+	"orderService/internal/service/mocks"
+	"orderService/rpc/orderpb"
+	"testing"
+	"time"
+)
+
+var (
+	uid         = uuid.MustParse("1e9ad4fb-2615-46f9-9458-20b59253086b")
+	dateCreated = time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC)
+	validOrder  = models.Order{
+		Uid:             uid,
+		TrackNumber:     "WBILMTESTTRACK",
+		Entry:           "WBIL",
+		CustomerID:      "100900",
+		DeliveryService: "meest",
+		ShardKey:        "2",
+		SmID:            99,
+		DateCreated:     dateCreated,
+		OofShard:        "1",
+		Delivery: models.Delivery{
+			Name:    "Test Testov",
+			Phone:   "+9720000000",
+			Zip:     "2639809",
+			City:    "Moscow",
+			Address: "Prospekt Mira 15",
+			Region:  "Moscow",
+			Email:   "test@gmail.com",
+		},
+		Payment: models.Payment{
+			Transaction:  "b563feb7b2b84b6test",
+			Currency:     "USD",
+			Provider:     "wbpay",
+			Amount:       1817,
+			PaymentDt:    1637907727,
+			Bank:         "alpha",
+			DeliveryCost: 1500,
+			GoodsTotal:   317,
+		},
+		Items: []models.Item{{
+			ChrtID:      9934930,
+			TrackNumber: "WBILMTESTTRACK",
+			Name:        "Mascaras",
+			Price:       317,
+			TotalPrice:  317,
+			RID:         "ab4219087a764ae0btest",
+			Brand:       "Vivienne Sabo",
+			NmID:        2389212,
+			Status:      202,
+		}},
+	}
+)
+
+// startServer serves the order service, audit may be nil when reads are not asserted
+func startServer(t *testing.T, orderService *mocks.IOrderService, bus *events.Bus, audit privacy.Auditor) *grpc.ClientConn {
+	staticKeys, err := auth.NewStaticKeyStore([]string{
+		fmt.Sprintf("reader;%s;orders:read;admin", auth.HashKey("reader-key")),
+		fmt.Sprintf("writer;%s;orders:write", auth.HashKey("writer-key")),
+	})
+	require.NoError(t, err)
+	authenticator, err := auth.NewAuthenticator(configs.Auth{Enabled: true}, staticKeys)
+	require.NoError(t, err)
+
+	projector := privacy.NewProjector(privacy.Policy{privacy.FieldPhone: {"admin"}}, audit)
+	server := NewServer(orderService, projector, bus, authenticator)
+
+	listener := bufconn.Listen(1024 * 1024)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+func withApiKey(key string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "x-api-key", key)
+}
+
+func TestOrderServer_GetOrder(t *testing.T) {
+	mockOrderService := new(mocks.IOrderService)
+	mockOrderService.On("GetById", uid).Return(validOrder.ToOrderView(), nil)
+	mockOrderService.On("GetById", mock.Anything).Return(models.OrderView{}, gorm.ErrRecordNotFound)
+	mockAudit := new(mocks.IAuditService)
+	mockAudit.On("Record", mock.Anything).Return()
+	client := orderpb.NewOrderServiceClient(startServer(t, mockOrderService, events.NewBus(0), mockAudit))
+
+	t.Run("Success", func(t *testing.T) {
+		order, err := client.GetOrder(withApiKey("reader-key"), &orderpb.GetOrderRequest{Uid: uid.String()})
+
+		require.NoError(t, err)
+		assert.Equal(t, uid.String(), order.GetUid())
+		assert.Equal(t, "+9720000000", order.GetDelivery().GetPhone())
+		assert.Equal(t, "t***@gmail.com", order.GetDelivery().GetEmail())
+		assert.Equal(t, dateCreated, order.GetDateCreated().AsTime())
+		assert.Equal(t, "Vivienne Sabo", order.GetItems()[0].GetBrand())
+	})
+
+	t.Run("UnmaskedReadIsAudited", func(t *testing.T) {
+		mockAudit.AssertNumberOfCalls(t, "Record", 1)
+		entry := mockAudit.Calls[0].Arguments.Get(0).(models.AuditEntry)
+		assert.Equal(t, models.AuditOrderViewed, entry.Action)
+		assert.Equal(t, "api_key:reader", entry.Actor)
+		assert.Equal(t, uid, *entry.OrderUid)
+		assert.JSONEq(t, `{"unmasked":["phone"]}`, string(entry.Details))
+	})
+
+	tableData := []struct {
+		name   string
+		ctx    context.Context
+		uid    string
+		code   codes.Code
+		errMsg string
+	}{
+		{name: "Unauthenticated", ctx: context.Background(), uid: uid.String(), code: codes.Unauthenticated, errMsg: "missing or invalid credentials"},
+		{name: "MissingScope", ctx: withApiKey("writer-key"), uid: uid.String(), code: codes.PermissionDenied, errMsg: "scope orders:read is required"},
+		{name: "InvalidUid", ctx: withApiKey("reader-key"), uid: "1", code: codes.InvalidArgument, errMsg: "uid is not UUID format"},
+		{name: "NotFound", ctx: withApiKey("reader-key"), uid: uuid.NewString(), code: codes.NotFound, errMsg: "record not found"},
+	}
+
+	for _, td := range tableData {
+		t.Run(td.name, func(t *testing.T) {
+			_, err := client.GetOrder(td.ctx, &orderpb.GetOrderRequest{Uid: td.uid})
+
+			assert.Equal(t, td.code, status.Code(err))
+			assert.Equal(t, td.errMsg, status.Convert(err).Message())
+		})
+	}
+}
+
+func TestOrderServer_ListOrders(t *testing.T) {
+	second := validOrder
+	second.Uid = uuid.New()
+	second.DateCreated = dateCreated.Add(-time.Hour)
+	first := validOrder
+	first.Items = nil
+
+	mockOrderService := new(mocks.IOrderService)
+	mockOrderService.On("ListByCustomer", "100900", (*models.OrderCursor)(nil), 2).Return([]models.Order{first, second}, nil)
+	mockOrderService.On("GetItems", []uuid.UUID{uid}).Return(map[uuid.UUID][]models.Item{uid: validOrder.Items}, nil)
+	client := orderpb.NewOrderServiceClient(startServer(t, mockOrderService, events.NewBus(0), nil))
+
+	response, err := client.ListOrders(withApiKey("reader-key"), &orderpb.ListOrdersRequest{CustomerId: "100900", PageSize: 1})
+
+	require.NoError(t, err)
+	require.Len(t, response.GetOrders(), 1)
+	assert.Equal(t, uid.String(), response.GetOrders()[0].GetUid())
+	assert.Len(t, response.GetOrders()[0].GetItems(), 1)
+	assert.Equal(t, models.NewOrderCursor(validOrder).Encode(), response.GetNextPageToken())
+}
+
+func TestOrderServer_CreateOrder(t *testing.T) {
+	mockOrderService := new(mocks.IOrderService)
+	mockOrderService.On("Create", validOrder, "api_key:writer").Return(nil)
+	client := orderpb.NewOrderServiceClient(startServer(t, mockOrderService, events.NewBus(0), nil))
+
+	t.Run("Success", func(t *testing.T) {
+		response, err := client.CreateOrder(withApiKey("writer-key"), &orderpb.CreateOrderRequest{Order: mapping.ToOrderPb(validOrder)})
+
+		require.NoError(t, err)
+		assert.Equal(t, uid.String(), response.GetUid())
+		mockOrderService.AssertCalled(t, "Create", validOrder, "api_key:writer")
+	})
+
+	t.Run("ReadScopeIsNotEnough", func(t *testing.T) {
+		_, err := client.CreateOrder(withApiKey("reader-key"), &orderpb.CreateOrderRequest{Order: mapping.ToOrderPb(validOrder)})
+
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("InvalidOrder", func(t *testing.T) {
+		invalid := validOrder
+		invalid.Uid = uuid.New()
+		mockOrderService.On("Create", invalid, "api_key:writer").Return(validator.ValidationErrors{})
+
+		_, err := client.CreateOrder(withApiKey("writer-key"), &orderpb.CreateOrderRequest{Order: mapping.ToOrderPb(invalid)})
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestOrderServer_WatchOrders(t *testing.T) {
+	bus := events.NewBus(0)
+	client := orderpb.NewOrderServiceClient(startServer(t, new(mocks.IOrderService), bus, nil))
+
+	ctx, cancel := context.WithCancel(withApiKey("reader-key"))
+	defer cancel()
+	stream, err := client.WatchOrders(ctx, &orderpb.WatchOrdersRequest{CustomerId: "100900"})
+	require.NoError(t, err)
+
+	// The subscription is created asynchronously, so events are published until the first one is received
+	other := validOrder
+	other.CustomerID = "other"
+	go func() {
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				bus.Publish(events.OrderCreated, other.ToOrderView())
+				bus.Publish(events.OrderCreated, validOrder.ToOrderView())
+			}
+		}
+	}()
+
+	event, err := stream.Recv()
+
+	require.NoError(t, err)
+	assert.Equal(t, string(events.OrderCreated), event.GetType())
+	assert.Equal(t, "100900", event.GetOrder().GetCustomerId())
+}
+
+func TestOrderServer_Health(t *testing.T) {
+	client := healthpb.NewHealthClient(startServer(t, new(mocks.IOrderService), events.NewBus(0), nil))
+
+	response, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: orderpb.OrderService_ServiceDesc.ServiceName})
+
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, response.GetStatus())
+}